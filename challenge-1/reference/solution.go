@@ -0,0 +1,28 @@
+//go:build reference
+
+// Reference solution for Challenge 1. Not shown in the web UI; used by
+// web-ui/cmd/verify-reference to check the tests.
+package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	var a, b int
+	// Read two integers from standard input
+	_, err := fmt.Scanf("%d, %d", &a, &b)
+	if err != nil {
+		fmt.Println("Error reading input:", err)
+		return
+	}
+
+	// Call the Sum function and print the result
+	result := Sum(a, b)
+	fmt.Println(result)
+}
+
+// Sum returns the sum of a and b.
+func Sum(a int, b int) int {
+	return a + b
+}
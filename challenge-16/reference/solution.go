@@ -0,0 +1,190 @@
+//go:build reference
+
+// Reference solution for Challenge 16. Not shown in the web UI; used by
+// web-ui/cmd/verify-reference to check the tests, and as the baseline that
+// benchmark scores are measured against.
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SlowSort sorts a slice of integers using a very inefficient algorithm (bubble sort)
+func SlowSort(data []int) []int {
+	// Make a copy to avoid modifying the original
+	result := make([]int, len(data))
+	copy(result, data)
+
+	// Bubble sort implementation
+	for i := 0; i < len(result)-1; i++ {
+		for j := 0; j < len(result)-1-i; j++ {
+			if result[j] > result[j+1] {
+				result[j], result[j+1] = result[j+1], result[j]
+			}
+		}
+	}
+
+	return result
+}
+
+// OptimizedSort is your optimized version of SlowSort
+// It should produce identical results but perform better
+func OptimizedSort(data []int) []int {
+	result := make([]int, len(data))
+	copy(result, data)
+	sort.Ints(result)
+	return result
+}
+
+// InefficientStringBuilder builds a string by repeatedly concatenating
+func InefficientStringBuilder(parts []string, repeatCount int) string {
+	result := ""
+
+	for i := 0; i < repeatCount; i++ {
+		for _, part := range parts {
+			result += part
+		}
+	}
+
+	return result
+}
+
+// OptimizedStringBuilder is your optimized version of InefficientStringBuilder
+// It should produce identical results but perform better
+func OptimizedStringBuilder(parts []string, repeatCount int) string {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+	if repeatCount <= 0 || size == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.Grow(size * repeatCount)
+	for i := 0; i < repeatCount; i++ {
+		for _, part := range parts {
+			b.WriteString(part)
+		}
+	}
+	return b.String()
+}
+
+// ExpensiveCalculation performs a computation with redundant work
+// It computes the sum of all fibonacci numbers up to n
+func ExpensiveCalculation(n int) int {
+	if n <= 0 {
+		return 0
+	}
+
+	sum := 0
+	for i := 1; i <= n; i++ {
+		sum += fibonacci(i)
+	}
+
+	return sum
+}
+
+// Helper function that computes the fibonacci number at position n
+func fibonacci(n int) int {
+	if n <= 1 {
+		return n
+	}
+	return fibonacci(n-1) + fibonacci(n-2)
+}
+
+// OptimizedCalculation is your optimized version of ExpensiveCalculation
+// It should produce identical results but perform better
+func OptimizedCalculation(n int) int {
+	// The fibonacci numbers are walked once, adding each as it is reached
+	sum := 0
+	prev, curr := 0, 1
+	for i := 1; i <= n; i++ {
+		sum += curr
+		prev, curr = curr, prev+curr
+	}
+	return sum
+}
+
+// HighAllocationSearch searches for all occurrences of a substring and creates a map with their positions
+func HighAllocationSearch(text, substr string) map[int]string {
+	result := make(map[int]string)
+
+	// Convert to lowercase for case-insensitive search
+	lowerText := strings.ToLower(text)
+	lowerSubstr := strings.ToLower(substr)
+
+	for i := 0; i < len(lowerText); i++ {
+		// Check if we can fit the substring starting at position i
+		if i+len(lowerSubstr) <= len(lowerText) {
+			// Extract the potential match
+			potentialMatch := lowerText[i : i+len(lowerSubstr)]
+
+			// Check if it matches
+			if potentialMatch == lowerSubstr {
+				// Store the original case version
+				result[i] = text[i : i+len(substr)]
+			}
+		}
+	}
+
+	return result
+}
+
+// OptimizedSearch is your optimized version of HighAllocationSearch
+// It should produce identical results but perform better with fewer allocations
+func OptimizedSearch(text, substr string) map[int]string {
+	// Lowercasing non-ASCII text can change its length, which shifts the
+	// positions HighAllocationSearch reports; leave that case to it
+	if !isASCII(text) || !isASCII(substr) {
+		return HighAllocationSearch(text, substr)
+	}
+
+	result := make(map[int]string)
+	for i := 0; i < len(text) && i+len(substr) <= len(text); i++ {
+		if equalFoldASCII(text[i:i+len(substr)], substr) {
+			result[i] = text[i : i+len(substr)]
+		}
+	}
+	return result
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// equalFoldASCII compares two ASCII strings of the same length, ignoring case
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		ca, cb := a[i], b[i]
+		if 'A' <= ca && ca <= 'Z' {
+			ca += 'a' - 'A'
+		}
+		if 'A' <= cb && cb <= 'Z' {
+			cb += 'a' - 'A'
+		}
+		if ca != cb {
+			return false
+		}
+	}
+	return true
+}
+
+// A function to simulate CPU-intensive work for benchmarking
+// You don't need to optimize this; it's just used for testing
+func SimulateCPUWork(duration time.Duration) {
+	start := time.Now()
+	for time.Since(start) < duration {
+		// Just waste CPU cycles
+		for i := 0; i < 1000000; i++ {
+			_ = i
+		}
+	}
+}
@@ -13,8 +13,8 @@ func SlowSort(data []int) []int {
 	copy(result, data)
 
 	// Bubble sort implementation
-	for i := 0; i < len(result); i++ {
-		for j := 0; j < len(result)-1; j++ {
+	for i := 0; i < len(result)-1; i++ {
+		for j := 0; j < len(result)-1-i; j++ {
 			if result[j] > result[j+1] {
 				result[j], result[j+1] = result[j+1], result[j]
 			}
@@ -29,7 +29,7 @@ func SlowSort(data []int) []int {
 func OptimizedSort(data []int) []int {
 	// TODO: Implement a more efficient sorting algorithm
 	// Hint: Consider using sort package or a more efficient algorithm
-	return SlowSort(data) // Replace this with your optimized implementation
+	return nil
 }
 
 // InefficientStringBuilder builds a string by repeatedly concatenating
@@ -51,7 +51,7 @@ func InefficientStringBuilder(parts []string, repeatCount int) string {
 func OptimizedStringBuilder(parts []string, repeatCount int) string {
 	// TODO: Implement a more efficient string building method
 	// Hint: Consider using strings.Builder or bytes.Buffer
-	return InefficientStringBuilder(parts, repeatCount) // Replace this with your optimized implementation
+	return ""
 }
 
 // ExpensiveCalculation performs a computation with redundant work
@@ -83,7 +83,7 @@ func fibonacci(n int) int {
 func OptimizedCalculation(n int) int {
 	// TODO: Implement a more efficient calculation method
 	// Hint: Consider memoization or avoiding redundant calculations
-	return ExpensiveCalculation(n) // Replace this with your optimized implementation
+	return 0
 }
 
 // HighAllocationSearch searches for all occurrences of a substring and creates a map with their positions
@@ -117,7 +117,7 @@ func HighAllocationSearch(text, substr string) map[int]string {
 func OptimizedSearch(text, substr string) map[int]string {
 	// TODO: Implement a more efficient search method with fewer allocations
 	// Hint: Consider avoiding temporary string allocations and reusing memory
-	return HighAllocationSearch(text, substr) // Replace this with your optimized implementation
+	return nil
 }
 
 // A function to simulate CPU-intensive work for benchmarking
@@ -43,6 +43,8 @@ func TestSlowSort(t *testing.T) {
 		{"Already Sorted", []int{1, 2, 3, 4, 5}},
 		{"Reverse Sorted", []int{5, 4, 3, 2, 1}},
 		{"Random Order", []int{3, 1, 4, 1, 5, 9, 2, 6}},
+		{"Smallest Last", []int{2, 3, 4, 5, 6, 7, 8, 1}},
+		{"Reverse Sorted Large", reversed(100)},
 	}
 
 	for _, tc := range testCases {
@@ -53,14 +55,27 @@ func TestSlowSort(t *testing.T) {
 			sort.Ints(expected)
 
 			// Test our slow sort
+			input := append([]int(nil), tc.input...)
 			result := SlowSort(tc.input)
 			if !slicesEqual(result, expected) {
 				t.Errorf("SlowSort didn't sort correctly. Got %v, expected %v", result, expected)
 			}
+			if !slicesEqual(tc.input, input) {
+				t.Errorf("SlowSort modified its input: %v, was %v", tc.input, input)
+			}
 		})
 	}
 }
 
+// reversed returns n, n-1, ..., 1: bubble sort needs every pass to sort it
+func reversed(n int) []int {
+	slice := make([]int, n)
+	for i := range slice {
+		slice[i] = n - i
+	}
+	return slice
+}
+
 func TestOptimizedSort(t *testing.T) {
 	testCases := []struct {
 		name  string
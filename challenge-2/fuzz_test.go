@@ -0,0 +1,27 @@
+//go:build fuzzoracle
+
+// Fuzz targets for Challenge 2. They compare a submission with the reference
+// solution, imported as the oracle package, and only build in the web UI's
+// fuzz runs.
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"challenge-2/oracle"
+)
+
+func FuzzReverseString(f *testing.F) {
+	for _, seed := range []string{"", "a", "hello", "Go is fun!", "Голанг 语言", "👋🌍"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if !utf8.ValidString(s) {
+			t.Skip("the challenge reverses text, not arbitrary bytes")
+		}
+		if got, want := ReverseString(s), oracle.ReverseString(s); got != want {
+			t.Errorf("ReverseString(%q) = %q, want %q", s, got, want)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+//go:build fuzzoracle
+
+// Fuzz targets for Challenge 21. They compare a submission with the reference
+// solution, imported as the oracle package, and only build in the web UI's
+// fuzz runs.
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"challenge-21/oracle"
+)
+
+// sortedUnique turns fuzzed bytes into a strictly increasing array, so that
+// every target has exactly one right answer
+func sortedUnique(data []byte) []int {
+	seen := make(map[int]bool)
+	arr := []int{}
+	for _, b := range data {
+		v := int(b) - 128
+		if !seen[v] {
+			seen[v] = true
+			arr = append(arr, v)
+		}
+	}
+	sort.Ints(arr)
+	return arr
+}
+
+func addSeeds(f *testing.F) {
+	f.Add([]byte{}, 0)
+	f.Add([]byte{128}, 0)
+	f.Add([]byte{129, 131, 133, 135, 137}, 5)
+	f.Add([]byte{129, 131, 133, 135, 137}, 4)
+	f.Add([]byte{10, 200, 100, 50}, -200)
+}
+
+func FuzzBinarySearch(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, target int) {
+		arr := sortedUnique(data)
+		if got, want := BinarySearch(arr, target), oracle.BinarySearch(arr, target); got != want {
+			t.Errorf("BinarySearch(%v, %d) = %d, want %d", arr, target, got, want)
+		}
+	})
+}
+
+func FuzzBinarySearchRecursive(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, target int) {
+		arr := sortedUnique(data)
+		got := BinarySearchRecursive(arr, target, 0, len(arr)-1)
+		want := oracle.BinarySearchRecursive(arr, target, 0, len(arr)-1)
+		if got != want {
+			t.Errorf("BinarySearchRecursive(%v, %d, 0, %d) = %d, want %d", arr, target, len(arr)-1, got, want)
+		}
+	})
+}
+
+func FuzzFindInsertPosition(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte, target int) {
+		arr := sortedUnique(data)
+		if got, want := FindInsertPosition(arr, target), oracle.FindInsertPosition(arr, target); got != want {
+			t.Errorf("FindInsertPosition(%v, %d) = %d, want %d", arr, target, got, want)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestHiddenBinarySearchLargeArray(t *testing.T) {
+	arr := make([]int, 100001)
+	for i := range arr {
+		arr[i] = 2*i - 100000
+	}
+
+	for _, i := range []int{0, 1, 49999, 50000, 50001, 99999, 100000} {
+		if result := BinarySearch(arr, arr[i]); result != i {
+			t.Errorf("BinarySearch(arr, %d) = %d, expected %d", arr[i], result, i)
+		}
+		if result := BinarySearchRecursive(arr, arr[i], 0, len(arr)-1); result != i {
+			t.Errorf("BinarySearchRecursive(arr, %d, 0, %d) = %d, expected %d", arr[i], len(arr)-1, result, i)
+		}
+	}
+	for _, target := range []int{-100002, -99999, 1, 99999, 100002} {
+		if result := BinarySearch(arr, target); result != -1 {
+			t.Errorf("BinarySearch(arr, %d) = %d, expected -1", target, result)
+		}
+		if result := BinarySearchRecursive(arr, target, 0, len(arr)-1); result != -1 {
+			t.Errorf("BinarySearchRecursive(arr, %d, 0, %d) = %d, expected -1", target, len(arr)-1, result)
+		}
+	}
+}
+
+func TestHiddenDuplicates(t *testing.T) {
+	tests := []struct {
+		name   string
+		arr    []int
+		target int
+	}{
+		{"All equal", []int{4, 4, 4, 4, 4}, 4},
+		{"Run in the middle", []int{1, 2, 3, 3, 3, 3, 8, 9}, 3},
+		{"Run at the start", []int{-5, -5, -5, 0, 7}, -5},
+		{"Run at the end", []int{0, 1, 2, 6, 6}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Any index of the target is a correct answer
+			if result := BinarySearch(tt.arr, tt.target); result < 0 || result >= len(tt.arr) || tt.arr[result] != tt.target {
+				t.Errorf("BinarySearch(%v, %d) = %d, expected an index of %d", tt.arr, tt.target, result, tt.target)
+			}
+			result := BinarySearchRecursive(tt.arr, tt.target, 0, len(tt.arr)-1)
+			if result < 0 || result >= len(tt.arr) || tt.arr[result] != tt.target {
+				t.Errorf("BinarySearchRecursive(%v, %d, 0, %d) = %d, expected an index of %d",
+					tt.arr, tt.target, len(tt.arr)-1, result, tt.target)
+			}
+		})
+	}
+}
+
+func TestHiddenFindInsertPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		arr      []int
+		target   int
+		expected int
+	}{
+		{"Negative numbers", []int{-9, -7, -3, -1}, -4, 2},
+		{"Below all negatives", []int{-9, -7, -3, -1}, -10, 0},
+		{"Above all negatives", []int{-9, -7, -3, -1}, 0, 4},
+		{"Between two elements", []int{1, 2}, 2, 1},
+		{"Odd length, insert before last", []int{10, 20, 30}, 25, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FindInsertPosition(tt.arr, tt.target)
+			if result != tt.expected {
+				t.Errorf("FindInsertPosition(%v, %d) = %d, expected %d", tt.arr, tt.target, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHiddenFindInsertPositionKeepsOrder(t *testing.T) {
+	arr := []int{1, 4, 9, 16, 25, 36, 49, 64, 81}
+	for target := 0; target <= 82; target++ {
+		pos := FindInsertPosition(arr, target)
+		if pos < 0 || pos > len(arr) {
+			t.Fatalf("FindInsertPosition(%v, %d) = %d, out of range", arr, target, pos)
+		}
+		if (pos > 0 && arr[pos-1] > target) || (pos < len(arr) && arr[pos] < target) {
+			t.Errorf("FindInsertPosition(%v, %d) = %d, inserting there breaks the order", arr, target, pos)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module challenge31
+
+go 1.22.10
@@ -0,0 +1,125 @@
+//go:build reference
+
+// Reference solution for Challenge 31. In this write-tests challenge it is the
+// code under test: the web UI shows it read-only, and a submission is graded by
+// how many of its mutants the submitted tests catch.
+package collections
+
+// Ordered is the set of types that support < and >
+type Ordered interface {
+	~int | ~int64 | ~float64 | ~string
+}
+
+// Number is the set of types that can be summed
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Sum returns the sum of values, or zero for an empty slice.
+func Sum[T Number](values []T) T {
+	var total T
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Clamp limits v to the range [lo, hi].
+func Clamp[T Ordered](v, lo, hi T) T {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// IndexOf returns the index of the first element equal to target, or -1 if
+// there is none.
+func IndexOf[T comparable](items []T, target T) int {
+	for i, item := range items {
+		if item == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// Filter returns the elements for which keep returns true, in order. It never
+// returns nil.
+func Filter[T any](items []T, keep func(T) bool) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if keep(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Chunk splits items into consecutive slices of size elements; the last one
+// may be shorter. It returns nil if size is less than 1.
+func Chunk[T any](items []T, size int) [][]T {
+	if size < 1 {
+		return nil
+	}
+	var chunks [][]T
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		chunks = append(chunks, items[start:end])
+	}
+	return chunks
+}
+
+// MaxBy returns the element with the largest key. When several share it, the
+// first one wins. ok is false for an empty slice.
+func MaxBy[T any, K Ordered](items []T, key func(T) K) (best T, ok bool) {
+	if len(items) == 0 {
+		return best, false
+	}
+	best, bestKey := items[0], key(items[0])
+	for _, item := range items[1:] {
+		if k := key(item); k > bestKey {
+			best, bestKey = item, k
+		}
+	}
+	return best, true
+}
+
+// Stack is a last-in, first-out stack. The zero value is an empty stack.
+type Stack[T any] struct {
+	items []T
+}
+
+// Push adds v to the top of the stack.
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v)
+}
+
+// Pop removes and returns the top element. ok is false if the stack is empty.
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	if len(s.items) == 0 {
+		return v, false
+	}
+	v = s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return v, true
+}
+
+// Peek returns the top element without removing it. ok is false if the stack
+// is empty.
+func (s *Stack[T]) Peek() (v T, ok bool) {
+	if len(s.items) == 0 {
+		return v, false
+	}
+	return s.items[len(s.items)-1], true
+}
+
+// Len returns the number of elements on the stack.
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
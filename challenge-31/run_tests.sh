@@ -0,0 +1,25 @@
+#!/bin/bash
+
+# Script to grade a participant's tests for this write-tests challenge
+
+# Verify that we are in a challenge directory
+if [ ! -f "reference/solution.go" ]; then
+    echo "Error: reference/solution.go not found. Please run this script from the challenge-31 directory."
+    exit 1
+fi
+
+# Prompt for GitHub username
+read -p "Enter your GitHub username: " USERNAME
+
+SUBMISSION_FILE="submissions/$USERNAME/solution-template_test.go"
+
+# Check if the submission file exists
+if [ ! -f "$SUBMISSION_FILE" ]; then
+    echo "Error: Test file '$SUBMISSION_FILE' not found."
+    exit 1
+fi
+
+echo "Grading tests for user '$USERNAME'..."
+
+# The grader runs the tests against the code under test and its mutants
+(cd ../web-ui && go run ./cmd/grade-tests -challenge 31 -user "$USERNAME")
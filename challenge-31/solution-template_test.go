@@ -0,0 +1,28 @@
+package collections
+
+import (
+	"testing"
+)
+
+// TestSum is an example to start from. Your tests are graded on how many
+// deliberately broken copies of the code under test they catch, so check
+// edge cases and exact results, not just that nothing panics.
+func TestSum(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   int
+	}{
+		{"three values", []int{1, 2, 3}, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sum(tt.values); got != tt.want {
+				t.Errorf("Sum(%v) = %d, want %d", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+// TODO: test Clamp, IndexOf, Filter, Chunk, MaxBy and Stack
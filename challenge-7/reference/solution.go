@@ -0,0 +1,135 @@
+//go:build reference
+
+// Reference solution for Challenge 7. Not shown in the web UI; used by
+// web-ui/cmd/verify-reference to check the tests.
+package challenge7
+
+import (
+	"fmt"
+	"sync"
+)
+
+// BankAccount represents a bank account with balance management and minimum balance requirements.
+type BankAccount struct {
+	ID         string
+	Owner      string
+	Balance    float64
+	MinBalance float64
+	mu         sync.Mutex // For thread safety
+}
+
+// Constants for account operations
+const (
+	MaxTransactionAmount = 10000.0 // Example limit for deposits/withdrawals
+)
+
+// AccountError is a general error type for bank account operations.
+type AccountError struct {
+	Field   string
+	Message string
+}
+
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+// InsufficientFundsError occurs when a withdrawal or transfer would bring the balance below minimum.
+type InsufficientFundsError struct {
+	Balance    float64
+	MinBalance float64
+	Amount     float64
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds: %.2f from a balance of %.2f would go below the minimum of %.2f",
+		e.Amount, e.Balance, e.MinBalance)
+}
+
+// NegativeAmountError occurs when an amount for deposit, withdrawal, or transfer is negative.
+type NegativeAmountError struct {
+	Amount float64
+}
+
+func (e *NegativeAmountError) Error() string {
+	return fmt.Sprintf("amount must not be negative: %.2f", e.Amount)
+}
+
+// ExceedsLimitError occurs when a deposit or withdrawal amount exceeds the defined limit.
+type ExceedsLimitError struct {
+	Amount float64
+	Limit  float64
+}
+
+func (e *ExceedsLimitError) Error() string {
+	return fmt.Sprintf("amount %.2f exceeds the limit of %.2f", e.Amount, e.Limit)
+}
+
+// NewBankAccount creates a new bank account with the given parameters.
+// It returns an error if any of the parameters are invalid.
+func NewBankAccount(id, owner string, initialBalance, minBalance float64) (*BankAccount, error) {
+	if id == "" {
+		return nil, &AccountError{Field: "id", Message: "must not be empty"}
+	}
+	if owner == "" {
+		return nil, &AccountError{Field: "owner", Message: "must not be empty"}
+	}
+	if initialBalance < 0 {
+		return nil, &NegativeAmountError{Amount: initialBalance}
+	}
+	if minBalance < 0 {
+		return nil, &NegativeAmountError{Amount: minBalance}
+	}
+	if initialBalance < minBalance {
+		return nil, &InsufficientFundsError{Balance: initialBalance, MinBalance: minBalance}
+	}
+	return &BankAccount{ID: id, Owner: owner, Balance: initialBalance, MinBalance: minBalance}, nil
+}
+
+// checkAmount validates an amount against the sign and transaction limit rules.
+func checkAmount(amount float64) error {
+	if amount < 0 {
+		return &NegativeAmountError{Amount: amount}
+	}
+	if amount > MaxTransactionAmount {
+		return &ExceedsLimitError{Amount: amount, Limit: MaxTransactionAmount}
+	}
+	return nil
+}
+
+// Deposit adds the specified amount to the account balance.
+// It returns an error if the amount is invalid or exceeds the transaction limit.
+func (a *BankAccount) Deposit(amount float64) error {
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.Balance += amount
+	return nil
+}
+
+// Withdraw removes the specified amount from the account balance.
+// It returns an error if the amount is invalid, exceeds the transaction limit,
+// or would bring the balance below the minimum required balance.
+func (a *BankAccount) Withdraw(amount float64) error {
+	if err := checkAmount(amount); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Balance-amount < a.MinBalance {
+		return &InsufficientFundsError{Balance: a.Balance, MinBalance: a.MinBalance, Amount: amount}
+	}
+	a.Balance -= amount
+	return nil
+}
+
+// Transfer moves the specified amount from this account to the target account.
+// It returns an error if the amount is invalid, exceeds the transaction limit,
+// or would bring the balance below the minimum required balance.
+func (a *BankAccount) Transfer(amount float64, target *BankAccount) error {
+	if err := a.Withdraw(amount); err != nil {
+		return err
+	}
+	return target.Deposit(amount)
+}
@@ -0,0 +1,114 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// InventoryService implements the Inventory service from inventory.proto
+type InventoryService struct {
+	// Answers Unimplemented for any RPC added to the service later
+	UnimplementedInventoryServer
+
+	mu    sync.Mutex
+	items map[string]*Item
+}
+
+// NewInventoryService creates an empty inventory
+func NewInventoryService() *InventoryService {
+	return &InventoryService{items: make(map[string]*Item)}
+}
+
+// AddItem creates an item. The SKU and name are required, the quantity
+// can't be negative, and the SKU must be new.
+func (s *InventoryService) AddItem(ctx context.Context, req *AddItemRequest) (*Item, error) {
+	sku, name := strings.TrimSpace(req.GetSku()), strings.TrimSpace(req.GetName())
+	switch {
+	case sku == "":
+		return nil, status.Error(codes.InvalidArgument, "sku is required")
+	case name == "":
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	case req.GetQuantity() < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "quantity %d is negative", req.GetQuantity())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[sku]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "item %q already exists", sku)
+	}
+	item := &Item{Sku: sku, Name: name, Quantity: req.GetQuantity()}
+	s.items[sku] = item
+	return cloneItem(item), nil
+}
+
+// GetItem returns the item with a SKU
+func (s *InventoryService) GetItem(ctx context.Context, req *GetItemRequest) (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[req.GetSku()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "item %q not found", req.GetSku())
+	}
+	return cloneItem(item), nil
+}
+
+// AdjustStock adds delta to an item's quantity. A change that would take the
+// quantity below zero fails without changing it.
+func (s *InventoryService) AdjustStock(ctx context.Context, req *AdjustStockRequest) (*Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[req.GetSku()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "item %q not found", req.GetSku())
+	}
+	if item.Quantity+req.GetDelta() < 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "only %d of %q in stock", item.Quantity, item.Sku)
+	}
+	item.Quantity += req.GetDelta()
+	return cloneItem(item), nil
+}
+
+// cloneItem copies an item, so gRPC can marshal the response while another
+// call changes the stored one
+func cloneItem(item *Item) *Item {
+	return &Item{Sku: item.Sku, Name: item.Name, Quantity: item.Quantity}
+}
+
+// NewGRPCServer returns a gRPC server with a new InventoryService registered
+func NewGRPCServer() *grpc.Server {
+	srv := grpc.NewServer()
+	RegisterInventoryServer(srv, NewInventoryService())
+	return srv
+}
+
+// Dial connects a client to the server listening on lis, an in-memory
+// listener: the connection never touches the network
+func Dial(lis *bufconn.Listener) (*grpc.ClientConn, error) {
+	return grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Inventory service listening on :50051")
+	log.Fatal(NewGRPCServer().Serve(lis))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// InventoryService implements the Inventory service from inventory.proto
+type InventoryService struct {
+	// Answers Unimplemented for any RPC added to the service later
+	UnimplementedInventoryServer
+
+	mu    sync.Mutex
+	items map[string]*Item
+}
+
+// NewInventoryService creates an empty inventory
+func NewInventoryService() *InventoryService {
+	return &InventoryService{items: make(map[string]*Item)}
+}
+
+// AddItem creates an item. The SKU and name are required, the quantity
+// can't be negative, and the SKU must be new.
+func (s *InventoryService) AddItem(ctx context.Context, req *AddItemRequest) (*Item, error) {
+	// TODO: Return codes.InvalidArgument for a blank SKU or name or a negative quantity
+	// TODO: Return codes.AlreadyExists if the SKU is taken
+	// TODO: Store the item and return a copy of it
+	return nil, nil
+}
+
+// GetItem returns the item with a SKU
+func (s *InventoryService) GetItem(ctx context.Context, req *GetItemRequest) (*Item, error) {
+	// TODO: Return a copy of the item, or codes.NotFound
+	return nil, nil
+}
+
+// AdjustStock adds delta to an item's quantity. A change that would take the
+// quantity below zero fails without changing it.
+func (s *InventoryService) AdjustStock(ctx context.Context, req *AdjustStockRequest) (*Item, error) {
+	// TODO: Return codes.NotFound for an unknown SKU
+	// TODO: Return codes.FailedPrecondition if the quantity would go below zero
+	// TODO: Apply the change and return a copy of the item
+	return nil, nil
+}
+
+// NewGRPCServer returns a gRPC server with a new InventoryService registered
+func NewGRPCServer() *grpc.Server {
+	// TODO: Create a server and register an InventoryService on it
+	return nil
+}
+
+// Dial connects a client to the server listening on lis, an in-memory
+// listener: the connection never touches the network
+func Dial(lis *bufconn.Listener) (*grpc.ClientConn, error) {
+	// TODO: Create a client whose dialer calls lis.DialContext, without TLS
+	return nil, nil
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Inventory service listening on :50051")
+	log.Fatal(NewGRPCServer().Serve(lis))
+}
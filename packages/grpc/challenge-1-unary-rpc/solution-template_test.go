@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves NewGRPCServer on an in-memory listener and returns a
+// client connected to it through Dial
+func newClient(t *testing.T) InventoryClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer()
+	if srv == nil {
+		t.Fatal("NewGRPCServer returned nil")
+	}
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := Dial(lis)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	if conn == nil {
+		t.Fatal("Dial returned nil")
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewInventoryClient(conn)
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// wantCode fails the test unless err is a gRPC status error with code
+func wantCode(t *testing.T, call string, err error, code codes.Code) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s succeeded, want %s", call, code)
+		return
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Errorf("%s error %v is not a gRPC status", call, err)
+		return
+	}
+	if st.Code() != code {
+		t.Errorf("%s code = %s (%s), want %s", call, st.Code(), st.Message(), code)
+	}
+}
+
+func TestAddAndGetItem(t *testing.T) {
+	client, ctx := newClient(t), testContext(t)
+
+	item, err := client.AddItem(ctx, &AddItemRequest{Sku: "GO-101", Name: "Gopher plush", Quantity: 12})
+	if err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if item.GetSku() != "GO-101" || item.GetName() != "Gopher plush" || item.GetQuantity() != 12 {
+		t.Errorf("AddItem returned %v", item)
+	}
+
+	got, err := client.GetItem(ctx, &GetItemRequest{Sku: "GO-101"})
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if got.GetName() != "Gopher plush" || got.GetQuantity() != 12 {
+		t.Errorf("GetItem returned %v", got)
+	}
+}
+
+func TestAddItemValidation(t *testing.T) {
+	client, ctx := newClient(t), testContext(t)
+
+	for name, req := range map[string]*AddItemRequest{
+		"missing sku":       {Name: "Mug", Quantity: 1},
+		"blank sku":         {Sku: "   ", Name: "Mug", Quantity: 1},
+		"missing name":      {Sku: "MUG-1", Quantity: 1},
+		"negative quantity": {Sku: "MUG-1", Name: "Mug", Quantity: -1},
+	} {
+		_, err := client.AddItem(ctx, req)
+		wantCode(t, "AddItem with "+name, err, codes.InvalidArgument)
+	}
+
+	if _, err := client.AddItem(ctx, &AddItemRequest{Sku: "MUG-1", Name: "Mug"}); err != nil {
+		t.Fatalf("AddItem with quantity 0: %v", err)
+	}
+	_, err := client.AddItem(ctx, &AddItemRequest{Sku: "MUG-1", Name: "Another mug", Quantity: 3})
+	wantCode(t, "AddItem with a used SKU", err, codes.AlreadyExists)
+}
+
+func TestGetItemNotFound(t *testing.T) {
+	client, ctx := newClient(t), testContext(t)
+	_, err := client.GetItem(ctx, &GetItemRequest{Sku: "NOPE"})
+	wantCode(t, "GetItem of an unknown SKU", err, codes.NotFound)
+}
+
+func TestAdjustStock(t *testing.T) {
+	client, ctx := newClient(t), testContext(t)
+	if _, err := client.AddItem(ctx, &AddItemRequest{Sku: "CAP-7", Name: "Cap", Quantity: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := client.AdjustStock(ctx, &AdjustStockRequest{Sku: "CAP-7", Delta: 3})
+	if err != nil || item.GetQuantity() != 8 {
+		t.Fatalf("AdjustStock(+3) = %v, %v; want quantity 8", item, err)
+	}
+	item, err = client.AdjustStock(ctx, &AdjustStockRequest{Sku: "CAP-7", Delta: -8})
+	if err != nil || item.GetQuantity() != 0 {
+		t.Fatalf("AdjustStock(-8) = %v, %v; want quantity 0", item, err)
+	}
+
+	_, err = client.AdjustStock(ctx, &AdjustStockRequest{Sku: "CAP-7", Delta: -1})
+	wantCode(t, "AdjustStock below zero", err, codes.FailedPrecondition)
+	if got, _ := client.GetItem(ctx, &GetItemRequest{Sku: "CAP-7"}); got.GetQuantity() != 0 {
+		t.Errorf("quantity is %d after a failed adjustment, want 0", got.GetQuantity())
+	}
+
+	_, err = client.AdjustStock(ctx, &AdjustStockRequest{Sku: "NOPE", Delta: 1})
+	wantCode(t, "AdjustStock of an unknown SKU", err, codes.NotFound)
+}
+
+func TestConcurrentAdjustments(t *testing.T) {
+	client, ctx := newClient(t), testContext(t)
+	if _, err := client.AddItem(ctx, &AddItemRequest{Sku: "PEN-1", Name: "Pen", Quantity: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 calls take one each, 50 put one back: every call must see a
+	// consistent quantity
+	var wg sync.WaitGroup
+	for i := 0; i < 150; i++ {
+		delta := int32(-1)
+		if i%3 == 0 {
+			delta = 1
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.AdjustStock(ctx, &AdjustStockRequest{Sku: "PEN-1", Delta: delta}); err != nil {
+				t.Errorf("AdjustStock(%d): %v", delta, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	item, err := client.GetItem(ctx, &GetItemRequest{Sku: "PEN-1"})
+	if err != nil || item.GetQuantity() != 50 {
+		t.Errorf("GetItem = %v, %v; want quantity 50", item, err)
+	}
+}
+
+func TestServersAreIndependent(t *testing.T) {
+	first, second, ctx := newClient(t), newClient(t), testContext(t)
+	if _, err := first.AddItem(ctx, &AddItemRequest{Sku: "BAG-2", Name: "Bag"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := second.GetItem(ctx, &GetItemRequest{Sku: "BAG-2"})
+	wantCode(t, "GetItem from another server", err, codes.NotFound)
+}
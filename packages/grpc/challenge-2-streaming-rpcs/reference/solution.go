@@ -0,0 +1,221 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SensorService implements the Sensors service from sensors.proto
+type SensorService struct {
+	UnimplementedSensorsServer
+
+	window int // readings averaged by Smooth
+
+	mu       sync.Mutex
+	readings map[string][]float64 // stored by Record, oldest first
+}
+
+// NewSensorService creates a service whose Smooth averages the latest
+// window readings of each sensor
+func NewSensorService(window int) *SensorService {
+	return &SensorService{window: window, readings: make(map[string][]float64)}
+}
+
+// Record receives readings until the client closes the stream, then stores
+// them and answers with a summary. A reading without a sensor fails the
+// call, and nothing from it is stored.
+func (s *SensorService) Record(stream grpc.ClientStreamingServer[Reading, RecordSummary]) error {
+	var received []*Reading
+	for {
+		reading, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if reading.GetSensor() == "" {
+			return status.Errorf(codes.InvalidArgument, "reading %d has no sensor", len(received)+1)
+		}
+		received = append(received, reading)
+	}
+
+	summary := &RecordSummary{Count: int32(len(received))}
+	if len(received) > 0 {
+		summary.Min, summary.Max = math.Inf(1), math.Inf(-1)
+	}
+	var sum float64
+	s.mu.Lock()
+	for _, reading := range received {
+		s.readings[reading.GetSensor()] = append(s.readings[reading.GetSensor()], reading.GetValue())
+		summary.Min = math.Min(summary.Min, reading.GetValue())
+		summary.Max = math.Max(summary.Max, reading.GetValue())
+		sum += reading.GetValue()
+	}
+	s.mu.Unlock()
+	if len(received) > 0 {
+		summary.Mean = sum / float64(len(received))
+	}
+	return stream.SendAndClose(summary)
+}
+
+// Replay sends a sensor's stored readings, oldest first: the latest
+// req.Limit of them, or all of them if the limit is 0
+func (s *SensorService) Replay(req *ReplayRequest, stream grpc.ServerStreamingServer[Reading]) error {
+	if req.GetLimit() < 0 {
+		return status.Errorf(codes.InvalidArgument, "limit %d is negative", req.GetLimit())
+	}
+	s.mu.Lock()
+	values, ok := s.readings[req.GetSensor()]
+	values = values[:len(values):len(values)] // Record may append while we send
+	s.mu.Unlock()
+	if !ok {
+		return status.Errorf(codes.NotFound, "no readings from sensor %q", req.GetSensor())
+	}
+
+	if limit := int(req.GetLimit()); limit > 0 && limit < len(values) {
+		values = values[len(values)-limit:]
+	}
+	for _, value := range values {
+		// Send fails once the client has gone away, ending the call
+		if err := stream.Send(&Reading{Sensor: req.GetSensor(), Value: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Smooth answers every reading as it arrives with the average of the latest
+// readings of its sensor in this stream, up to the window size
+func (s *SensorService) Smooth(stream grpc.BidiStreamingServer[Reading, Reading]) error {
+	latest := make(map[string][]float64)
+	for {
+		reading, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if reading.GetSensor() == "" {
+			return status.Error(codes.InvalidArgument, "reading has no sensor")
+		}
+
+		values := append(latest[reading.GetSensor()], reading.GetValue())
+		if len(values) > s.window {
+			values = values[len(values)-s.window:]
+		}
+		latest[reading.GetSensor()] = values
+
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		if err := stream.Send(&Reading{Sensor: reading.GetSensor(), Value: sum / float64(len(values))}); err != nil {
+			return err
+		}
+	}
+}
+
+// UploadReadings records readings through one Record stream and returns the
+// server's summary
+func UploadReadings(ctx context.Context, client SensorsClient, readings []*Reading) (*RecordSummary, error) {
+	stream, err := client.Record(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, reading := range readings {
+		// io.EOF means the server ended the call; CloseAndRecv returns why
+		if err := stream.Send(reading); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// ReplayReadings collects what Replay streams for a sensor
+func ReplayReadings(ctx context.Context, client SensorsClient, sensor string, limit int32) ([]*Reading, error) {
+	stream, err := client.Replay(ctx, &ReplayRequest{Sensor: sensor, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	var readings []*Reading
+	for {
+		reading, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return readings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, reading)
+	}
+}
+
+// SmoothReadings sends readings through one Smooth stream and returns the
+// averages the server answers with, in order. It sends and receives at the
+// same time, so a long stream never stalls on flow control.
+func SmoothReadings(ctx context.Context, client SensorsClient, readings []*Reading) ([]float64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the sender if receiving fails
+	stream, err := client.Smooth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		for _, reading := range readings {
+			if err := stream.Send(reading); err != nil {
+				// io.EOF: the server ended the call, and Recv reports why
+				if !errors.Is(err, io.EOF) {
+					sendErr <- err
+					return
+				}
+				break
+			}
+		}
+		sendErr <- stream.CloseSend()
+	}()
+
+	averages := make([]float64, 0, len(readings))
+	for {
+		reading, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		averages = append(averages, reading.GetValue())
+	}
+	if err := <-sendErr; err != nil {
+		return nil, err
+	}
+	return averages, nil
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	RegisterSensorsServer(srv, NewSensorService(5))
+	log.Println("Sensors service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,201 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: sensors.proto
+
+package main
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Sensors_Record_FullMethodName = "/sensors.v1.Sensors/Record"
+	Sensors_Replay_FullMethodName = "/sensors.v1.Sensors/Replay"
+	Sensors_Smooth_FullMethodName = "/sensors.v1.Sensors/Smooth"
+)
+
+// SensorsClient is the client API for Sensors service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Sensors collects and replays readings from named sensors
+type SensorsClient interface {
+	// Record stores a stream of readings and summarizes them once the client
+	// closes the stream
+	Record(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Reading, RecordSummary], error)
+	// Replay streams a sensor's stored readings, oldest first
+	Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Reading], error)
+	// Smooth answers every reading with the moving average of its sensor's
+	// latest readings in the same stream
+	Smooth(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Reading, Reading], error)
+}
+
+type sensorsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSensorsClient(cc grpc.ClientConnInterface) SensorsClient {
+	return &sensorsClient{cc}
+}
+
+func (c *sensorsClient) Record(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[Reading, RecordSummary], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sensors_ServiceDesc.Streams[0], Sensors_Record_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Reading, RecordSummary]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_RecordClient = grpc.ClientStreamingClient[Reading, RecordSummary]
+
+func (c *sensorsClient) Replay(ctx context.Context, in *ReplayRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Reading], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sensors_ServiceDesc.Streams[1], Sensors_Replay_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReplayRequest, Reading]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_ReplayClient = grpc.ServerStreamingClient[Reading]
+
+func (c *sensorsClient) Smooth(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Reading, Reading], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sensors_ServiceDesc.Streams[2], Sensors_Smooth_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Reading, Reading]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_SmoothClient = grpc.BidiStreamingClient[Reading, Reading]
+
+// SensorsServer is the server API for Sensors service.
+// All implementations must embed UnimplementedSensorsServer
+// for forward compatibility.
+//
+// Sensors collects and replays readings from named sensors
+type SensorsServer interface {
+	// Record stores a stream of readings and summarizes them once the client
+	// closes the stream
+	Record(grpc.ClientStreamingServer[Reading, RecordSummary]) error
+	// Replay streams a sensor's stored readings, oldest first
+	Replay(*ReplayRequest, grpc.ServerStreamingServer[Reading]) error
+	// Smooth answers every reading with the moving average of its sensor's
+	// latest readings in the same stream
+	Smooth(grpc.BidiStreamingServer[Reading, Reading]) error
+	mustEmbedUnimplementedSensorsServer()
+}
+
+// UnimplementedSensorsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSensorsServer struct{}
+
+func (UnimplementedSensorsServer) Record(grpc.ClientStreamingServer[Reading, RecordSummary]) error {
+	return status.Errorf(codes.Unimplemented, "method Record not implemented")
+}
+func (UnimplementedSensorsServer) Replay(*ReplayRequest, grpc.ServerStreamingServer[Reading]) error {
+	return status.Errorf(codes.Unimplemented, "method Replay not implemented")
+}
+func (UnimplementedSensorsServer) Smooth(grpc.BidiStreamingServer[Reading, Reading]) error {
+	return status.Errorf(codes.Unimplemented, "method Smooth not implemented")
+}
+func (UnimplementedSensorsServer) mustEmbedUnimplementedSensorsServer() {}
+func (UnimplementedSensorsServer) testEmbeddedByValue()                 {}
+
+// UnsafeSensorsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SensorsServer will
+// result in compilation errors.
+type UnsafeSensorsServer interface {
+	mustEmbedUnimplementedSensorsServer()
+}
+
+func RegisterSensorsServer(s grpc.ServiceRegistrar, srv SensorsServer) {
+	// If the following call pancis, it indicates UnimplementedSensorsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Sensors_ServiceDesc, srv)
+}
+
+func _Sensors_Record_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorsServer).Record(&grpc.GenericServerStream[Reading, RecordSummary]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_RecordServer = grpc.ClientStreamingServer[Reading, RecordSummary]
+
+func _Sensors_Replay_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplayRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SensorsServer).Replay(m, &grpc.GenericServerStream[ReplayRequest, Reading]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_ReplayServer = grpc.ServerStreamingServer[Reading]
+
+func _Sensors_Smooth_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SensorsServer).Smooth(&grpc.GenericServerStream[Reading, Reading]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sensors_SmoothServer = grpc.BidiStreamingServer[Reading, Reading]
+
+// Sensors_ServiceDesc is the grpc.ServiceDesc for Sensors service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sensors_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sensors.v1.Sensors",
+	HandlerType: (*SensorsServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Record",
+			Handler:       _Sensors_Record_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Replay",
+			Handler:       _Sensors_Replay_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Smooth",
+			Handler:       _Sensors_Smooth_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sensors.proto",
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// SensorService implements the Sensors service from sensors.proto
+type SensorService struct {
+	UnimplementedSensorsServer
+
+	window int // readings averaged by Smooth
+
+	mu       sync.Mutex
+	readings map[string][]float64 // stored by Record, oldest first
+}
+
+// NewSensorService creates a service whose Smooth averages the latest
+// window readings of each sensor
+func NewSensorService(window int) *SensorService {
+	return &SensorService{window: window, readings: make(map[string][]float64)}
+}
+
+// Record receives readings until the client closes the stream, then stores
+// them and answers with a summary. A reading without a sensor fails the
+// call, and nothing from it is stored.
+func (s *SensorService) Record(stream grpc.ClientStreamingServer[Reading, RecordSummary]) error {
+	// TODO: Recv until io.EOF, failing with codes.InvalidArgument on a reading without a sensor
+	// TODO: Store the readings and answer with SendAndClose: count, min, max and mean (all 0 for no readings)
+	return nil
+}
+
+// Replay sends a sensor's stored readings, oldest first: the latest
+// req.Limit of them, or all of them if the limit is 0
+func (s *SensorService) Replay(req *ReplayRequest, stream grpc.ServerStreamingServer[Reading]) error {
+	// TODO: Return codes.InvalidArgument for a negative limit and codes.NotFound for an unknown sensor
+	// TODO: Send the readings one at a time, stopping if Send fails
+	return nil
+}
+
+// Smooth answers every reading as it arrives with the average of the latest
+// readings of its sensor in this stream, up to the window size
+func (s *SensorService) Smooth(stream grpc.BidiStreamingServer[Reading, Reading]) error {
+	// TODO: For each reading, Send back its sensor's moving average; keep the windows per stream
+	// TODO: Fail with codes.InvalidArgument on a reading without a sensor; return nil at io.EOF
+	return nil
+}
+
+// UploadReadings records readings through one Record stream and returns the
+// server's summary
+func UploadReadings(ctx context.Context, client SensorsClient, readings []*Reading) (*RecordSummary, error) {
+	// TODO: Open a Record stream, Send every reading and finish with CloseAndRecv
+	return nil, nil
+}
+
+// ReplayReadings collects what Replay streams for a sensor
+func ReplayReadings(ctx context.Context, client SensorsClient, sensor string, limit int32) ([]*Reading, error) {
+	// TODO: Call Replay and Recv until io.EOF
+	return nil, nil
+}
+
+// SmoothReadings sends readings through one Smooth stream and returns the
+// averages the server answers with, in order. It sends and receives at the
+// same time, so a long stream never stalls on flow control.
+func SmoothReadings(ctx context.Context, client SensorsClient, readings []*Reading) ([]float64, error) {
+	// TODO: Send in a goroutine, then CloseSend
+	// TODO: Recv the averages until io.EOF
+	return nil, nil
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	RegisterSensorsServer(srv, NewSensorService(5))
+	log.Println("Sensors service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves a SensorService with the given Smooth window on an
+// in-memory listener and returns a client connected to it
+func newClient(t *testing.T, window int) SensorsClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterSensorsServer(srv, NewSensorService(window))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewSensorsClient(conn)
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func readings(sensor string, values ...float64) []*Reading {
+	var rs []*Reading
+	for _, v := range values {
+		rs = append(rs, &Reading{Sensor: sensor, Value: v})
+	}
+	return rs
+}
+
+func values(rs []*Reading) string {
+	var vs []float64
+	for _, r := range rs {
+		vs = append(vs, r.GetValue())
+	}
+	return fmt.Sprint(vs)
+}
+
+func wantCode(t *testing.T, call string, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Errorf("%s: code = %s (%v), want %s", call, got, err, code)
+	}
+}
+
+func TestRecordSummary(t *testing.T) {
+	client, ctx := newClient(t, 3), testContext(t)
+
+	summary, err := UploadReadings(ctx, client, append(readings("boiler", 3, 1, 2), readings("attic", 10)...))
+	if err != nil {
+		t.Fatalf("UploadReadings: %v", err)
+	}
+	if summary.GetCount() != 4 || summary.GetMin() != 1 || summary.GetMax() != 10 || summary.GetMean() != 4 {
+		t.Errorf("summary = %v, want count 4, min 1, max 10, mean 4", summary)
+	}
+
+	summary, err = UploadReadings(ctx, client, readings("cellar", 7))
+	if err != nil {
+		t.Fatalf("UploadReadings: %v", err)
+	}
+	if summary.GetCount() != 1 || summary.GetMin() != 7 || summary.GetMax() != 7 || summary.GetMean() != 7 {
+		t.Errorf("summary of one reading = %v, want count 1, min, max and mean 7", summary)
+	}
+
+	summary, err = UploadReadings(ctx, client, nil)
+	if err != nil {
+		t.Fatalf("UploadReadings with no readings: %v", err)
+	}
+	if summary.GetCount() != 0 || summary.GetMin() != 0 || summary.GetMax() != 0 || summary.GetMean() != 0 {
+		t.Errorf("summary of nothing = %v, want all zero", summary)
+	}
+}
+
+func TestRecordRejectsBadReading(t *testing.T) {
+	client, ctx := newClient(t, 3), testContext(t)
+	if _, err := UploadReadings(ctx, client, readings("boiler", 20)); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := append(readings("boiler", 21, 22), &Reading{Value: 99})
+	_, err := UploadReadings(ctx, client, bad)
+	wantCode(t, "UploadReadings with a reading without a sensor", err, codes.InvalidArgument)
+
+	got, err := ReplayReadings(ctx, client, "boiler", 0)
+	if err != nil || values(got) != "[20]" {
+		t.Errorf("Replay after a failed Record = %s, %v; want [20], as nothing from a failed stream is stored", values(got), err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	client, ctx := newClient(t, 3), testContext(t)
+	if _, err := UploadReadings(ctx, client, readings("boiler", 1, 2, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UploadReadings(ctx, client, readings("boiler", 4, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReplayReadings(ctx, client, "boiler", 0)
+	if err != nil || values(got) != "[1 2 3 4 5]" {
+		t.Errorf("Replay(limit 0) = %s, %v; want [1 2 3 4 5]", values(got), err)
+	}
+	if len(got) > 0 && got[0].GetSensor() != "boiler" {
+		t.Errorf("replayed reading has sensor %q, want boiler", got[0].GetSensor())
+	}
+	got, err = ReplayReadings(ctx, client, "boiler", 2)
+	if err != nil || values(got) != "[4 5]" {
+		t.Errorf("Replay(limit 2) = %s, %v; want the latest two, [4 5]", values(got), err)
+	}
+	got, err = ReplayReadings(ctx, client, "boiler", 1)
+	if err != nil || values(got) != "[5]" {
+		t.Errorf("Replay(limit 1) = %s, %v; want [5]", values(got), err)
+	}
+	got, err = ReplayReadings(ctx, client, "boiler", 50)
+	if err != nil || len(got) != 5 {
+		t.Errorf("Replay(limit 50) = %s, %v; want all five", values(got), err)
+	}
+
+	_, err = ReplayReadings(ctx, client, "garage", 0)
+	wantCode(t, "Replay of an unknown sensor", err, codes.NotFound)
+	_, err = ReplayReadings(ctx, client, "boiler", -1)
+	wantCode(t, "Replay with a negative limit", err, codes.InvalidArgument)
+}
+
+func TestReplayManyReadings(t *testing.T) {
+	client, ctx := newClient(t, 3), testContext(t)
+	var many []float64
+	for i := 0; i < 5000; i++ {
+		many = append(many, float64(i))
+	}
+	if _, err := UploadReadings(ctx, client, readings("meter", many...)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReplayReadings(ctx, client, "meter", 0)
+	if err != nil || len(got) != 5000 || got[4999].GetValue() != 4999 {
+		t.Errorf("Replay returned %d readings, %v; want all 5000 in order", len(got), err)
+	}
+}
+
+func TestSmoothAnswersEachReading(t *testing.T) {
+	client, ctx := newClient(t, 2), testContext(t)
+
+	// Without closing the stream: the server must answer each reading as
+	// it arrives, not wait for the end
+	stream, err := client.Smooth(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []struct{ in, want float64 }{{4, 4}, {6, 5}, {10, 8}} {
+		if err := stream.Send(&Reading{Sensor: "boiler", Value: step.in}); err != nil {
+			t.Fatalf("Send(%v): %v", step.in, err)
+		}
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv after sending %v: %v", step.in, err)
+		}
+		if reply.GetSensor() != "boiler" || reply.GetValue() != step.want {
+			t.Errorf("after %v got %v, want boiler %v", step.in, reply, step.want)
+		}
+	}
+	stream.CloseSend()
+}
+
+func TestSmoothReadings(t *testing.T) {
+	client, ctx := newClient(t, 3), testContext(t)
+
+	in := []*Reading{
+		{Sensor: "a", Value: 1}, {Sensor: "a", Value: 2}, {Sensor: "b", Value: 10},
+		{Sensor: "a", Value: 3}, {Sensor: "a", Value: 4}, {Sensor: "b", Value: 20},
+	}
+	got, err := SmoothReadings(ctx, client, in)
+	if err != nil {
+		t.Fatalf("SmoothReadings: %v", err)
+	}
+	if fmt.Sprint(got) != "[1 1.5 10 2 3 15]" {
+		t.Errorf("SmoothReadings = %v, want [1 1.5 10 2 3 15]; each sensor averages its own latest 3", got)
+	}
+
+	// A second stream starts from nothing
+	got, err = SmoothReadings(ctx, client, readings("a", 100))
+	if err != nil || fmt.Sprint(got) != "[100]" {
+		t.Errorf("SmoothReadings on a new stream = %v, %v; want [100]", got, err)
+	}
+}
+
+func TestSmoothLongStream(t *testing.T) {
+	client, ctx := newClient(t, 4), testContext(t)
+	var in []float64
+	for i := 0; i < 3000; i++ {
+		in = append(in, 8)
+	}
+	got, err := SmoothReadings(ctx, client, readings("flat", in...))
+	if err != nil {
+		t.Fatalf("SmoothReadings: %v", err)
+	}
+	if len(got) != len(in) {
+		t.Fatalf("got %d averages for %d readings", len(got), len(in))
+	}
+	for i, v := range got {
+		if math.Abs(v-8) > 1e-9 {
+			t.Fatalf("average %d = %v, want 8", i, v)
+		}
+	}
+}
+
+func TestSmoothRejectsBadReading(t *testing.T) {
+	client, ctx := newClient(t, 3), testContext(t)
+	_, err := SmoothReadings(ctx, client, append(readings("a", 1), &Reading{Value: 2}))
+	wantCode(t, "SmoothReadings with a reading without a sensor", err, codes.InvalidArgument)
+}
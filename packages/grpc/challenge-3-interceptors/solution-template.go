@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userKey is the context key of the authenticated user
+type userKey struct{}
+
+// UserFromContext returns the user the auth interceptors authenticated
+func UserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(userKey{}).(string)
+	return user, ok
+}
+
+func withUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// NoteService implements the Notes service from notes.proto. It relies on
+// the auth interceptors to put the caller in the context.
+type NoteService struct {
+	UnimplementedNotesServer
+
+	mu     sync.Mutex
+	notes  []*Note
+	nextID int64
+}
+
+// CreateNote saves a note owned by the caller
+func (s *NoteService) CreateNote(ctx context.Context, req *CreateNoteRequest) (*Note, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "not signed in")
+	}
+	if strings.TrimSpace(req.GetText()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "text is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	note := &Note{Id: s.nextID, Owner: user, Text: req.GetText()}
+	s.notes = append(s.notes, note)
+	return &Note{Id: note.Id, Owner: note.Owner, Text: note.Text}, nil
+}
+
+// ListNotes streams the caller's notes, oldest first
+func (s *NoteService) ListNotes(req *ListNotesRequest, stream grpc.ServerStreamingServer[Note]) error {
+	user, ok := UserFromContext(stream.Context())
+	if !ok {
+		return status.Error(codes.Unauthenticated, "not signed in")
+	}
+
+	s.mu.Lock()
+	var mine []*Note
+	for _, note := range s.notes {
+		if note.Owner == user {
+			mine = append(mine, &Note{Id: note.Id, Owner: note.Owner, Text: note.Text})
+		}
+	}
+	s.mu.Unlock()
+
+	for _, note := range mine {
+		if err := stream.Send(note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CallRecord describes one finished unary call
+type CallRecord struct {
+	Method   string // the full method name, like "/notes.v1.Notes/CreateNote"
+	Code     codes.Code
+	Duration time.Duration
+}
+
+// authenticate finds the user for the bearer token in the incoming
+// "authorization" metadata and returns ctx with the user in it
+func authenticate(ctx context.Context, tokens map[string]string) (context.Context, error) {
+	// TODO: Read the "authorization" metadata from the incoming context
+	// TODO: Require "Bearer <token>" with a known token, or return codes.Unauthenticated
+	// TODO: Return the context with the user in it (see withUser)
+	return ctx, nil
+}
+
+// AuthUnaryInterceptor authenticates unary calls with bearer tokens, mapped
+// to users by tokens, and puts the user in the handler's context
+func AuthUnaryInterceptor(tokens map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		// TODO: Authenticate, and call the handler with the authenticated context
+		return handler(ctx, req)
+	}
+}
+
+// authenticatedStream is a server stream whose context carries the user
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	// TODO: Return the context with the user in it
+	return s.ServerStream.Context()
+}
+
+// AuthStreamInterceptor authenticates streaming calls like AuthUnaryInterceptor
+func AuthStreamInterceptor(tokens map[string]string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		// TODO: Authenticate, and call the handler with an authenticatedStream
+		return handler(srv, stream)
+	}
+}
+
+// RecoveryInterceptor turns a panic in a handler into an Internal error,
+// logging the panic rather than sending it to the client
+func RecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		// TODO: Recover from a panic in the handler: log it and return codes.Internal
+		return handler(ctx, req)
+	}
+}
+
+// LoggingInterceptor passes a CallRecord for every unary call to record
+func LoggingInterceptor(record func(CallRecord)) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		// TODO: Time the handler and record the method, status code and duration
+		return handler(ctx, req)
+	}
+}
+
+// NewGRPCServer returns a server with a NoteService registered behind the
+// interceptors: logging outermost, so it records every outcome, then
+// recovery, then authentication
+func NewGRPCServer(tokens map[string]string, record func(CallRecord)) *grpc.Server {
+	// TODO: Chain the unary interceptors in that order, and the stream auth interceptor
+	// TODO: Register a NoteService
+	return nil
+}
+
+// BearerToken sends token with every unary call
+func BearerToken(token string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		// TODO: Add "authorization: Bearer <token>" to the outgoing metadata
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamBearerToken sends token with every streaming call
+func StreamBearerToken(token string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		// TODO: Add "authorization: Bearer <token>" to the outgoing metadata
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokens := map[string]string{"dev-token": "developer"}
+	srv := NewGRPCServer(tokens, func(r CallRecord) {
+		log.Printf("%s %s %s", r.Method, r.Code, r.Duration)
+	})
+	log.Println("Notes service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testTokens = map[string]string{"alice-token": "alice", "bob-token": "bob"}
+
+// recorder collects CallRecords; the server calls it concurrently
+type recorder struct {
+	mu      sync.Mutex
+	records []CallRecord
+}
+
+func (r *recorder) record(c CallRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, c)
+}
+
+func (r *recorder) all() []CallRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]CallRecord(nil), r.records...)
+}
+
+// serve starts srv on an in-memory listener and returns a function that
+// connects a client to it with the given options
+func serve(t *testing.T, srv *grpc.Server) func(opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+	if srv == nil {
+		t.Fatal("server is nil")
+	}
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return func(opts ...grpc.DialOption) *grpc.ClientConn {
+		opts = append(opts,
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+}
+
+// clientAs returns a Notes client that authenticates with token through
+// BearerToken and StreamBearerToken
+func clientAs(dial func(...grpc.DialOption) *grpc.ClientConn, token string) NotesClient {
+	return NewNotesClient(dial(
+		grpc.WithUnaryInterceptor(BearerToken(token)),
+		grpc.WithStreamInterceptor(StreamBearerToken(token)),
+	))
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func listNotes(ctx context.Context, client NotesClient) ([]*Note, error) {
+	stream, err := client.ListNotes(ctx, &ListNotesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var notes []*Note
+	for {
+		note, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return notes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+}
+
+func wantCode(t *testing.T, call string, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Errorf("%s: code = %s (%v), want %s", call, got, err, code)
+	}
+}
+
+func TestAuthenticatedCalls(t *testing.T) {
+	dial := serve(t, NewGRPCServer(testTokens, func(CallRecord) {}))
+	alice, bob, ctx := clientAs(dial, "alice-token"), clientAs(dial, "bob-token"), testContext(t)
+
+	note, err := alice.CreateNote(ctx, &CreateNoteRequest{Text: "buy milk"})
+	if err != nil {
+		t.Fatalf("CreateNote as alice: %v", err)
+	}
+	if note.GetOwner() != "alice" {
+		t.Errorf("note owner = %q, want alice: put the user from the token in the context", note.GetOwner())
+	}
+	if _, err := bob.CreateNote(ctx, &CreateNoteRequest{Text: "call mum"}); err != nil {
+		t.Fatalf("CreateNote as bob: %v", err)
+	}
+	if _, err := alice.CreateNote(ctx, &CreateNoteRequest{Text: "water plants"}); err != nil {
+		t.Fatal(err)
+	}
+
+	notes, err := listNotes(ctx, alice)
+	if err != nil {
+		t.Fatalf("ListNotes as alice: %v", err)
+	}
+	if len(notes) != 2 || notes[0].GetText() != "buy milk" || notes[1].GetText() != "water plants" {
+		t.Errorf("alice's notes = %v, want her two notes", notes)
+	} else if notes[0].GetId() <= 0 || notes[0].GetId() == notes[1].GetId() {
+		t.Errorf("note ids = %d and %d, want distinct positive ids", notes[0].GetId(), notes[1].GetId())
+	}
+	notes, err = listNotes(ctx, bob)
+	if err != nil || len(notes) != 1 || notes[0].GetOwner() != "bob" {
+		t.Errorf("bob's notes = %v, %v; want his one note", notes, err)
+	}
+}
+
+func TestUnauthenticatedCalls(t *testing.T) {
+	dial := serve(t, NewGRPCServer(testTokens, func(CallRecord) {}))
+	ctx := testContext(t)
+
+	anonymous := NewNotesClient(dial())
+	_, err := anonymous.CreateNote(ctx, &CreateNoteRequest{Text: "hi"})
+	wantCode(t, "CreateNote without a token", err, codes.Unauthenticated)
+	_, err = listNotes(ctx, anonymous)
+	wantCode(t, "ListNotes without a token", err, codes.Unauthenticated)
+
+	stranger := clientAs(dial, "stolen-token")
+	_, err = stranger.CreateNote(ctx, &CreateNoteRequest{Text: "hi"})
+	wantCode(t, "CreateNote with an unknown token", err, codes.Unauthenticated)
+	_, err = listNotes(ctx, stranger)
+	wantCode(t, "ListNotes with an unknown token", err, codes.Unauthenticated)
+
+	// The right token in the wrong scheme
+	basic := metadata.AppendToOutgoingContext(ctx, "authorization", "Basic alice-token")
+	_, err = anonymous.CreateNote(basic, &CreateNoteRequest{Text: "hi"})
+	wantCode(t, "CreateNote with a Basic token", err, codes.Unauthenticated)
+	bare := metadata.AppendToOutgoingContext(ctx, "authorization", "alice-token")
+	_, err = anonymous.CreateNote(bare, &CreateNoteRequest{Text: "hi"})
+	wantCode(t, "CreateNote with a token without \"Bearer \"", err, codes.Unauthenticated)
+}
+
+func TestLogging(t *testing.T) {
+	var rec recorder
+	dial := serve(t, NewGRPCServer(testTokens, rec.record))
+	ctx := testContext(t)
+
+	alice := clientAs(dial, "alice-token")
+	if _, err := alice.CreateNote(ctx, &CreateNoteRequest{Text: "log me"}); err != nil {
+		t.Fatal(err)
+	}
+	alice.CreateNote(ctx, &CreateNoteRequest{Text: "   "})
+	NewNotesClient(dial()).CreateNote(ctx, &CreateNoteRequest{Text: "who am I"})
+
+	records := rec.all()
+	if len(records) != 3 {
+		t.Fatalf("recorded %d calls, want 3: %+v", len(records), records)
+	}
+	for i, want := range []codes.Code{codes.OK, codes.InvalidArgument, codes.Unauthenticated} {
+		if records[i].Method != "/notes.v1.Notes/CreateNote" {
+			t.Errorf("record %d method = %q, want /notes.v1.Notes/CreateNote", i, records[i].Method)
+		}
+		if records[i].Code != want {
+			t.Errorf("record %d code = %s, want %s; the logging interceptor must run before auth to see rejected calls", i, records[i].Code, want)
+		}
+		if records[i].Duration <= 0 {
+			t.Errorf("record %d duration = %v, want it measured", i, records[i].Duration)
+		}
+	}
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	defer func() {
+		if p := recover(); p != nil {
+			t.Fatalf("the panic reached the caller: %v", p)
+		}
+	}()
+	info := &grpc.UnaryServerInfo{FullMethod: "/notes.v1.Notes/CreateNote"}
+	_, err := RecoveryInterceptor()(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("database password is hunter2")
+	})
+	wantCode(t, "a panicking handler", err, codes.Internal)
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("error %q leaks the panic to the client", err)
+	}
+
+	resp, err := RecoveryInterceptor()(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+		return "resp", status.Error(codes.NotFound, "nope")
+	})
+	if resp != "resp" || status.Code(err) != codes.NotFound {
+		t.Errorf("without a panic got %v, %v; want the handler's result unchanged", resp, err)
+	}
+}
+
+// panickingNotes fails every CreateNote with a panic
+type panickingNotes struct {
+	UnimplementedNotesServer
+}
+
+func (panickingNotes) CreateNote(context.Context, *CreateNoteRequest) (*Note, error) {
+	panic("boom")
+}
+
+// safetyNet stops a panic that gets past RecoveryInterceptor from crashing
+// the test binary
+func safetyNet(t *testing.T) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				t.Errorf("panic got past RecoveryInterceptor: %v", p)
+				err = status.Error(codes.Unknown, "unrecovered panic")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func TestChainedInterceptors(t *testing.T) {
+	var rec recorder
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		safetyNet(t),
+		LoggingInterceptor(rec.record),
+		RecoveryInterceptor(),
+		AuthUnaryInterceptor(testTokens),
+	))
+	RegisterNotesServer(srv, panickingNotes{})
+	dial := serve(t, srv)
+	ctx := testContext(t)
+
+	_, err := clientAs(dial, "bob-token").CreateNote(ctx, &CreateNoteRequest{Text: "boom"})
+	wantCode(t, "CreateNote on a panicking server", err, codes.Internal)
+
+	// The server survived the panic
+	_, err = clientAs(dial, "bob-token").CreateNote(ctx, &CreateNoteRequest{Text: "again"})
+	wantCode(t, "a second CreateNote", err, codes.Internal)
+
+	records := rec.all()
+	if len(records) != 2 || records[0].Code != codes.Internal {
+		t.Errorf("records = %+v, want two Internal calls", records)
+	}
+}
+
+func TestAuthInterceptorSetsUser(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer bob-token"))
+	var user string
+	_, err := AuthUnaryInterceptor(testTokens)(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		user, _ = UserFromContext(ctx)
+		return nil, nil
+	})
+	if err != nil || user != "bob" {
+		t.Errorf("handler saw user %q, err %v; want bob", user, err)
+	}
+
+	called := false
+	_, err = AuthUnaryInterceptor(testTokens)(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		called = true
+		return nil, nil
+	})
+	wantCode(t, "a call without metadata", err, codes.Unauthenticated)
+	if called {
+		t.Error("the handler ran for an unauthenticated call")
+	}
+}
@@ -0,0 +1,188 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxWeightGrams is the heaviest parcel the carriers take
+const MaxWeightGrams = 30000
+
+// Carrier prices parcels. Quote must give up when its context is done.
+type Carrier struct {
+	Name  string
+	Quote func(ctx context.Context, req *QuoteRequest) (priceCents int64, err error)
+}
+
+// ShippingService implements the Shipping service from shipping.proto
+type ShippingService struct {
+	UnimplementedShippingServer
+
+	carriers []Carrier
+}
+
+// NewShippingService creates a service that asks carriers for quotes
+func NewShippingService(carriers ...Carrier) *ShippingService {
+	return &ShippingService{carriers: carriers}
+}
+
+// validateQuoteRequest returns an InvalidArgument error with a BadRequest
+// detail listing every invalid field, or nil
+func validateQuoteRequest(req *QuoteRequest) error {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if strings.TrimSpace(req.GetOrigin()) == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "origin", Description: "origin is required"})
+	}
+	if strings.TrimSpace(req.GetDestination()) == "" {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "destination", Description: "destination is required"})
+	}
+	if req.GetWeightGrams() <= 0 {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "weight_grams", Description: "weight must be positive"})
+	} else if req.GetWeightGrams() > MaxWeightGrams {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: "weight_grams", Description: "parcels weigh at most 30 kg"})
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid quote request").WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid quote request")
+	}
+	return st.Err()
+}
+
+// GetQuote asks every carrier at once and returns the cheapest quote. It
+// fails with Unavailable if no carrier could quote, and with the code of the
+// context's error if the call is cancelled or runs out of time first.
+func (s *ShippingService) GetQuote(ctx context.Context, req *QuoteRequest) (*Quote, error) {
+	if err := validateQuoteRequest(req); err != nil {
+		return nil, err
+	}
+
+	type answer struct {
+		carrier string
+		price   int64
+		err     error
+	}
+	// Buffered so carriers that answer after we return don't block forever
+	answers := make(chan answer, len(s.carriers))
+	for _, carrier := range s.carriers {
+		go func() {
+			price, err := carrier.Quote(ctx, req)
+			answers <- answer{carrier: carrier.Name, price: price, err: err}
+		}()
+	}
+
+	var best *Quote
+	for range s.carriers {
+		select {
+		case a := <-answers:
+			if a.err != nil {
+				log.Printf("carrier %s: %v", a.carrier, a.err)
+				continue
+			}
+			if best == nil || a.price < best.PriceCents {
+				best = &Quote{Carrier: a.carrier, PriceCents: a.price}
+			}
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if best == nil {
+		return nil, status.Error(codes.Unavailable, "no carrier could quote the parcel")
+	}
+	return best, nil
+}
+
+// NewGRPCServer returns a server with a ShippingService for carriers
+func NewGRPCServer(carriers ...Carrier) *grpc.Server {
+	srv := grpc.NewServer()
+	RegisterShippingServer(srv, NewShippingService(carriers...))
+	return srv
+}
+
+// QuoteWithTimeout asks for a quote, giving up after timeout
+func QuoteWithTimeout(ctx context.Context, client ShippingClient, req *QuoteRequest, timeout time.Duration) (*Quote, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return client.GetQuote(ctx, req)
+}
+
+// FieldViolations returns the field violations in the BadRequest details of
+// a status error, mapping each field to its description. It returns nil if
+// there are none.
+func FieldViolations(err error) map[string]string {
+	var violations map[string]string
+	for _, detail := range status.Convert(err).Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+		for _, v := range badRequest.GetFieldViolations() {
+			if violations == nil {
+				violations = make(map[string]string)
+			}
+			violations[v.GetField()] = v.GetDescription()
+		}
+	}
+	return violations
+}
+
+// IsRetryable reports whether a call that failed with err may succeed if
+// made again unchanged
+func IsRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// CallWithRetry makes call up to attempts times while it fails with a
+// retryable error. It waits backoff before the first retry and twice as long
+// before each one after that, and returns the last error once it runs out of
+// attempts or ctx is done.
+func CallWithRetry(ctx context.Context, attempts int, backoff time.Duration, call func(context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := call(ctx)
+		if err == nil || !IsRetryable(err) || attempt >= attempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	flat := func(price int64) func(context.Context, *QuoteRequest) (int64, error) {
+		return func(context.Context, *QuoteRequest) (int64, error) { return price, nil }
+	}
+	srv := NewGRPCServer(
+		Carrier{Name: "Parcel Post", Quote: flat(899)},
+		Carrier{Name: "Express", Quote: flat(1499)},
+	)
+	log.Println("Shipping service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: shipping.proto
+
+package main
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Shipping_GetQuote_FullMethodName = "/shipping.v1.Shipping/GetQuote"
+)
+
+// ShippingClient is the client API for Shipping service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Shipping quotes parcels by asking every carrier it works with
+type ShippingClient interface {
+	// GetQuote returns the cheapest carrier's price for a parcel
+	GetQuote(ctx context.Context, in *QuoteRequest, opts ...grpc.CallOption) (*Quote, error)
+}
+
+type shippingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShippingClient(cc grpc.ClientConnInterface) ShippingClient {
+	return &shippingClient{cc}
+}
+
+func (c *shippingClient) GetQuote(ctx context.Context, in *QuoteRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, Shipping_GetQuote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShippingServer is the server API for Shipping service.
+// All implementations must embed UnimplementedShippingServer
+// for forward compatibility.
+//
+// Shipping quotes parcels by asking every carrier it works with
+type ShippingServer interface {
+	// GetQuote returns the cheapest carrier's price for a parcel
+	GetQuote(context.Context, *QuoteRequest) (*Quote, error)
+	mustEmbedUnimplementedShippingServer()
+}
+
+// UnimplementedShippingServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShippingServer struct{}
+
+func (UnimplementedShippingServer) GetQuote(context.Context, *QuoteRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuote not implemented")
+}
+func (UnimplementedShippingServer) mustEmbedUnimplementedShippingServer() {}
+func (UnimplementedShippingServer) testEmbeddedByValue()                  {}
+
+// UnsafeShippingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShippingServer will
+// result in compilation errors.
+type UnsafeShippingServer interface {
+	mustEmbedUnimplementedShippingServer()
+}
+
+func RegisterShippingServer(s grpc.ServiceRegistrar, srv ShippingServer) {
+	// If the following call pancis, it indicates UnimplementedShippingServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Shipping_ServiceDesc, srv)
+}
+
+func _Shipping_GetQuote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShippingServer).GetQuote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shipping_GetQuote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShippingServer).GetQuote(ctx, req.(*QuoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Shipping_ServiceDesc is the grpc.ServiceDesc for Shipping service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Shipping_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shipping.v1.Shipping",
+	HandlerType: (*ShippingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetQuote",
+			Handler:    _Shipping_GetQuote_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "shipping.proto",
+}
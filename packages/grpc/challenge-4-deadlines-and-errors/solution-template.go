@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// MaxWeightGrams is the heaviest parcel the carriers take
+const MaxWeightGrams = 30000
+
+// Carrier prices parcels. Quote must give up when its context is done.
+type Carrier struct {
+	Name  string
+	Quote func(ctx context.Context, req *QuoteRequest) (priceCents int64, err error)
+}
+
+// ShippingService implements the Shipping service from shipping.proto
+type ShippingService struct {
+	UnimplementedShippingServer
+
+	carriers []Carrier
+}
+
+// NewShippingService creates a service that asks carriers for quotes
+func NewShippingService(carriers ...Carrier) *ShippingService {
+	return &ShippingService{carriers: carriers}
+}
+
+// validateQuoteRequest returns an InvalidArgument error with a BadRequest
+// detail listing every invalid field, or nil
+func validateQuoteRequest(req *QuoteRequest) error {
+	// TODO: Collect an errdetails.BadRequest_FieldViolation for each problem:
+	//   - "origin" or "destination" blank
+	//   - "weight_grams" not positive, or over MaxWeightGrams
+	// TODO: Attach them with status.New(codes.InvalidArgument, ...).WithDetails
+	return nil
+}
+
+// GetQuote asks every carrier at once and returns the cheapest quote. It
+// fails with Unavailable if no carrier could quote, and with the code of the
+// context's error if the call is cancelled or runs out of time first.
+func (s *ShippingService) GetQuote(ctx context.Context, req *QuoteRequest) (*Quote, error) {
+	// TODO: Validate the request
+	// TODO: Ask each carrier in its own goroutine, passing ctx along
+	// TODO: Keep the cheapest answer; skip carriers that fail
+	// TODO: Stop waiting when ctx is done and return status.FromContextError(ctx.Err()).Err()
+	return nil, nil
+}
+
+// NewGRPCServer returns a server with a ShippingService for carriers
+func NewGRPCServer(carriers ...Carrier) *grpc.Server {
+	srv := grpc.NewServer()
+	RegisterShippingServer(srv, NewShippingService(carriers...))
+	return srv
+}
+
+// QuoteWithTimeout asks for a quote, giving up after timeout
+func QuoteWithTimeout(ctx context.Context, client ShippingClient, req *QuoteRequest, timeout time.Duration) (*Quote, error) {
+	// TODO: Call GetQuote with a context that times out
+	return nil, nil
+}
+
+// FieldViolations returns the field violations in the BadRequest details of
+// a status error, mapping each field to its description. It returns nil if
+// there are none.
+func FieldViolations(err error) map[string]string {
+	// TODO: Look through status.Convert(err).Details() for *errdetails.BadRequest
+	return nil
+}
+
+// IsRetryable reports whether a call that failed with err may succeed if
+// made again unchanged
+func IsRetryable(err error) bool {
+	// TODO: Unavailable, ResourceExhausted and Aborted are worth retrying
+	return false
+}
+
+// CallWithRetry makes call up to attempts times while it fails with a
+// retryable error. It waits backoff before the first retry and twice as long
+// before each one after that, and returns the last error once it runs out of
+// attempts or ctx is done.
+func CallWithRetry(ctx context.Context, attempts int, backoff time.Duration, call func(context.Context) error) error {
+	// TODO: Call, and return at once on success or a non-retryable error
+	// TODO: Otherwise wait for the backoff or ctx, whichever comes first, then double the backoff
+	return nil
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	flat := func(price int64) func(context.Context, *QuoteRequest) (int64, error) {
+		return func(context.Context, *QuoteRequest) (int64, error) { return price, nil }
+	}
+	srv := NewGRPCServer(
+		Carrier{Name: "Parcel Post", Quote: flat(899)},
+		Carrier{Name: "Express", Quote: flat(1499)},
+	)
+	log.Println("Shipping service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newClient serves a ShippingService for carriers on an in-memory listener
+// and returns a client connected to it
+func newClient(t *testing.T, carriers ...Carrier) ShippingClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(carriers...)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewShippingClient(conn)
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func wantCode(t *testing.T, call string, err error, code codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != code {
+		t.Errorf("%s: code = %s (%v), want %s", call, got, err, code)
+	}
+}
+
+func flat(name string, price int64) Carrier {
+	return Carrier{Name: name, Quote: func(context.Context, *QuoteRequest) (int64, error) {
+		return price, nil
+	}}
+}
+
+func failing(name string) Carrier {
+	return Carrier{Name: name, Quote: func(context.Context, *QuoteRequest) (int64, error) {
+		return 0, errors.New("rate service down")
+	}}
+}
+
+// stuck never answers: it waits for its context and then reports the
+// context's error on gaveUp
+func stuck(name string, gaveUp chan<- error) Carrier {
+	return Carrier{Name: name, Quote: func(ctx context.Context, _ *QuoteRequest) (int64, error) {
+		<-ctx.Done()
+		gaveUp <- ctx.Err()
+		return 0, ctx.Err()
+	}}
+}
+
+var parcel = &QuoteRequest{Origin: "Berlin", Destination: "Lisbon", WeightGrams: 1200}
+
+func TestCheapestQuote(t *testing.T) {
+	client := newClient(t, flat("Express", 1499), failing("Budget"), flat("Parcel Post", 899), flat("Courier", 2500))
+
+	quote, err := QuoteWithTimeout(testContext(t), client, parcel, 5*time.Second)
+	if err != nil {
+		t.Fatalf("QuoteWithTimeout: %v", err)
+	}
+	if quote.GetCarrier() != "Parcel Post" || quote.GetPriceCents() != 899 {
+		t.Errorf("quote = %v, want Parcel Post at 899; skip carriers that fail", quote)
+	}
+}
+
+func TestNoCarrierCanQuote(t *testing.T) {
+	client := newClient(t, failing("Budget"), failing("Express"))
+	_, err := QuoteWithTimeout(testContext(t), client, parcel, 5*time.Second)
+	wantCode(t, "GetQuote when every carrier fails", err, codes.Unavailable)
+
+	client = newClient(t)
+	_, err = QuoteWithTimeout(testContext(t), client, parcel, 5*time.Second)
+	wantCode(t, "GetQuote without carriers", err, codes.Unavailable)
+}
+
+func TestFieldViolations(t *testing.T) {
+	client, ctx := newClient(t, flat("Express", 1499)), testContext(t)
+
+	tests := []struct {
+		name   string
+		req    *QuoteRequest
+		fields []string
+	}{
+		{"empty request", &QuoteRequest{}, []string{"origin", "destination", "weight_grams"}},
+		{"blank origin", &QuoteRequest{Origin: "  ", Destination: "Lisbon", WeightGrams: 500}, []string{"origin"}},
+		{"negative weight", &QuoteRequest{Origin: "Berlin", Destination: "Lisbon", WeightGrams: -1}, []string{"weight_grams"}},
+		{"too heavy", &QuoteRequest{Origin: "Berlin", Destination: "Lisbon", WeightGrams: MaxWeightGrams + 1}, []string{"weight_grams"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetQuote(ctx, tt.req)
+			wantCode(t, "GetQuote", err, codes.InvalidArgument)
+
+			violations := FieldViolations(err)
+			if len(violations) != len(tt.fields) {
+				t.Fatalf("violations = %v, want one for each of %v", violations, tt.fields)
+			}
+			for _, field := range tt.fields {
+				if violations[field] == "" {
+					t.Errorf("no description for %q in %v", field, violations)
+				}
+			}
+		})
+	}
+
+	for _, weight := range []int32{1, MaxWeightGrams} {
+		if _, err := client.GetQuote(ctx, &QuoteRequest{Origin: "Berlin", Destination: "Lisbon", WeightGrams: weight}); err != nil {
+			t.Errorf("a parcel of %d grams: %v", weight, err)
+		}
+	}
+	if v := FieldViolations(status.Error(codes.NotFound, "no such parcel")); v != nil {
+		t.Errorf("FieldViolations of an error without details = %v, want nil", v)
+	}
+	if v := FieldViolations(nil); v != nil {
+		t.Errorf("FieldViolations(nil) = %v, want nil", v)
+	}
+}
+
+func TestDeadlineReachesCarriers(t *testing.T) {
+	gaveUp := make(chan error, 1)
+	client := newClient(t, flat("Express", 1499), stuck("Slowpoke", gaveUp))
+
+	start := time.Now()
+	_, err := QuoteWithTimeout(testContext(t), client, parcel, 100*time.Millisecond)
+	wantCode(t, "GetQuote with a stuck carrier", err, codes.DeadlineExceeded)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("QuoteWithTimeout took %v; it should give up after its timeout", elapsed)
+	}
+
+	select {
+	case err := <-gaveUp:
+		if err == nil {
+			t.Error("the stuck carrier's context ended without an error")
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("the stuck carrier was never told to give up; pass the call's context to carriers")
+	}
+}
+
+func TestGetQuoteContextErrors(t *testing.T) {
+	service := NewShippingService(stuck("Slowpoke", make(chan error, 2)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := service.GetQuote(ctx, parcel)
+	wantCode(t, "GetQuote past its deadline", err, codes.DeadlineExceeded)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = service.GetQuote(ctx, parcel)
+	wantCode(t, "GetQuote after a cancel", err, codes.Canceled)
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{status.Error(codes.Unavailable, "try again"), true},
+		{status.Error(codes.ResourceExhausted, "slow down"), true},
+		{status.Error(codes.Aborted, "conflict"), true},
+		{status.Error(codes.InvalidArgument, "bad request"), false},
+		{status.Error(codes.NotFound, "gone"), false},
+		{status.Error(codes.DeadlineExceeded, "too slow"), false},
+		{errors.New("not a status"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// flaky fails with err for its first failures calls, then succeeds
+func flaky(failures int32, err error) (func(context.Context) error, *atomic.Int32) {
+	var calls atomic.Int32
+	return func(context.Context) error {
+		if calls.Add(1) <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestCallWithRetry(t *testing.T) {
+	ctx := testContext(t)
+	unavailable := status.Error(codes.Unavailable, "try again")
+
+	call, calls := flaky(2, unavailable)
+	start := time.Now()
+	if err := CallWithRetry(ctx, 5, 20*time.Millisecond, call); err != nil {
+		t.Errorf("CallWithRetry = %v, want success on the third call", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("made %d calls, want 3", calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("two retries took %v, want at least 20ms + 40ms of backoff", elapsed)
+	}
+
+	call, calls = flaky(10, unavailable)
+	if err := CallWithRetry(ctx, 3, time.Millisecond, call); status.Code(err) != codes.Unavailable {
+		t.Errorf("CallWithRetry = %v, want the last Unavailable error", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("made %d calls with 3 attempts, want 3", calls.Load())
+	}
+
+	call, calls = flaky(10, status.Error(codes.InvalidArgument, "bad request"))
+	if err := CallWithRetry(ctx, 3, time.Millisecond, call); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CallWithRetry = %v, want the InvalidArgument error", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls for a non-retryable error, want 1", calls.Load())
+	}
+}
+
+func TestCallWithRetryStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	call, calls := flaky(10, status.Error(codes.Unavailable, "try again"))
+	start := time.Now()
+	err := CallWithRetry(ctx, 5, time.Hour, call)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("CallWithRetry = %v, want the last Unavailable error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("CallWithRetry took %v; it should stop waiting when the context is done", elapsed)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d calls, want 1", calls.Load())
+	}
+}
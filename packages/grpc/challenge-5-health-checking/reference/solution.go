@@ -0,0 +1,170 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthMonitor runs dependency checks and publishes their results through
+// the standard gRPC health service
+type HealthMonitor struct {
+	server       *health.Server
+	checkTimeout time.Duration
+
+	mu     sync.Mutex
+	checks map[string]func(context.Context) error
+}
+
+// NewHealthMonitor creates a monitor that gives each check checkTimeout to
+// pass
+func NewHealthMonitor(checkTimeout time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		server:       health.NewServer(),
+		checkTimeout: checkTimeout,
+		checks:       make(map[string]func(context.Context) error),
+	}
+}
+
+// Server returns the health service to register on a gRPC server
+func (m *HealthMonitor) Server() *health.Server {
+	return m.server
+}
+
+// AddCheck registers check for service. The service, and with it the server
+// as a whole, is NOT_SERVING until the check first passes.
+func (m *HealthMonitor) AddCheck(service string, check func(context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks[service] = check
+	m.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	m.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// runCheck runs check with the check timeout. A check that overruns fails
+// even if it ignores its context.
+func (m *HealthMonitor) runCheck(ctx context.Context, check func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, m.checkTimeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() { result <- check(ctx) }()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CheckNow runs every check once and updates the statuses. A check fails if
+// it returns an error or takes longer than the check timeout. The server as
+// a whole, the service "", is SERVING only when every check passes.
+func (m *HealthMonitor) CheckNow(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for service, check := range m.checks {
+		err := m.runCheck(ctx, check)
+		status := healthpb.HealthCheckResponse_SERVING
+		if err != nil {
+			log.Printf("health check %s: %v", service, err)
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		m.server.SetServingStatus(service, status)
+	}
+	m.server.SetServingStatus("", overall)
+}
+
+// Run calls CheckNow right away and then every interval, until ctx is done
+func (m *HealthMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.CheckNow(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Shutdown marks every service NOT_SERVING for good: later checks no longer
+// change the statuses
+func (m *HealthMonitor) Shutdown() {
+	m.server.Shutdown()
+}
+
+// NewGRPCServer returns a server with the monitor's health service
+func NewGRPCServer(monitor *HealthMonitor) *grpc.Server {
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, monitor.Server())
+	return srv
+}
+
+// WaitForServing watches the health of service until it is SERVING. It
+// fails if the watch fails or ctx is done first.
+func WaitForServing(ctx context.Context, client healthpb.HealthClient, service string) error {
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			return nil
+		}
+	}
+}
+
+// Drain takes srv out of service: it marks every service NOT_SERVING so
+// clients move away, then stops srv gracefully. Calls still running after
+// grace are cut off.
+func Drain(srv *grpc.Server, monitor *HealthMonitor, grace time.Duration) {
+	monitor.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		srv.Stop()
+		<-stopped
+	}
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("database", func(ctx context.Context) error {
+		return nil // ping the database here
+	})
+	go monitor.Run(context.Background(), 5*time.Second)
+
+	srv := NewGRPCServer(monitor)
+	log.Println("Health service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthMonitor runs dependency checks and publishes their results through
+// the standard gRPC health service
+type HealthMonitor struct {
+	server       *health.Server
+	checkTimeout time.Duration
+
+	mu     sync.Mutex
+	checks map[string]func(context.Context) error
+}
+
+// NewHealthMonitor creates a monitor that gives each check checkTimeout to
+// pass
+func NewHealthMonitor(checkTimeout time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		server:       health.NewServer(),
+		checkTimeout: checkTimeout,
+		checks:       make(map[string]func(context.Context) error),
+	}
+}
+
+// Server returns the health service to register on a gRPC server
+func (m *HealthMonitor) Server() *health.Server {
+	return m.server
+}
+
+// AddCheck registers check for service. The service, and with it the server
+// as a whole, is NOT_SERVING until the check first passes.
+func (m *HealthMonitor) AddCheck(service string, check func(context.Context) error) {
+	// TODO: Store the check and set service and "" to NOT_SERVING with m.server.SetServingStatus
+}
+
+// runCheck runs check with the check timeout. A check that overruns fails
+// even if it ignores its context.
+func (m *HealthMonitor) runCheck(ctx context.Context, check func(context.Context) error) error {
+	// TODO: Give the check a context that times out after m.checkTimeout
+	// TODO: Run it in a goroutine and return its error, or the context's error if that comes first
+	return nil
+}
+
+// CheckNow runs every check once and updates the statuses. A check fails if
+// it returns an error or takes longer than the check timeout. The server as
+// a whole, the service "", is SERVING only when every check passes.
+func (m *HealthMonitor) CheckNow(ctx context.Context) {
+	// TODO: Set each service to SERVING or NOT_SERVING from its check
+	// TODO: Set "" to SERVING only if every check passed
+}
+
+// Run calls CheckNow right away and then every interval, until ctx is done
+func (m *HealthMonitor) Run(ctx context.Context, interval time.Duration) {
+	// TODO: Check, then wait for a time.Ticker or ctx, whichever comes first
+}
+
+// Shutdown marks every service NOT_SERVING for good: later checks no longer
+// change the statuses
+func (m *HealthMonitor) Shutdown() {
+	// TODO: health.Server has a method for this
+}
+
+// NewGRPCServer returns a server with the monitor's health service
+func NewGRPCServer(monitor *HealthMonitor) *grpc.Server {
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, monitor.Server())
+	return srv
+}
+
+// WaitForServing watches the health of service until it is SERVING. It
+// fails if the watch fails or ctx is done first.
+func WaitForServing(ctx context.Context, client healthpb.HealthClient, service string) error {
+	// TODO: Call Watch and Recv statuses until one is SERVING
+	return nil
+}
+
+// Drain takes srv out of service: it marks every service NOT_SERVING so
+// clients move away, then stops srv gracefully. Calls still running after
+// grace are cut off.
+func Drain(srv *grpc.Server, monitor *HealthMonitor, grace time.Duration) {
+	// TODO: Shut the monitor down
+	// TODO: GracefulStop in a goroutine; if it hasn't finished after grace, Stop
+}
+
+func main() {
+	lis, err := net.Listen("tcp", ":50051")
+	if err != nil {
+		log.Fatal(err)
+	}
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("database", func(ctx context.Context) error {
+		return nil // ping the database here
+	})
+	go monitor.Run(context.Background(), 5*time.Second)
+
+	srv := NewGRPCServer(monitor)
+	log.Println("Health service listening on :50051")
+	log.Fatal(srv.Serve(lis))
+}
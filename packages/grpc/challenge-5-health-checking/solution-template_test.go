@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	serving    = healthpb.HealthCheckResponse_SERVING
+	notServing = healthpb.HealthCheckResponse_NOT_SERVING
+)
+
+// serve runs a server for monitor on an in-memory listener and returns it
+// with a health client connected to it
+func serve(t *testing.T, monitor *HealthMonitor) (*grpc.Server, healthpb.HealthClient) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := NewGRPCServer(monitor)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, healthpb.NewHealthClient(conn)
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func statusOf(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := client.Check(testContext(t), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q): %v", service, err)
+	}
+	return resp.GetStatus()
+}
+
+func wantStatus(t *testing.T, client healthpb.HealthClient, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	if got := statusOf(t, client, service); got != want {
+		t.Errorf("status of %q = %s, want %s", service, got, want)
+	}
+}
+
+// eventually waits up to two seconds for service to reach want
+func eventually(t *testing.T, client healthpb.HealthClient, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := statusOf(t, client, service)
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status of %q = %s after 2s, want %s", service, got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// toggle is a check that the test makes pass or fail
+type toggle struct {
+	failing atomic.Bool
+}
+
+func (c *toggle) check(context.Context) error {
+	if c.failing.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestChecksSetStatus(t *testing.T) {
+	var db, cache toggle
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("db", db.check)
+	monitor.AddCheck("cache", cache.check)
+	_, client := serve(t, monitor)
+
+	wantStatus(t, client, "db", notServing)
+	wantStatus(t, client, "", notServing)
+
+	monitor.CheckNow(testContext(t))
+	wantStatus(t, client, "db", serving)
+	wantStatus(t, client, "cache", serving)
+	wantStatus(t, client, "", serving)
+
+	db.failing.Store(true)
+	monitor.CheckNow(testContext(t))
+	wantStatus(t, client, "db", notServing)
+	wantStatus(t, client, "cache", serving)
+	wantStatus(t, client, "", notServing)
+
+	db.failing.Store(false)
+	monitor.CheckNow(testContext(t))
+	wantStatus(t, client, "db", serving)
+	wantStatus(t, client, "", serving)
+
+	_, err := client.Check(testContext(t), &healthpb.HealthCheckRequest{Service: "payments"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Check of an unknown service: %v, want NotFound", err)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	monitor := NewHealthMonitor(50 * time.Millisecond)
+	monitor.AddCheck("stuck", func(context.Context) error {
+		<-release // ignores its context
+		return nil
+	})
+	monitor.AddCheck("deadline", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return errors.New("the check's context has no deadline")
+		}
+		return nil
+	})
+	_, client := serve(t, monitor)
+
+	start := time.Now()
+	monitor.CheckNow(testContext(t))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("CheckNow took %v with a 50ms check timeout", elapsed)
+	}
+	wantStatus(t, client, "stuck", notServing)
+	wantStatus(t, client, "deadline", serving)
+	wantStatus(t, client, "", notServing)
+}
+
+func TestRun(t *testing.T) {
+	var db toggle
+	db.failing.Store(true)
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("db", db.check)
+	_, client := serve(t, monitor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	done := make(chan struct{})
+	go func() {
+		monitor.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	db.failing.Store(false)
+	eventually(t, client, "db", serving)
+	db.failing.Store(true)
+	eventually(t, client, "db", notServing)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Error("Run kept going after its context was cancelled")
+	}
+}
+
+func TestWaitForServing(t *testing.T) {
+	var db toggle
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("db", db.check)
+	_, client := serve(t, monitor)
+
+	time.AfterFunc(50*time.Millisecond, func() { monitor.CheckNow(context.Background()) })
+	if err := WaitForServing(testContext(t), client, "db"); err != nil {
+		t.Fatalf("WaitForServing: %v", err)
+	}
+	wantStatus(t, client, "db", serving)
+
+	db.failing.Store(true)
+	monitor.CheckNow(testContext(t))
+	for _, service := range []string{"db", "payments"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		err := WaitForServing(ctx, client, service)
+		cancel()
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Errorf("WaitForServing(%q) = %v, want DeadlineExceeded", service, err)
+		}
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	var db toggle
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("db", db.check)
+	_, client := serve(t, monitor)
+	monitor.CheckNow(testContext(t))
+
+	monitor.Shutdown()
+	wantStatus(t, client, "", notServing)
+	wantStatus(t, client, "db", notServing)
+
+	monitor.CheckNow(testContext(t))
+	wantStatus(t, client, "", notServing)
+}
+
+func TestDrainCutsOffLongCalls(t *testing.T) {
+	var db toggle
+	monitor := NewHealthMonitor(time.Second)
+	monitor.AddCheck("db", db.check)
+	monitor.CheckNow(testContext(t))
+	srv, client := serve(t, monitor)
+
+	// A watch never finishes by itself, so GracefulStop alone would hang
+	stream, err := client.Watch(testContext(t), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(chan healthpb.HealthCheckResponse_ServingStatus, 10)
+	go func() {
+		defer close(statuses)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			statuses <- resp.GetStatus()
+		}
+	}()
+	if st := <-statuses; st != serving {
+		t.Fatalf("first watched status = %s, want SERVING", st)
+	}
+
+	start := time.Now()
+	Drain(srv, monitor, 100*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Drain took %v with a 100ms grace period", elapsed)
+	}
+
+	sawNotServing := false
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case st, ok := <-statuses:
+			if !ok {
+				if !sawNotServing {
+					t.Error("the watch ended without reporting NOT_SERVING")
+				}
+				return
+			}
+			sawNotServing = sawNotServing || st == notServing
+		case <-timeout:
+			t.Fatalf("the watch is still open after Drain (saw NOT_SERVING: %v)", sawNotServing)
+		}
+	}
+}
+
+func TestDrainWithoutCalls(t *testing.T) {
+	monitor := NewHealthMonitor(time.Second)
+	srv, client := serve(t, monitor)
+	wantStatus(t, client, "", serving)
+
+	start := time.Now()
+	Drain(srv, monitor, 5*time.Second)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Drain took %v with no calls running; it should not wait out the grace period", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err == nil {
+		t.Error("Check succeeded after Drain; the server should be stopped")
+	}
+}
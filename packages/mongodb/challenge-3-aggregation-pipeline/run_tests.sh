@@ -30,8 +30,8 @@ fi
 # Create a temporary directory to avoid modifying the original files
 TEMP_DIR=$(mktemp -d)
 
-# Copy the participant's solution, test file, and go.mod to the temporary directory
-cp "$SUBMISSION_FILE" "solution-template_test.go" "$TEMP_DIR/"
+# Copy the participant's solution and test files to the temporary directory
+cp "$SUBMISSION_FILE" "solution-template_test.go" "database_test.go" "$TEMP_DIR/"
 
 # Copy go.mod if it exists
 if [ -f "go.mod" ]; then
@@ -11,24 +11,21 @@ import (
 
 // Order represents an order document
 type Order struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	CustomerID primitive.ObjectID `bson:"customer_id" json:"customer_id"`
-	ProductID  primitive.ObjectID `bson:"product_id" json:"product_id"`
-	Quantity   int                `bson:"quantity" json:"quantity"`
-	Price      float64            `bson:"price" json:"price"`
-	Total      float64            `bson:"total" json:"total"`
-	Category   string             `bson:"category" json:"category"`
-	OrderDate  time.Time          `bson:"order_date" json:"order_date"`
-	Status     string             `bson:"status" json:"status"`
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CustomerID  primitive.ObjectID `bson:"customer_id" json:"customer_id"`
+	ProductName string             `bson:"product_name" json:"product_name"`
+	Quantity    int                `bson:"quantity" json:"quantity"`
+	Total       float64            `bson:"total" json:"total"`
+	Status      string             `bson:"status" json:"status"`
+	Category    string             `bson:"category" json:"category"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
 }
 
 // Customer represents a customer document
 type Customer struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name     string             `bson:"name" json:"name"`
-	Email    string             `bson:"email" json:"email"`
-	Location string             `bson:"location" json:"location"`
-	JoinDate time.Time          `bson:"join_date" json:"join_date"`
+	ID    primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name  string             `bson:"name" json:"name"`
+	Email string             `bson:"email" json:"email"`
 }
 
 // SalesByCategory represents sales analytics by category
@@ -41,28 +38,23 @@ type SalesByCategory struct {
 
 // TopProduct represents top-selling product analytics
 type TopProduct struct {
-	ProductID     primitive.ObjectID `bson:"_id" json:"product_id"`
-	TotalQuantity int                `bson:"total_quantity" json:"total_quantity"`
-	TotalRevenue  float64            `bson:"total_revenue" json:"total_revenue"`
-	OrderCount    int                `bson:"order_count" json:"order_count"`
+	ProductName string  `bson:"_id" json:"product_name"`
+	TotalSold   int     `bson:"total_sold" json:"total_sold"`
+	Revenue     float64 `bson:"revenue" json:"revenue"`
 }
 
-// MonthlyRevenue represents revenue analytics by month
+// MonthlyRevenue represents monthly revenue analytics
 type MonthlyRevenue struct {
-	Year         int     `bson:"year" json:"year"`
-	Month        int     `bson:"month" json:"month"`
-	TotalRevenue float64 `bson:"total_revenue" json:"total_revenue"`
-	OrderCount   int     `bson:"order_count" json:"order_count"`
+	Month   int     `bson:"_id" json:"month"`
+	Revenue float64 `bson:"revenue" json:"revenue"`
 }
 
-// CustomerAnalytics represents customer behavior analytics
+// CustomerAnalytics represents customer spending analytics
 type CustomerAnalytics struct {
-	CustomerID    primitive.ObjectID `bson:"_id" json:"customer_id"`
-	TotalSpent    float64            `bson:"total_spent" json:"total_spent"`
-	OrderCount    int                `bson:"order_count" json:"order_count"`
-	AvgOrderValue float64            `bson:"avg_order_value" json:"avg_order_value"`
-	FirstOrder    time.Time          `bson:"first_order" json:"first_order"`
-	LastOrder     time.Time          `bson:"last_order" json:"last_order"`
+	CustomerID   primitive.ObjectID `bson:"_id" json:"customer_id"`
+	TotalSpent   float64            `bson:"total_spent" json:"total_spent"`
+	OrderCount   int                `bson:"order_count" json:"order_count"`
+	AvgOrderSize float64            `bson:"avg_order_size" json:"avg_order_size"`
 }
 
 // Response represents a standardized API response
@@ -76,8 +68,7 @@ type Response struct {
 
 // AnalyticsService handles analytics operations using aggregation
 type AnalyticsService struct {
-	OrdersCollection    *mongo.Collection
-	CustomersCollection *mongo.Collection
+	Collection *mongo.Collection // orders
 }
 
 func main() {
@@ -93,7 +84,7 @@ func (as *AnalyticsService) GetSalesByCategory(ctx context.Context) Response {
 	// TODO: Use $match to filter completed orders
 	// TODO: Use $group to group by category and calculate totals
 	// TODO: Use $sort to order results by total sales
-	// TODO: Execute aggregation and return results
+	// TODO: Execute aggregation and return the results in Data as []SalesByCategory
 	return Response{
 		Success: false,
 		Error:   "GetSalesByCategory not implemented",
@@ -105,9 +96,10 @@ func (as *AnalyticsService) GetSalesByCategory(ctx context.Context) Response {
 func (as *AnalyticsService) GetTopSellingProducts(ctx context.Context, limit int) Response {
 	// TODO: Build aggregation pipeline
 	// TODO: Filter completed orders
-	// TODO: Group by product_id and calculate metrics
+	// TODO: Group by product_name and sum quantity and total
 	// TODO: Sort by total quantity sold
 	// TODO: Apply limit for top N products
+	// TODO: Return the results in Data as []TopProduct
 	return Response{
 		Success: false,
 		Error:   "GetTopSellingProducts not implemented",
@@ -119,9 +111,9 @@ func (as *AnalyticsService) GetTopSellingProducts(ctx context.Context, limit int
 func (as *AnalyticsService) GetRevenueByMonth(ctx context.Context, year int) Response {
 	// TODO: Build aggregation pipeline
 	// TODO: Filter orders by year if specified
-	// TODO: Extract year and month from order_date
-	// TODO: Group by year/month and calculate revenue
-	// TODO: Sort by year and month
+	// TODO: Extract the month from created_at
+	// TODO: Group by month and calculate revenue
+	// TODO: Sort by month and return the results in Data as []MonthlyRevenue
 	return Response{
 		Success: false,
 		Error:   "GetRevenueByMonth not implemented",
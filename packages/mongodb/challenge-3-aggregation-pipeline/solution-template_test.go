@@ -523,3 +523,54 @@ func TestAggregationConcepts(t *testing.T) {
 		assert.Less(t, year, 2100)
 	})
 }
+
+// seedOrders inserts order documents into a live database's orders
+// collection
+func seedOrders(t *testing.T, orders ...bson.M) *AnalyticsService {
+	t.Helper()
+	coll := liveDatabase(t).Collection("orders")
+	docs := make([]interface{}, len(orders))
+	for i, o := range orders {
+		docs[i] = o
+	}
+	if _, err := coll.InsertMany(context.Background(), docs); err != nil {
+		t.Fatal(err)
+	}
+	return &AnalyticsService{Collection: coll}
+}
+
+func TestLiveSalesByCategory(t *testing.T) {
+	service := seedOrders(t,
+		bson.M{"category": "Electronics", "total": 100.0, "status": "completed"},
+		bson.M{"category": "Electronics", "total": 300.0, "status": "completed"},
+		bson.M{"category": "Electronics", "total": 5000.0, "status": "pending"},
+		bson.M{"category": "Books", "total": 20.0, "status": "completed"},
+		bson.M{"category": "Books", "total": 40.0, "status": "completed"},
+		bson.M{"category": "Toys", "total": 75.0, "status": "completed"},
+	)
+
+	response := service.GetSalesByCategory(context.Background())
+	assert.True(t, response.Success, response.Error)
+	assert.Equal(t, []SalesByCategory{
+		{Category: "Electronics", TotalSales: 400, OrderCount: 2, AvgOrderValue: 200},
+		{Category: "Toys", TotalSales: 75, OrderCount: 1, AvgOrderValue: 75},
+		{Category: "Books", TotalSales: 60, OrderCount: 2, AvgOrderValue: 30},
+	}, response.Data, "completed orders only, highest sales first")
+}
+
+func TestLiveTopSellingProducts(t *testing.T) {
+	service := seedOrders(t,
+		bson.M{"product_name": "Mouse", "quantity": 3, "total": 60.0, "status": "completed"},
+		bson.M{"product_name": "Mouse", "quantity": 4, "total": 80.0, "status": "completed"},
+		bson.M{"product_name": "Laptop", "quantity": 1, "total": 1200.0, "status": "completed"},
+		bson.M{"product_name": "Cable", "quantity": 5, "total": 25.0, "status": "completed"},
+		bson.M{"product_name": "Laptop", "quantity": 9, "total": 10800.0, "status": "cancelled"},
+	)
+
+	response := service.GetTopSellingProducts(context.Background(), 2)
+	assert.True(t, response.Success, response.Error)
+	assert.Equal(t, []TopProduct{
+		{ProductName: "Mouse", TotalSold: 7, Revenue: 140},
+		{ProductName: "Cable", TotalSold: 5, Revenue: 25},
+	}, response.Data, "completed orders only, most sold first, limited")
+}
@@ -0,0 +1,160 @@
+package main
+
+// The tests in solution-template_test.go use mtest's mock client, which
+// replays scripted replies and needs no server. Tests that call
+// liveDatabase run against a real MongoDB instead, chosen by the
+// environment:
+//
+//	MONGODB_TEST_URI  a running server, e.g. mongodb://localhost:27017/?directConnection=true
+//	MONGOD_BINARY     a mongod binary to start for the run, in a temporary directory
+//
+// A mongod on the PATH is used if neither is set. Without any, the live
+// tests skip with a message starting "needs database:", which the web UI
+// looks for. A started mongod runs as a single-node replica set, so
+// transactions work, and is stopped when the tests finish.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const needsDatabase = "needs database: set MONGODB_TEST_URI, or MONGOD_BINARY to a mongod binary, to run this test"
+
+var live struct {
+	once   sync.Once
+	client *mongo.Client
+	err    error
+	stop   func()
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if live.client != nil {
+		live.client.Disconnect(context.Background())
+	}
+	if live.stop != nil {
+		live.stop()
+	}
+	os.Exit(code)
+}
+
+// liveDatabase returns an empty database of its own on a real server, and
+// drops it after the test. It skips the test if there is no server to use.
+func liveDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	live.once.Do(func() {
+		uri := os.Getenv("MONGODB_TEST_URI")
+		if uri == "" {
+			binary := os.Getenv("MONGOD_BINARY")
+			if binary == "" {
+				binary, _ = exec.LookPath("mongod")
+			}
+			if binary == "" {
+				return
+			}
+			uri, live.stop, live.err = startMongod(binary)
+			if live.err != nil {
+				return
+			}
+		}
+		live.client, live.err = connect(uri)
+	})
+	if live.err != nil {
+		t.Fatalf("live MongoDB: %v", live.err)
+	}
+	if live.client == nil {
+		t.Skip(needsDatabase)
+	}
+
+	db := live.client.Database("test_" + primitive.NewObjectID().Hex())
+	t.Cleanup(func() { db.Drop(context.Background()) })
+	return db
+}
+
+// connect connects to uri and waits for the server to answer
+func connect(uri string) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("ping %s: %w", uri, err)
+	}
+	return client, nil
+}
+
+// startMongod starts binary on a free local port with its data in a
+// temporary directory, as replica set rs0, and waits until it is primary
+func startMongod(binary string) (uri string, stop func(), err error) {
+	dir, err := os.MkdirTemp("", "mongod")
+	if err != nil {
+		return "", nil, err
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	addr := l.Addr().String()
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	cmd := exec.Command(binary, "--dbpath", dir, "--bind_ip", "127.0.0.1", "--port", fmt.Sprint(port), "--replSet", "rs0", "--quiet")
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	stop = func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+		os.RemoveAll(dir)
+	}
+
+	uri = "mongodb://" + addr + "/?directConnection=true"
+	client, err := connect(uri)
+	if err != nil {
+		stop()
+		return "", nil, err
+	}
+	defer client.Disconnect(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	admin := client.Database("admin")
+	config := bson.D{{Key: "_id", Value: "rs0"}, {Key: "members", Value: bson.A{
+		bson.D{{Key: "_id", Value: 0}, {Key: "host", Value: addr}},
+	}}}
+	if err := admin.RunCommand(ctx, bson.D{{Key: "replSetInitiate", Value: config}}).Err(); err != nil {
+		stop()
+		return "", nil, fmt.Errorf("replSetInitiate: %w", err)
+	}
+	for {
+		var hello struct {
+			IsWritablePrimary bool `bson:"isWritablePrimary"`
+		}
+		if err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err == nil && hello.IsWritablePrimary {
+			return uri, stop, nil
+		}
+		select {
+		case <-ctx.Done():
+			stop()
+			return "", nil, errors.New("mongod did not become primary")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
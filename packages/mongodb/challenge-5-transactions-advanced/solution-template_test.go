@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -850,3 +852,100 @@ func TestEdgeCases(t *testing.T) {
 		assert.Contains(t, response.Error, "Transactions collection not initialized")
 	})
 }
+
+// seedAccounts inserts an active account per balance into a live database
+// and returns a BankingService on it, with the accounts' IDs
+func seedAccounts(t *testing.T, balances ...float64) (*BankingService, []primitive.ObjectID) {
+	t.Helper()
+	db := liveDatabase(t)
+	service := &BankingService{
+		Client:                 db.Client(),
+		Database:               db,
+		AccountsCollection:     db.Collection("accounts"),
+		TransactionsCollection: db.Collection("transactions"),
+		AuditCollection:        db.Collection("audit_logs"),
+	}
+	ids := make([]primitive.ObjectID, len(balances))
+	for i, balance := range balances {
+		ids[i] = primitive.NewObjectID()
+		_, err := service.AccountsCollection.InsertOne(context.Background(), bson.M{
+			"_id":        ids[i],
+			"user_id":    fmt.Sprintf("user-%d", i+1),
+			"balance":    balance,
+			"status":     "active",
+			"created_at": time.Now(),
+			"updated_at": time.Now(),
+			"version":    int64(1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return service, ids
+}
+
+// balanceOf reads an account's balance straight from the database
+func balanceOf(t *testing.T, service *BankingService, id primitive.ObjectID) float64 {
+	t.Helper()
+	var account struct {
+		Balance float64 `bson:"balance"`
+	}
+	if err := service.AccountsCollection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&account); err != nil {
+		t.Fatal(err)
+	}
+	return account.Balance
+}
+
+func TestLiveTransferMoney(t *testing.T) {
+	service, ids := seedAccounts(t, 100, 20)
+	ctx := context.Background()
+
+	response := service.TransferMoney(ctx, ids[0], ids[1], 30, "Rent")
+	assert.True(t, response.Success, response.Error)
+	assert.Equal(t, 70.0, balanceOf(t, service, ids[0]))
+	assert.Equal(t, 50.0, balanceOf(t, service, ids[1]))
+
+	n, err := service.TransactionsCollection.CountDocuments(ctx, bson.M{
+		"from_account": ids[0], "to_account": ids[1], "amount": 30.0, "status": "completed",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n, "one completed transaction record")
+
+	response = service.TransferMoney(ctx, ids[1], ids[0], 500, "Too much")
+	assert.False(t, response.Success, "transfer of more than the balance")
+	assert.Equal(t, 70.0, balanceOf(t, service, ids[0]), "a failed transfer changes nothing")
+	assert.Equal(t, 50.0, balanceOf(t, service, ids[1]), "a failed transfer changes nothing")
+
+	_, err = service.AccountsCollection.UpdateByID(ctx, ids[1], bson.M{"$set": bson.M{"status": "frozen"}})
+	assert.NoError(t, err)
+	response = service.TransferMoney(ctx, ids[0], ids[1], 10, "To a frozen account")
+	assert.False(t, response.Success, "transfer to a frozen account")
+	assert.Equal(t, 70.0, balanceOf(t, service, ids[0]), "a failed transfer changes nothing")
+}
+
+func TestLiveConcurrentTransfers(t *testing.T) {
+	service, ids := seedAccounts(t, 100, 0)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if service.TransferMoney(context.Background(), ids[0], ids[1], 20, "Split bill").Success {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	from, to := balanceOf(t, service, ids[0]), balanceOf(t, service, ids[1])
+	assert.Equal(t, 100.0, from+to, "money is neither created nor destroyed")
+	assert.GreaterOrEqual(t, from, 0.0, "no overdraft")
+	assert.Equal(t, float64(20*succeeded), to, "each successful transfer moved 20")
+}
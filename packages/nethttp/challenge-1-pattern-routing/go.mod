@@ -0,0 +1,3 @@
+module nethttp-challenge-1
+
+go 1.22
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Book is a book in the catalogue
+type Book struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+// ErrorResponse is the body of every error response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// BookStore is an in-memory, concurrency-safe book catalogue
+type BookStore struct {
+	mu     sync.Mutex
+	books  map[int]Book
+	nextID int
+}
+
+// NewBookStore creates a store holding the given books
+func NewBookStore(books ...Book) *BookStore {
+	s := &BookStore{books: make(map[int]Book), nextID: 1}
+	for _, b := range books {
+		s.books[b.ID] = b
+		if b.ID >= s.nextID {
+			s.nextID = b.ID + 1
+		}
+	}
+	return s
+}
+
+// List returns the books ordered by ID
+func (s *BookStore) List() []Book {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	books := make([]Book, 0, len(s.books))
+	for _, b := range s.books {
+		books = append(books, b)
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	return books
+}
+
+// Get returns the book with the given ID
+func (s *BookStore) Get(id int) (Book, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.books[id]
+	return b, ok
+}
+
+// Create stores a new book and returns it with its ID set
+func (s *BookStore) Create(b Book) Book {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b.ID = s.nextID
+	s.nextID++
+	s.books[b.ID] = b
+	return b
+}
+
+// Update replaces the book with the given ID
+func (s *BookStore) Update(id int, b Book) (Book, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[id]; !ok {
+		return Book{}, false
+	}
+	b.ID = id
+	s.books[id] = b
+	return b, true
+}
+
+// Delete removes the book with the given ID
+func (s *BookStore) Delete(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[id]; !ok {
+		return false
+	}
+	delete(s.books, id)
+	return true
+}
+
+func main() {
+	store := NewBookStore(
+		Book{ID: 1, Title: "The Go Programming Language", Author: "Donovan"},
+		Book{ID: 2, Title: "Concurrency in Go", Author: "Cox-Buday"},
+	)
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", NewRouter(store)))
+}
+
+// NewRouter registers the book API routes on a new ServeMux
+func NewRouter(store *BookStore) *http.ServeMux {
+	mux := http.NewServeMux()
+	h := &bookHandler{store: store}
+	_ = h
+
+	// TODO: Register the routes with Go 1.22 method and wildcard patterns
+	// GET /{$}                    - h.index, the root path only
+	// GET /books                  - h.list
+	// POST /books                 - h.create
+	// GET /books/{id}             - h.get
+	// PUT /books/{id}             - h.update
+	// DELETE /books/{id}          - h.delete
+	// GET /authors/{author}/books - h.byAuthor
+	// GET /files/{path...}        - h.file
+
+	return mux
+}
+
+type bookHandler struct {
+	store *BookStore
+}
+
+// index handles GET / (and only /)
+func (h *bookHandler) index(w http.ResponseWriter, r *http.Request) {
+	// TODO: Respond with {"message": "Book API"}
+}
+
+// list handles GET /books, optionally filtered by ?author=
+func (h *bookHandler) list(w http.ResponseWriter, r *http.Request) {
+	// TODO: Return all books, or only those by the ?author= query parameter
+}
+
+// create handles POST /books
+func (h *bookHandler) create(w http.ResponseWriter, r *http.Request) {
+	// TODO: Decode the book, store it and respond 201 Created
+	// Set the Location header to /books/{id}
+}
+
+// get handles GET /books/{id}
+func (h *bookHandler) get(w http.ResponseWriter, r *http.Request) {
+	// TODO: Read the {id} wildcard with r.PathValue
+	// Return 400 for an invalid ID and 404 if the book does not exist
+}
+
+// update handles PUT /books/{id}
+func (h *bookHandler) update(w http.ResponseWriter, r *http.Request) {
+	// TODO: Replace the book, returning 400 or 404 as for get
+}
+
+// delete handles DELETE /books/{id}
+func (h *bookHandler) delete(w http.ResponseWriter, r *http.Request) {
+	// TODO: Delete the book and respond 204 No Content, or 404
+}
+
+// byAuthor handles GET /authors/{author}/books
+func (h *bookHandler) byAuthor(w http.ResponseWriter, r *http.Request) {
+	// TODO: Return the books by the {author} wildcard
+}
+
+// file handles GET /files/{path...}
+func (h *bookHandler) file(w http.ResponseWriter, r *http.Request) {
+	// TODO: Respond with {"path": "<the rest of the path>"}
+}
+
+// bookID parses the {id} wildcard, writing a 400 when it is not a number
+func bookID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid book id")
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeBook reads a book from the request body, writing a 400 when the
+// body is not JSON or has no title
+func decodeBook(w http.ResponseWriter, r *http.Request) (Book, bool) {
+	var book Book
+	if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return Book{}, false
+	}
+	if strings.TrimSpace(book.Title) == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return Book{}, false
+	}
+	return book, true
+}
+
+func filterByAuthor(books []Book, author string) []Book {
+	if author == "" {
+		return books
+	}
+	filtered := []Book{}
+	for _, b := range books {
+		if strings.EqualFold(b.Author, author) {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
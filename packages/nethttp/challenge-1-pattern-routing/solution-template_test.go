@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestRouter() *http.ServeMux {
+	return NewRouter(NewBookStore(
+		Book{ID: 1, Title: "The Go Programming Language", Author: "Donovan"},
+		Book{ID: 2, Title: "Concurrency in Go", Author: "Cox-Buday"},
+		Book{ID: 3, Title: "Go in Practice", Author: "Donovan"},
+	))
+}
+
+func do(t *testing.T, mux http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("response is not JSON: %v\n%s", err, rec.Body.String())
+	}
+}
+
+func TestIndexMatchesRootOnly(t *testing.T) {
+	mux := newTestRouter()
+
+	rec := do(t, mux, "GET", "/", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET / = %d, want 200", rec.Code)
+	}
+	var body map[string]string
+	decode(t, rec, &body)
+	if body["message"] != "Book API" {
+		t.Errorf("message = %q, want %q", body["message"], "Book API")
+	}
+
+	// {$} anchors the pattern: / must not match every path
+	if rec := do(t, mux, "GET", "/nope", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /nope = %d, want 404", rec.Code)
+	}
+}
+
+func TestListBooks(t *testing.T) {
+	rec := do(t, newTestRouter(), "GET", "/books", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /books = %d, want 200", rec.Code)
+	}
+	var books []Book
+	decode(t, rec, &books)
+	if len(books) != 3 || books[0].ID != 1 || books[2].ID != 3 {
+		t.Errorf("books = %+v, want the three books in ID order", books)
+	}
+}
+
+func TestListBooksByAuthorQuery(t *testing.T) {
+	rec := do(t, newTestRouter(), "GET", "/books?author=donovan", "")
+	var books []Book
+	decode(t, rec, &books)
+	if len(books) != 2 {
+		t.Fatalf("got %d books by donovan, want 2: %+v", len(books), books)
+	}
+	for _, b := range books {
+		if b.Author != "Donovan" {
+			t.Errorf("book %d is by %q", b.ID, b.Author)
+		}
+	}
+}
+
+func TestGetBook(t *testing.T) {
+	tests := []struct {
+		path  string
+		code  int
+		title string
+	}{
+		{"/books/2", http.StatusOK, "Concurrency in Go"},
+		{"/books/42", http.StatusNotFound, ""},
+		{"/books/abc", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := do(t, newTestRouter(), "GET", tt.path, "")
+			if rec.Code != tt.code {
+				t.Fatalf("GET %s = %d, want %d", tt.path, rec.Code, tt.code)
+			}
+			if tt.code != http.StatusOK {
+				var e ErrorResponse
+				decode(t, rec, &e)
+				if e.Error == "" {
+					t.Error("error response has no error message")
+				}
+				return
+			}
+			var b Book
+			decode(t, rec, &b)
+			if b.Title != tt.title {
+				t.Errorf("title = %q, want %q", b.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestCreateBook(t *testing.T) {
+	mux := newTestRouter()
+	rec := do(t, mux, "POST", "/books", `{"title":"Learning Go","author":"Bodner"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /books = %d, want 201", rec.Code)
+	}
+	var b Book
+	decode(t, rec, &b)
+	if b.ID != 4 || b.Title != "Learning Go" {
+		t.Errorf("created %+v, want ID 4 and the posted title", b)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/books/4" {
+		t.Errorf("Location = %q, want /books/4", loc)
+	}
+
+	if rec := do(t, mux, "GET", "/books/4", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /books/4 after create = %d, want 200", rec.Code)
+	}
+
+	rec = do(t, mux, "POST", "/books", `{"title":"100 Go Mistakes","author":"Harsanyi"}`)
+	decode(t, rec, &b)
+	if b.ID != 5 {
+		t.Errorf("second created book has ID %d, want 5", b.ID)
+	}
+}
+
+func TestCreateBookValidation(t *testing.T) {
+	for _, body := range []string{`{"title":`, `{"author":"Nobody"}`, `{"title":"   "}`} {
+		rec := do(t, newTestRouter(), "POST", "/books", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST /books %s = %d, want 400", body, rec.Code)
+		}
+	}
+}
+
+func TestUpdateBook(t *testing.T) {
+	mux := newTestRouter()
+	rec := do(t, mux, "PUT", "/books/1", `{"title":"The Go Programming Language (2nd ed.)","author":"Donovan"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT /books/1 = %d, want 200", rec.Code)
+	}
+	var b Book
+	decode(t, rec, &b)
+	if b.ID != 1 || !strings.Contains(b.Title, "2nd ed.") {
+		t.Errorf("updated %+v", b)
+	}
+
+	if rec := do(t, mux, "PUT", "/books/99", `{"title":"Missing"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("PUT /books/99 = %d, want 404", rec.Code)
+	}
+	if rec := do(t, mux, "PUT", "/books/1", `{"author":"No title"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /books/1 without a title = %d, want 400", rec.Code)
+	}
+	if rec := do(t, mux, "PUT", "/books/x", `{"title":"Bad ID"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /books/x = %d, want 400", rec.Code)
+	}
+}
+
+func TestDeleteBook(t *testing.T) {
+	mux := newTestRouter()
+	rec := do(t, mux, "DELETE", "/books/3", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE /books/3 = %d, want 204", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("204 response has a body: %q", rec.Body.String())
+	}
+	if rec := do(t, mux, "GET", "/books/3", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /books/3 after delete = %d, want 404", rec.Code)
+	}
+	if rec := do(t, mux, "DELETE", "/books/3", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second DELETE /books/3 = %d, want 404", rec.Code)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rec := do(t, newTestRouter(), "PATCH", "/books/1", `{"title":"x"}`)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("PATCH /books/1 = %d, want 405", rec.Code)
+	}
+	allow := rec.Header().Get("Allow")
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		if !strings.Contains(allow, method) {
+			t.Errorf("Allow = %q, missing %s", allow, method)
+		}
+	}
+}
+
+func TestBooksByAuthorWildcard(t *testing.T) {
+	rec := do(t, newTestRouter(), "GET", "/authors/Cox-Buday/books", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /authors/Cox-Buday/books = %d, want 200", rec.Code)
+	}
+	var books []Book
+	decode(t, rec, &books)
+	if len(books) != 1 || books[0].ID != 2 {
+		t.Errorf("books = %+v, want only book 2", books)
+	}
+
+	rec = do(t, newTestRouter(), "GET", "/authors/Nobody/books", "")
+	books = nil
+	decode(t, rec, &books)
+	if books == nil || len(books) != 0 {
+		t.Errorf("books by an unknown author = %s, want []", strings.TrimSpace(rec.Body.String()))
+	}
+}
+
+func TestRemainderWildcard(t *testing.T) {
+	rec := do(t, newTestRouter(), "GET", "/files/covers/2024/go.png", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /files/... = %d, want 200", rec.Code)
+	}
+	var body map[string]string
+	decode(t, rec, &body)
+	if body["path"] != "covers/2024/go.png" {
+		t.Errorf("path = %q, want covers/2024/go.png", body["path"])
+	}
+}
@@ -0,0 +1,3 @@
+module nethttp-challenge-2
+
+go 1.22
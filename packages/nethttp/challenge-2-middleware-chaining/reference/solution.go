@@ -0,0 +1,172 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+// Middleware wraps a handler with behaviour that runs before and after it
+type Middleware func(http.Handler) http.Handler
+
+type contextKey string
+
+const requestIDKey contextKey = "request-id"
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+func main() {
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", NewApp(os.Stdout, "secret-key")))
+}
+
+// NewApp builds the application: a mux whose /private route needs an API
+// key, wrapped in the request ID, logging and recovery middleware
+func NewApp(logOut io.Writer, apiKeys ...string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /public", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"message": "hello", "request_id": RequestIDFrom(r.Context())})
+	})
+	mux.Handle("GET /private", RequireAPIKey(apiKeys...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"message": "secret"})
+	})))
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	})
+
+	return Chain(mux, RequestID, Logger(logOut), Recoverer)
+}
+
+// Chain wraps h in the middlewares so that the first one is the outermost:
+// it sees the request first and the response last
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// RequestID gives every request an ID, taken from the X-Request-ID header
+// when the client sent one. The ID is echoed in the response header and
+// stored in the request context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFrom returns the request ID stored by RequestID, or ""
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// Logger writes one line per request to out:
+//
+//	<request-id> <method> <path> <status> <bytes>B
+func Logger(out io.Writer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			fmt.Fprintf(out, "%s %s %s %d %dB\n", RequestIDFrom(r.Context()), r.Method, r.URL.Path, rec.Status(), rec.bytes)
+		})
+	}
+}
+
+// statusRecorder remembers the status code and body size a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Status is the status code written, 200 if the handler wrote nothing
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Recoverer turns a panic in a handler into a 500 JSON error
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				log.Printf("panic serving %s: %v", r.URL.Path, err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAPIKey rejects requests whose X-API-Key header is not one of keys
+// with a 401 JSON error
+func RequireAPIKey(keys ...string) Middleware {
+	valid := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		valid[key] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !valid[r.Header.Get("X-API-Key")] {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid or missing API key"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
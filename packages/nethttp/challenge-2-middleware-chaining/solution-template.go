@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+// Middleware wraps a handler with behaviour that runs before and after it
+type Middleware func(http.Handler) http.Handler
+
+type contextKey string
+
+const requestIDKey contextKey = "request-id"
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+func main() {
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", NewApp(os.Stdout, "secret-key")))
+}
+
+// NewApp builds the application: a mux whose /private route needs an API
+// key, wrapped in the request ID, logging and recovery middleware
+func NewApp(logOut io.Writer, apiKeys ...string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /public", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"message": "hello", "request_id": RequestIDFrom(r.Context())})
+	})
+	mux.Handle("GET /private", RequireAPIKey(apiKeys...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"message": "secret"})
+	})))
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went wrong")
+	})
+
+	// TODO: Wrap the mux with Chain so that every request gets an ID, is
+	// logged with its final status, and cannot crash the server with a panic
+	return mux
+}
+
+// Chain wraps h in the middlewares so that the first one is the outermost:
+// it sees the request first and the response last
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	// TODO: Apply the middlewares so that middlewares[0] runs first
+	return h
+}
+
+// RequestID gives every request an ID, taken from the X-Request-ID header
+// when the client sent one. The ID is echoed in the response header and
+// stored in the request context.
+func RequestID(next http.Handler) http.Handler {
+	// TODO: Reuse the incoming X-Request-ID or generate one with newRequestID
+	// Set it on the response and store it in the request context
+	return next
+}
+
+// RequestIDFrom returns the request ID stored by RequestID, or ""
+func RequestIDFrom(ctx context.Context) string {
+	// TODO: Read the ID back from the context
+	return ""
+}
+
+// Logger writes one line per request to out:
+//
+//	<request-id> <method> <path> <status> <bytes>B
+func Logger(out io.Writer) Middleware {
+	return func(next http.Handler) http.Handler {
+		// TODO: Wrap w in a statusRecorder, call next, then write the line
+		return next
+	}
+}
+
+// statusRecorder remembers the status code and body size a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	// TODO: Remember the first status written
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	// TODO: A Write without WriteHeader means 200; count the bytes written
+	return r.ResponseWriter.Write(b)
+}
+
+// Status is the status code written, 200 if the handler wrote nothing
+func (r *statusRecorder) Status() int {
+	return r.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Recoverer turns a panic in a handler into a 500 JSON error
+func Recoverer(next http.Handler) http.Handler {
+	// TODO: recover() in a deferred function and respond with
+	// 500 {"error": "internal server error"}
+	// Re-panic http.ErrAbortHandler, which net/http uses to abort a response
+	return next
+}
+
+// RequireAPIKey rejects requests whose X-API-Key header is not one of keys
+// with a 401 JSON error
+func RequireAPIKey(keys ...string) Middleware {
+	return func(next http.Handler) http.Handler {
+		// TODO: Respond 401 {"error": "..."} unless X-API-Key is a valid key
+		return next
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
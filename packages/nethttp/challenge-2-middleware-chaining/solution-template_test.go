@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// trace returns a middleware that records when it runs before and after the
+// handler it wraps
+func trace(name string, events *[]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*events = append(*events, name+" before")
+			next.ServeHTTP(w, r)
+			*events = append(*events, name+" after")
+		})
+	}
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// servePanicking serves a request whose handler panics, failing the test
+// instead of crashing it when nothing recovers the panic
+func servePanicking(t *testing.T, h http.Handler, req *http.Request) (rec *httptest.ResponseRecorder) {
+	t.Helper()
+	defer func() {
+		if err := recover(); err != nil {
+			t.Fatalf("panic was not recovered: %v", err)
+		}
+	}()
+	return serve(h, req)
+}
+
+func TestChainOrder(t *testing.T) {
+	var events []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events = append(events, "handler")
+	})
+
+	h := Chain(handler, trace("a", &events), trace("b", &events), trace("c", &events))
+	serve(h, httptest.NewRequest("GET", "/", nil))
+
+	want := "a before,b before,c before,handler,c after,b after,a after"
+	if got := strings.Join(events, ","); got != want {
+		t.Errorf("events = %s\nwant     %s", got, want)
+	}
+}
+
+func TestChainWithoutMiddleware(t *testing.T) {
+	called := false
+	h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	serve(h, httptest.NewRequest("GET", "/", nil))
+	if !called {
+		t.Error("Chain with no middleware did not call the handler")
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r.Context())
+	}))
+
+	rec := serve(h, httptest.NewRequest("GET", "/", nil))
+	header := rec.Header().Get(RequestIDHeader)
+	if header == "" {
+		t.Fatal("no X-Request-ID response header")
+	}
+	if seen != header {
+		t.Errorf("context ID %q differs from header %q", seen, header)
+	}
+
+	other := serve(h, httptest.NewRequest("GET", "/", nil)).Header().Get(RequestIDHeader)
+	if other == header {
+		t.Errorf("two requests got the same ID %q", header)
+	}
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "upstream-123")
+	rec := serve(h, req)
+	if seen != "upstream-123" || rec.Header().Get(RequestIDHeader) != "upstream-123" {
+		t.Errorf("context %q, header %q; want the incoming upstream-123", seen, rec.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestRequestIDFromEmptyContext(t *testing.T) {
+	if id := RequestIDFrom(httptest.NewRequest("GET", "/", nil).Context()); id != "" {
+		t.Errorf("RequestIDFrom without RequestID = %q, want empty", id)
+	}
+}
+
+func TestLoggerRecordsStatusAndSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"explicit status", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte("short and stout"))
+		}, "GET /tea 418 15B"},
+		{"implicit 200", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+			w.Write([]byte("!"))
+		}, "GET /tea 200 3B"},
+		{"nothing written", func(w http.ResponseWriter, r *http.Request) {}, "GET /tea 200 0B"},
+		{"first status wins", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.WriteHeader(http.StatusInternalServerError)
+		}, "GET /tea 202 0B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			serve(Logger(&out)(tt.handler), httptest.NewRequest("GET", "/tea", nil))
+			if got := strings.TrimSpace(out.String()); got != tt.want {
+				t.Errorf("log line = %q, want %q", got, tt.want)
+			}
+			if strings.Count(out.String(), "\n") != 1 {
+				t.Errorf("want exactly one line, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestRecovererReturns500(t *testing.T) {
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := servePanicking(t, h, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["error"] == "" {
+		t.Errorf("body = %q, want a JSON error", rec.Body.String())
+	}
+}
+
+func TestRecovererPassesThrough(t *testing.T) {
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	if rec := serve(h, httptest.NewRequest("GET", "/", nil)); rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want the handler's 201", rec.Code)
+	}
+}
+
+func TestRecovererRepanicsAbortHandler(t *testing.T) {
+	h := Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler to be re-panicked", err)
+		}
+	}()
+	serve(h, httptest.NewRequest("GET", "/", nil))
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	h := RequireAPIKey("k1", "k2")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("in"))
+	}))
+
+	for key, code := range map[string]int{"": 401, "wrong": 401, "k1": 200, "k2": 200} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := serve(h, req)
+		if rec.Code != code {
+			t.Errorf("key %q: status %d, want %d", key, rec.Code, code)
+		}
+		if code == 401 && !strings.Contains(rec.Header().Get("Content-Type"), "application/json") {
+			t.Errorf("key %q: 401 is not JSON", key)
+		}
+	}
+}
+
+func TestAppWiring(t *testing.T) {
+	var out bytes.Buffer
+	app := NewApp(&out, "secret-key")
+
+	rec := serve(app, httptest.NewRequest("GET", "/public", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(RequestIDHeader) == "" {
+		t.Fatalf("GET /public = %d with request ID %q", rec.Code, rec.Header().Get(RequestIDHeader))
+	}
+	var body map[string]string
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["request_id"] != rec.Header().Get(RequestIDHeader) {
+		t.Errorf("handler saw request ID %q, response header %q", body["request_id"], rec.Header().Get(RequestIDHeader))
+	}
+
+	if rec := serve(app, httptest.NewRequest("GET", "/private", nil)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /private without a key = %d, want 401", rec.Code)
+	}
+	req := httptest.NewRequest("GET", "/private", nil)
+	req.Header.Set("X-API-Key", "secret-key")
+	if rec := serve(app, req); rec.Code != http.StatusOK {
+		t.Errorf("GET /private with the key = %d, want 200", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-panic")
+	if rec := servePanicking(t, app, req); rec.Code != http.StatusInternalServerError {
+		t.Errorf("GET /panic = %d, want 500", rec.Code)
+	}
+
+	// The logger sits outside the recoverer, so the panic is logged as a 500
+	// along with its request ID
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d log lines, want 4:\n%s", len(lines), out.String())
+	}
+	if !strings.Contains(lines[1], "GET /private 401") {
+		t.Errorf("line 2 = %q, want the 401", lines[1])
+	}
+	if !strings.HasPrefix(lines[3], "req-panic GET /panic 500") {
+		t.Errorf("line 4 = %q, want req-panic GET /panic 500 ...", lines[3])
+	}
+}
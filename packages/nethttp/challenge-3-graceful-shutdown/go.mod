@@ -0,0 +1,3 @@
+module nethttp-challenge-3
+
+go 1.22
@@ -0,0 +1,118 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Server timeouts. Without them a slow or idle client holds a connection,
+// and a goroutine, forever.
+const (
+	ReadHeaderTimeout = 5 * time.Second
+	ReadTimeout       = 10 * time.Second
+	WriteTimeout      = 30 * time.Second
+	IdleTimeout       = 120 * time.Second
+)
+
+func main() {
+	ready := &Readiness{}
+	mux := http.NewServeMux()
+	mux.Handle("GET /readyz", ready)
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Second)
+		w.Write([]byte("done\n"))
+	})
+
+	// Cancelled on Ctrl+C or when an orchestrator sends SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := NewServer(":8080", mux)
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Listening on :8080")
+	if err := Run(ctx, srv, ln, ready, 10*time.Second); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Shut down cleanly")
+}
+
+// NewServer returns a server for handler on addr with all four timeouts set
+func NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+		ReadTimeout:       ReadTimeout,
+		WriteTimeout:      WriteTimeout,
+		IdleTimeout:       IdleTimeout,
+	}
+}
+
+// Readiness is a readiness probe: it answers 200 until Drain is called,
+// then 503 so load balancers stop sending new traffic
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// Drain makes the probe fail from now on
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.draining.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok\n"))
+}
+
+// Run serves srv on ln until ctx is cancelled, then shuts down gracefully:
+// it fails the readiness probe, stops accepting connections and waits up to
+// timeout for in-flight requests to finish.
+//
+// Run returns nil after a clean shutdown. If requests are still running when
+// the timeout expires, it closes their connections and returns the
+// Shutdown error. If serving fails, it returns that error straight away.
+func Run(ctx context.Context, srv *http.Server, ln net.Listener, ready *Readiness, timeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	if ready != nil {
+		ready.Drain()
+	}
+
+	// ctx is already cancelled, so the shutdown deadline needs a fresh context
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// client never waits long, so a server that is not running fails the test
+// instead of hanging it
+var client = &http.Client{Timeout: 3 * time.Second}
+
+// startRun listens on a free local port and calls Run in the background. The
+// returned channel receives Run's result.
+func startRun(t *testing.T, handler http.Handler, ready *Readiness, timeout time.Duration) (cancel context.CancelFunc, url string, done chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	srv := NewServer(ln.Addr().String(), handler)
+	done = make(chan error, 1)
+	go func() {
+		done <- Run(ctx, srv, ln, ready, timeout)
+	}()
+	return cancel, "http://" + ln.Addr().String(), done
+}
+
+func waitRun(t *testing.T, done chan error, within time.Duration) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(within):
+		t.Fatalf("Run did not return within %v", within)
+		return nil
+	}
+}
+
+func get(url string) (int, string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func TestNewServerTimeouts(t *testing.T) {
+	h := http.NotFoundHandler()
+	srv := NewServer(":9999", h)
+	if srv.Addr != ":9999" || srv.Handler == nil {
+		t.Errorf("Addr = %q, Handler = %v", srv.Addr, srv.Handler)
+	}
+	checks := map[string][2]time.Duration{
+		"ReadHeaderTimeout": {srv.ReadHeaderTimeout, ReadHeaderTimeout},
+		"ReadTimeout":       {srv.ReadTimeout, ReadTimeout},
+		"WriteTimeout":      {srv.WriteTimeout, WriteTimeout},
+		"IdleTimeout":       {srv.IdleTimeout, IdleTimeout},
+	}
+	for name, c := range checks {
+		if c[0] != c[1] {
+			t.Errorf("%s = %v, want %v", name, c[0], c[1])
+		}
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	ready := &Readiness{}
+
+	rec := httptest.NewRecorder()
+	ready.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("before Drain: %d, want 200", rec.Code)
+	}
+
+	ready.Drain()
+	rec = httptest.NewRecorder()
+	ready.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("after Drain: %d, want 503", rec.Code)
+	}
+}
+
+func TestRunServesUntilCancelled(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	})
+	cancel, url, done := startRun(t, handler, nil, time.Second)
+
+	code, body, err := get(url)
+	if err != nil || code != http.StatusOK || body != "hello" {
+		t.Fatalf("GET while running: %d %q %v", code, body, err)
+	}
+
+	cancel()
+	if err := waitRun(t, done, 2*time.Second); err != nil {
+		t.Errorf("Run after a clean shutdown = %v, want nil", err)
+	}
+	if _, _, err := get(url); err == nil {
+		t.Error("server still accepts connections after Run returned")
+	}
+}
+
+func TestRunWaitsForInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	})
+	ready := &Readiness{}
+	cancel, url, done := startRun(t, handler, ready, 5*time.Second)
+
+	type result struct {
+		code int
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		code, body, err := get(url)
+		response <- result{code, body, err}
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("request never reached the handler")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		close(release)
+		t.Fatalf("Run returned %v while a request was still in flight", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	rec := httptest.NewRecorder()
+	ready.ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("readiness during shutdown = %d, want 503", rec.Code)
+	}
+
+	close(release)
+	r := <-response
+	if r.err != nil || r.code != http.StatusOK || r.body != "finished" {
+		t.Errorf("in-flight request got %d %q %v, want it to complete", r.code, r.body, r.err)
+	}
+	if err := waitRun(t, done, 2*time.Second); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	cancel, url, done := startRun(t, handler, nil, 100*time.Millisecond)
+
+	response := make(chan error, 1)
+	go func() {
+		_, _, err := get(url)
+		response <- err
+	}()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("request never reached the handler")
+	}
+
+	begin := time.Now()
+	cancel()
+	err := waitRun(t, done, 2*time.Second)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("Run took %v with a 100ms shutdown timeout", elapsed)
+	}
+
+	// The stuck request's connection is closed rather than left hanging
+	select {
+	case err := <-response:
+		if err == nil {
+			t.Error("stuck request completed; want its connection closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("stuck request's connection was not closed")
+	}
+}
+
+func TestRunServeError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(context.Background(), NewServer("", http.NotFoundHandler()), ln, nil, time.Second)
+	}()
+	err = waitRun(t, done, 2*time.Second)
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Run on a closed listener = %v, want the Serve error", err)
+	}
+}
@@ -0,0 +1,3 @@
+module nethttp-challenge-4
+
+go 1.22
@@ -0,0 +1,158 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Forecast is the weather for one city, as returned by the upstream API
+type Forecast struct {
+	City       string  `json:"city"`
+	TempC      float64 `json:"temp_c"`
+	Conditions string  `json:"conditions"`
+}
+
+// ErrNotFound is returned when the upstream API does not know the city
+var ErrNotFound = errors.New("city not found")
+
+// APIError is an error response from the upstream API other than 404
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("weather API: %d %s", e.StatusCode, e.Message)
+}
+
+// Client calls the upstream weather API
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	Retries    int           // extra attempts after a 5xx response
+	RetryDelay time.Duration // wait between attempts
+}
+
+// NewClient creates a client with a 5 second timeout that retries a 5xx
+// response twice
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		BaseURL:    baseURL,
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		Retries:    2,
+		RetryDelay: 100 * time.Millisecond,
+	}
+}
+
+// Forecast fetches GET {BaseURL}/v1/forecast?city={city}
+func (c *Client) Forecast(ctx context.Context, city string) (*Forecast, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.RetryDelay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		forecast, retry, err := c.fetch(ctx, city)
+		if !retry {
+			return forecast, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// fetch makes one attempt, and reports whether it is worth retrying
+func (c *Client) fetch(ctx context.Context, city string) (*Forecast, bool, error) {
+	endpoint := c.BaseURL + "/v1/forecast?city=" + url.QueryEscape(city)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var forecast Forecast
+		if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+			return nil, false, fmt.Errorf("decoding forecast: %w", err)
+		}
+		return &forecast, false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, ErrNotFound
+	default:
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		}
+		return nil, resp.StatusCode >= 500, apiErr
+	}
+}
+
+// ForecastGetter is what the weather handler needs from a client. Tests can
+// pass a fake instead of a real Client.
+type ForecastGetter interface {
+	Forecast(ctx context.Context, city string) (*Forecast, error)
+}
+
+// WeatherResponse is the body of GET /weather/{city}
+type WeatherResponse struct {
+	Forecast
+	Summary string `json:"summary"`
+}
+
+// NewWeatherHandler serves GET /weather/{city} from getter
+func NewWeatherHandler(getter ForecastGetter) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /weather/{city}", func(w http.ResponseWriter, r *http.Request) {
+		forecast, err := getter.Forecast(r.Context(), r.PathValue("city"))
+		switch {
+		case errors.Is(err, ErrNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown city"})
+		case err != nil:
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": "weather service unavailable"})
+		default:
+			writeJSON(w, http.StatusOK, WeatherResponse{
+				Forecast: *forecast,
+				Summary:  fmt.Sprintf("%s, %.1f°C in %s", forecast.Conditions, forecast.TempC, forecast.City),
+			})
+		}
+	})
+	return mux
+}
+
+func main() {
+	client := NewClient("https://weather.example.com", os.Getenv("WEATHER_API_KEY"))
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", NewWeatherHandler(client)))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Forecast is the weather for one city, as returned by the upstream API
+type Forecast struct {
+	City       string  `json:"city"`
+	TempC      float64 `json:"temp_c"`
+	Conditions string  `json:"conditions"`
+}
+
+// ErrNotFound is returned when the upstream API does not know the city
+var ErrNotFound = errors.New("city not found")
+
+// APIError is an error response from the upstream API other than 404
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("weather API: %d %s", e.StatusCode, e.Message)
+}
+
+// Client calls the upstream weather API
+type Client struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+	Retries    int           // extra attempts after a 5xx response
+	RetryDelay time.Duration // wait between attempts
+}
+
+// NewClient creates a client with a 5 second timeout that retries a 5xx
+// response twice
+func NewClient(baseURL, apiKey string) *Client {
+	// TODO: Set an HTTPClient with a 5 second timeout, 2 retries and a
+	// 100ms retry delay
+	return &Client{BaseURL: baseURL, APIKey: apiKey}
+}
+
+// Forecast fetches GET {BaseURL}/v1/forecast?city={city}
+func (c *Client) Forecast(ctx context.Context, city string) (*Forecast, error) {
+	// TODO: Build the request with ctx, escaping the city in the query string
+	// Send "Authorization: Bearer <APIKey>" and "Accept: application/json"
+	// 200: decode the Forecast
+	// 404: return ErrNotFound
+	// other statuses: return an *APIError with the JSON "error" message, or
+	// the status text; retry 5xx responses up to c.Retries more times,
+	// waiting c.RetryDelay between attempts
+	return nil, errors.New("not implemented")
+}
+
+// ForecastGetter is what the weather handler needs from a client. Tests can
+// pass a fake instead of a real Client.
+type ForecastGetter interface {
+	Forecast(ctx context.Context, city string) (*Forecast, error)
+}
+
+// WeatherResponse is the body of GET /weather/{city}
+type WeatherResponse struct {
+	Forecast
+	Summary string `json:"summary"`
+}
+
+// NewWeatherHandler serves GET /weather/{city} from getter
+func NewWeatherHandler(getter ForecastGetter) http.Handler {
+	mux := http.NewServeMux()
+	// TODO: Register GET /weather/{city}
+	// 200 with a WeatherResponse, e.g. summary "Sunny, 21.5°C in Paris"
+	// 404 {"error": "unknown city"} for ErrNotFound
+	// 502 {"error": "weather service unavailable"} for any other error
+	return mux
+}
+
+func main() {
+	client := NewClient("https://weather.example.com", os.Getenv("WEATHER_API_KEY"))
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", NewWeatherHandler(client)))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newUpstream starts a stub of the weather API. Each test decides how it
+// answers; the server is shut down when the test ends.
+func newUpstream(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestClient points a client at the stub with no delay between retries
+func newTestClient(srv *httptest.Server) *Client {
+	c := NewClient(srv.URL, "test-key")
+	c.RetryDelay = 0
+	return c
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient("https://weather.example.com", "k")
+	if c.HTTPClient == nil || c.HTTPClient.Timeout != 5*time.Second {
+		t.Errorf("HTTPClient = %+v, want a client with a 5s timeout", c.HTTPClient)
+	}
+	if c.Retries != 2 || c.RetryDelay != 100*time.Millisecond {
+		t.Errorf("Retries = %d, RetryDelay = %v; want 2 and 100ms", c.Retries, c.RetryDelay)
+	}
+}
+
+func TestForecastRequest(t *testing.T) {
+	var got *http.Request
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Clone(context.Background())
+		fmt.Fprint(w, `{"city":"São Paulo","temp_c":27.5,"conditions":"Cloudy"}`)
+	})
+
+	forecast, err := newTestClient(srv).Forecast(context.Background(), "São Paulo")
+	if err != nil {
+		t.Fatalf("Forecast: %v", err)
+	}
+	if *forecast != (Forecast{City: "São Paulo", TempC: 27.5, Conditions: "Cloudy"}) {
+		t.Errorf("forecast = %+v", forecast)
+	}
+
+	if got == nil {
+		t.Fatal("the stub never received a request")
+	}
+	if got.Method != "GET" || got.URL.Path != "/v1/forecast" {
+		t.Errorf("request = %s %s, want GET /v1/forecast", got.Method, got.URL.Path)
+	}
+	if city := got.URL.Query().Get("city"); city != "São Paulo" {
+		t.Errorf("city query = %q; escape it with url.QueryEscape", city)
+	}
+	if auth := got.Header.Get("Authorization"); auth != "Bearer test-key" {
+		t.Errorf("Authorization = %q, want Bearer test-key", auth)
+	}
+	if accept := got.Header.Get("Accept"); accept != "application/json" {
+		t.Errorf("Accept = %q, want application/json", accept)
+	}
+}
+
+func TestForecastNotFound(t *testing.T) {
+	var calls int32
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, `{"error":"no such city"}`, http.StatusNotFound)
+	})
+
+	_, err := newTestClient(srv).Forecast(context.Background(), "Atlantis")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+	if calls != 1 {
+		t.Errorf("a 404 was requested %d times, want 1", calls)
+	}
+}
+
+func TestForecastClientErrorIsNotRetried(t *testing.T) {
+	var calls int32
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"bad API key"}`)
+	})
+
+	_, err := newTestClient(srv).Forecast(context.Background(), "Paris")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "bad API key" {
+		t.Errorf("APIError = %+v, want 401 with the message from the body", apiErr)
+	}
+	if calls != 1 {
+		t.Errorf("a 401 was requested %d times, want 1", calls)
+	}
+}
+
+func TestForecastRetriesServerErrors(t *testing.T) {
+	var calls int32
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"city":"Oslo","temp_c":-3,"conditions":"Snow"}`)
+	})
+
+	forecast, err := newTestClient(srv).Forecast(context.Background(), "Oslo")
+	if err != nil {
+		t.Fatalf("Forecast after two 503s: %v", err)
+	}
+	if forecast.City != "Oslo" || calls != 3 {
+		t.Errorf("forecast %+v after %d calls, want Oslo after 3", forecast, calls)
+	}
+}
+
+func TestForecastGivesUpAfterRetries(t *testing.T) {
+	var calls int32
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "upstream down")
+	})
+
+	c := newTestClient(srv)
+	c.Retries = 3
+	_, err := c.Forecast(context.Background(), "Lima")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("err = %v, want an *APIError with status 500", err)
+	}
+	if apiErr.Message != "Internal Server Error" {
+		t.Errorf("Message = %q; a body that is not JSON should fall back to the status text", apiErr.Message)
+	}
+	if calls != 4 {
+		t.Errorf("upstream called %d times, want 1 + 3 retries", calls)
+	}
+}
+
+func TestForecastInvalidJSON(t *testing.T) {
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"city":`)
+	})
+	if _, err := newTestClient(srv).Forecast(context.Background(), "Rome"); err == nil {
+		t.Error("truncated JSON decoded without an error")
+	}
+}
+
+func TestForecastHonoursContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	_, err := newTestClient(srv).Forecast(ctx, "Cairo")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("Forecast took %v with a 50ms deadline", elapsed)
+	}
+}
+
+func TestForecastOverTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"city":"Tokyo","temp_c":18,"conditions":"Clear"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "k")
+	if c.HTTPClient == nil {
+		t.Fatal("NewClient did not set an HTTPClient")
+	}
+	// srv.Client trusts the test server's self-signed certificate
+	c.HTTPClient = srv.Client()
+	forecast, err := c.Forecast(context.Background(), "Tokyo")
+	if err != nil || forecast.City != "Tokyo" {
+		t.Errorf("Forecast over TLS = %+v, %v", forecast, err)
+	}
+}
+
+// fakeGetter stands in for the client in handler tests, so they need no
+// upstream server at all
+type fakeGetter struct {
+	forecast *Forecast
+	err      error
+	city     string
+}
+
+func (f *fakeGetter) Forecast(ctx context.Context, city string) (*Forecast, error) {
+	f.city = city
+	return f.forecast, f.err
+}
+
+func TestWeatherHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		getter *fakeGetter
+		code   int
+		want   string
+	}{
+		{"found", &fakeGetter{forecast: &Forecast{City: "Paris", TempC: 21.5, Conditions: "Sunny"}},
+			http.StatusOK, "Sunny, 21.5°C in Paris"},
+		{"unknown city", &fakeGetter{err: ErrNotFound}, http.StatusNotFound, "unknown city"},
+		{"wrapped not found", &fakeGetter{err: fmt.Errorf("lookup: %w", ErrNotFound)}, http.StatusNotFound, "unknown city"},
+		{"upstream failure", &fakeGetter{err: &APIError{StatusCode: 500, Message: "boom"}},
+			http.StatusBadGateway, "weather service unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewWeatherHandler(tt.getter).ServeHTTP(rec, httptest.NewRequest("GET", "/weather/Paris", nil))
+
+			if rec.Code != tt.code {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if tt.getter.city != "Paris" {
+				t.Errorf("getter asked for %q, want the {city} from the path", tt.getter.city)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body is not JSON: %s", rec.Body.String())
+			}
+			field := "error"
+			if tt.code == http.StatusOK {
+				field = "summary"
+				if body["city"] != "Paris" || body["temp_c"] != 21.5 {
+					t.Errorf("body = %v, want the forecast fields inline", body)
+				}
+			}
+			if body[field] != tt.want {
+				t.Errorf("%s = %v, want %q", field, body[field], tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherHandlerEndToEnd(t *testing.T) {
+	upstream := newUpstream(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("city") == "Berlin" {
+			fmt.Fprint(w, `{"city":"Berlin","temp_c":12,"conditions":"Rain"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	app := httptest.NewServer(NewWeatherHandler(newTestClient(upstream)))
+	defer app.Close()
+
+	resp, err := app.Client().Get(app.URL + "/weather/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body WeatherResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body.Summary, "Rain, 12.0°C in Berlin") {
+		t.Errorf("GET /weather/Berlin = %d %+v", resp.StatusCode, body)
+	}
+
+	resp, err = app.Client().Get(app.URL + "/weather/Nowhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /weather/Nowhere = %d, want 404", resp.StatusCode)
+	}
+}
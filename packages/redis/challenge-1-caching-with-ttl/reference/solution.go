@@ -0,0 +1,117 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Product is what the cache stores, as JSON
+type Product struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	PriceCents int64  `json:"price_cents"`
+}
+
+// ErrNotFound is returned for a product that doesn't exist
+var ErrNotFound = errors.New("product not found")
+
+// Loader fetches a product from the source of truth, usually a database
+type Loader func(ctx context.Context, id string) (Product, error)
+
+// notFoundMarker is cached in place of a product that doesn't exist
+const notFoundMarker = "-"
+
+// ProductCache puts Redis in front of a Loader
+type ProductCache struct {
+	client      *redis.Client
+	load        Loader
+	ttl         time.Duration
+	notFoundTTL time.Duration
+}
+
+// NewProductCache returns a cache that keeps products for ttl and remembers
+// missing products for notFoundTTL
+func NewProductCache(client *redis.Client, load Loader, ttl, notFoundTTL time.Duration) *ProductCache {
+	return &ProductCache{client: client, load: load, ttl: ttl, notFoundTTL: notFoundTTL}
+}
+
+// productKey is the Redis key for a product
+func productKey(id string) string {
+	return "product:" + id
+}
+
+// Get returns a product from the cache, or loads and caches it on a miss.
+// Missing products are cached too, so repeated lookups don't reach the
+// loader; other loader errors are not cached. A corrupt entry counts as a
+// miss, and if Redis is unreachable Get goes straight to the loader.
+func (c *ProductCache) Get(ctx context.Context, id string) (Product, error) {
+	key := productKey(id)
+	data, err := c.client.Get(ctx, key).Result()
+	switch {
+	case err == nil:
+		if data == notFoundMarker {
+			return Product{}, ErrNotFound
+		}
+		var p Product
+		if json.Unmarshal([]byte(data), &p) == nil {
+			return p, nil
+		}
+	case errors.Is(err, redis.Nil):
+	default:
+		return c.load(ctx, id)
+	}
+
+	p, err := c.load(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		c.client.Set(ctx, key, notFoundMarker, c.notFoundTTL)
+		return Product{}, err
+	}
+	if err != nil {
+		return Product{}, err
+	}
+	// A failed SET only means the next Get is a miss too
+	if data, err := json.Marshal(p); err == nil {
+		c.client.Set(ctx, key, data, c.ttl)
+	}
+	return p, nil
+}
+
+// Invalidate removes a product from the cache, after it changed
+func (c *ProductCache) Invalidate(ctx context.Context, id string) error {
+	return c.client.Del(ctx, productKey(id)).Err()
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	cache := NewProductCache(client, func(ctx context.Context, id string) (Product, error) {
+		fmt.Println("loading", id, "from the database")
+		if id != "p1" {
+			return Product{}, ErrNotFound
+		}
+		return Product{ID: "p1", Name: "Gopher plush", PriceCents: 1999}, nil
+	}, 10*time.Minute, time.Minute)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		p, err := cache.Get(ctx, "p1")
+		fmt.Println(p, err)
+	}
+	_, err := cache.Get(ctx, "p2")
+	fmt.Println(err)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Product is what the cache stores, as JSON
+type Product struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	PriceCents int64  `json:"price_cents"`
+}
+
+// ErrNotFound is returned for a product that doesn't exist
+var ErrNotFound = errors.New("product not found")
+
+// Loader fetches a product from the source of truth, usually a database
+type Loader func(ctx context.Context, id string) (Product, error)
+
+// notFoundMarker is cached in place of a product that doesn't exist
+const notFoundMarker = "-"
+
+// ProductCache puts Redis in front of a Loader
+type ProductCache struct {
+	client      *redis.Client
+	load        Loader
+	ttl         time.Duration
+	notFoundTTL time.Duration
+}
+
+// NewProductCache returns a cache that keeps products for ttl and remembers
+// missing products for notFoundTTL
+func NewProductCache(client *redis.Client, load Loader, ttl, notFoundTTL time.Duration) *ProductCache {
+	return &ProductCache{client: client, load: load, ttl: ttl, notFoundTTL: notFoundTTL}
+}
+
+// productKey is the Redis key for a product
+func productKey(id string) string {
+	return "product:" + id
+}
+
+// Get returns a product from the cache, or loads and caches it on a miss.
+// Missing products are cached too, so repeated lookups don't reach the
+// loader; other loader errors are not cached. A corrupt entry counts as a
+// miss, and if Redis is unreachable Get goes straight to the loader.
+func (c *ProductCache) Get(ctx context.Context, id string) (Product, error) {
+	// TODO: GET the key; a hit is either notFoundMarker or the product's JSON
+	// TODO: redis.Nil is a miss; any other error means Redis is down, so just load
+	// TODO: On a miss, load, then SET the JSON for c.ttl, or notFoundMarker for c.notFoundTTL on ErrNotFound
+	return c.load(ctx, id)
+}
+
+// Invalidate removes a product from the cache, after it changed
+func (c *ProductCache) Invalidate(ctx context.Context, id string) error {
+	// TODO: DEL the key
+	return nil
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	cache := NewProductCache(client, func(ctx context.Context, id string) (Product, error) {
+		fmt.Println("loading", id, "from the database")
+		if id != "p1" {
+			return Product{}, ErrNotFound
+		}
+		return Product{ID: "p1", Name: "Gopher plush", PriceCents: 1999}, nil
+	}, 10*time.Minute, time.Minute)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		p, err := cache.Get(ctx, "p1")
+		fmt.Println(p, err)
+	}
+	_, err := cache.Get(ctx, "p2")
+	fmt.Println(err)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	testTTL         = 10 * time.Minute
+	testNotFoundTTL = time.Minute
+)
+
+// newTestRedis starts an in-process Redis and a client for it
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+// fakeDB is a Loader that counts its calls
+type fakeDB struct {
+	products map[string]Product
+	err      error
+	calls    int
+}
+
+func (db *fakeDB) load(ctx context.Context, id string) (Product, error) {
+	db.calls++
+	if db.err != nil {
+		return Product{}, db.err
+	}
+	p, ok := db.products[id]
+	if !ok {
+		return Product{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{products: map[string]Product{
+		"p1": {ID: "p1", Name: "Gopher plush", PriceCents: 1999},
+	}}
+}
+
+func TestCacheAside(t *testing.T) {
+	mr, client := newTestRedis(t)
+	db := newFakeDB()
+	cache := NewProductCache(client, db.load, testTTL, testNotFoundTTL)
+	ctx := context.Background()
+	want := db.products["p1"]
+
+	for i := 0; i < 3; i++ {
+		got, err := cache.Get(ctx, "p1")
+		if err != nil || got != want {
+			t.Fatalf("Get #%d = %v, %v; want %v", i+1, got, err, want)
+		}
+	}
+	if db.calls != 1 {
+		t.Errorf("loader called %d times for 3 Gets, want 1", db.calls)
+	}
+
+	raw, err := mr.Get("product:p1")
+	if err != nil {
+		t.Fatalf("nothing cached at product:p1: %v", err)
+	}
+	var cached Product
+	if err := json.Unmarshal([]byte(raw), &cached); err != nil || cached != want {
+		t.Errorf("cached %q, want the product as JSON", raw)
+	}
+	if ttl := mr.TTL("product:p1"); ttl != testTTL {
+		t.Errorf("TTL = %v, want %v", ttl, testTTL)
+	}
+
+	mr.FastForward(testTTL)
+	if _, err := cache.Get(ctx, "p1"); err != nil || db.calls != 2 {
+		t.Errorf("after the TTL: err %v, loader calls %d; want the product loaded again", err, db.calls)
+	}
+}
+
+func TestNotFoundIsCached(t *testing.T) {
+	mr, client := newTestRedis(t)
+	db := newFakeDB()
+	cache := NewProductCache(client, db.load, testTTL, testNotFoundTTL)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get(ctx, "nope"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get #%d = %v, want ErrNotFound", i+1, err)
+		}
+	}
+	if db.calls != 1 {
+		t.Errorf("loader called %d times, want 1: missing products should be cached", db.calls)
+	}
+	if ttl := mr.TTL("product:nope"); ttl != testNotFoundTTL {
+		t.Errorf("TTL of the not-found entry = %v, want %v", ttl, testNotFoundTTL)
+	}
+
+	db.products["nope"] = Product{ID: "nope", Name: "Now in stock"}
+	mr.FastForward(testNotFoundTTL)
+	if p, err := cache.Get(ctx, "nope"); err != nil || p.Name != "Now in stock" {
+		t.Errorf("after the not-found TTL: %v, %v", p, err)
+	}
+}
+
+func TestLoaderErrorsAreNotCached(t *testing.T) {
+	mr, client := newTestRedis(t)
+	db := newFakeDB()
+	db.err = errors.New("database timeout")
+	cache := NewProductCache(client, db.load, testTTL, testNotFoundTTL)
+	ctx := context.Background()
+
+	if _, err := cache.Get(ctx, "p1"); !errors.Is(err, db.err) {
+		t.Fatalf("Get = %v, want the loader's error", err)
+	}
+	if mr.Exists("product:p1") {
+		t.Error("a loader error was cached")
+	}
+	db.err = nil
+	if p, err := cache.Get(ctx, "p1"); err != nil || p.ID != "p1" {
+		t.Errorf("Get after the database recovered = %v, %v", p, err)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	mr, client := newTestRedis(t)
+	db := newFakeDB()
+	cache := NewProductCache(client, db.load, testTTL, testNotFoundTTL)
+	ctx := context.Background()
+
+	cache.Get(ctx, "p1")
+	db.products["p1"] = Product{ID: "p1", Name: "Gopher plush", PriceCents: 1499}
+	if p, _ := cache.Get(ctx, "p1"); p.PriceCents != 1999 {
+		t.Fatalf("price %d before Invalidate, want the cached 1999", p.PriceCents)
+	}
+	if err := cache.Invalidate(ctx, "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("product:p1") {
+		t.Error("product:p1 still exists after Invalidate")
+	}
+	if p, _ := cache.Get(ctx, "p1"); p.PriceCents != 1499 {
+		t.Errorf("price %d after Invalidate, want the new 1499", p.PriceCents)
+	}
+}
+
+func TestCorruptEntryIsAMiss(t *testing.T) {
+	mr, client := newTestRedis(t)
+	db := newFakeDB()
+	cache := NewProductCache(client, db.load, testTTL, testNotFoundTTL)
+
+	mr.Set("product:p1", "{not json")
+	p, err := cache.Get(context.Background(), "p1")
+	if err != nil || p != db.products["p1"] {
+		t.Fatalf("Get = %v, %v; want the loaded product", p, err)
+	}
+	if raw, _ := mr.Get("product:p1"); !json.Valid([]byte(raw)) {
+		t.Errorf("corrupt entry not replaced: %q", raw)
+	}
+}
+
+func TestRedisDown(t *testing.T) {
+	mr, client := newTestRedis(t)
+	db := newFakeDB()
+	cache := NewProductCache(client, db.load, testTTL, testNotFoundTTL)
+	mr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	p, err := cache.Get(ctx, "p1")
+	if err != nil || p != db.products["p1"] {
+		t.Errorf("Get with Redis down = %v, %v; want the product from the loader", p, err)
+	}
+}
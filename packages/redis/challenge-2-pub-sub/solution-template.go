@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// channelPrefix starts the Redis channel of every room
+const channelPrefix = "chat:"
+
+var (
+	// ErrEmptyMessage is returned by Publish for a message without text
+	ErrEmptyMessage = errors.New("message has no text")
+	// ErrNoRooms is returned by Subscribe without any rooms
+	ErrNoRooms = errors.New("no rooms to subscribe to")
+)
+
+// Message is a chat message, sent over Redis as JSON
+type Message struct {
+	Room string `json:"-"` // filled in from the channel on receipt
+	User string `json:"user"`
+	Text string `json:"text"`
+}
+
+// roomChannel is the Redis channel for a room
+func roomChannel(room string) string {
+	return channelPrefix + room
+}
+
+// Chat sends messages between chat rooms over Redis pub/sub
+type Chat struct {
+	client *redis.Client
+}
+
+// NewChat returns a Chat using client
+func NewChat(client *redis.Client) *Chat {
+	return &Chat{client: client}
+}
+
+// Publish sends msg to room and returns how many subscribers received it
+func (c *Chat) Publish(ctx context.Context, room string, msg Message) (int64, error) {
+	// TODO: Reject blank text with ErrEmptyMessage
+	// TODO: PUBLISH the JSON to the room's channel, returning the receiver count
+	return 0, nil
+}
+
+// Subscribers returns how many clients are subscribed to room by name
+func (c *Chat) Subscribers(ctx context.Context, room string) (int64, error) {
+	// TODO: PUBSUB NUMSUB for the room's channel
+	return 0, nil
+}
+
+// Subscription delivers the messages of the rooms it is subscribed to
+type Subscription struct {
+	// Messages is closed after Close, or when the context passed to
+	// Subscribe is done
+	Messages <-chan Message
+
+	pubsub *redis.PubSub
+}
+
+// Close unsubscribes and closes Messages
+func (s *Subscription) Close() error {
+	// TODO: Close the PubSub
+	return nil
+}
+
+// Subscribe subscribes to rooms. It returns once Redis has confirmed the
+// subscription, so every message published after that is delivered.
+func (c *Chat) Subscribe(ctx context.Context, rooms ...string) (*Subscription, error) {
+	// TODO: Return ErrNoRooms without rooms, then SUBSCRIBE to every room's channel and listen
+	return &Subscription{Messages: make(chan Message)}, nil
+}
+
+// SubscribeAll subscribes to every room with a pattern
+func (c *Chat) SubscribeAll(ctx context.Context) (*Subscription, error) {
+	// TODO: PSUBSCRIBE to channelPrefix+"*" and listen
+	return &Subscription{Messages: make(chan Message)}, nil
+}
+
+// listen waits for pubsub's subscription to be confirmed, then decodes its
+// messages into a Subscription until it is closed or ctx is done. Messages
+// that aren't valid JSON are skipped.
+func listen(ctx context.Context, pubsub *redis.PubSub) (*Subscription, error) {
+	// TODO: Wait for the confirmation with pubsub.Receive
+	// TODO: In a goroutine, decode pubsub.Channel() into Messages, setting Room from the channel name
+	// TODO: Stop and close Messages when the PubSub channel closes or ctx is done
+	return &Subscription{Messages: make(chan Message), pubsub: pubsub}, nil
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	chat := NewChat(client)
+	sub, err := chat.Subscribe(ctx, "general")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sub.Close()
+
+	n, _ := chat.Publish(ctx, "general", Message{User: "ada", Text: "hello, gophers"})
+	fmt.Println("delivered to", n, "subscriber(s)")
+	msg := <-sub.Messages
+	fmt.Printf("[%s] %s: %s\n", msg.Room, msg.User, msg.Text)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestChat starts an in-process Redis and a Chat on it
+func newTestChat(t *testing.T) (*miniredis.Miniredis, *redis.Client, *Chat) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client, NewChat(client)
+}
+
+// subscribe subscribes to rooms, or to every room without any, and closes
+// the subscription at the end of the test
+func subscribe(ctx context.Context, t *testing.T, chat *Chat, rooms ...string) *Subscription {
+	t.Helper()
+	var sub *Subscription
+	var err error
+	if len(rooms) == 0 {
+		sub, err = chat.SubscribeAll(ctx)
+	} else {
+		sub, err = chat.Subscribe(ctx, rooms...)
+	}
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	t.Cleanup(func() { sub.Close() })
+	return sub
+}
+
+// receive waits for the next message
+func receive(t *testing.T, sub *Subscription) Message {
+	t.Helper()
+	select {
+	case msg, ok := <-sub.Messages:
+		if !ok {
+			t.Fatal("Messages closed, want a message")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message within 2s")
+	}
+	return Message{}
+}
+
+// expectClosed waits for Messages to be closed
+func expectClosed(t *testing.T, sub *Subscription) {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-sub.Messages:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Messages not closed within 2s")
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	_, _, chat := newTestChat(t)
+	ctx := context.Background()
+	sub := subscribe(ctx, t, chat, "general")
+
+	n, err := chat.Publish(ctx, "general", Message{User: "ada", Text: "hello"})
+	if err != nil || n != 1 {
+		t.Fatalf("Publish = %d, %v; want 1 receiver", n, err)
+	}
+	if n, _ := chat.Publish(ctx, "random", Message{User: "bob", Text: "elsewhere"}); n != 0 {
+		t.Errorf("Publish to a room nobody is in = %d receivers, want 0", n)
+	}
+	chat.Publish(ctx, "general", Message{User: "bob", Text: "hi ada"})
+
+	want := []Message{
+		{Room: "general", User: "ada", Text: "hello"},
+		{Room: "general", User: "bob", Text: "hi ada"},
+	}
+	for _, w := range want {
+		if got := receive(t, sub); got != w {
+			t.Errorf("received %+v, want %+v", got, w)
+		}
+	}
+}
+
+func TestMessagesAreJSON(t *testing.T) {
+	_, client, chat := newTestChat(t)
+	ctx := context.Background()
+	raw := client.Subscribe(ctx, "chat:general")
+	defer raw.Close()
+	if _, err := raw.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	chat.Publish(ctx, "general", Message{Room: "ignored", User: "ada", Text: "hello"})
+	select {
+	case m := <-raw.Channel():
+		if m.Payload != `{"user":"ada","text":"hello"}` {
+			t.Errorf("payload %s, want {\"user\":\"ada\",\"text\":\"hello\"}", m.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("nothing published on chat:general")
+	}
+}
+
+func TestSubscribeToSeveralRooms(t *testing.T) {
+	_, _, chat := newTestChat(t)
+	ctx := context.Background()
+	sub := subscribe(ctx, t, chat, "go", "redis")
+
+	chat.Publish(ctx, "go", Message{User: "ada", Text: "generics!"})
+	chat.Publish(ctx, "rust", Message{User: "bob", Text: "not subscribed"})
+	chat.Publish(ctx, "redis", Message{User: "cy", Text: "streams!"})
+
+	if got := receive(t, sub); got.Room != "go" || got.Text != "generics!" {
+		t.Errorf("first message %+v", got)
+	}
+	if got := receive(t, sub); got.Room != "redis" || got.Text != "streams!" {
+		t.Errorf("second message %+v", got)
+	}
+
+	if _, err := chat.Subscribe(ctx); !errors.Is(err, ErrNoRooms) {
+		t.Errorf("Subscribe without rooms = %v, want ErrNoRooms", err)
+	}
+}
+
+func TestSubscribeAll(t *testing.T) {
+	_, client, chat := newTestChat(t)
+	ctx := context.Background()
+	sub := subscribe(ctx, t, chat)
+
+	if n, _ := chat.Publish(ctx, "anything", Message{User: "ada", Text: "one"}); n != 1 {
+		t.Errorf("Publish = %d receivers, want 1 pattern subscriber", n)
+	}
+	client.Publish(ctx, "news:today", `{"user":"x","text":"not a chat room"}`)
+	chat.Publish(ctx, "other", Message{User: "bob", Text: "two"})
+
+	if got := receive(t, sub); got != (Message{Room: "anything", User: "ada", Text: "one"}) {
+		t.Errorf("first message %+v", got)
+	}
+	if got := receive(t, sub); got != (Message{Room: "other", User: "bob", Text: "two"}) {
+		t.Errorf("second message %+v, want only chat rooms", got)
+	}
+}
+
+func TestMalformedMessagesAreSkipped(t *testing.T) {
+	_, client, chat := newTestChat(t)
+	ctx := context.Background()
+	sub := subscribe(ctx, t, chat, "general")
+
+	client.Publish(ctx, "chat:general", "not json")
+	chat.Publish(ctx, "general", Message{User: "ada", Text: "still here"})
+	if got := receive(t, sub); got.Text != "still here" {
+		t.Errorf("received %+v, want the malformed message skipped", got)
+	}
+}
+
+func TestEmptyMessage(t *testing.T) {
+	_, _, chat := newTestChat(t)
+	ctx := context.Background()
+	sub := subscribe(ctx, t, chat, "general")
+
+	if n, err := chat.Publish(ctx, "general", Message{User: "ada", Text: "  "}); n != 0 || !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("Publish of blank text = %d, %v; want 0, ErrEmptyMessage", n, err)
+	}
+	chat.Publish(ctx, "general", Message{User: "ada", Text: "real"})
+	if got := receive(t, sub); got.Text != "real" {
+		t.Errorf("received %+v: the blank message was published", got)
+	}
+}
+
+func TestSubscribersAndClose(t *testing.T) {
+	_, _, chat := newTestChat(t)
+	ctx := context.Background()
+	first := subscribe(ctx, t, chat, "general")
+	subscribe(ctx, t, chat, "general", "other")
+
+	if n, err := chat.Subscribers(ctx, "general"); err != nil || n != 2 {
+		t.Fatalf("Subscribers = %d, %v; want 2", n, err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expectClosed(t, first)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n, _ := chat.Subscribers(ctx, "general")
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Subscribers = %d after Close, want 1", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestContextCancelEndsSubscription(t *testing.T) {
+	_, _, chat := newTestChat(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := subscribe(ctx, t, chat, "general")
+
+	cancel()
+	expectClosed(t, sub)
+}
@@ -0,0 +1,191 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotAcquired is returned when someone else holds the lock
+	ErrNotAcquired = errors.New("lock is held by someone else")
+	// ErrNotHeld is returned when a lock expired or was taken over
+	ErrNotHeld = errors.New("lock is no longer held")
+)
+
+// releaseScript deletes the lock only if it still holds our token
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// refreshScript extends the lock only if it still holds our token
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Locker hands out locks stored in Redis
+type Locker struct {
+	client *redis.Client
+}
+
+// NewLocker returns a Locker using client
+func NewLocker(client *redis.Client) *Locker {
+	return &Locker{client: client}
+}
+
+// Lock is a lock held by this process until it is released or expires
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+// lockKey is the Redis key for a lock name
+func lockKey(name string) string {
+	return "lock:" + name
+}
+
+// newToken returns a random value that identifies one holder of a lock
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Obtain takes the lock called name for ttl, or returns ErrNotAcquired if
+// it is taken
+func (l *Locker) Obtain(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	lock := &Lock{client: l.client, key: lockKey(name), token: newToken()}
+	ok, err := l.client.SetNX(ctx, lock.key, lock.token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotAcquired
+	}
+	return lock, nil
+}
+
+// ObtainWait tries to take the lock every retry until it succeeds or ctx is
+// done
+func (l *Locker) ObtainWait(ctx context.Context, name string, ttl, retry time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(retry)
+	defer ticker.Stop()
+	for {
+		lock, err := l.Obtain(ctx, name, ttl)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release gives the lock up. It returns ErrNotHeld, and leaves the key alone,
+// if the lock expired or someone else has taken it since.
+func (lk *Lock) Release(ctx context.Context) error {
+	n, err := releaseScript.Run(ctx, lk.client, []string{lk.key}, lk.token).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Refresh extends the lock to ttl from now, or returns ErrNotHeld if it is
+// no longer ours
+func (lk *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	n, err := refreshScript.Run(ctx, lk.client, []string{lk.key}, lk.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// WithLock runs fn while holding the lock called name, refreshing it every
+// ttl/3 and releasing it afterwards. If a refresh fails, fn's context is
+// cancelled and WithLock returns ErrNotHeld. Otherwise it returns fn's
+// error, or the error from releasing the lock.
+func (l *Locker) WithLock(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	lock, err := l.Obtain(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lost := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-fnCtx.Done():
+				return
+			case <-ticker.C:
+				if err := lock.Refresh(fnCtx, ttl); err != nil && fnCtx.Err() == nil {
+					close(lost)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(fnCtx)
+	cancel()
+	<-stopped
+	select {
+	case <-lost:
+		return ErrNotHeld
+	default:
+	}
+	if releaseErr := lock.Release(context.WithoutCancel(ctx)); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	locker := NewLocker(client)
+	err := locker.WithLock(ctx, "nightly-report", 30*time.Second, func(ctx context.Context) error {
+		_, err := locker.Obtain(ctx, "nightly-report", time.Minute)
+		fmt.Println("second worker:", err)
+		return nil
+	})
+	fmt.Println("first worker:", err)
+}
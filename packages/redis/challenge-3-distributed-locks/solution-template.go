@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotAcquired is returned when someone else holds the lock
+	ErrNotAcquired = errors.New("lock is held by someone else")
+	// ErrNotHeld is returned when a lock expired or was taken over
+	ErrNotHeld = errors.New("lock is no longer held")
+)
+
+// releaseScript deletes the lock only if it still holds our token
+var releaseScript = redis.NewScript(`
+-- TODO: DEL KEYS[1] if its value is ARGV[1], and return 0 otherwise
+return 0
+`)
+
+// refreshScript extends the lock only if it still holds our token
+var refreshScript = redis.NewScript(`
+-- TODO: PEXPIRE KEYS[1] to ARGV[2] milliseconds if its value is ARGV[1], and return 0 otherwise
+return 0
+`)
+
+// Locker hands out locks stored in Redis
+type Locker struct {
+	client *redis.Client
+}
+
+// NewLocker returns a Locker using client
+func NewLocker(client *redis.Client) *Locker {
+	return &Locker{client: client}
+}
+
+// Lock is a lock held by this process until it is released or expires
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+// lockKey is the Redis key for a lock name
+func lockKey(name string) string {
+	return "lock:" + name
+}
+
+// newToken returns a random value that identifies one holder of a lock
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Obtain takes the lock called name for ttl, or returns ErrNotAcquired if
+// it is taken
+func (l *Locker) Obtain(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	// TODO: SET NX the lock key to a new token with ttl; not set means ErrNotAcquired
+	return &Lock{client: l.client, key: lockKey(name), token: newToken()}, nil
+}
+
+// ObtainWait tries to take the lock every retry until it succeeds or ctx is
+// done
+func (l *Locker) ObtainWait(ctx context.Context, name string, ttl, retry time.Duration) (*Lock, error) {
+	// TODO: Call Obtain until it returns anything but ErrNotAcquired, waiting retry in between
+	// TODO: Return ctx.Err() once ctx is done
+	return l.Obtain(ctx, name, ttl)
+}
+
+// Release gives the lock up. It returns ErrNotHeld, and leaves the key alone,
+// if the lock expired or someone else has taken it since.
+func (lk *Lock) Release(ctx context.Context) error {
+	// TODO: Run releaseScript with the key and token; 0 means ErrNotHeld
+	return nil
+}
+
+// Refresh extends the lock to ttl from now, or returns ErrNotHeld if it is
+// no longer ours
+func (lk *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	// TODO: Run refreshScript with the key, token and ttl in milliseconds; 0 means ErrNotHeld
+	return nil
+}
+
+// WithLock runs fn while holding the lock called name, refreshing it every
+// ttl/3 and releasing it afterwards. If a refresh fails, fn's context is
+// cancelled and WithLock returns ErrNotHeld. Otherwise it returns fn's
+// error, or the error from releasing the lock.
+func (l *Locker) WithLock(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context) error) error {
+	// TODO: Obtain the lock
+	// TODO: Refresh it every ttl/3 in a goroutine until fn returns; cancel fn's context if a refresh fails
+	// TODO: Stop the goroutine, then return ErrNotHeld if the lock was lost, or release it
+	return fn(ctx)
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	locker := NewLocker(client)
+	err := locker.WithLock(ctx, "nightly-report", 30*time.Second, func(ctx context.Context) error {
+		_, err := locker.Obtain(ctx, "nightly-report", time.Minute)
+		fmt.Println("second worker:", err)
+		return nil
+	})
+	fmt.Println("first worker:", err)
+}
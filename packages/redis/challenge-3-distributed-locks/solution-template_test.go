@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestLocker starts an in-process Redis and a Locker on it
+func newTestLocker(t *testing.T) (*miniredis.Miniredis, *Locker) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, NewLocker(client)
+}
+
+// mustObtain obtains a lock or fails the test
+func mustObtain(t *testing.T, locker *Locker, name string, ttl time.Duration) *Lock {
+	t.Helper()
+	lock, err := locker.Obtain(context.Background(), name, ttl)
+	if err != nil {
+		t.Fatalf("Obtain(%q) = %v", name, err)
+	}
+	return lock
+}
+
+func TestObtainAndRelease(t *testing.T) {
+	mr, locker := newTestLocker(t)
+	ctx := context.Background()
+
+	lock := mustObtain(t, locker, "job", 10*time.Second)
+	if got, _ := mr.Get("lock:job"); got == "" || got != lock.token {
+		t.Errorf("lock:job = %q, want the lock's token", got)
+	}
+	if ttl := mr.TTL("lock:job"); ttl != 10*time.Second {
+		t.Errorf("TTL = %v, want 10s", ttl)
+	}
+	if _, err := locker.Obtain(ctx, "job", time.Second); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("second Obtain = %v, want ErrNotAcquired", err)
+	}
+	if _, err := locker.Obtain(ctx, "other-job", time.Second); err != nil {
+		t.Errorf("Obtain of a different lock = %v", err)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release = %v", err)
+	}
+	if mr.Exists("lock:job") {
+		t.Error("lock:job still exists after Release")
+	}
+	if err := lock.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("second Release = %v, want ErrNotHeld", err)
+	}
+	mustObtain(t, locker, "job", time.Second)
+}
+
+func TestTokensAreUnique(t *testing.T) {
+	_, locker := newTestLocker(t)
+	first := mustObtain(t, locker, "a", time.Second)
+	second := mustObtain(t, locker, "b", time.Second)
+	if first.token == "" || first.token == second.token {
+		t.Errorf("tokens %q and %q, want two different random tokens", first.token, second.token)
+	}
+}
+
+func TestReleaseAfterExpiry(t *testing.T) {
+	mr, locker := newTestLocker(t)
+	ctx := context.Background()
+
+	slow := mustObtain(t, locker, "job", time.Second)
+	mr.FastForward(time.Second)
+	fast := mustObtain(t, locker, "job", time.Minute)
+
+	if err := slow.Release(ctx); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Release of an expired lock = %v, want ErrNotHeld", err)
+	}
+	if got, _ := mr.Get("lock:job"); got != fast.token {
+		t.Error("releasing an expired lock deleted the new holder's lock")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	mr, locker := newTestLocker(t)
+	ctx := context.Background()
+
+	lock := mustObtain(t, locker, "job", time.Second)
+	if err := lock.Refresh(ctx, 1500*time.Millisecond); err != nil {
+		t.Fatalf("Refresh = %v", err)
+	}
+	if ttl := mr.TTL("lock:job"); ttl != 1500*time.Millisecond {
+		t.Errorf("TTL after Refresh = %v, want 1.5s", ttl)
+	}
+
+	mr.FastForward(1500 * time.Millisecond)
+	other := mustObtain(t, locker, "job", time.Minute)
+	if err := lock.Refresh(ctx, time.Hour); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("Refresh of a lost lock = %v, want ErrNotHeld", err)
+	}
+	if ttl := mr.TTL("lock:job"); ttl != time.Minute {
+		t.Errorf("refreshing a lost lock changed the new holder's TTL to %v", ttl)
+	}
+	other.Release(ctx)
+}
+
+func TestOnlyOneWinner(t *testing.T) {
+	_, locker := newTestLocker(t)
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := locker.Obtain(context.Background(), "job", time.Minute); err == nil {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 1 {
+		t.Errorf("%d goroutines obtained the lock, want 1", wins.Load())
+	}
+}
+
+func TestObtainWait(t *testing.T) {
+	_, locker := newTestLocker(t)
+	held := mustObtain(t, locker, "job", time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := locker.ObtainWait(ctx, "job", time.Second, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ObtainWait on a held lock = %v, want context.DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		held.Release(context.Background())
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := locker.ObtainWait(ctx, "job", time.Second, 10*time.Millisecond); err != nil {
+		t.Fatalf("ObtainWait after the holder released = %v", err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("ObtainWait returned after %v, before the lock was released", waited)
+	}
+}
+
+func TestWithLock(t *testing.T) {
+	mr, locker := newTestLocker(t)
+	ctx := context.Background()
+
+	ran := false
+	err := locker.WithLock(ctx, "job", time.Minute, func(ctx context.Context) error {
+		ran = true
+		if !mr.Exists("lock:job") {
+			t.Error("fn ran without the lock")
+		}
+		return nil
+	})
+	if err != nil || !ran {
+		t.Fatalf("WithLock = %v, ran %v", err, ran)
+	}
+	if mr.Exists("lock:job") {
+		t.Error("lock not released after fn")
+	}
+
+	boom := errors.New("boom")
+	if err := locker.WithLock(ctx, "job", time.Minute, func(context.Context) error { return boom }); !errors.Is(err, boom) {
+		t.Errorf("WithLock = %v, want fn's error", err)
+	}
+	if mr.Exists("lock:job") {
+		t.Error("lock not released after fn failed")
+	}
+
+	mustObtain(t, locker, "job", time.Minute)
+	err = locker.WithLock(ctx, "job", time.Minute, func(context.Context) error {
+		t.Error("fn ran while someone else held the lock")
+		return nil
+	})
+	if !errors.Is(err, ErrNotAcquired) {
+		t.Errorf("WithLock on a held lock = %v, want ErrNotAcquired", err)
+	}
+}
+
+func TestWithLockRefreshes(t *testing.T) {
+	mr, locker := newTestLocker(t)
+	const ttl = 300 * time.Millisecond
+
+	err := locker.WithLock(context.Background(), "job", ttl, func(ctx context.Context) error {
+		// Make the lock nearly expired, then wait for a refresh to restore
+		// it, which should come within a third of the TTL
+		mr.SetTTL("lock:job", time.Millisecond)
+		deadline := time.Now().Add(ttl)
+		for mr.TTL("lock:job") != ttl {
+			if time.Now().After(deadline) {
+				t.Error("the lock was not refreshed within its TTL while fn ran")
+				return nil
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("WithLock = %v", err)
+	}
+}
+
+func TestWithLockLost(t *testing.T) {
+	mr, locker := newTestLocker(t)
+
+	err := locker.WithLock(context.Background(), "job", 60*time.Millisecond, func(ctx context.Context) error {
+		mr.Del("lock:job")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+			t.Error("fn's context not cancelled after the lock was lost")
+			return nil
+		}
+	})
+	if !errors.Is(err, ErrNotHeld) {
+		t.Errorf("WithLock after losing the lock = %v, want ErrNotHeld", err)
+	}
+}
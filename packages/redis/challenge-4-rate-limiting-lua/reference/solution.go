@@ -0,0 +1,144 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket refills KEYS[1] at ARGV[1] tokens per second up to ARGV[2]
+// tokens, as of ARGV[3] in Unix milliseconds, then takes one token if there
+// is one. It returns {allowed (0 or 1), whole tokens left, milliseconds until
+// the next token if not allowed}.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate))
+return {allowed, math.floor(tokens), retry}
+`)
+
+// Result is the outcome of one request
+type Result struct {
+	Allowed    bool
+	Remaining  int           // whole tokens left
+	RetryAfter time.Duration // until the next token, if not allowed
+}
+
+// Limiter is a token bucket per key, kept in Redis so every server shares it
+type Limiter struct {
+	client *redis.Client
+	rate   float64 // tokens added per second
+	burst  int     // most tokens a bucket holds
+	now    func() time.Time
+}
+
+// NewLimiter returns a Limiter that allows bursts of burst requests, refilled
+// at rate per second
+func NewLimiter(client *redis.Client, rate float64, burst int) *Limiter {
+	return &Limiter{client: client, rate: rate, burst: burst, now: time.Now}
+}
+
+// rateKey is the Redis key of a bucket
+func rateKey(key string) string {
+	return "ratelimit:" + key
+}
+
+// Allow takes a token from key's bucket, atomically, if there is one
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	res, err := tokenBucket.Run(ctx, l.client, []string{rateKey(key)}, l.rate, l.burst, l.now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// ClientKey identifies the client of a request: "key:" and its X-API-Key
+// header if it has one, or "ip:" and its remote IP address
+func ClientKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return "key:" + key
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// Middleware limits each client, as named by keyFunc. Every response gets
+// X-RateLimit-Limit and X-RateLimit-Remaining; a denied request gets 429 Too
+// Many Requests with Retry-After in whole seconds, rounded up. If Redis
+// fails, requests are let through.
+func (l *Limiter) Middleware(keyFunc func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := l.Allow(r.Context(), keyFunc(r))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.burst))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter := NewLimiter(client, 1, 3)
+	h := limiter.Middleware(ClientKey, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	}))
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		fmt.Println(rec.Code, rec.Header().Get("X-RateLimit-Remaining"), rec.Header().Get("Retry-After"))
+	}
+}
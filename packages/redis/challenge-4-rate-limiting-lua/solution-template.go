@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucket refills KEYS[1] at ARGV[1] tokens per second up to ARGV[2]
+// tokens, as of ARGV[3] in Unix milliseconds, then takes one token if there
+// is one. It returns {allowed (0 or 1), whole tokens left, milliseconds until
+// the next token if not allowed}.
+var tokenBucket = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+-- TODO: Read the "tokens" and "ts" fields with HMGET; a new bucket is full as of now
+-- TODO: Add the tokens earned since ts, capped at burst
+-- TODO: Take one token if there is one, or work out the milliseconds until there will be
+-- TODO: Save tokens and ts with HSET, and PEXPIRE the key once it would be full again
+return {1, 0, 0}
+`)
+
+// Result is the outcome of one request
+type Result struct {
+	Allowed    bool
+	Remaining  int           // whole tokens left
+	RetryAfter time.Duration // until the next token, if not allowed
+}
+
+// Limiter is a token bucket per key, kept in Redis so every server shares it
+type Limiter struct {
+	client *redis.Client
+	rate   float64 // tokens added per second
+	burst  int     // most tokens a bucket holds
+	now    func() time.Time
+}
+
+// NewLimiter returns a Limiter that allows bursts of burst requests, refilled
+// at rate per second
+func NewLimiter(client *redis.Client, rate float64, burst int) *Limiter {
+	return &Limiter{client: client, rate: rate, burst: burst, now: time.Now}
+}
+
+// rateKey is the Redis key of a bucket
+func rateKey(key string) string {
+	return "ratelimit:" + key
+}
+
+// Allow takes a token from key's bucket, atomically, if there is one
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	// TODO: Run tokenBucket for rateKey(key) with l.rate, l.burst and l.now() in Unix milliseconds
+	// TODO: Turn the three integers it returns into a Result
+	return Result{Allowed: true}, nil
+}
+
+// ClientKey identifies the client of a request: "key:" and its X-API-Key
+// header if it has one, or "ip:" and its remote IP address
+func ClientKey(r *http.Request) string {
+	// TODO: Prefer the X-API-Key header, else the host part of r.RemoteAddr
+	return ""
+}
+
+// Middleware limits each client, as named by keyFunc. Every response gets
+// X-RateLimit-Limit and X-RateLimit-Remaining; a denied request gets 429 Too
+// Many Requests with Retry-After in whole seconds, rounded up. If Redis
+// fails, requests are let through.
+func (l *Limiter) Middleware(keyFunc func(*http.Request) string, next http.Handler) http.Handler {
+	// TODO: Allow the request's key, and let it through if Redis fails
+	// TODO: Set X-RateLimit-Limit and X-RateLimit-Remaining
+	// TODO: Deny with 429 and Retry-After in whole seconds, rounded up
+	return next
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	limiter := NewLimiter(client, 1, 3)
+	h := limiter.Middleware(ClientKey, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	}))
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		fmt.Println(rec.Code, rec.Header().Get("X-RateLimit-Remaining"), rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testClock is a clock the tests move by hand
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestLimiter starts an in-process Redis and a Limiter on it, allowing
+// bursts of 5 refilled at 10 per second, on a clock the test controls
+func newTestLimiter(t *testing.T) (*miniredis.Miniredis, *Limiter, *testClock) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	clock := &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(client, 10, 5)
+	limiter.now = clock.Now
+	return mr, limiter, clock
+}
+
+func allow(t *testing.T, l *Limiter, key string) Result {
+	t.Helper()
+	res, err := l.Allow(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Allow(%q) = %v", key, err)
+	}
+	return res
+}
+
+func TestBurstThenDeny(t *testing.T) {
+	_, limiter, _ := newTestLimiter(t)
+	for want := 4; want >= 0; want-- {
+		if res := allow(t, limiter, "u1"); !res.Allowed || res.Remaining != want || res.RetryAfter != 0 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", 5-want, res, want)
+		}
+	}
+	res := allow(t, limiter, "u1")
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != 100*time.Millisecond {
+		t.Errorf("6th request = %+v, want denied, retry after 100ms", res)
+	}
+}
+
+func TestRefill(t *testing.T) {
+	_, limiter, clock := newTestLimiter(t)
+	for i := 0; i < 5; i++ {
+		allow(t, limiter, "u1")
+	}
+
+	clock.Advance(250 * time.Millisecond) // 2.5 tokens
+	if res := allow(t, limiter, "u1"); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("after 250ms = %+v, want allowed with 1 remaining", res)
+	}
+	if res := allow(t, limiter, "u1"); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("second after 250ms = %+v, want allowed with 0 remaining", res)
+	}
+	if res := allow(t, limiter, "u1"); res.Allowed || res.RetryAfter != 50*time.Millisecond {
+		t.Errorf("third after 250ms = %+v, want denied, retry after 50ms for the missing half token", res)
+	}
+
+	clock.Advance(time.Hour)
+	if res := allow(t, limiter, "u1"); !res.Allowed || res.Remaining != 4 {
+		t.Errorf("after an hour = %+v, want a full bucket of 5, so 4 remaining", res)
+	}
+}
+
+func TestClockGoingBackwards(t *testing.T) {
+	_, limiter, clock := newTestLimiter(t)
+	allow(t, limiter, "u1")
+	clock.Advance(-time.Minute) // another server's clock is behind
+	if res := allow(t, limiter, "u1"); !res.Allowed || res.Remaining != 3 {
+		t.Errorf("with an earlier time = %+v, want 3 remaining and no tokens taken away", res)
+	}
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	_, limiter, _ := newTestLimiter(t)
+	for i := 0; i < 6; i++ {
+		allow(t, limiter, "busy")
+	}
+	if res := allow(t, limiter, "quiet"); !res.Allowed || res.Remaining != 4 {
+		t.Errorf("another key = %+v, want its own full bucket", res)
+	}
+}
+
+func TestBucketInRedis(t *testing.T) {
+	mr, limiter, _ := newTestLimiter(t)
+	allow(t, limiter, "u1")
+	if !mr.Exists("ratelimit:u1") {
+		t.Fatal("no ratelimit:u1 key in Redis")
+	}
+	if tokens := mr.HGet("ratelimit:u1", "tokens"); tokens != "4" {
+		t.Errorf("tokens field = %q, want 4", tokens)
+	}
+	if ttl := mr.TTL("ratelimit:u1"); ttl != 500*time.Millisecond {
+		t.Errorf("TTL = %v, want 500ms, the time to refill 5 tokens at 10/s", ttl)
+	}
+}
+
+func TestAtomicUnderConcurrency(t *testing.T) {
+	_, limiter, _ := newTestLimiter(t)
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := limiter.Allow(context.Background(), "shared"); err == nil && res.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed.Load() != 5 {
+		t.Errorf("%d of 40 concurrent requests allowed, want exactly the burst of 5", allowed.Load())
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	for _, tc := range []struct {
+		apiKey, remote, want string
+	}{
+		{"abc123", "203.0.113.7:51234", "key:abc123"},
+		{"", "203.0.113.7:51234", "ip:203.0.113.7"},
+		{"", "[2001:db8::1]:443", "ip:2001:db8::1"},
+		{"", "unix-socket", "ip:unix-socket"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tc.remote
+		if tc.apiKey != "" {
+			r.Header.Set("X-API-Key", tc.apiKey)
+		}
+		if got := ClientKey(r); got != tc.want {
+			t.Errorf("ClientKey(%q, %q) = %q, want %q", tc.apiKey, tc.remote, got, tc.want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	_, limiter, _ := newTestLimiter(t)
+	limiter.rate = 0.5 // one token every 2s
+	calls := 0
+	h := limiter.Middleware(ClientKey, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+
+	serve := func(apiKey string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	for i := 0; i < 5; i++ {
+		serve("a")
+	}
+	rec := serve("a")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("6th request = %d, want 429", rec.Code)
+	}
+	if calls != 5 {
+		t.Errorf("handler called %d times, want 5", calls)
+	}
+	for header, want := range map[string]string{"X-RateLimit-Limit": "5", "X-RateLimit-Remaining": "0", "Retry-After": "2"} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	rec = serve("b")
+	if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Remaining") != "4" || rec.Header().Get("Retry-After") != "" {
+		t.Errorf("other client = %d %v, want 200 with 4 remaining", rec.Code, rec.Header())
+	}
+}
+
+func TestMiddlewareFailsOpen(t *testing.T) {
+	mr, limiter, _ := newTestLimiter(t)
+	mr.Close()
+	called := false
+	h := limiter.Middleware(ClientKey, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !called || rec.Code != http.StatusOK {
+		t.Errorf("with Redis down: handler called %v, status %d; want the request let through", called, rec.Code)
+	}
+}
@@ -0,0 +1,180 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxTransferAttempts is how often Transfer tries before giving up on a
+// leaderboard that keeps changing under it
+const maxTransferAttempts = 5
+
+var (
+	// ErrInsufficientPoints is returned by Transfer when the sender has too
+	// few points
+	ErrInsufficientPoints = errors.New("not enough points")
+	// ErrContention is returned by Transfer after maxTransferAttempts
+	// conflicting attempts
+	ErrContention = errors.New("leaderboard changed on every attempt")
+)
+
+// Score is points won by a player
+type Score struct {
+	Player string
+	Points int64
+}
+
+// Profile is what a player's hash holds
+type Profile struct {
+	Player  string
+	Name    string
+	Country string
+}
+
+// Entry is a line of the leaderboard
+type Entry struct {
+	Rank   int // from 1
+	Player string
+	Name   string // empty if the player has no profile
+	Points int64
+}
+
+// Leaderboard ranks players in the sorted set leaderboard:<name> and keeps
+// their profiles in profile:<player> hashes
+type Leaderboard struct {
+	client *redis.Client
+	key    string
+}
+
+// NewLeaderboard returns the leaderboard called name
+func NewLeaderboard(client *redis.Client, name string) *Leaderboard {
+	return &Leaderboard{client: client, key: "leaderboard:" + name}
+}
+
+// profileKey is the Redis key of a player's profile
+func profileKey(player string) string {
+	return "profile:" + player
+}
+
+// RecordScores adds every score's points to its player, in one round trip
+func (lb *Leaderboard) RecordScores(ctx context.Context, scores []Score) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	_, err := lb.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, s := range scores {
+			pipe.ZIncrBy(ctx, lb.key, float64(s.Points), s.Player)
+		}
+		return nil
+	})
+	return err
+}
+
+// SaveProfiles stores every profile's name and country, in one round trip
+func (lb *Leaderboard) SaveProfiles(ctx context.Context, profiles []Profile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	_, err := lb.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, p := range profiles {
+			pipe.HSet(ctx, profileKey(p.Player), "name", p.Name, "country", p.Country)
+		}
+		return nil
+	})
+	return err
+}
+
+// Top returns the n highest scoring players with their names, in two round
+// trips: one for the ranking, one for all the names
+func (lb *Leaderboard) Top(ctx context.Context, n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	ranked, err := lb.client.ZRevRangeWithScores(ctx, lb.key, 0, int64(n-1)).Result()
+	if err != nil || len(ranked) == 0 {
+		return nil, err
+	}
+
+	names := make([]*redis.StringCmd, len(ranked))
+	_, err = lb.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, z := range ranked {
+			names[i] = pipe.HGet(ctx, profileKey(z.Member.(string)), "name")
+		}
+		return nil
+	})
+	// A player without a profile makes its HGET, and so the pipeline, fail
+	// with redis.Nil; that only means an empty name
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(ranked))
+	for i, z := range ranked {
+		entries[i] = Entry{
+			Rank:   i + 1,
+			Player: z.Member.(string),
+			Name:   names[i].Val(),
+			Points: int64(z.Score),
+		}
+	}
+	return entries, nil
+}
+
+// Transfer moves points from one player to another atomically. The sender's
+// score is checked under WATCH, and the two updates run in MULTI/EXEC, so
+// a change by anyone else in between makes the attempt start over.
+func (lb *Leaderboard) Transfer(ctx context.Context, from, to string, points int64) error {
+	transfer := func(tx *redis.Tx) error {
+		balance, err := tx.ZScore(ctx, lb.key, from).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if int64(balance) < points {
+			return ErrInsufficientPoints
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZIncrBy(ctx, lb.key, float64(-points), from)
+			pipe.ZIncrBy(ctx, lb.key, float64(points), to)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < maxTransferAttempts; i++ {
+		err := lb.client.Watch(ctx, transfer, lb.key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return ErrContention
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	lb := NewLeaderboard(client, "weekly")
+	lb.SaveProfiles(ctx, []Profile{{"p1", "Ada", "UK"}, {"p2", "Grace", "US"}})
+	lb.RecordScores(ctx, []Score{{"p1", 120}, {"p2", 90}, {"p1", 15}, {"p3", 200}})
+	if err := lb.Transfer(ctx, "p3", "p2", 100); err != nil {
+		log.Fatal(err)
+	}
+
+	top, _ := lb.Top(ctx, 3)
+	for _, e := range top {
+		fmt.Printf("%d. %-6s %-5s %d\n", e.Rank, e.Player, e.Name, e.Points)
+	}
+}
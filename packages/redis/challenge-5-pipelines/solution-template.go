@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxTransferAttempts is how often Transfer tries before giving up on a
+// leaderboard that keeps changing under it
+const maxTransferAttempts = 5
+
+var (
+	// ErrInsufficientPoints is returned by Transfer when the sender has too
+	// few points
+	ErrInsufficientPoints = errors.New("not enough points")
+	// ErrContention is returned by Transfer after maxTransferAttempts
+	// conflicting attempts
+	ErrContention = errors.New("leaderboard changed on every attempt")
+)
+
+// Score is points won by a player
+type Score struct {
+	Player string
+	Points int64
+}
+
+// Profile is what a player's hash holds
+type Profile struct {
+	Player  string
+	Name    string
+	Country string
+}
+
+// Entry is a line of the leaderboard
+type Entry struct {
+	Rank   int // from 1
+	Player string
+	Name   string // empty if the player has no profile
+	Points int64
+}
+
+// Leaderboard ranks players in the sorted set leaderboard:<name> and keeps
+// their profiles in profile:<player> hashes
+type Leaderboard struct {
+	client *redis.Client
+	key    string
+}
+
+// NewLeaderboard returns the leaderboard called name
+func NewLeaderboard(client *redis.Client, name string) *Leaderboard {
+	return &Leaderboard{client: client, key: "leaderboard:" + name}
+}
+
+// profileKey is the Redis key of a player's profile
+func profileKey(player string) string {
+	return "profile:" + player
+}
+
+// RecordScores adds every score's points to its player, in one round trip
+func (lb *Leaderboard) RecordScores(ctx context.Context, scores []Score) error {
+	// TODO: Queue a ZINCRBY per score on a pipeline, and send them all at once with Pipelined
+	return nil
+}
+
+// SaveProfiles stores every profile's name and country, in one round trip
+func (lb *Leaderboard) SaveProfiles(ctx context.Context, profiles []Profile) error {
+	// TODO: Queue an HSET of name and country per profile on a pipeline
+	return nil
+}
+
+// Top returns the n highest scoring players with their names, in two round
+// trips: one for the ranking, one for all the names
+func (lb *Leaderboard) Top(ctx context.Context, n int) ([]Entry, error) {
+	// TODO: Read the n best players and their scores with ZREVRANGE ... WITHSCORES
+	// TODO: Queue an HGET of each player's name on one pipeline, keeping the commands
+	// TODO: A missing profile fails its HGET with redis.Nil; treat that as an empty name
+	// TODO: Build the entries, ranked from 1
+	return nil, nil
+}
+
+// Transfer moves points from one player to another atomically. The sender's
+// score is checked under WATCH, and the two updates run in MULTI/EXEC, so
+// a change by anyone else in between makes the attempt start over.
+func (lb *Leaderboard) Transfer(ctx context.Context, from, to string, points int64) error {
+	// TODO: In a function of a *redis.Tx, read the sender's score and refuse with ErrInsufficientPoints
+	// TODO: Move the points with two ZINCRBYs in TxPipelined (MULTI/EXEC)
+	// TODO: Run it with client.Watch on the leaderboard key, trying again on redis.TxFailedErr
+	// TODO: Give up with ErrContention after maxTransferAttempts
+	return nil
+}
+
+func main() {
+	mr := miniredis.NewMiniRedis()
+	if err := mr.Start(); err != nil {
+		log.Fatal(err)
+	}
+	defer mr.Close()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	lb := NewLeaderboard(client, "weekly")
+	lb.SaveProfiles(ctx, []Profile{{"p1", "Ada", "UK"}, {"p2", "Grace", "US"}})
+	lb.RecordScores(ctx, []Score{{"p1", 120}, {"p2", 90}, {"p1", 15}, {"p3", 200}})
+	if err := lb.Transfer(ctx, "p3", "p2", 100); err != nil {
+		log.Fatal(err)
+	}
+
+	top, _ := lb.Top(ctx, 3)
+	for _, e := range top {
+		fmt.Printf("%d. %-6s %-5s %d\n", e.Rank, e.Player, e.Name, e.Points)
+	}
+}
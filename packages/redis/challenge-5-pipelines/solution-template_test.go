@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// roundTrips counts the requests a client sends: one per command, and one
+// per pipeline however many commands it holds
+type roundTrips struct{ n atomic.Int64 }
+
+func (h *roundTrips) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *roundTrips) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *roundTrips) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmds)
+	}
+}
+
+// count returns how many round trips fn makes
+func (h *roundTrips) count(fn func()) int64 {
+	before := h.n.Load()
+	fn()
+	return h.n.Load() - before
+}
+
+// newTestLeaderboard starts an in-process Redis and a "weekly" Leaderboard
+// on it whose round trips are counted
+func newTestLeaderboard(t *testing.T) (*miniredis.Miniredis, *redis.Client, *Leaderboard, *roundTrips) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatal(err)
+	}
+	trips := &roundTrips{}
+	client.AddHook(trips)
+	return mr, client, NewLeaderboard(client, "weekly"), trips
+}
+
+func score(t *testing.T, mr *miniredis.Miniredis, player string) int64 {
+	t.Helper()
+	s, err := mr.ZScore("leaderboard:weekly", player)
+	if err != nil {
+		t.Fatalf("no score for %s: %v", player, err)
+	}
+	return int64(s)
+}
+
+func TestRecordScoresInOneRoundTrip(t *testing.T) {
+	mr, _, lb, trips := newTestLeaderboard(t)
+	ctx := context.Background()
+
+	var scores []Score
+	for i := 0; i < 100; i++ {
+		scores = append(scores, Score{Player: fmt.Sprintf("p%d", i%10), Points: int64(i)})
+	}
+	var err error
+	if n := trips.count(func() { err = lb.RecordScores(ctx, scores) }); n != 1 {
+		t.Errorf("RecordScores of 100 scores made %d round trips, want 1", n)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	// p3 got 3 + 13 + ... + 93
+	if got := score(t, mr, "p3"); got != 480 {
+		t.Errorf("p3 has %d points, want 480", got)
+	}
+
+	if n := trips.count(func() { err = lb.RecordScores(ctx, nil) }); n != 0 || err != nil {
+		t.Errorf("RecordScores(nil) = %v with %d round trips, want nil with none", err, n)
+	}
+}
+
+func TestSaveProfilesInOneRoundTrip(t *testing.T) {
+	mr, _, lb, trips := newTestLeaderboard(t)
+	ctx := context.Background()
+
+	profiles := []Profile{{"p1", "Ada", "UK"}, {"p2", "Grace", "US"}, {"p3", "Linus", "FI"}}
+	var err error
+	if n := trips.count(func() { err = lb.SaveProfiles(ctx, profiles) }); n != 1 {
+		t.Errorf("SaveProfiles of 3 profiles made %d round trips, want 1", n)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mr.HGet("profile:p2", "name"); got != "Grace" {
+		t.Errorf("profile:p2 name = %q, want Grace", got)
+	}
+	if got := mr.HGet("profile:p3", "country"); got != "FI" {
+		t.Errorf("profile:p3 country = %q, want FI", got)
+	}
+	if err := lb.SaveProfiles(ctx, []Profile{{"p4", "Ken", "US"}}); err != nil || mr.HGet("profile:p4", "name") != "Ken" {
+		t.Errorf("SaveProfiles of one profile = %v, saved name %q", err, mr.HGet("profile:p4", "name"))
+	}
+	if n := trips.count(func() { err = lb.SaveProfiles(ctx, nil) }); n != 0 || err != nil {
+		t.Errorf("SaveProfiles(nil) = %v with %d round trips, want nil with none", err, n)
+	}
+}
+
+func TestTop(t *testing.T) {
+	mr, _, lb, trips := newTestLeaderboard(t)
+	ctx := context.Background()
+	mr.ZAdd("leaderboard:weekly", 50, "p1")
+	mr.ZAdd("leaderboard:weekly", 300, "p2")
+	mr.ZAdd("leaderboard:weekly", 120, "p3")
+	mr.ZAdd("leaderboard:weekly", 10, "p4")
+	mr.HSet("profile:p1", "name", "Ada")
+	mr.HSet("profile:p2", "name", "Grace")
+
+	var (
+		top []Entry
+		err error
+	)
+	if n := trips.count(func() { top, err = lb.Top(ctx, 3) }); n != 2 {
+		t.Errorf("Top(3) made %d round trips, want 2", n)
+	}
+	if err != nil {
+		t.Fatalf("Top(3) = %v; a player without a profile is not an error", err)
+	}
+	want := []Entry{
+		{Rank: 1, Player: "p2", Name: "Grace", Points: 300},
+		{Rank: 2, Player: "p3", Name: "", Points: 120},
+		{Rank: 3, Player: "p1", Name: "Ada", Points: 50},
+	}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("Top(3) =\n%+v\nwant\n%+v", top, want)
+	}
+
+	if top, err := lb.Top(ctx, 1); err != nil || !reflect.DeepEqual(top, want[:1]) {
+		t.Errorf("Top(1) = %+v, %v; want %+v", top, err, want[:1])
+	}
+	if top, err := lb.Top(ctx, 10); err != nil || len(top) != 4 {
+		t.Errorf("Top(10) of 4 players = %d entries, %v", len(top), err)
+	}
+	if top, err := lb.Top(ctx, 0); err != nil || len(top) != 0 {
+		t.Errorf("Top(0) = %+v, %v; want nothing", top, err)
+	}
+	empty := NewLeaderboard(lb.client, "empty")
+	if n := trips.count(func() { top, err = empty.Top(ctx, 5) }); n != 1 || err != nil || len(top) != 0 {
+		t.Errorf("Top on an empty leaderboard = %+v, %v in %d round trips; want nothing in 1", top, err, n)
+	}
+}
+
+func TestTopReportsRedisErrors(t *testing.T) {
+	mr, _, lb, _ := newTestLeaderboard(t)
+	mr.Close()
+	if _, err := lb.Top(context.Background(), 3); err == nil {
+		t.Error("Top with Redis down returned no error")
+	}
+	if err := lb.RecordScores(context.Background(), []Score{{"p1", 1}}); err == nil {
+		t.Error("RecordScores with Redis down returned no error")
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	mr, _, lb, _ := newTestLeaderboard(t)
+	ctx := context.Background()
+	mr.ZAdd("leaderboard:weekly", 100, "p1")
+	mr.ZAdd("leaderboard:weekly", 20, "p2")
+
+	if err := lb.Transfer(ctx, "p1", "p2", 30); err != nil {
+		t.Fatal(err)
+	}
+	if p1, p2 := score(t, mr, "p1"), score(t, mr, "p2"); p1 != 70 || p2 != 50 {
+		t.Errorf("after moving 30: p1 = %d, p2 = %d; want 70 and 50", p1, p2)
+	}
+
+	if err := lb.Transfer(ctx, "p1", "p3", 70); err != nil {
+		t.Errorf("moving every point = %v", err)
+	}
+	if p3 := score(t, mr, "p3"); p3 != 70 {
+		t.Errorf("new player p3 has %d points, want 70", p3)
+	}
+
+	if err := lb.Transfer(ctx, "p2", "p1", 51); !errors.Is(err, ErrInsufficientPoints) {
+		t.Errorf("moving 51 of 50 points = %v, want ErrInsufficientPoints", err)
+	}
+	if err := lb.Transfer(ctx, "nobody", "p1", 1); !errors.Is(err, ErrInsufficientPoints) {
+		t.Errorf("moving from an unknown player = %v, want ErrInsufficientPoints", err)
+	}
+	if p2 := score(t, mr, "p2"); p2 != 50 {
+		t.Errorf("a refused transfer changed p2 to %d", p2)
+	}
+}
+
+// interfere changes the leaderboard from a second client right after the
+// first `times` ZSCOREs, between WATCH and EXEC, so those attempts conflict
+type interfere struct {
+	roundTrips
+	other *redis.Client
+	times atomic.Int64
+}
+
+func (h *interfere) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if cmd.Name() == "zscore" && h.times.Add(-1) >= 0 {
+			h.other.ZIncrBy(ctx, "leaderboard:weekly", 1, "bystander")
+		}
+		return err
+	}
+}
+
+func TestTransferRetriesOnConflict(t *testing.T) {
+	mr := miniredis.RunT(t)
+	other := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { other.Close() })
+	hook := &interfere{other: other}
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	client.AddHook(hook)
+	lb := NewLeaderboard(client, "weekly")
+	ctx := context.Background()
+	mr.ZAdd("leaderboard:weekly", 100, "p1")
+
+	hook.times.Store(2)
+	if err := lb.Transfer(ctx, "p1", "p2", 40); err != nil {
+		t.Fatalf("Transfer after 2 conflicts = %v, want it to retry and succeed", err)
+	}
+	if p1, p2 := score(t, mr, "p1"), score(t, mr, "p2"); p1 != 60 || p2 != 40 {
+		t.Errorf("p1 = %d, p2 = %d; want 60 and 40, moved exactly once", p1, p2)
+	}
+
+	hook.times.Store(1000)
+	if err := lb.Transfer(ctx, "p1", "p2", 10); !errors.Is(err, ErrContention) {
+		t.Errorf("Transfer that always conflicts = %v, want ErrContention", err)
+	}
+	if left := hook.times.Load(); left != 1000-maxTransferAttempts {
+		t.Errorf("made %d attempts, want maxTransferAttempts (%d)", 1000-left, maxTransferAttempts)
+	}
+	if p1 := score(t, mr, "p1"); p1 != 60 {
+		t.Errorf("p1 = %d after failed transfers, want 60", p1)
+	}
+}
+
+func TestConcurrentTransfersKeepTheTotal(t *testing.T) {
+	mr, client, _, _ := newTestLeaderboard(t)
+	ctx := context.Background()
+	players := []string{"a", "b", "c", "d"}
+	for _, p := range players {
+		mr.ZAdd("leaderboard:weekly", 100, p)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lb := NewLeaderboard(client, "weekly")
+			err := lb.Transfer(ctx, players[i%4], players[(i+1)%4], 7)
+			if err != nil && !errors.Is(err, ErrInsufficientPoints) && !errors.Is(err, ErrContention) {
+				t.Errorf("Transfer = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var total int64
+	for _, p := range players {
+		s := score(t, mr, p)
+		if s < 0 {
+			t.Errorf("%s went negative: %d", p, s)
+		}
+		total += s
+	}
+	if total != 400 {
+		t.Errorf("points add up to %d after transfers, want 400", total)
+	}
+}
@@ -0,0 +1,3 @@
+module slog-challenge-1
+
+go 1.22
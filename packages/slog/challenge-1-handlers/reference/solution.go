@@ -0,0 +1,112 @@
+//go:build reference
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LargeOrderCents is the total above which an order also logs a warning
+const LargeOrderCents = 100000
+
+// Order is a placed order
+type Order struct {
+	ID         int
+	Customer   string
+	Items      []string
+	TotalCents int64
+}
+
+// NewLogger returns a logger that writes to w in format, "json" or "text",
+// and drops records below level
+func NewLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q, want json or text", format)
+}
+
+// productionAttr renames the built-in attributes for NewProductionLogger.
+// Attributes inside groups keep their names.
+func productionAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		return slog.String("ts", a.Value.Time().UTC().Format(time.RFC3339))
+	case slog.LevelKey:
+		return slog.String("severity", strings.ToLower(a.Value.String()))
+	case slog.MessageKey:
+		return slog.Attr{Key: "message", Value: a.Value}
+	case slog.SourceKey:
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			return slog.String("source", fmt.Sprintf("%s:%d", filepath.Base(src.File), src.Line))
+		}
+	}
+	return a
+}
+
+// NewProductionLogger returns a JSON logger for a log collector. It logs Info
+// and above with the source location, and renames the built-in keys: "ts"
+// in RFC 3339 UTC, "severity" in lower case, "message", and "source" as
+// file:line.
+func NewProductionLogger(w io.Writer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       slog.LevelInfo,
+		AddSource:   true,
+		ReplaceAttr: productionAttr,
+	}))
+}
+
+// WithService returns a logger that adds service and version to every record
+func WithService(logger *slog.Logger, service, version string) *slog.Logger {
+	return logger.With("service", service, "version", version)
+}
+
+// LogOrder logs "order placed" at Info with order_id, customer, item_count
+// and total_cents. Orders over LargeOrderCents also log "large order" at
+// Warn with order_id and total_cents.
+func LogOrder(logger *slog.Logger, o Order) {
+	logger.Info("order placed",
+		"order_id", o.ID,
+		"customer", o.Customer,
+		"item_count", len(o.Items),
+		"total_cents", o.TotalCents,
+	)
+	if o.TotalCents > LargeOrderCents {
+		logger.Warn("large order", "order_id", o.ID, "total_cents", o.TotalCents)
+	}
+}
+
+// InstallDefault makes logger the default, so that slog's top-level functions
+// and the log package both write through it
+func InstallDefault(logger *slog.Logger) {
+	slog.SetDefault(logger)
+}
+
+// ErrorLogger returns a *log.Logger, for APIs like http.Server.ErrorLog,
+// whose output goes to logger at Error level
+func ErrorLogger(logger *slog.Logger) *log.Logger {
+	return slog.NewLogLogger(logger.Handler(), slog.LevelError)
+}
+
+func main() {
+	logger := WithService(NewProductionLogger(os.Stdout), "orders", "1.4.2")
+	InstallDefault(logger)
+
+	LogOrder(logger, Order{ID: 1, Customer: "ada", Items: []string{"keyboard"}, TotalCents: 4999})
+	LogOrder(logger, Order{ID: 2, Customer: "grace", Items: []string{"server", "rack"}, TotalCents: 250000})
+	log.Print("the log package goes through slog too")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io"
+	"log"
+	"log/slog"
+	"os"
+)
+
+// LargeOrderCents is the total above which an order also logs a warning
+const LargeOrderCents = 100000
+
+// Order is a placed order
+type Order struct {
+	ID         int
+	Customer   string
+	Items      []string
+	TotalCents int64
+}
+
+// NewLogger returns a logger that writes to w in format, "json" or "text",
+// and drops records below level
+func NewLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	// TODO: Build slog.HandlerOptions with the level
+	// TODO: Pick slog.NewJSONHandler or slog.NewTextHandler, and return an error for any other format
+	return nil, nil
+}
+
+// productionAttr renames the built-in attributes for NewProductionLogger.
+// Attributes inside groups keep their names.
+func productionAttr(groups []string, a slog.Attr) slog.Attr {
+	// TODO: Leave attributes inside groups alone
+	// TODO: slog.TimeKey -> "ts" as RFC 3339 in UTC
+	// TODO: slog.LevelKey -> "severity" in lower case
+	// TODO: slog.MessageKey -> "message"
+	// TODO: slog.SourceKey -> "source" as "file.go:42"; the value holds a *slog.Source
+	return a
+}
+
+// NewProductionLogger returns a JSON logger for a log collector. It logs Info
+// and above with the source location, and renames the built-in keys: "ts"
+// in RFC 3339 UTC, "severity" in lower case, "message", and "source" as
+// file:line.
+func NewProductionLogger(w io.Writer) *slog.Logger {
+	// TODO: Set Level, AddSource and ReplaceAttr in the handler options
+	return slog.New(slog.NewJSONHandler(w, nil))
+}
+
+// WithService returns a logger that adds service and version to every record
+func WithService(logger *slog.Logger, service, version string) *slog.Logger {
+	// TODO: Use logger.With
+	return logger
+}
+
+// LogOrder logs "order placed" at Info with order_id, customer, item_count
+// and total_cents. Orders over LargeOrderCents also log "large order" at
+// Warn with order_id and total_cents.
+func LogOrder(logger *slog.Logger, o Order) {
+	// TODO: Log the order with key-value pairs
+}
+
+// InstallDefault makes logger the default, so that slog's top-level functions
+// and the log package both write through it
+func InstallDefault(logger *slog.Logger) {
+	// TODO: Set the default logger
+}
+
+// ErrorLogger returns a *log.Logger, for APIs like http.Server.ErrorLog,
+// whose output goes to logger at Error level
+func ErrorLogger(logger *slog.Logger) *log.Logger {
+	// TODO: Bridge to logger's handler with slog.NewLogLogger
+	return log.New(io.Discard, "", 0)
+}
+
+func main() {
+	logger := WithService(NewProductionLogger(os.Stdout), "orders", "1.4.2")
+	InstallDefault(logger)
+
+	LogOrder(logger, Order{ID: 1, Customer: "ada", Items: []string{"keyboard"}, TotalCents: 4999})
+	LogOrder(logger, Order{ID: 2, Customer: "grace", Items: []string{"server", "rack"}, TotalCents: 250000})
+	log.Print("the log package goes through slog too")
+}
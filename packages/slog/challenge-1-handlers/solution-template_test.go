@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// entries decodes the JSON lines a JSON handler wrote
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %v\n%s", err, line)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func newLogger(t *testing.T, buf *bytes.Buffer, format string, level slog.Level) *slog.Logger {
+	t.Helper()
+	logger, err := NewLogger(buf, format, level)
+	if err != nil {
+		t.Fatalf("NewLogger(%q): %v", format, err)
+	}
+	if logger == nil {
+		t.Fatalf("NewLogger(%q) returned a nil logger", format)
+	}
+	return logger
+}
+
+func TestNewLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(t, &buf, "json", slog.LevelInfo)
+	logger.Debug("cache miss", "key", "user:1")
+	logger.Info("server started", "port", 8080)
+	logger.Warn("disk almost full", "free_mb", 512)
+
+	got := entries(t, &buf)
+	if len(got) != 2 {
+		t.Fatalf("logged %d lines at level Info, want 2 (Debug is dropped):\n%s", len(got), buf.String())
+	}
+	if got[0]["level"] != "INFO" || got[0]["msg"] != "server started" || got[0]["port"] != float64(8080) {
+		t.Errorf("first line = %v, want level INFO, msg \"server started\", port 8080", got[0])
+	}
+	if got[1]["level"] != "WARN" || got[1]["free_mb"] != float64(512) {
+		t.Errorf("second line = %v, want level WARN, free_mb 512", got[1])
+	}
+	if _, ok := got[0]["time"]; !ok {
+		t.Errorf("first line = %v, want a time", got[0])
+	}
+}
+
+func TestNewLoggerText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(t, &buf, "text", slog.LevelDebug)
+	logger.Debug("cache miss", "key", "user:1")
+	logger.Warn("disk almost full", "free_mb", 512)
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=DEBUG msg="cache miss" key=user:1`,
+		`level=WARN msg="disk almost full" free_mb=512`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNewLoggerUnknownFormat(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "xml", slog.LevelInfo); err == nil {
+		t.Error("NewLogger(\"xml\") succeeded, want an error")
+	}
+}
+
+var sourcePattern = regexp.MustCompile(`^[^/\\]+_test\.go:\d+$`)
+
+func TestProductionLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewProductionLogger(&buf)
+	logger.Debug("not shown")
+	logger.Warn("slow query", "ms", 1200, slog.Group("db", "msg", "kept", "level", "kept"))
+
+	got := entries(t, &buf)
+	if len(got) != 1 {
+		t.Fatalf("logged %d lines, want 1 (Debug is dropped):\n%s", len(got), buf.String())
+	}
+	entry := got[0]
+	for _, key := range []string{"time", "level", "msg"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("line still has the built-in key %q: %v", key, entry)
+		}
+	}
+	if entry["severity"] != "warn" {
+		t.Errorf("severity = %v, want warn", entry["severity"])
+	}
+	if entry["message"] != "slow query" {
+		t.Errorf("message = %v, want \"slow query\"", entry["message"])
+	}
+	ts, _ := entry["ts"].(string)
+	if parsed, err := time.Parse(time.RFC3339, ts); err != nil || !strings.HasSuffix(ts, "Z") {
+		t.Errorf("ts = %q, want RFC 3339 in UTC (%v)", ts, err)
+	} else if time.Since(parsed) > time.Minute {
+		t.Errorf("ts = %q, want about now", ts)
+	}
+	if src, _ := entry["source"].(string); !sourcePattern.MatchString(src) {
+		t.Errorf("source = %v, want file:line of the log call, like solution_test.go:42", entry["source"])
+	}
+	db, _ := entry["db"].(map[string]any)
+	if db["msg"] != "kept" || db["level"] != "kept" {
+		t.Errorf("db group = %v; keys inside groups must not be renamed", entry["db"])
+	}
+}
+
+func TestWithService(t *testing.T) {
+	var buf bytes.Buffer
+	logger := WithService(newLogger(t, &buf, "json", slog.LevelInfo), "orders", "1.4.2")
+	logger.Info("ready")
+	logger.Error("payment failed", "order_id", 7)
+
+	for _, entry := range entries(t, &buf) {
+		if entry["service"] != "orders" || entry["version"] != "1.4.2" {
+			t.Errorf("line = %v, want service orders and version 1.4.2", entry)
+		}
+	}
+}
+
+func TestLogOrder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(t, &buf, "json", slog.LevelInfo)
+	LogOrder(logger, Order{ID: 1, Customer: "ada", Items: []string{"keyboard", "mouse"}, TotalCents: 4999})
+	LogOrder(logger, Order{ID: 2, Customer: "grace", Items: []string{"server"}, TotalCents: LargeOrderCents})
+	LogOrder(logger, Order{ID: 3, Customer: "linus", Items: []string{"rack"}, TotalCents: LargeOrderCents + 1})
+
+	got := entries(t, &buf)
+	if len(got) != 4 {
+		t.Fatalf("logged %d lines, want 4: three orders and one large order warning:\n%s", len(got), buf.String())
+	}
+	first := got[0]
+	if first["level"] != "INFO" || first["msg"] != "order placed" || first["order_id"] != float64(1) ||
+		first["customer"] != "ada" || first["item_count"] != float64(2) || first["total_cents"] != float64(4999) {
+		t.Errorf("first order = %v", first)
+	}
+	warning := got[3]
+	if warning["level"] != "WARN" || warning["msg"] != "large order" || warning["order_id"] != float64(3) ||
+		warning["total_cents"] != float64(LargeOrderCents+1) {
+		t.Errorf("large order warning = %v", warning)
+	}
+}
+
+// keepDefault restores the default logger and the log package's settings
+// after a test changes them
+func keepDefault(t *testing.T) {
+	logger, writer, flags := slog.Default(), log.Writer(), log.Flags()
+	t.Cleanup(func() {
+		slog.SetDefault(logger)
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+	})
+}
+
+func TestInstallDefault(t *testing.T) {
+	keepDefault(t)
+	var buf bytes.Buffer
+	InstallDefault(newLogger(t, &buf, "json", slog.LevelInfo))
+
+	slog.Info("from slog", "n", 1)
+	log.Print("from the log package")
+
+	got := entries(t, &buf)
+	if len(got) != 2 || got[0]["msg"] != "from slog" || got[1]["msg"] != "from the log package" {
+		t.Errorf("default logger wrote %v, want both lines", got)
+	}
+}
+
+func TestErrorLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ErrorLogger(newLogger(t, &buf, "json", slog.LevelInfo)).Printf("http: TLS handshake error from %s", "10.0.0.1")
+
+	got := entries(t, &buf)
+	if len(got) != 1 || got[0]["level"] != "ERROR" || got[0]["msg"] != "http: TLS handshake error from 10.0.0.1" {
+		t.Errorf("error logger wrote %v, want one ERROR line", got)
+	}
+}
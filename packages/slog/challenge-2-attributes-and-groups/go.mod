@@ -0,0 +1,3 @@
+module slog-challenge-2
+
+go 1.22
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// User is an account. It logs as a group of its ID and masked email, and
+// never with its password.
+type User struct {
+	ID       int
+	Email    string
+	Password string
+}
+
+// LogValue implements slog.LogValuer
+func (u User) LogValue() slog.Value {
+	// TODO: Return a slog.GroupValue of "id" and the masked "email"
+	return slog.Value{}
+}
+
+// Secret is a string that logs as "[REDACTED]"
+type Secret string
+
+// LogValue implements slog.LogValuer
+func (Secret) LogValue() slog.Value {
+	// TODO: Never return the secret itself
+	return slog.Value{}
+}
+
+// MaskEmail keeps the first letter and the domain of an address:
+// "ada@example.com" becomes "a***@example.com". An address without a
+// local part becomes "***@example.com", and anything without an @ "***".
+func MaskEmail(email string) string {
+	// TODO: Split at the @ with strings.Cut
+	return email
+}
+
+// RequestGroup returns a "request" group with the method, path and
+// user_agent of r
+func RequestGroup(r *http.Request) slog.Attr {
+	// TODO: Use slog.Group with typed attributes
+	return slog.Attr{}
+}
+
+// Payment is a captured card payment
+type Payment struct {
+	ID          string
+	AmountCents int64
+	Currency    string
+	CardBrand   string
+	CardLast4   string
+}
+
+// LogPayment logs "payment captured" at Info with payment_id, amount_cents,
+// currency and a "card" group of brand and last4, using typed attributes
+func LogPayment(ctx context.Context, logger *slog.Logger, p Payment) {
+	// TODO: Use logger.LogAttrs with slog.String, slog.Int64 and slog.Group
+}
+
+// NewAuditLogger returns a logger for audit events. It adds tenant to every
+// record and puts everything else logged through it in an "audit" group.
+func NewAuditLogger(h slog.Handler, tenant string) *slog.Logger {
+	// TODO: Add the tenant with With, then open the group with WithGroup; the order matters
+	return slog.New(h)
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	user := User{ID: 42, Email: "ada@example.com", Password: "correct horse"}
+	logger.Info("user signed up", "user", user, "api_key", Secret("sk_live_123"))
+
+	LogPayment(context.Background(), logger, Payment{
+		ID: "pay_1", AmountCents: 4999, Currency: "EUR", CardBrand: "visa", CardLast4: "4242",
+	})
+
+	audit := NewAuditLogger(logger.Handler(), "acme")
+	audit.Info("role changed", "user_id", user.ID, "role", "admin")
+}
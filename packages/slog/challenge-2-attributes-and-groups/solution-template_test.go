@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// jsonLog is a JSON logger writing to a buffer
+type jsonLog struct {
+	buf    bytes.Buffer
+	logger *slog.Logger
+}
+
+func newJSONLog() *jsonLog {
+	l := &jsonLog{}
+	l.logger = slog.New(slog.NewJSONHandler(&l.buf, nil))
+	return l
+}
+
+// last decodes the last line logged
+func (l *jsonLog) last(t *testing.T) map[string]any {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(l.buf.String()), "\n")
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v\n%s", err, l.buf.String())
+	}
+	return entry
+}
+
+func group(t *testing.T, entry map[string]any, key string) map[string]any {
+	t.Helper()
+	g, ok := entry[key].(map[string]any)
+	if !ok {
+		t.Fatalf("%q = %#v, want a group: %v", key, entry[key], entry)
+	}
+	return g
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := map[string]string{
+		"ada@example.com":  "a***@example.com",
+		"x@y.io":           "x***@y.io",
+		"@example.com":     "***@example.com",
+		"not-an-address":   "***",
+		"":                 "***",
+		"grace@corp.co.uk": "g***@corp.co.uk",
+	}
+	for email, want := range tests {
+		if got := MaskEmail(email); got != want {
+			t.Errorf("MaskEmail(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
+
+func TestUserLogValue(t *testing.T) {
+	l := newJSONLog()
+	l.logger.Info("user signed up", "user", User{ID: 42, Email: "ada@example.com", Password: "correct horse"})
+
+	if strings.Contains(l.buf.String(), "correct horse") || strings.Contains(l.buf.String(), "ada@example.com") {
+		t.Fatalf("the log leaks the password or the full email:\n%s", l.buf.String())
+	}
+	user := group(t, l.last(t), "user")
+	if user["id"] != float64(42) || user["email"] != "a***@example.com" || len(user) != 2 {
+		t.Errorf("user = %v, want only id 42 and email a***@example.com", user)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	l := newJSONLog()
+	l.logger.Info("connecting", "password", Secret("hunter2"), slog.Group("db", "token", Secret("tok_abc")))
+
+	if strings.Contains(l.buf.String(), "hunter2") || strings.Contains(l.buf.String(), "tok_abc") {
+		t.Fatalf("the log leaks a secret:\n%s", l.buf.String())
+	}
+	entry := l.last(t)
+	if entry["password"] != "[REDACTED]" || group(t, entry, "db")["token"] != "[REDACTED]" {
+		t.Errorf("entry = %v, want both secrets as [REDACTED]", entry)
+	}
+
+	var text bytes.Buffer
+	slog.New(slog.NewTextHandler(&text, nil)).Info("connecting", "password", Secret("hunter2"))
+	if !strings.Contains(text.String(), "password=[REDACTED]") {
+		t.Errorf("text output = %q, want password=[REDACTED]", text.String())
+	}
+}
+
+func TestRequestGroup(t *testing.T) {
+	r := httptest.NewRequest("GET", "/orders?page=2", nil)
+	r.Header.Set("User-Agent", "curl/8.5.0")
+
+	l := newJSONLog()
+	l.logger.Info("request", RequestGroup(r))
+	request := group(t, l.last(t), "request")
+	if request["method"] != "GET" || request["path"] != "/orders" || request["user_agent"] != "curl/8.5.0" {
+		t.Errorf("request = %v, want method GET, path /orders and the user agent", request)
+	}
+
+	var text bytes.Buffer
+	slog.New(slog.NewTextHandler(&text, nil)).Info("request", RequestGroup(r))
+	if !strings.Contains(text.String(), "request.method=GET request.path=/orders request.user_agent=curl/8.5.0") {
+		t.Errorf("text output = %q, want dotted request.* keys", text.String())
+	}
+}
+
+func TestLogPayment(t *testing.T) {
+	l := newJSONLog()
+	LogPayment(context.Background(), l.logger, Payment{
+		ID: "pay_1", AmountCents: 4999, Currency: "EUR", CardBrand: "visa", CardLast4: "4242",
+	})
+
+	entry := l.last(t)
+	if entry["msg"] != "payment captured" || entry["level"] != "INFO" {
+		t.Errorf("entry = %v, want an INFO \"payment captured\"", entry)
+	}
+	if entry["payment_id"] != "pay_1" || entry["amount_cents"] != float64(4999) || entry["currency"] != "EUR" {
+		t.Errorf("entry = %v, want payment_id, amount_cents and currency", entry)
+	}
+	card := group(t, entry, "card")
+	if card["brand"] != "visa" || card["last4"] != "4242" {
+		t.Errorf("card = %v, want brand visa and last4 4242", card)
+	}
+}
+
+func TestAuditLogger(t *testing.T) {
+	l := newJSONLog()
+	audit := NewAuditLogger(l.logger.Handler(), "acme")
+	audit.Info("role changed", "user_id", 7, "role", "admin")
+
+	entry := l.last(t)
+	if entry["tenant"] != "acme" {
+		t.Errorf("tenant = %v, want acme at the top level: %v", entry["tenant"], entry)
+	}
+	a := group(t, entry, "audit")
+	if a["user_id"] != float64(7) || a["role"] != "admin" {
+		t.Errorf("audit = %v, want user_id 7 and role admin", a)
+	}
+
+	audit.With("actor", "root").Warn("user deleted", "user_id", 9)
+	a = group(t, l.last(t), "audit")
+	if a["actor"] != "root" || a["user_id"] != float64(9) {
+		t.Errorf("audit = %v; attributes added with With belong in the group too", a)
+	}
+}
@@ -0,0 +1,3 @@
+module slog-challenge-3
+
+go 1.22
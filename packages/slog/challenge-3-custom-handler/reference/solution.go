@@ -0,0 +1,164 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// DevHandlerOptions configure a DevHandler
+type DevHandlerOptions struct {
+	// Level is the minimum level to write; Info if nil
+	Level slog.Leveler
+}
+
+// DevHandler writes one line per record for people to read in a terminal:
+//
+//	[09:30:00.123] WARN  disk almost full | free_mb=512 db.host=primary
+//
+// The time is left out if the record has none. Attributes follow " | ",
+// only if there are any, with their groups joined to their keys by dots.
+type DevHandler struct {
+	opts   DevHandlerOptions
+	prefix string // groups opened with WithGroup, like "db.query."
+	attrs  string // attributes from WithAttrs, formatted, each after a space
+
+	mu *sync.Mutex // shared by every handler derived from this one
+	w  io.Writer
+}
+
+// NewDevHandler returns a DevHandler that writes to w
+func NewDevHandler(w io.Writer, opts *DevHandlerOptions) *DevHandler {
+	h := &DevHandler{mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	return h
+}
+
+// Enabled reports whether records at level are written
+func (h *DevHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle writes r as one line, with a single Write
+func (h *DevHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := make([]byte, 0, 256)
+	if !r.Time.IsZero() {
+		buf = append(buf, '[')
+		buf = r.Time.AppendFormat(buf, "15:04:05.000")
+		buf = append(buf, "] "...)
+	}
+	buf = fmt.Appendf(buf, "%-5s %s", r.Level, r.Message)
+
+	attrs := []byte(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendAttr(attrs, h.prefix, a)
+		return true
+	})
+	if len(attrs) > 0 {
+		buf = append(buf, " |"...)
+		buf = append(buf, attrs...)
+	}
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+// WithAttrs returns a handler that adds attrs, in the current groups, to
+// every record
+func (h *DevHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	buf := []byte(h.attrs)
+	for _, a := range attrs {
+		buf = appendAttr(buf, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs = string(buf)
+	return &h2
+}
+
+// WithGroup returns a handler that puts attributes added later in the group
+// name
+func (h *DevHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendAttr appends " key=value" for a, with prefix before the key. It
+// resolves LogValuers, skips empty attributes and empty groups, and inlines
+// groups without a key.
+func appendAttr(buf []byte, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			buf = appendAttr(buf, prefix, ga)
+		}
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = append(buf, prefix...)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+	return appendValue(buf, a.Value)
+}
+
+// appendValue appends v, quoting strings that would be ambiguous bare
+func appendValue(buf []byte, v slog.Value) []byte {
+	if v.Kind() == slog.KindTime {
+		return v.Time().AppendFormat(buf, time.RFC3339)
+	}
+	s := v.String()
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// needsQuoting reports whether s is empty or has a space, quote, equals
+// sign or unprintable character
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r == ' ' || r == '"' || r == '=' || !unicode.IsPrint(r)
+	}) >= 0
+}
+
+func main() {
+	logger := slog.New(NewDevHandler(os.Stdout, &DevHandlerOptions{Level: slog.LevelDebug}))
+	logger.Debug("config loaded", "path", "/etc/app/config.yaml")
+
+	db := logger.WithGroup("db").With("host", "primary")
+	db.Info("query", "table", "users", "took", 12*time.Millisecond)
+	logger.Warn("user logged in", slog.Group("user", "id", 42, "name", "Ada Lovelace"))
+}
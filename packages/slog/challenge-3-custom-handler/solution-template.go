@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DevHandlerOptions configure a DevHandler
+type DevHandlerOptions struct {
+	// Level is the minimum level to write; Info if nil
+	Level slog.Leveler
+}
+
+// DevHandler writes one line per record for people to read in a terminal:
+//
+//	[09:30:00.123] WARN  disk almost full | free_mb=512 db.host=primary
+//
+// The time is left out if the record has none. Attributes follow " | ",
+// only if there are any, with their groups joined to their keys by dots.
+type DevHandler struct {
+	opts   DevHandlerOptions
+	prefix string // groups opened with WithGroup, like "db.query."
+	attrs  string // attributes from WithAttrs, formatted, each after a space
+
+	mu *sync.Mutex // shared by every handler derived from this one
+	w  io.Writer
+}
+
+// NewDevHandler returns a DevHandler that writes to w
+func NewDevHandler(w io.Writer, opts *DevHandlerOptions) *DevHandler {
+	h := &DevHandler{mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	return h
+}
+
+// Enabled reports whether records at level are written
+func (h *DevHandler) Enabled(_ context.Context, level slog.Level) bool {
+	// TODO: Compare with h.opts.Level.Level()
+	return false
+}
+
+// Handle writes r as one line, with a single Write
+func (h *DevHandler) Handle(_ context.Context, r slog.Record) error {
+	// TODO: "[15:04:05.000] " unless r.Time is zero, then the level padded to 5 characters, a space and the message
+	// TODO: The WithAttrs attributes, then the record's from r.Attrs, after " |" if there are any
+	// TODO: End the line, and Write it while holding h.mu
+	return nil
+}
+
+// WithAttrs returns a handler that adds attrs, in the current groups, to
+// every record
+func (h *DevHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// TODO: Copy the handler and append the formatted attrs to the copy's attrs
+	return h
+}
+
+// WithGroup returns a handler that puts attributes added later in the group
+// name
+func (h *DevHandler) WithGroup(name string) slog.Handler {
+	// TODO: Copy the handler and extend the copy's prefix; ignore an empty name
+	return h
+}
+
+// appendAttr appends " key=value" for a, with prefix before the key. It
+// resolves LogValuers, skips empty attributes and empty groups, and inlines
+// groups without a key.
+func appendAttr(buf []byte, prefix string, a slog.Attr) []byte {
+	// TODO: Resolve the value, and skip an attribute equal to slog.Attr{}
+	// TODO: Recurse into groups, extending the prefix unless the key is empty
+	// TODO: Append " ", the prefix, the key, "=" and the value
+	return buf
+}
+
+// appendValue appends v, quoting strings that would be ambiguous bare
+func appendValue(buf []byte, v slog.Value) []byte {
+	// TODO: Times as RFC 3339; everything else as v.String(), quoted with strconv.AppendQuote if needsQuoting
+	return buf
+}
+
+// needsQuoting reports whether s is empty or has a space, quote, equals
+// sign or unprintable character
+func needsQuoting(s string) bool {
+	// TODO: Check every rune
+	return false
+}
+
+func main() {
+	logger := slog.New(NewDevHandler(os.Stdout, &DevHandlerOptions{Level: slog.LevelDebug}))
+	logger.Debug("config loaded", "path", "/etc/app/config.yaml")
+
+	db := logger.WithGroup("db").With("host", "primary")
+	db.Info("query", "table", "users", "took", 12*time.Millisecond)
+	logger.Warn("user logged in", slog.Group("user", "id", 42, "name", "Ada Lovelace"))
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+// parseLine turns a DevHandler line back into the nested map slogtest
+// expects: time, level and msg, then each dotted key as nested groups
+func parseLine(t *testing.T, line string) map[string]any {
+	t.Helper()
+	m := make(map[string]any)
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "] ")
+		if end < 0 {
+			t.Fatalf("no end to the time in %q", line)
+		}
+		m[slog.TimeKey] = line[1:end]
+		line = line[end+2:]
+	}
+	level, rest, _ := strings.Cut(line, " ")
+	m[slog.LevelKey] = level
+	msg, attrs, _ := strings.Cut(strings.TrimLeft(rest, " "), " | ")
+	m[slog.MessageKey] = msg
+
+	for attrs != "" {
+		key, rest, ok := strings.Cut(attrs, "=")
+		if !ok {
+			t.Fatalf("attribute without '=' in %q", line)
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				t.Fatalf("bad quoted value in %q: %v", line, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+			rest = " " + rest
+		}
+		attrs = strings.TrimPrefix(rest, " ")
+
+		groups := strings.Split(key, ".")
+		target := m
+		for _, g := range groups[:len(groups)-1] {
+			next, ok := target[g].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				target[g] = next
+			}
+			target = next
+		}
+		target[groups[len(groups)-1]] = value
+	}
+	return m
+}
+
+func TestSlogtest(t *testing.T) {
+	var buf bytes.Buffer
+	slogtest.Run(t, func(*testing.T) slog.Handler {
+		buf.Reset()
+		return NewDevHandler(&buf, nil)
+	}, func(t *testing.T) map[string]any {
+		line := strings.TrimSuffix(buf.String(), "\n")
+		if line == "" || strings.Contains(line, "\n") {
+			t.Fatalf("want exactly one line, got %q", buf.String())
+		}
+		return parseLine(t, line)
+	})
+}
+
+func TestExactFormat(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewDevHandler(&buf, nil)
+
+	r := slog.NewRecord(time.Date(2025, 1, 15, 9, 30, 0, 123_000_000, time.UTC), slog.LevelWarn, "disk almost full", 0)
+	r.AddAttrs(
+		slog.Int("free_mb", 512),
+		slog.String("path", "/var/lib"),
+		slog.String("owner", "Ada Lovelace"),
+		slog.String("empty", ""),
+		slog.String("expr", "a=b"),
+		slog.String("said", `"hi"`),
+		slog.Duration("took", 1500*time.Millisecond),
+		slog.Group("db", slog.String("host", "primary"), slog.Group("pool", slog.Int("size", 4))),
+	)
+	if err := h.WithGroup("svc").WithAttrs([]slog.Attr{slog.Bool("ok", true)}).Handle(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[09:30:00.123] WARN  disk almost full | svc.ok=true svc.free_mb=512 svc.path=/var/lib svc.owner="Ada Lovelace" svc.empty="" svc.expr="a=b" svc.said="\"hi\"" svc.took=1.5s svc.db.host=primary svc.db.pool.size=4` + "\n"
+	if buf.String() != want {
+		t.Errorf("output:\n%q\nwant:\n%q", buf.String(), want)
+	}
+
+	buf.Reset()
+	h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelError, "no attributes", 0))
+	if want := "ERROR no attributes\n"; buf.String() != want {
+		t.Errorf("output %q, want %q: no time for a zero time, no \" | \" without attributes", buf.String(), want)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	ctx := context.Background()
+	h := NewDevHandler(&bytes.Buffer{}, nil)
+	if h.Enabled(ctx, slog.LevelDebug) || !h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("without options, want Info and above only")
+	}
+
+	var level slog.LevelVar
+	level.Set(slog.LevelError)
+	h = NewDevHandler(&bytes.Buffer{}, &DevHandlerOptions{Level: &level})
+	if h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("Warn is enabled at level Error")
+	}
+	level.Set(slog.LevelDebug)
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("Debug is disabled after the LevelVar changed to Debug")
+	}
+}
+
+func TestDerivedHandlersAreIndependent(t *testing.T) {
+	var buf bytes.Buffer
+	parent := slog.New(NewDevHandler(&buf, nil))
+	child := parent.With("request_id", "r-1").WithGroup("user")
+	child.Info("child", "id", 7)
+	parent.Info("parent")
+	parent.With("a", 1).Info("sibling")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "child | request_id=r-1 user.id=7") {
+		t.Errorf("child line = %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "parent") {
+		t.Errorf("parent line = %q; With and WithGroup must not change the parent", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "sibling | a=1") {
+		t.Errorf("sibling line = %q", lines[2])
+	}
+}
+
+// lineWriter records Writes and notices if two overlap
+type lineWriter struct {
+	mu      sync.Mutex
+	lines   []string
+	active  atomic.Int32
+	overlap atomic.Bool
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if w.active.Add(1) > 1 {
+		w.overlap.Store(true)
+	}
+	time.Sleep(10 * time.Microsecond)
+	w.active.Add(-1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, string(p))
+	return len(p), nil
+}
+
+func TestConcurrentLogging(t *testing.T) {
+	var w lineWriter
+	logger := slog.New(NewDevHandler(&w, nil))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger.With("worker", i).Info("working", "step", i)
+		}(i)
+	}
+	wg.Wait()
+
+	if w.overlap.Load() {
+		t.Error("two Writes overlapped; derived handlers must share one mutex")
+	}
+	if len(w.lines) != 50 {
+		t.Fatalf("got %d Writes for 50 records, want one each", len(w.lines))
+	}
+	for _, line := range w.lines {
+		if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+			t.Errorf("Write %q is not exactly one line", line)
+		}
+	}
+}
+
+// failingWriter fails every Write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestHandleReturnsWriteErrors(t *testing.T) {
+	err := NewDevHandler(failingWriter{}, nil).Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "lost", 0))
+	if err == nil || !strings.Contains(fmt.Sprint(err), "disk full") {
+		t.Errorf("Handle = %v, want the writer's error", err)
+	}
+}
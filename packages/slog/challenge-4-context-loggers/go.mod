@@ -0,0 +1,3 @@
+module slog-challenge-4
+
+go 1.22
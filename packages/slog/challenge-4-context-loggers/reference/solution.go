@@ -0,0 +1,165 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+)
+
+// RequestIDHeader carries the request ID in and out
+const RequestIDHeader = "X-Request-ID"
+
+type loggerKey struct{}
+
+type attrsKey struct{}
+
+// WithLogger returns a copy of ctx that carries logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger in ctx, or slog.Default() if there is none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// ContextWithAttrs returns a copy of ctx with attrs added to those already in
+// it, for a ContextHandler to log. ctx itself is unchanged.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, attrsKey{}, slices.Concat(existing, attrs))
+}
+
+// ContextHandler adds the attributes from ContextWithAttrs to every record
+// logged with a context, then passes it on to the handler it wraps
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle adds the context's attributes to r and passes it on
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler wrapping the inner handler's WithAttrs
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler wrapping the inner handler's WithGroup
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// statusRecorder remembers the status code written to a ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// newRequestID returns 16 random hex characters
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger is middleware that gives every request a logger with
+// request_id, method and path, stored in the request's context. The ID comes
+// from the X-Request-ID header, or is made up, and is sent back in the same
+// header. When next returns, it logs "request finished" with status and
+// duration, at Error for 5xx responses and at Info otherwise.
+func RequestLogger(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		reqLogger := logger.With("request_id", id, "method", r.Method, "path", r.URL.Path)
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(rec, r.WithContext(WithLogger(r.Context(), reqLogger)))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if rec.status >= 500 {
+			level = slog.LevelError
+		}
+		reqLogger.LogAttrs(r.Context(), level, "request finished",
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+		)
+	})
+}
+
+// ErrEmptyCart is returned by Checkout for a cart with no items
+var ErrEmptyCart = errors.New("cart is empty")
+
+// Checkout logs "checkout started" at Info with cart_id and items, using the
+// logger from ctx. An empty cart logs "checkout failed" at Warn with cart_id
+// and error, and returns ErrEmptyCart.
+func Checkout(ctx context.Context, cartID string, items int) error {
+	logger := FromContext(ctx).With("cart_id", cartID)
+	if items == 0 {
+		logger.WarnContext(ctx, "checkout failed", "error", ErrEmptyCart)
+		return ErrEmptyCart
+	}
+	logger.InfoContext(ctx, "checkout started", "items", items)
+	return nil
+}
+
+func main() {
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(os.Stdout, nil)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /checkout", func(w http.ResponseWriter, r *http.Request) {
+		ctx := ContextWithAttrs(r.Context(), slog.String("tenant", r.Header.Get("X-Tenant")))
+		if err := Checkout(ctx, r.FormValue("cart"), len(r.Form["item"])); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	logger.Info("listening", "addr", ":8080")
+	if err := http.ListenAndServe(":8080", RequestLogger(logger, mux)); err != nil {
+		logger.Error("server stopped", "error", err)
+	}
+}
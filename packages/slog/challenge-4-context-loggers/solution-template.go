@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// RequestIDHeader carries the request ID in and out
+const RequestIDHeader = "X-Request-ID"
+
+type loggerKey struct{}
+
+type attrsKey struct{}
+
+// WithLogger returns a copy of ctx that carries logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	// TODO: Store logger under loggerKey{}
+	return ctx
+}
+
+// FromContext returns the logger in ctx, or slog.Default() if there is none
+func FromContext(ctx context.Context) *slog.Logger {
+	// TODO: Look up loggerKey{} with a type assertion
+	return slog.Default()
+}
+
+// ContextWithAttrs returns a copy of ctx with attrs added to those already in
+// it, for a ContextHandler to log. ctx itself is unchanged.
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	// TODO: Store a NEW slice of the existing attributes followed by attrs under attrsKey{}
+	return ctx
+}
+
+// ContextHandler adds the attributes from ContextWithAttrs to every record
+// logged with a context, then passes it on to the handler it wraps
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+// Handle adds the context's attributes to r and passes it on
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	// TODO: Add the attributes from ctx to r
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler wrapping the inner handler's WithAttrs
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// TODO: Wrap h.Handler.WithAttrs(attrs), so the result is still a ContextHandler
+	return h
+}
+
+// WithGroup returns a ContextHandler wrapping the inner handler's WithGroup
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	// TODO: Wrap h.Handler.WithGroup(name)
+	return h
+}
+
+// statusRecorder remembers the status code written to a ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	// TODO: Remember the first code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	// TODO: A Write without WriteHeader means 200
+	return r.ResponseWriter.Write(b)
+}
+
+// newRequestID returns 16 random hex characters
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger is middleware that gives every request a logger with
+// request_id, method and path, stored in the request's context. The ID comes
+// from the X-Request-ID header, or is made up, and is sent back in the same
+// header. When next returns, it logs "request finished" with status and
+// duration, at Error for 5xx responses and at Info otherwise.
+func RequestLogger(logger *slog.Logger, next http.Handler) http.Handler {
+	// TODO: Take the ID from the header or newRequestID, and set it on the response
+	// TODO: Derive the request's logger with With, and serve next with it in the context and a statusRecorder
+	// TODO: Log "request finished" with status and duration at the right level
+	return next
+}
+
+// ErrEmptyCart is returned by Checkout for a cart with no items
+var ErrEmptyCart = errors.New("cart is empty")
+
+// Checkout logs "checkout started" at Info with cart_id and items, using the
+// logger from ctx. An empty cart logs "checkout failed" at Warn with cart_id
+// and error, and returns ErrEmptyCart.
+func Checkout(ctx context.Context, cartID string, items int) error {
+	// TODO: Log through FromContext(ctx) with the ...Context methods, so a ContextHandler sees ctx
+	return nil
+}
+
+func main() {
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(os.Stdout, nil)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /checkout", func(w http.ResponseWriter, r *http.Request) {
+		ctx := ContextWithAttrs(r.Context(), slog.String("tenant", r.Header.Get("X-Tenant")))
+		if err := Checkout(ctx, r.FormValue("cart"), len(r.Form["item"])); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	logger.Info("listening", "addr", ":8080")
+	if err := http.ListenAndServe(":8080", RequestLogger(logger, mux)); err != nil {
+		logger.Error("server stopped", "error", err)
+	}
+}
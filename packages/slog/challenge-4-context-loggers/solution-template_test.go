@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// records decodes the JSON lines in buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad JSON line %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func newJSONLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext of an empty context is not slog.Default()")
+	}
+	logger := newJSONLogger(&bytes.Buffer{})
+	ctx := WithLogger(context.Background(), logger)
+	if FromContext(ctx) != logger {
+		t.Error("FromContext did not return the logger from WithLogger")
+	}
+	child, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if FromContext(child) != logger {
+		t.Error("a derived context lost the logger")
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	h := RequestLogger(newJSONLogger(&buf), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("inside handler")
+		w.WriteHeader(http.StatusCreated)
+		w.WriteHeader(http.StatusTeapot) // ignored by net/http, and should be by the log too
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?x=1", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("response %s = %q, want req-123", RequestIDHeader, got)
+	}
+	recs := records(t, &buf)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(recs), buf.String())
+	}
+	for _, r := range recs {
+		if r["request_id"] != "req-123" || r["method"] != "POST" || r["path"] != "/orders" {
+			t.Errorf("record without request_id, method and path: %v", r)
+		}
+	}
+	if recs[0]["msg"] != "inside handler" {
+		t.Errorf("first record = %v, want the handler's", recs[0])
+	}
+	done := recs[1]
+	if done["msg"] != "request finished" || done["level"] != "INFO" {
+		t.Errorf("last record = %v, want \"request finished\" at INFO", done)
+	}
+	if done["status"] != float64(http.StatusCreated) {
+		t.Errorf("status = %v, want 201", done["status"])
+	}
+	if _, ok := done["duration"].(float64); !ok {
+		t.Errorf("duration = %#v, want a number of nanoseconds", done["duration"])
+	}
+}
+
+func TestRequestLoggerMakesUpIDs(t *testing.T) {
+	var buf bytes.Buffer
+	h := RequestLogger(newJSONLogger(&buf), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		ids = append(ids, rec.Header().Get(RequestIDHeader))
+	}
+	if ids[0] == "" || ids[0] == ids[1] {
+		t.Fatalf("made-up IDs %q: want two different, non-empty IDs", ids)
+	}
+
+	recs := records(t, &buf)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2", len(recs))
+	}
+	for i, r := range recs {
+		if r["request_id"] != ids[i] {
+			t.Errorf("logged request_id %v, but the response said %q", r["request_id"], ids[i])
+		}
+		if r["status"] != float64(http.StatusOK) {
+			t.Errorf("status = %v, want 200 for a handler that only writes a body", r["status"])
+		}
+	}
+}
+
+func TestRequestLoggerStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		handle func(w http.ResponseWriter)
+		status float64
+		level  string
+	}{
+		{"no content", func(w http.ResponseWriter) { w.WriteHeader(http.StatusNoContent) }, 204, "INFO"},
+		{"not found", func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) }, 404, "INFO"},
+		{"internal error", func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) }, 500, "ERROR"},
+		{"unavailable", func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }, 503, "ERROR"},
+		{"nothing written", func(w http.ResponseWriter) {}, 200, "INFO"},
+		{"header after body", func(w http.ResponseWriter) {
+			w.Write([]byte("ok"))
+			w.WriteHeader(http.StatusInternalServerError) // too late, 200 was sent
+		}, 200, "INFO"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := RequestLogger(newJSONLogger(&buf), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tc.handle(w)
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			recs := records(t, &buf)
+			if len(recs) != 1 || recs[0]["status"] != tc.status || recs[0]["level"] != tc.level {
+				t.Errorf("logged %v, want one record with status %v at %s", recs, tc.status, tc.level)
+			}
+		})
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+	base := ContextWithAttrs(context.Background(), slog.String("tenant", "acme"))
+	base = ContextWithAttrs(base, slog.String("trace_id", "t-1"))
+	base = ContextWithAttrs(base, slog.Int("attempt", 1))
+	first := ContextWithAttrs(base, slog.String("step", "first"))
+	second := ContextWithAttrs(base, slog.String("step", "second"))
+
+	logger.InfoContext(first, "one")
+	logger.With("job", "sync").WithGroup("detail").InfoContext(second, "two", "n", 2)
+	logger.InfoContext(context.Background(), "three")
+	logger.Info("four")
+
+	recs := records(t, &buf)
+	if len(recs) != 4 {
+		t.Fatalf("got %d records, want 4:\n%s", len(recs), buf.String())
+	}
+	if r := recs[0]; r["tenant"] != "acme" || r["trace_id"] != "t-1" || r["attempt"] != float64(1) || r["step"] != "first" {
+		t.Errorf("record with context attributes = %v", r)
+	}
+	r := recs[1]
+	if r["job"] != "sync" {
+		t.Errorf("With attribute lost: %v", r)
+	}
+	detail, _ := r["detail"].(map[string]any)
+	if detail == nil || detail["n"] != float64(2) || detail["tenant"] != "acme" || detail["step"] != "second" {
+		t.Errorf("handlers from With and WithGroup must still add the context's attributes, inside the open group: %v", r)
+	}
+	for _, r := range recs[2:] {
+		if _, ok := r["tenant"]; ok {
+			t.Errorf("record without context attributes has tenant: %v", r)
+		}
+	}
+}
+
+func TestContextHandlerKeepsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	logger.InfoContext(ContextWithAttrs(context.Background(), slog.Int("a", 1)), "hidden")
+	if buf.Len() != 0 {
+		t.Errorf("Info logged through a Warn handler: %s", buf.String())
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+	ctx := ContextWithAttrs(WithLogger(context.Background(), logger.With("request_id", "r-9")), slog.String("tenant", "acme"))
+
+	if err := Checkout(ctx, "cart-1", 3); err != nil {
+		t.Fatalf("Checkout = %v", err)
+	}
+	if err := Checkout(ctx, "cart-2", 0); !errors.Is(err, ErrEmptyCart) {
+		t.Fatalf("Checkout of an empty cart = %v, want ErrEmptyCart", err)
+	}
+
+	recs := records(t, &buf)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records, want 2:\n%s", len(recs), buf.String())
+	}
+	for _, r := range recs {
+		if r["request_id"] != "r-9" || r["tenant"] != "acme" {
+			t.Errorf("want the context's logger and attributes: %v", r)
+		}
+	}
+	if r := recs[0]; r["msg"] != "checkout started" || r["level"] != "INFO" || r["cart_id"] != "cart-1" || r["items"] != float64(3) {
+		t.Errorf("first record = %v", r)
+	}
+	if r := recs[1]; r["msg"] != "checkout failed" || r["level"] != "WARN" || r["cart_id"] != "cart-2" || r["error"] != ErrEmptyCart.Error() {
+		t.Errorf("second record = %v", r)
+	}
+}
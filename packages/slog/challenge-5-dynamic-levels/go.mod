@@ -0,0 +1,3 @@
+module slog-challenge-5
+
+go 1.22
@@ -0,0 +1,153 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ParseLevel parses "debug", "info", "warn" or "error", in any case and
+// optionally with an offset like "info+2"
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, want debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// LevelController hands out a logger per component and changes each
+// component's level while the program runs
+type LevelController struct {
+	handler      slog.Handler
+	defaultLevel slog.Level
+
+	mu     sync.Mutex
+	levels map[string]*slog.LevelVar
+}
+
+// NewLevelController returns a controller whose loggers write JSON to w.
+// Components start at defaultLevel.
+func NewLevelController(w io.Writer, defaultLevel slog.Level) *LevelController {
+	return &LevelController{
+		// every level gets through the JSON handler: levelHandler filters
+		handler:      slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.Level(math.MinInt)}),
+		defaultLevel: defaultLevel,
+		levels:       make(map[string]*slog.LevelVar),
+	}
+}
+
+// levelVar returns component's level, adding it at the default level if it
+// is new
+func (c *LevelController) levelVar(component string) *slog.LevelVar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lv, ok := c.levels[component]
+	if !ok {
+		lv = new(slog.LevelVar)
+		lv.Set(c.defaultLevel)
+		c.levels[component] = lv
+	}
+	return lv
+}
+
+// Logger returns a logger for component that adds a component attribute and
+// follows later changes to the component's level
+func (c *LevelController) Logger(component string) *slog.Logger {
+	h := &levelHandler{Handler: c.handler, level: c.levelVar(component)}
+	return slog.New(h).With("component", component)
+}
+
+// SetLevel changes component's level, for loggers already handed out too. A
+// component with no logger yet gets level once it has one.
+func (c *LevelController) SetLevel(component string, level slog.Level) {
+	c.levelVar(component).Set(level)
+}
+
+// Levels returns the level of every known component, like "DEBUG"
+func (c *LevelController) Levels() map[string]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	levels := make(map[string]string, len(c.levels))
+	for component, lv := range c.levels {
+		levels[component] = lv.Level().String()
+	}
+	return levels
+}
+
+// Handler serves the levels over HTTP:
+//
+//	GET /log-level              {"db":"INFO","http":"WARN"}
+//	PUT /log-level/{component}  {"level":"debug"} -> {"component":"db","level":"DEBUG"}
+//
+// A PUT with a bad body or level gets 400 Bad Request.
+func (c *LevelController) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /log-level", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, c.Levels())
+	})
+	mux.HandleFunc("PUT /log-level/{component}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		level, err := ParseLevel(body.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		component := r.PathValue("component")
+		c.SetLevel(component, level)
+		writeJSON(w, map[string]string{"component": component, "level": level.String()})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// levelHandler drops records below its level and passes the rest on
+type levelHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
+func main() {
+	level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	levels := NewLevelController(os.Stdout, level)
+	db := levels.Logger("db")
+	db.Debug("query", "sql", "SELECT 1")
+
+	// curl -X PUT localhost:8080/log-level/db -d '{"level":"debug"}'
+	levels.Logger("admin").Info("listening", "addr", ":8080")
+	http.ListenAndServe(":8080", levels.Handler())
+}
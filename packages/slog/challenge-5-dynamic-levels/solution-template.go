@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// ParseLevel parses "debug", "info", "warn" or "error", in any case and
+// optionally with an offset like "info+2"
+func ParseLevel(s string) (slog.Level, error) {
+	// TODO: Use slog.Level's UnmarshalText, and return an error naming s for anything else
+	return 0, nil
+}
+
+// LevelController hands out a logger per component and changes each
+// component's level while the program runs
+type LevelController struct {
+	handler      slog.Handler
+	defaultLevel slog.Level
+
+	mu     sync.Mutex
+	levels map[string]*slog.LevelVar
+}
+
+// NewLevelController returns a controller whose loggers write JSON to w.
+// Components start at defaultLevel.
+func NewLevelController(w io.Writer, defaultLevel slog.Level) *LevelController {
+	return &LevelController{
+		// every level gets through the JSON handler: levelHandler filters
+		handler:      slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.Level(math.MinInt)}),
+		defaultLevel: defaultLevel,
+		levels:       make(map[string]*slog.LevelVar),
+	}
+}
+
+// levelVar returns component's level, adding it at the default level if it
+// is new
+func (c *LevelController) levelVar(component string) *slog.LevelVar {
+	// TODO: Look up or add the component's LevelVar while holding c.mu
+	lv := new(slog.LevelVar)
+	lv.Set(c.defaultLevel)
+	return lv
+}
+
+// Logger returns a logger for component that adds a component attribute and
+// follows later changes to the component's level
+func (c *LevelController) Logger(component string) *slog.Logger {
+	// TODO: Wrap c.handler in a levelHandler with the component's LevelVar, and add the component attribute
+	return slog.New(c.handler)
+}
+
+// SetLevel changes component's level, for loggers already handed out too. A
+// component with no logger yet gets level once it has one.
+func (c *LevelController) SetLevel(component string, level slog.Level) {
+	// TODO: Set the component's LevelVar
+}
+
+// Levels returns the level of every known component, like "DEBUG"
+func (c *LevelController) Levels() map[string]string {
+	// TODO: Copy the levels into a new map while holding c.mu
+	return map[string]string{}
+}
+
+// Handler serves the levels over HTTP:
+//
+//	GET /log-level              {"db":"INFO","http":"WARN"}
+//	PUT /log-level/{component}  {"level":"debug"} -> {"component":"db","level":"DEBUG"}
+//
+// A PUT with a bad body or level gets 400 Bad Request.
+func (c *LevelController) Handler() http.Handler {
+	mux := http.NewServeMux()
+	// TODO: Register "GET /log-level" and "PUT /log-level/{component}"
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// levelHandler drops records below its level and passes the rest on
+type levelHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	// TODO: Compare with h.level.Level(), read every time
+	return true
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// TODO: Keep filtering after With
+	return h.Handler.WithAttrs(attrs)
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	// TODO: Keep filtering after WithGroup
+	return h.Handler.WithGroup(name)
+}
+
+func main() {
+	level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	levels := NewLevelController(os.Stdout, level)
+	db := levels.Logger("db")
+	db.Debug("query", "sql", "SELECT 1")
+
+	// curl -X PUT localhost:8080/log-level/db -d '{"level":"debug"}'
+	levels.Logger("admin").Info("listening", "addr", ":8080")
+	http.ListenAndServe(":8080", levels.Handler())
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{" Warn ", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"info+2", slog.LevelInfo + 2},
+		{"ERROR-1", slog.LevelError - 1},
+	} {
+		got, err := ParseLevel(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, in := range []string{"", "verbose", "info+", "3"} {
+		if _, err := ParseLevel(in); err == nil {
+			t.Errorf("ParseLevel(%q) returned no error", in)
+		} else if !strings.Contains(err.Error(), in) {
+			t.Errorf("ParseLevel(%q) error %q does not name the input", in, err)
+		}
+	}
+}
+
+// messages returns the msg of every JSON line in buf, as component:msg
+func messages(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("bad JSON line %q: %v", line, err)
+		}
+		out = append(out, fmt.Sprintf("%v:%v", m["component"], m["msg"]))
+	}
+	buf.Reset()
+	return out
+}
+
+func TestLoggersFollowLevelChanges(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewLevelController(&buf, slog.LevelInfo)
+	db := c.Logger("db")
+	api := c.Logger("api").With("version", 2).WithGroup("req")
+
+	db.Debug("db debug")
+	db.Info("db info")
+	api.Debug("api debug")
+	api.Info("api info")
+	if got, want := messages(t, &buf), []string{"db:db info", "api:api info"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("at the default Info level got %q, want %q", got, want)
+	}
+
+	c.SetLevel("db", slog.LevelDebug)
+	c.SetLevel("api", slog.LevelError)
+	db.Debug("db debug")
+	api.Warn("api warn")
+	api.Error("api error")
+	if got, want := messages(t, &buf), []string{"db:db debug", "api:api error"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after SetLevel got %q, want %q: loggers handed out earlier, and ones made with With and WithGroup, must follow", got, want)
+	}
+
+	if again := c.Logger("db"); !again.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("a second Logger for db does not share its level")
+	}
+}
+
+func TestSetLevelBeforeLogger(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewLevelController(&buf, slog.LevelWarn)
+	c.SetLevel("cache", slog.LevelDebug)
+	c.Logger("cache").Debug("warming up")
+	c.Logger("other").Info("hidden")
+	if got, want := messages(t, &buf), []string{"cache:warming up"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	c := NewLevelController(&bytes.Buffer{}, slog.LevelInfo)
+	c.Logger("db")
+	c.Logger("api")
+	c.SetLevel("api", slog.LevelWarn+1)
+
+	levels := c.Levels()
+	want := map[string]string{"db": "INFO", "api": "WARN+1"}
+	if !reflect.DeepEqual(levels, want) {
+		t.Fatalf("Levels() = %v, want %v", levels, want)
+	}
+	levels["db"] = "ERROR"
+	if c.Levels()["db"] != "INFO" {
+		t.Error("changing the map from Levels changed the controller")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	c := NewLevelController(&buf, slog.LevelInfo)
+	db := c.Logger("db")
+	c.Logger("api")
+	h := c.Handler()
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/log-level/db", `{"level":"debug"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s, want 200", rec.Code, rec.Body)
+	}
+	var put map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &put); err != nil || put["component"] != "db" || put["level"] != "DEBUG" {
+		t.Errorf("PUT body = %s, want {\"component\":\"db\",\"level\":\"DEBUG\"}", rec.Body)
+	}
+	db.Debug("now visible")
+	if got := messages(t, &buf); !reflect.DeepEqual(got, []string{"db:now visible"}) {
+		t.Errorf("after PUT, Debug logged %q", got)
+	}
+
+	rec = serve(http.MethodGet, "/log-level", "")
+	var levels map[string]string
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &levels) != nil {
+		t.Fatalf("GET = %d %s", rec.Code, rec.Body)
+	}
+	if want := map[string]string{"db": "DEBUG", "api": "INFO"}; !reflect.DeepEqual(levels, want) {
+		t.Errorf("GET = %v, want %v", levels, want)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	for _, body := range []string{`{"level":"loud"}`, `{"level":`, `{}`} {
+		if rec := serve(http.MethodPut, "/log-level/db", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s = %d, want 400", body, rec.Code)
+		}
+	}
+	if c.Levels()["db"] != "DEBUG" {
+		t.Error("a rejected PUT changed the level")
+	}
+	if rec := serve(http.MethodDelete, "/log-level/db", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE = %d, want 405", rec.Code)
+	}
+}
+
+func TestConcurrentLevelChanges(t *testing.T) {
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	c := NewLevelController(writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return buf.Write(p)
+	}), slog.LevelInfo)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.Logger("worker").Debug("tick")
+			c.Levels()
+		}()
+		go func(i int) {
+			defer wg.Done()
+			c.SetLevel("worker", slog.LevelDebug+slog.Level(i%2)*4)
+		}(i)
+	}
+	wg.Wait()
+	if len(c.Levels()) != 1 {
+		t.Errorf("Levels() = %v, want only worker", c.Levels())
+	}
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
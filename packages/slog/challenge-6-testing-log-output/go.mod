@@ -0,0 +1,3 @@
+module slog-challenge-6
+
+go 1.22
@@ -0,0 +1,128 @@
+//go:build reference
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"testing/slogtest"
+)
+
+// ParseJSONLines decodes output from a slog.JSONHandler, one record per
+// line. Blank lines are skipped; a bad line is an error with its line number.
+func ParseJSONLines(data []byte) ([]map[string]any, error) {
+	var entries []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Find returns the first entry whose message is msg
+func Find(entries []map[string]any, msg string) (map[string]any, bool) {
+	for _, entry := range entries {
+		if entry[slog.MessageKey] == msg {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// Lookup returns the value at a dotted path like "request.user.id", following
+// groups down through nested maps
+func Lookup(entry map[string]any, path string) (any, bool) {
+	var value any = entry
+	for _, key := range strings.Split(path, ".") {
+		group, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = group[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// DropTime is a ReplaceAttr function that removes the record's time, so
+// output is the same on every run
+func DropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// tbWriter sends each record to a test's log
+type tbWriter struct {
+	tb testing.TB
+}
+
+func (w tbWriter) Write(p []byte) (int, error) {
+	w.tb.Helper()
+	w.tb.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// NewTestLogger returns a logger that writes text records without times to
+// tb's log, so they show up next to the test that wrote them, and only when
+// it fails or runs with -v
+func NewTestLogger(tb testing.TB, level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewTextHandler(tbWriter{tb}, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: DropTime,
+	}))
+}
+
+// CheckHandler runs testing/slogtest against the handler newHandler returns
+// for a buffer, reading the buffer back with ParseJSONLines. It returns nil if
+// the handler follows every rule.
+func CheckHandler(newHandler func(w io.Writer) slog.Handler) error {
+	var buf bytes.Buffer
+	return slogtest.TestHandler(newHandler(&buf), func() []map[string]any {
+		entries, err := ParseJSONLines(buf.Bytes())
+		if err != nil {
+			return nil
+		}
+		return entries
+	})
+}
+
+// Transfer moves cents between accounts and logs what happened. It is the
+// code the tests in this challenge check the logs of.
+func Transfer(logger *slog.Logger, from, to string, cents int64) error {
+	logger = logger.With(slog.Group("transfer", "from", from, "to", to))
+	if cents <= 0 {
+		err := fmt.Errorf("amount must be positive, got %d", cents)
+		logger.Warn("transfer rejected", "error", err)
+		return err
+	}
+	logger.Debug("checking balance")
+	logger.Info("transfer complete", slog.Group("amount", "cents", cents, "currency", "EUR"))
+	return nil
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: DropTime}))
+	Transfer(logger, "acc-1", "acc-2", 2500)
+	Transfer(logger, "acc-1", "acc-2", 0)
+
+	err := CheckHandler(func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, nil) })
+	fmt.Println("JSONHandler passes slogtest:", err == nil)
+}
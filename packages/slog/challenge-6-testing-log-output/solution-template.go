@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+// ParseJSONLines decodes output from a slog.JSONHandler, one record per
+// line. Blank lines are skipped; a bad line is an error with its line number.
+func ParseJSONLines(data []byte) ([]map[string]any, error) {
+	// TODO: Scan the lines, skip blank ones, and json.Unmarshal each into a map
+	// TODO: Return "line N: ..." for a line that isn't JSON
+	return nil, nil
+}
+
+// Find returns the first entry whose message is msg
+func Find(entries []map[string]any, msg string) (map[string]any, bool) {
+	// TODO: Compare entry[slog.MessageKey] with msg
+	return nil, false
+}
+
+// Lookup returns the value at a dotted path like "request.user.id", following
+// groups down through nested maps
+func Lookup(entry map[string]any, path string) (any, bool) {
+	// TODO: Split the path at dots and step into a map[string]any for each part
+	return nil, false
+}
+
+// DropTime is a ReplaceAttr function that removes the record's time, so
+// output is the same on every run
+func DropTime(groups []string, a slog.Attr) slog.Attr {
+	// TODO: Return slog.Attr{} for the top-level time attribute
+	return a
+}
+
+// tbWriter sends each record to a test's log
+type tbWriter struct {
+	tb testing.TB
+}
+
+func (w tbWriter) Write(p []byte) (int, error) {
+	// TODO: Mark this as a helper and tb.Log the record without its newline
+	return len(p), nil
+}
+
+// NewTestLogger returns a logger that writes text records without times to
+// tb's log, so they show up next to the test that wrote them, and only when
+// it fails or runs with -v
+func NewTestLogger(tb testing.TB, level slog.Leveler) *slog.Logger {
+	// TODO: A TextHandler writing to a tbWriter, with level and DropTime
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// CheckHandler runs testing/slogtest against the handler newHandler returns
+// for a buffer, reading the buffer back with ParseJSONLines. It returns nil if
+// the handler follows every rule.
+func CheckHandler(newHandler func(w io.Writer) slog.Handler) error {
+	// TODO: Call slogtest.TestHandler with the handler and a results function
+	return nil
+}
+
+// Transfer moves cents between accounts and logs what happened. It is the
+// code the tests in this challenge check the logs of.
+func Transfer(logger *slog.Logger, from, to string, cents int64) error {
+	logger = logger.With(slog.Group("transfer", "from", from, "to", to))
+	if cents <= 0 {
+		err := fmt.Errorf("amount must be positive, got %d", cents)
+		logger.Warn("transfer rejected", "error", err)
+		return err
+	}
+	logger.Debug("checking balance")
+	logger.Info("transfer complete", slog.Group("amount", "cents", cents, "currency", "EUR"))
+	return nil
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: DropTime}))
+	Transfer(logger, "acc-1", "acc-2", 2500)
+	Transfer(logger, "acc-1", "acc-2", 0)
+
+	err := CheckHandler(func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, nil) })
+	fmt.Println("JSONHandler passes slogtest:", err == nil)
+}
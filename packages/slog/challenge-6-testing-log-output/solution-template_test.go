@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONLines(t *testing.T) {
+	data := []byte(`{"msg":"one","n":1}
+
+{"msg":"two","g":{"k":"v"}}
+`)
+	entries, err := ParseJSONLines(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{
+		{"msg": "one", "n": float64(1)},
+		{"msg": "two", "g": map[string]any{"k": "v"}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ParseJSONLines = %v, want %v", entries, want)
+	}
+
+	if entries, err := ParseJSONLines(nil); err != nil || len(entries) != 0 {
+		t.Errorf("ParseJSONLines(nil) = %v, %v; want no entries and no error", entries, err)
+	}
+
+	_, err = ParseJSONLines([]byte("{\"msg\":\"ok\"}\n\nlevel=INFO msg=text\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("error = %v, want one naming line 3", err)
+	}
+}
+
+func TestFindAndLookup(t *testing.T) {
+	entries := []map[string]any{
+		{"msg": "start"},
+		{"msg": "done", "request": map[string]any{"user": map[string]any{"id": float64(7)}, "path": "/"}},
+		{"msg": "done", "second": true},
+	}
+	entry, ok := Find(entries, "done")
+	if !ok || entry["request"] == nil {
+		t.Fatalf("Find(done) = %v, %v; want the first match", entry, ok)
+	}
+	if entry, ok := Find(entries, "missing"); ok || entry != nil {
+		t.Errorf("Find(missing) = %v, %v", entry, ok)
+	}
+
+	for _, tc := range []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{"msg", "done", true},
+		{"request.path", "/", true},
+		{"request.user.id", float64(7), true},
+		{"request.user", map[string]any{"id": float64(7)}, true},
+		{"request.user.name", nil, false},
+		{"request.path.more", nil, false},
+		{"nothing", nil, false},
+	} {
+		got, ok := Lookup(entry, tc.path)
+		if ok != tc.ok || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Lookup(%q) = %v, %v; want %v, %v", tc.path, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestDropTime(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: DropTime}))
+	logger.Info("hello", slog.Group("g", slog.String("time", "kept")))
+	if want := `{"level":"INFO","msg":"hello","g":{"time":"kept"}}` + "\n"; buf.String() != want {
+		t.Errorf("output %s, want %s: only the record's own time goes", buf.String(), want)
+	}
+}
+
+// fakeTB records what a logger sends to a test's log
+type fakeTB struct {
+	testing.TB
+	logs    []string
+	helpers int
+}
+
+func (tb *fakeTB) Log(args ...any) { tb.logs = append(tb.logs, fmt.Sprint(args...)) }
+
+func (tb *fakeTB) Helper() { tb.helpers++ }
+
+func TestNewTestLogger(t *testing.T) {
+	tb := &fakeTB{}
+	var level slog.LevelVar
+	logger := NewTestLogger(tb, &level)
+	logger.Debug("hidden")
+	logger.Info("hello", "user", "ada lovelace")
+	level.Set(slog.LevelDebug)
+	logger.WithGroup("db").Debug("query", "rows", 3)
+
+	want := []string{
+		`level=INFO msg=hello user="ada lovelace"`,
+		`level=DEBUG msg=query db.rows=3`,
+	}
+	if !reflect.DeepEqual(tb.logs, want) {
+		t.Errorf("logged %q, want %q", tb.logs, want)
+	}
+	if tb.helpers == 0 {
+		t.Error("tbWriter.Write should call Helper, so log lines don't all point at it")
+	}
+
+	// and it works with a real test, visible with go test -v
+	NewTestLogger(t, slog.LevelDebug).Debug("logged through t.Log")
+}
+
+// forgetfulHandler ignores WithGroup, which slogtest should catch
+type forgetfulHandler struct{ slog.Handler }
+
+func (h forgetfulHandler) WithGroup(string) slog.Handler { return h }
+
+func TestCheckHandler(t *testing.T) {
+	if err := CheckHandler(func(w io.Writer) slog.Handler { return slog.NewJSONHandler(w, nil) }); err != nil {
+		t.Errorf("CheckHandler(JSONHandler) = %v, want nil", err)
+	}
+	if err := CheckHandler(func(w io.Writer) slog.Handler {
+		return forgetfulHandler{slog.NewJSONHandler(w, nil)}
+	}); err == nil || !strings.Contains(err.Error(), "group") {
+		t.Errorf("CheckHandler(a handler that ignores WithGroup) = %v, want an error about groups", err)
+	}
+	if err := CheckHandler(func(w io.Writer) slog.Handler { return slog.NewTextHandler(w, nil) }); err == nil {
+		t.Error("CheckHandler(TextHandler) = nil, but its output isn't JSON")
+	}
+}
+
+// TestTransferLogs is how the helpers come together in a real test
+func TestTransferLogs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: DropTime}))
+
+	if err := Transfer(logger, "acc-1", "acc-2", 2500); err != nil {
+		t.Fatal(err)
+	}
+	if err := Transfer(logger, "acc-1", "acc-2", -5); err == nil {
+		t.Fatal("Transfer of a negative amount succeeded")
+	}
+
+	entries, err := ParseJSONLines(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3:\n%s", len(entries), buf.String())
+	}
+	for _, entry := range entries {
+		if _, ok := entry[slog.TimeKey]; ok {
+			t.Errorf("entry has a time after DropTime: %v", entry)
+		}
+	}
+
+	done, ok := Find(entries, "transfer complete")
+	if !ok {
+		t.Fatalf("no \"transfer complete\" in:\n%s", buf.String())
+	}
+	for path, want := range map[string]any{
+		"level":           "INFO",
+		"transfer.from":   "acc-1",
+		"transfer.to":     "acc-2",
+		"amount.cents":    float64(2500),
+		"amount.currency": "EUR",
+	} {
+		if got, _ := Lookup(done, path); got != want {
+			t.Errorf("%s = %v, want %v", path, got, want)
+		}
+	}
+
+	rejected, ok := Find(entries, "transfer rejected")
+	if got, _ := Lookup(rejected, "error"); !ok || got != "amount must be positive, got -5" {
+		t.Errorf("rejected entry = %v", rejected)
+	}
+}
@@ -0,0 +1,169 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// PoolConfig holds the connection pool settings of a *sql.DB
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultPoolConfig returns pool settings suitable for a small service
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+	}
+}
+
+// Note is a row of the notes table
+type Note struct {
+	ID    int64
+	Title string
+	Body  string
+}
+
+// ErrNotFound is returned when no note has the requested ID
+var ErrNotFound = errors.New("note not found")
+
+const schema = `CREATE TABLE IF NOT EXISTS notes (
+	id    INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	body  TEXT NOT NULL DEFAULT ''
+)`
+
+// Open opens the SQLite database at path, applies the pool settings and
+// checks that a connection can be made
+func Open(ctx context.Context, path string, cfg PoolConfig) (*sql.DB, error) {
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// sql.Open only validates its arguments; the first connection is made here
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting to %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// NoteStore reads and writes notes
+type NoteStore struct {
+	db *sql.DB
+}
+
+// NewNoteStore creates the notes table if needed
+func NewNoteStore(ctx context.Context, db *sql.DB) (*NoteStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+	return &NoteStore{db: db}, nil
+}
+
+// Add inserts a note and returns its ID
+func (s *NoteStore) Add(ctx context.Context, title, body string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO notes (title, body) VALUES (?, ?)", title, body)
+	if err != nil {
+		return 0, fmt.Errorf("adding note: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// Get returns the note with the given ID, or ErrNotFound
+func (s *NoteStore) Get(ctx context.Context, id int64) (*Note, error) {
+	var n Note
+	err := s.db.QueryRowContext(ctx, "SELECT id, title, body FROM notes WHERE id = ?", id).
+		Scan(&n.ID, &n.Title, &n.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting note %d: %w", id, err)
+	}
+	return &n, nil
+}
+
+// Search returns the notes whose title contains term, ordered by ID
+func (s *NoteStore) Search(ctx context.Context, term string) ([]Note, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, title, body FROM notes WHERE title LIKE '%' || ? || '%' ORDER BY id", term)
+	if err != nil {
+		return nil, fmt.Errorf("searching notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []Note{}
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.Title, &n.Body); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// SumTo adds up 1..n in SQL. A large n keeps the query running long enough
+// for the context to cancel it.
+func SumTo(ctx context.Context, db *sql.DB, n int64) (int64, error) {
+	const query = `WITH RECURSIVE series(x) AS (
+		SELECT 1 UNION ALL SELECT x + 1 FROM series WHERE x < ?
+	) SELECT sum(x) FROM series`
+
+	var sum int64
+	if err := db.QueryRowContext(ctx, query, n).Scan(&sum); err != nil {
+		// The driver reports an interrupted query in its own words;
+		// callers should see why it was interrupted
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
+		return 0, err
+	}
+	return sum, nil
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := Open(ctx, "notes.db", DefaultPoolConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := NewNoteStore(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	id, err := store.Add(ctx, "Pool settings", "Set MaxOpenConns before you need it")
+	if err != nil {
+		log.Fatal(err)
+	}
+	note, err := store.Get(ctx, id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d: %s\n", note.ID, note.Title)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// PoolConfig holds the connection pool settings of a *sql.DB
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultPoolConfig returns pool settings suitable for a small service
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+	}
+}
+
+// Note is a row of the notes table
+type Note struct {
+	ID    int64
+	Title string
+	Body  string
+}
+
+// ErrNotFound is returned when no note has the requested ID
+var ErrNotFound = errors.New("note not found")
+
+const schema = `CREATE TABLE IF NOT EXISTS notes (
+	id    INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	body  TEXT NOT NULL DEFAULT ''
+)`
+
+// Open opens the SQLite database at path, applies the pool settings and
+// checks that a connection can be made
+func Open(ctx context.Context, path string, cfg PoolConfig) (*sql.DB, error) {
+	// TODO: Open the database with sql.Open(driverName, path)
+	// TODO: Apply every PoolConfig setting with the db.Set* methods
+	// TODO: sql.Open does not connect; ping with the context, and close the
+	// database if that fails
+	return nil, nil
+}
+
+// NoteStore reads and writes notes
+type NoteStore struct {
+	db *sql.DB
+}
+
+// NewNoteStore creates the notes table if needed
+func NewNoteStore(ctx context.Context, db *sql.DB) (*NoteStore, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+	return &NoteStore{db: db}, nil
+}
+
+// Add inserts a note and returns its ID
+func (s *NoteStore) Add(ctx context.Context, title, body string) (int64, error) {
+	// TODO: Insert with ExecContext and ? placeholders, and return LastInsertId
+	return 0, nil
+}
+
+// Get returns the note with the given ID, or ErrNotFound
+func (s *NoteStore) Get(ctx context.Context, id int64) (*Note, error) {
+	// TODO: Use QueryRowContext and Scan; map sql.ErrNoRows to ErrNotFound
+	return nil, nil
+}
+
+// Search returns the notes whose title contains term, ordered by ID
+func (s *NoteStore) Search(ctx context.Context, term string) ([]Note, error) {
+	// TODO: Use QueryContext with a placeholder for term
+	// TODO: Close the rows when done, or the connection never returns to the pool
+	// TODO: Check rows.Err after the loop
+	return nil, nil
+}
+
+// SumTo adds up 1..n in SQL. A large n keeps the query running long enough
+// for the context to cancel it.
+func SumTo(ctx context.Context, db *sql.DB, n int64) (int64, error) {
+	const query = `WITH RECURSIVE series(x) AS (
+		SELECT 1 UNION ALL SELECT x + 1 FROM series WHERE x < ?
+	) SELECT sum(x) FROM series`
+
+	// TODO: Run the query with QueryRowContext and scan the sum
+	// TODO: If the query fails because the context is done, return ctx.Err()
+	return 0, nil
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := Open(ctx, "notes.db", DefaultPoolConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	store, err := NewNoteStore(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	id, err := store.Add(ctx, "Pool settings", "Set MaxOpenConns before you need it")
+	if err != nil {
+		log.Fatal(err)
+	}
+	note, err := store.Get(ctx, id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d: %s\n", note.ID, note.Title)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestDB opens a fresh database file that is removed when the test ends.
+// A file rather than ":memory:" matters here: every pooled connection to
+// ":memory:" would see its own empty database.
+func openTestDB(t *testing.T, cfg PoolConfig) *sql.DB {
+	t.Helper()
+	db, err := Open(context.Background(), filepath.Join(t.TempDir(), "notes.db"), cfg)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if db == nil {
+		t.Fatal("Open returned a nil *sql.DB")
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func openTestStore(t *testing.T) (*sql.DB, *NoteStore) {
+	t.Helper()
+	db := openTestDB(t, DefaultPoolConfig())
+	store, err := NewNoteStore(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, store
+}
+
+// waitIdle fails the test if a connection stays checked out of the pool
+func waitIdle(t *testing.T, db *sql.DB, after string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for db.Stats().InUse != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connection(s) still in use after %s; close your rows", db.Stats().InUse, after)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOpenAppliesPoolConfig(t *testing.T) {
+	db := openTestDB(t, PoolConfig{MaxOpenConns: 4, MaxIdleConns: 2, ConnMaxLifetime: time.Hour, ConnMaxIdleTime: time.Minute})
+	if got := db.Stats().MaxOpenConnections; got != 4 {
+		t.Errorf("MaxOpenConnections = %d, want 4", got)
+	}
+
+	// Check out three connections at once, then give them all back: only
+	// MaxIdleConns of them may stay open
+	ctx := context.Background()
+	var conns []*sql.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+	stats := db.Stats()
+	if stats.Idle != 2 || stats.OpenConnections != 2 {
+		t.Errorf("after returning 3 connections: %d idle, %d open; want 2 and 2", stats.Idle, stats.OpenConnections)
+	}
+}
+
+func TestOpenConnects(t *testing.T) {
+	db := openTestDB(t, DefaultPoolConfig())
+	if stats := db.Stats(); stats.OpenConnections != 1 {
+		t.Errorf("OpenConnections = %d after Open, want 1; sql.Open alone does not connect", stats.OpenConnections)
+	}
+}
+
+func TestOpenFails(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "no-such-dir", "notes.db")
+	if db, err := Open(context.Background(), missing, DefaultPoolConfig()); err == nil {
+		if db != nil {
+			db.Close()
+		}
+		t.Error("Open succeeded for a file in a missing directory; ping the database to find out")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	path := filepath.Join(t.TempDir(), "notes.db")
+	if _, err := Open(ctx, path, DefaultPoolConfig()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Open with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestAddAndGet(t *testing.T) {
+	db, store := openTestStore(t)
+	ctx := context.Background()
+
+	first, err := store.Add(ctx, "Groceries", "milk, eggs")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	second, err := store.Add(ctx, "Ideas", "")
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if first != 1 || second != 2 {
+		t.Errorf("IDs = %d, %d; want 1, 2", first, second)
+	}
+
+	note, err := store.Get(ctx, first)
+	if err != nil || note == nil {
+		t.Fatalf("Get(%d) = %v, %v", first, note, err)
+	}
+	if *note != (Note{ID: 1, Title: "Groceries", Body: "milk, eggs"}) {
+		t.Errorf("Get(%d) = %+v", first, *note)
+	}
+
+	if _, err := store.Get(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(99) error = %v, want ErrNotFound", err)
+	}
+	waitIdle(t, db, "Get")
+}
+
+func TestSearch(t *testing.T) {
+	db, store := openTestStore(t)
+	ctx := context.Background()
+	for _, title := range []string{"Go pools", "SQL basics", "Pooling in Go", "Cooking"} {
+		if _, err := store.Add(ctx, title, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes, err := store.Search(ctx, "pool")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(notes) != 2 || notes[0].Title != "Go pools" || notes[1].Title != "Pooling in Go" {
+		t.Errorf("Search(pool) = %+v, want Go pools and Pooling in Go in ID order", notes)
+	}
+
+	for _, term := range []string{"nothing like this", "' OR '1'='1"} {
+		notes, err := store.Search(ctx, term)
+		if err != nil {
+			t.Errorf("Search(%q): %v", term, err)
+		}
+		if len(notes) != 0 {
+			t.Errorf("Search(%q) = %d notes, want 0; pass the term as a parameter", term, len(notes))
+		}
+	}
+	waitIdle(t, db, "Search")
+}
+
+func TestSumTo(t *testing.T) {
+	db := openTestDB(t, DefaultPoolConfig())
+	sum, err := SumTo(context.Background(), db, 1000)
+	if err != nil || sum != 500500 {
+		t.Errorf("SumTo(1000) = %d, %v; want 500500", sum, err)
+	}
+}
+
+func TestSumToCancelled(t *testing.T) {
+	db := openTestDB(t, DefaultPoolConfig())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := SumTo(ctx, db, 1_000_000_000_000)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("SumTo error = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SumTo kept running after its context expired; use QueryRowContext")
+	}
+	waitIdle(t, db, "a cancelled query")
+}
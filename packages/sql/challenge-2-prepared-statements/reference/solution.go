@@ -0,0 +1,172 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// Product is a row of the products table. Prices are in cents.
+type Product struct {
+	ID         int64
+	SKU        string
+	Name       string
+	PriceCents int64
+}
+
+// ErrNotFound is returned when no product has the requested SKU
+var ErrNotFound = errors.New("product not found")
+
+const schema = `CREATE TABLE IF NOT EXISTS products (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	sku         TEXT NOT NULL UNIQUE,
+	name        TEXT NOT NULL,
+	price_cents INTEGER NOT NULL
+)`
+
+// Catalog runs its queries through statements prepared once, when it is
+// created
+type Catalog struct {
+	db           *sql.DB
+	insert       *sql.Stmt
+	bySKU        *sql.Stmt
+	inPriceRange *sql.Stmt
+}
+
+// NewCatalog creates the products table if needed and prepares the
+// catalog's statements
+func NewCatalog(ctx context.Context, db *sql.DB) (*Catalog, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+
+	c := &Catalog{db: db}
+	var err error
+	prepare := func(query string) *sql.Stmt {
+		if err != nil {
+			return nil
+		}
+		var stmt *sql.Stmt
+		stmt, err = db.PrepareContext(ctx, query)
+		return stmt
+	}
+	c.insert = prepare("INSERT INTO products (sku, name, price_cents) VALUES (?, ?, ?)")
+	c.bySKU = prepare("SELECT id, sku, name, price_cents FROM products WHERE sku = ?")
+	c.inPriceRange = prepare(`SELECT id, sku, name, price_cents FROM products
+		WHERE price_cents BETWEEN ? AND ? ORDER BY price_cents, id`)
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("preparing statements: %w", err)
+	}
+	return c, nil
+}
+
+// Close releases the prepared statements
+func (c *Catalog) Close() error {
+	var errs []error
+	for _, stmt := range []*sql.Stmt{c.insert, c.bySKU, c.inPriceRange} {
+		if stmt != nil {
+			errs = append(errs, stmt.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Add inserts a product and returns its ID
+func (c *Catalog) Add(ctx context.Context, p Product) (int64, error) {
+	result, err := c.insert.ExecContext(ctx, p.SKU, p.Name, p.PriceCents)
+	if err != nil {
+		return 0, fmt.Errorf("adding %s: %w", p.SKU, err)
+	}
+	return result.LastInsertId()
+}
+
+// BySKU returns the product with the given SKU, or ErrNotFound
+func (c *Catalog) BySKU(ctx context.Context, sku string) (*Product, error) {
+	var p Product
+	err := c.bySKU.QueryRowContext(ctx, sku).Scan(&p.ID, &p.SKU, &p.Name, &p.PriceCents)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// InPriceRange returns the products priced from min to max cents inclusive,
+// cheapest first
+func (c *Catalog) InPriceRange(ctx context.Context, min, max int64) ([]Product, error) {
+	rows, err := c.inPriceRange.QueryContext(ctx, min, max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []Product{}
+	for rows.Next() {
+		var p Product
+		if err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.PriceCents); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+// Import adds all the products in one transaction, reusing the prepared
+// insert. If any insert fails, none of the products are added.
+func (c *Catalog) Import(ctx context.Context, products []Product) (int, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The statement was prepared on the pool; bind it to the transaction
+	insert := tx.StmtContext(ctx, c.insert)
+	defer insert.Close()
+
+	for i, p := range products {
+		if _, err := insert.ExecContext(ctx, p.SKU, p.Name, p.PriceCents); err != nil {
+			return 0, fmt.Errorf("importing product %d (%s): %w", i, p.SKU, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(products), nil
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := sql.Open(driverName, "catalog.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	catalog, err := NewCatalog(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer catalog.Close()
+
+	n, err := catalog.Import(ctx, []Product{
+		{SKU: "KB-01", Name: "Keyboard", PriceCents: 4999},
+		{SKU: "MS-01", Name: "Mouse", PriceCents: 1999},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("imported %d products\n", n)
+}
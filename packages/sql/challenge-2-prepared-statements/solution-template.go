@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// Product is a row of the products table. Prices are in cents.
+type Product struct {
+	ID         int64
+	SKU        string
+	Name       string
+	PriceCents int64
+}
+
+// ErrNotFound is returned when no product has the requested SKU
+var ErrNotFound = errors.New("product not found")
+
+const schema = `CREATE TABLE IF NOT EXISTS products (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	sku         TEXT NOT NULL UNIQUE,
+	name        TEXT NOT NULL,
+	price_cents INTEGER NOT NULL
+)`
+
+// Catalog runs its queries through statements prepared once, when it is
+// created
+type Catalog struct {
+	db           *sql.DB
+	insert       *sql.Stmt
+	bySKU        *sql.Stmt
+	inPriceRange *sql.Stmt
+}
+
+// NewCatalog creates the products table if needed and prepares the
+// catalog's statements
+func NewCatalog(ctx context.Context, db *sql.DB) (*Catalog, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+
+	c := &Catalog{db: db}
+	// TODO: Prepare the three statements with db.PrepareContext:
+	//   insert:       INSERT INTO products (sku, name, price_cents) VALUES (?, ?, ?)
+	//   bySKU:        SELECT id, sku, name, price_cents FROM products WHERE sku = ?
+	//   inPriceRange: the same columns WHERE price_cents BETWEEN ? AND ?,
+	//                 ordered by price_cents then id
+	// TODO: If one fails, close those already prepared and return the error
+	return c, nil
+}
+
+// Close releases the prepared statements
+func (c *Catalog) Close() error {
+	// TODO: Close every prepared statement and return any errors joined
+	return nil
+}
+
+// Add inserts a product and returns its ID
+func (c *Catalog) Add(ctx context.Context, p Product) (int64, error) {
+	// TODO: Execute the prepared insert and return LastInsertId
+	return 0, nil
+}
+
+// BySKU returns the product with the given SKU, or ErrNotFound
+func (c *Catalog) BySKU(ctx context.Context, sku string) (*Product, error) {
+	// TODO: Query one row with the prepared bySKU statement
+	// TODO: Map sql.ErrNoRows to ErrNotFound
+	return nil, nil
+}
+
+// InPriceRange returns the products priced from min to max cents inclusive,
+// cheapest first
+func (c *Catalog) InPriceRange(ctx context.Context, min, max int64) ([]Product, error) {
+	// TODO: Query with the prepared inPriceRange statement and scan every row
+	return nil, nil
+}
+
+// Import adds all the products in one transaction, reusing the prepared
+// insert. If any insert fails, none of the products are added.
+func (c *Catalog) Import(ctx context.Context, products []Product) (int, error) {
+	// TODO: Begin a transaction, and defer a Rollback for every early return
+	// TODO: Bind the prepared insert to the transaction with tx.StmtContext
+	// TODO: Insert every product, then commit
+	return 0, nil
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := sql.Open(driverName, "catalog.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	catalog, err := NewCatalog(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer catalog.Close()
+
+	n, err := catalog.Import(ctx, []Product{
+		{SKU: "KB-01", Name: "Keyboard", PriceCents: 4999},
+		{SKU: "MS-01", Name: "Mouse", PriceCents: 1999},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("imported %d products\n", n)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// prepares counts the statements the SQLite driver has been asked to
+// prepare through the "counting" driver
+var prepares int64
+
+// countingDriver wraps the SQLite driver and counts Prepare calls. Its
+// connections only implement driver.Conn, so database/sql prepares a
+// statement for every query it runs, and the count shows which queries
+// reused a prepared statement.
+type countingDriver struct{ driver.Driver }
+
+type countingConn struct{ driver.Conn }
+
+func (d countingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return countingConn{conn}, nil
+}
+
+func (c countingConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt64(&prepares, 1)
+	return c.Conn.Prepare(query)
+}
+
+var registerCounting sync.Once
+
+// openCatalog creates a catalog over a fresh database file. The catalog is
+// closed when the test ends.
+func openCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	registerCounting.Do(func() {
+		db, err := sql.Open(driverName, "")
+		if err != nil {
+			panic(err)
+		}
+		sql.Register("counting", countingDriver{db.Driver()})
+	})
+
+	db, err := sql.Open("counting", filepath.Join(t.TempDir(), "catalog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	c, err := NewCatalog(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewCatalog: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+var products = []Product{
+	{SKU: "KB-01", Name: "Keyboard", PriceCents: 4999},
+	{SKU: "MS-01", Name: "Mouse", PriceCents: 1999},
+	{SKU: "MN-27", Name: "27in Monitor", PriceCents: 24900},
+	{SKU: "CB-01", Name: "USB-C Cable", PriceCents: 999},
+}
+
+func TestAddAndBySKU(t *testing.T) {
+	c := openCatalog(t)
+	ctx := context.Background()
+
+	for i, p := range products {
+		id, err := c.Add(ctx, p)
+		if err != nil {
+			t.Fatalf("Add(%s): %v", p.SKU, err)
+		}
+		if id != int64(i+1) {
+			t.Errorf("Add(%s) = %d, want %d", p.SKU, id, i+1)
+		}
+	}
+
+	got, err := c.BySKU(ctx, "MS-01")
+	if err != nil || got == nil {
+		t.Fatalf("BySKU(MS-01) = %v, %v", got, err)
+	}
+	if *got != (Product{ID: 2, SKU: "MS-01", Name: "Mouse", PriceCents: 1999}) {
+		t.Errorf("BySKU(MS-01) = %+v", *got)
+	}
+	if _, err := c.BySKU(ctx, "NOPE"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("BySKU(NOPE) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := c.Add(ctx, products[0]); err == nil {
+		t.Error("adding a duplicate SKU succeeded")
+	}
+}
+
+func TestParametersAreNotSQL(t *testing.T) {
+	c := openCatalog(t)
+	ctx := context.Background()
+	name := "Robert'); DROP TABLE products;--"
+	if _, err := c.Add(ctx, Product{SKU: "X-1", Name: name, PriceCents: 1}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	got, err := c.BySKU(ctx, "X-1")
+	if err != nil || got == nil || got.Name != name {
+		t.Errorf("BySKU(X-1) = %+v, %v; want the name stored as-is", got, err)
+	}
+}
+
+func TestInPriceRange(t *testing.T) {
+	c := openCatalog(t)
+	ctx := context.Background()
+	for _, p := range products {
+		if _, err := c.Add(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := c.InPriceRange(ctx, 999, 4999)
+	if err != nil {
+		t.Fatalf("InPriceRange: %v", err)
+	}
+	var skus []string
+	for _, p := range got {
+		skus = append(skus, p.SKU)
+	}
+	if fmt.Sprint(skus) != "[CB-01 MS-01 KB-01]" {
+		t.Errorf("InPriceRange(999, 4999) = %v, want [CB-01 MS-01 KB-01], inclusive and cheapest first", skus)
+	}
+
+	got, err = c.InPriceRange(ctx, 100000, 200000)
+	if err != nil || len(got) != 0 {
+		t.Errorf("InPriceRange(100000, 200000) = %v, %v; want no products", got, err)
+	}
+}
+
+func TestStatementsArePreparedOnce(t *testing.T) {
+	c := openCatalog(t)
+	ctx := context.Background()
+
+	before := atomic.LoadInt64(&prepares)
+	for i := 0; i < 10; i++ {
+		sku := fmt.Sprintf("SKU-%02d", i)
+		if _, err := c.Add(ctx, Product{SKU: sku, Name: "Item", PriceCents: int64(i * 100)}); err != nil {
+			t.Fatal(err)
+		}
+		if p, err := c.BySKU(ctx, sku); err != nil || p == nil {
+			t.Fatalf("BySKU(%s) = %v, %v", sku, p, err)
+		}
+		if _, err := c.InPriceRange(ctx, 0, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt64(&prepares) - before; n != 0 {
+		t.Errorf("30 queries prepared %d new statements; run them through the statements NewCatalog prepared", n)
+	}
+}
+
+func TestImport(t *testing.T) {
+	c := openCatalog(t)
+	ctx := context.Background()
+
+	many := make([]Product, 100)
+	for i := range many {
+		many[i] = Product{SKU: fmt.Sprintf("BULK-%03d", i), Name: "Bulk item", PriceCents: 500}
+	}
+	before := atomic.LoadInt64(&prepares)
+	n, err := c.Import(ctx, many)
+	if err != nil || n != 100 {
+		t.Fatalf("Import(100 products) = %d, %v", n, err)
+	}
+	if n := atomic.LoadInt64(&prepares) - before; n > 1 {
+		t.Errorf("importing 100 products prepared %d statements; reuse the insert with tx.StmtContext", n)
+	}
+	if p, err := c.BySKU(ctx, "BULK-099"); err != nil || p == nil {
+		t.Errorf("BySKU(BULK-099) after Import = %v, %v", p, err)
+	}
+}
+
+func TestImportIsAllOrNothing(t *testing.T) {
+	c := openCatalog(t)
+	ctx := context.Background()
+
+	batch := []Product{products[0], products[1], products[0]} // KB-01 twice
+	if _, err := c.Import(ctx, batch); err == nil {
+		t.Fatal("Import with a duplicate SKU succeeded")
+	}
+	for _, sku := range []string{"KB-01", "MS-01"} {
+		if _, err := c.BySKU(ctx, sku); !errors.Is(err, ErrNotFound) {
+			t.Errorf("BySKU(%s) after a failed import = %v, want ErrNotFound; roll the transaction back", sku, err)
+		}
+	}
+
+	if n, err := c.Import(ctx, products); err != nil || n != len(products) {
+		t.Errorf("Import after a failed import = %d, %v; was the connection left in a transaction?", n, err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	c := openCatalog(t)
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := c.Add(context.Background(), products[0]); err == nil {
+		t.Error("Add after Close succeeded; Close should close the prepared statements")
+	}
+}
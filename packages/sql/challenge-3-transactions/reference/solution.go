@@ -0,0 +1,189 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+var (
+	// ErrInsufficientFunds is returned when a transfer would overdraw an account
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrAccountNotFound is returned when a transfer names an unknown account
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInvalidAmount is returned for a non-positive amount or a transfer
+	// from an account to itself
+	ErrInvalidAmount = errors.New("invalid transfer")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS accounts (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner         TEXT NOT NULL,
+	balance_cents INTEGER NOT NULL CHECK (balance_cents >= 0)
+);
+CREATE TABLE IF NOT EXISTS transfers (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	from_id      INTEGER NOT NULL REFERENCES accounts (id),
+	to_id        INTEGER NOT NULL REFERENCES accounts (id),
+	amount_cents INTEGER NOT NULL,
+	created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Payment is one transfer out of a payroll account
+type Payment struct {
+	To          int64
+	AmountCents int64
+}
+
+// Bank moves money between accounts
+type Bank struct {
+	db *sql.DB
+}
+
+// NewBank creates the bank's tables if needed
+func NewBank(ctx context.Context, db *sql.DB) (*Bank, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+	return &Bank{db: db}, nil
+}
+
+// OpenAccount creates an account and returns its ID
+func (b *Bank) OpenAccount(ctx context.Context, owner string, balanceCents int64) (int64, error) {
+	result, err := b.db.ExecContext(ctx,
+		"INSERT INTO accounts (owner, balance_cents) VALUES (?, ?)", owner, balanceCents)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Balance returns an account's balance in cents
+func (b *Bank) Balance(ctx context.Context, id int64) (int64, error) {
+	var balance int64
+	err := b.db.QueryRowContext(ctx, "SELECT balance_cents FROM accounts WHERE id = ?", id).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAccountNotFound
+	}
+	return balance, err
+}
+
+// WithTx runs fn in a transaction. The transaction is committed if fn
+// returns nil, and rolled back if it returns an error or panics.
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rolling back: %w", rollbackErr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing: %w", err)
+	}
+	return nil
+}
+
+// Transfer moves amountCents from one account to another and records the
+// transfer. Either all of that happens or none of it does.
+func (b *Bank) Transfer(ctx context.Context, from, to, amountCents int64) error {
+	return WithTx(ctx, b.db, func(tx *sql.Tx) error {
+		return transfer(ctx, tx, from, to, amountCents)
+	})
+}
+
+// Pay makes every payment from one account, or none of them
+func (b *Bank) Pay(ctx context.Context, from int64, payments []Payment) error {
+	return WithTx(ctx, b.db, func(tx *sql.Tx) error {
+		for _, p := range payments {
+			if err := transfer(ctx, tx, from, p.To, p.AmountCents); err != nil {
+				return fmt.Errorf("paying account %d: %w", p.To, err)
+			}
+		}
+		return nil
+	})
+}
+
+// transfer does the work of Transfer inside the caller's transaction
+func transfer(ctx context.Context, tx *sql.Tx, from, to, amountCents int64) error {
+	if amountCents <= 0 || from == to {
+		return ErrInvalidAmount
+	}
+
+	var balance int64
+	err := tx.QueryRowContext(ctx, "SELECT balance_cents FROM accounts WHERE id = ?", from).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("account %d: %w", from, ErrAccountNotFound)
+	}
+	if err != nil {
+		return err
+	}
+	if balance < amountCents {
+		return ErrInsufficientFunds
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE accounts SET balance_cents = balance_cents - ? WHERE id = ?", amountCents, from); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx,
+		"UPDATE accounts SET balance_cents = balance_cents + ? WHERE id = ?", amountCents, to)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		// The debit above is undone when the transaction rolls back
+		return fmt.Errorf("account %d: %w", to, ErrAccountNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO transfers (from_id, to_id, amount_cents) VALUES (?, ?, ?)", from, to, amountCents)
+	return err
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := sql.Open(driverName, "bank.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	bank, err := NewBank(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	alice, _ := bank.OpenAccount(ctx, "alice", 10000)
+	bob, _ := bank.OpenAccount(ctx, "bob", 0)
+
+	if err := bank.Transfer(ctx, alice, bob, 2500); err != nil {
+		log.Fatal(err)
+	}
+	if err := bank.Transfer(ctx, bob, alice, 99999); err != nil {
+		fmt.Println("second transfer:", err)
+	}
+	balance, _ := bank.Balance(ctx, bob)
+	fmt.Printf("bob has %d cents\n", balance)
+}
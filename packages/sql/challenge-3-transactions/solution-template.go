@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+var (
+	// ErrInsufficientFunds is returned when a transfer would overdraw an account
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrAccountNotFound is returned when a transfer names an unknown account
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInvalidAmount is returned for a non-positive amount or a transfer
+	// from an account to itself
+	ErrInvalidAmount = errors.New("invalid transfer")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS accounts (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner         TEXT NOT NULL,
+	balance_cents INTEGER NOT NULL CHECK (balance_cents >= 0)
+);
+CREATE TABLE IF NOT EXISTS transfers (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	from_id      INTEGER NOT NULL REFERENCES accounts (id),
+	to_id        INTEGER NOT NULL REFERENCES accounts (id),
+	amount_cents INTEGER NOT NULL,
+	created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// Payment is one transfer out of a payroll account
+type Payment struct {
+	To          int64
+	AmountCents int64
+}
+
+// Bank moves money between accounts
+type Bank struct {
+	db *sql.DB
+}
+
+// NewBank creates the bank's tables if needed
+func NewBank(ctx context.Context, db *sql.DB) (*Bank, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, fmt.Errorf("creating schema: %w", err)
+	}
+	return &Bank{db: db}, nil
+}
+
+// OpenAccount creates an account and returns its ID
+func (b *Bank) OpenAccount(ctx context.Context, owner string, balanceCents int64) (int64, error) {
+	result, err := b.db.ExecContext(ctx,
+		"INSERT INTO accounts (owner, balance_cents) VALUES (?, ?)", owner, balanceCents)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Balance returns an account's balance in cents
+func (b *Bank) Balance(ctx context.Context, id int64) (int64, error) {
+	var balance int64
+	err := b.db.QueryRowContext(ctx, "SELECT balance_cents FROM accounts WHERE id = ?", id).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAccountNotFound
+	}
+	return balance, err
+}
+
+// WithTx runs fn in a transaction. The transaction is committed if fn
+// returns nil, and rolled back if it returns an error or panics.
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	// TODO: Begin a transaction with db.BeginTx
+	// TODO: If fn panics, roll back and panic again with the same value
+	// TODO: If fn returns an error, roll back and return it
+	// TODO: Otherwise commit, returning any commit error
+	return nil
+}
+
+// Transfer moves amountCents from one account to another and records the
+// transfer. Either all of that happens or none of it does.
+func (b *Bank) Transfer(ctx context.Context, from, to, amountCents int64) error {
+	// TODO: Run transfer inside WithTx
+	return nil
+}
+
+// Pay makes every payment from one account, or none of them
+func (b *Bank) Pay(ctx context.Context, from int64, payments []Payment) error {
+	// TODO: Run transfer for every payment inside a single WithTx
+	return nil
+}
+
+// transfer does the work of Transfer inside the caller's transaction
+func transfer(ctx context.Context, tx *sql.Tx, from, to, amountCents int64) error {
+	// TODO: Return ErrInvalidAmount for a non-positive amount or from == to
+	// TODO: Read the source balance; ErrAccountNotFound if there is no such account
+	// TODO: Return ErrInsufficientFunds if the balance is too low
+	// TODO: Debit the source, credit the destination, and return
+	// ErrAccountNotFound if the credit updated no rows
+	// TODO: Record the transfer in the transfers table
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := sql.Open(driverName, "bank.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	bank, err := NewBank(ctx, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	alice, _ := bank.OpenAccount(ctx, "alice", 10000)
+	bob, _ := bank.OpenAccount(ctx, "bob", 0)
+
+	if err := bank.Transfer(ctx, alice, bob, 2500); err != nil {
+		log.Fatal(err)
+	}
+	if err := bank.Transfer(ctx, bob, alice, 99999); err != nil {
+		fmt.Println("second transfer:", err)
+	}
+	balance, _ := bank.Balance(ctx, bob)
+	fmt.Printf("bob has %d cents\n", balance)
+}
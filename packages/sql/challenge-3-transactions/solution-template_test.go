@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// openBank creates a bank over a fresh database file with two accounts:
+// alice with 100.00 and bob with 20.00
+func openBank(t *testing.T) (db *sql.DB, bank *Bank, alice, bob int64) {
+	t.Helper()
+	db, err := sql.Open(driverName, filepath.Join(t.TempDir(), "bank.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	if bank, err = NewBank(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if alice, err = bank.OpenAccount(ctx, "alice", 10000); err != nil {
+		t.Fatal(err)
+	}
+	if bob, err = bank.OpenAccount(ctx, "bob", 2000); err != nil {
+		t.Fatal(err)
+	}
+	return db, bank, alice, bob
+}
+
+// checkBalances fails the test unless the accounts hold the given balances
+func checkBalances(t *testing.T, bank *Bank, want map[int64]int64) {
+	t.Helper()
+	for id, cents := range want {
+		got, err := bank.Balance(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Balance(%d): %v", id, err)
+		}
+		if got != cents {
+			t.Errorf("account %d has %d cents, want %d", id, got, cents)
+		}
+	}
+}
+
+func countTransfers(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM transfers").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestTransfer(t *testing.T) {
+	db, bank, alice, bob := openBank(t)
+	if err := bank.Transfer(context.Background(), alice, bob, 2550); err != nil {
+		t.Fatalf("Transfer: %v", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 7450, bob: 4550})
+
+	var from, to, amount int64
+	err := db.QueryRow("SELECT from_id, to_id, amount_cents FROM transfers").Scan(&from, &to, &amount)
+	if err != nil {
+		t.Fatalf("reading the recorded transfer: %v", err)
+	}
+	if from != alice || to != bob || amount != 2550 {
+		t.Errorf("recorded transfer = %d -> %d, %d cents", from, to, amount)
+	}
+}
+
+func TestTransferInsufficientFunds(t *testing.T) {
+	db, bank, alice, bob := openBank(t)
+	err := bank.Transfer(context.Background(), bob, alice, 2001)
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Transfer of 2001 from 2000 = %v, want ErrInsufficientFunds", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 10000, bob: 2000})
+	if n := countTransfers(t, db); n != 0 {
+		t.Errorf("%d transfers recorded, want 0", n)
+	}
+
+	if err := bank.Transfer(context.Background(), bob, alice, 2000); err != nil {
+		t.Errorf("Transfer of the whole balance: %v", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 12000, bob: 0})
+}
+
+func TestTransferUnknownAccount(t *testing.T) {
+	db, bank, alice, _ := openBank(t)
+	ctx := context.Background()
+
+	if err := bank.Transfer(ctx, 99, alice, 100); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("Transfer from account 99 = %v, want ErrAccountNotFound", err)
+	}
+
+	// The debit from alice succeeds before the credit finds no account 99;
+	// the rollback must undo it
+	if err := bank.Transfer(ctx, alice, 99, 100); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("Transfer to account 99 = %v, want ErrAccountNotFound", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 10000})
+	if n := countTransfers(t, db); n != 0 {
+		t.Errorf("%d transfers recorded, want 0", n)
+	}
+}
+
+func TestTransferInvalid(t *testing.T) {
+	_, bank, alice, bob := openBank(t)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name     string
+		from, to int64
+		amount   int64
+	}{
+		{"zero amount", alice, bob, 0},
+		{"negative amount", alice, bob, -500},
+		{"same account", alice, alice, 100},
+	} {
+		if err := bank.Transfer(ctx, tc.from, tc.to, tc.amount); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("%s: Transfer = %v, want ErrInvalidAmount", tc.name, err)
+		}
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 10000, bob: 2000})
+}
+
+func TestPay(t *testing.T) {
+	db, bank, alice, bob := openBank(t)
+	ctx := context.Background()
+	carol, err := bank.OpenAccount(ctx, "carol", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bank.Pay(ctx, alice, []Payment{{bob, 3000}, {carol, 4000}}); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 3000, bob: 5000, carol: 4000})
+
+	// The first payment fits the remaining 3000, the second does not
+	err = bank.Pay(ctx, alice, []Payment{{bob, 2000}, {carol, 2000}})
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("Pay beyond the balance = %v, want ErrInsufficientFunds", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 3000, bob: 5000, carol: 4000})
+	if n := countTransfers(t, db); n != 2 {
+		t.Errorf("%d transfers recorded, want the 2 from the first payroll", n)
+	}
+}
+
+func TestWithTx(t *testing.T) {
+	db, bank, alice, _ := openBank(t)
+	ctx := context.Background()
+	debit := func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE accounts SET balance_cents = balance_cents - 1 WHERE id = ?", alice)
+		return err
+	}
+
+	called := false
+	err := WithTx(ctx, db, func(tx *sql.Tx) error {
+		called = true
+		return debit(tx)
+	})
+	if err != nil || !called {
+		t.Fatalf("WithTx = %v, fn called: %v", err, called)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 9999})
+
+	errBoom := errors.New("boom")
+	err = WithTx(ctx, db, func(tx *sql.Tx) error {
+		if err := debit(tx); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Errorf("WithTx = %v, want fn's error", err)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 9999})
+}
+
+func TestWithTxPanic(t *testing.T) {
+	db, bank, alice, _ := openBank(t)
+	ctx := context.Background()
+
+	recovered := func() (p interface{}) {
+		defer func() { p = recover() }()
+		WithTx(ctx, db, func(tx *sql.Tx) error {
+			tx.ExecContext(ctx, "UPDATE accounts SET balance_cents = 0 WHERE id = ?", alice)
+			panic("half way")
+		})
+		return nil
+	}()
+
+	if recovered != "half way" {
+		t.Errorf("recovered %v, want WithTx to re-panic with \"half way\"", recovered)
+	}
+	checkBalances(t, bank, map[int64]int64{alice: 10000})
+	if inUse := db.Stats().InUse; inUse != 0 {
+		t.Errorf("%d connection(s) still in use; roll back before re-panicking", inUse)
+	}
+}
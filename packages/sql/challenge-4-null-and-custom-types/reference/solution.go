@@ -0,0 +1,202 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// ErrNotFound is returned when no contact has the requested ID
+var ErrNotFound = errors.New("contact not found")
+
+const schema = `CREATE TABLE IF NOT EXISTS contacts (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	name      TEXT NOT NULL,
+	email     TEXT NOT NULL,
+	nickname  TEXT,
+	age       INTEGER,
+	last_seen DATETIME,
+	tags      TEXT NOT NULL DEFAULT '[]'
+)`
+
+// Contact is a row of the contacts table. The nullable columns use the
+// sql.Null types, so "no value" stays distinct from "" and 0.
+type Contact struct {
+	ID       int64
+	Name     string
+	Email    Email
+	Nickname sql.NullString
+	Age      sql.Null[int64]
+	LastSeen sql.NullTime
+	Tags     Tags
+}
+
+// DisplayName returns the nickname if there is one, and the name otherwise
+func (c Contact) DisplayName() string {
+	if c.Nickname.Valid && c.Nickname.String != "" {
+		return c.Nickname.String
+	}
+	return c.Name
+}
+
+// Tags is a list of labels, stored in a single column as a JSON array
+type Tags []string
+
+// Value implements driver.Valuer
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (t *Tags) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("cannot scan %T into Tags", src)
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// Email is an email address. It is stored trimmed and lower-cased, and
+// refuses to be stored if it has no @.
+type Email string
+
+// Value implements driver.Valuer
+func (e Email) Value() (driver.Value, error) {
+	normalized := strings.ToLower(strings.TrimSpace(string(e)))
+	if !strings.Contains(normalized, "@") {
+		return nil, fmt.Errorf("invalid email address %q", string(e))
+	}
+	return normalized, nil
+}
+
+// Scan implements sql.Scanner
+func (e *Email) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*e = Email(v)
+	case []byte:
+		*e = Email(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Email", src)
+	}
+	return nil
+}
+
+// CreateSchema creates the contacts table if needed
+func CreateSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, schema)
+	return err
+}
+
+// SaveContact inserts c and sets its ID
+func SaveContact(ctx context.Context, db *sql.DB, c *Contact) error {
+	result, err := db.ExecContext(ctx,
+		`INSERT INTO contacts (name, email, nickname, age, last_seen, tags)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		c.Name, c.Email, c.Nickname, c.Age, c.LastSeen, c.Tags)
+	if err != nil {
+		return fmt.Errorf("saving contact: %w", err)
+	}
+	c.ID, err = result.LastInsertId()
+	return err
+}
+
+// GetContact returns the contact with the given ID, or ErrNotFound
+func GetContact(ctx context.Context, db *sql.DB, id int64) (*Contact, error) {
+	var c Contact
+	err := db.QueryRowContext(ctx,
+		"SELECT id, name, email, nickname, age, last_seen, tags FROM contacts WHERE id = ?", id).
+		Scan(&c.ID, &c.Name, &c.Email, &c.Nickname, &c.Age, &c.LastSeen, &c.Tags)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// NotSeenSince returns the IDs of the contacts last seen before t, or
+// never seen at all, in ID order
+func NotSeenSince(ctx context.Context, db *sql.DB, t time.Time) ([]int64, error) {
+	// NULL < t is NULL, not true: never-seen contacts need their own test
+	rows, err := db.QueryContext(ctx,
+		"SELECT id FROM contacts WHERE last_seen IS NULL OR last_seen < ? ORDER BY id", t)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AverageAge returns the average of the known ages. It is not Valid when
+// no contact has an age.
+func AverageAge(ctx context.Context, db *sql.DB) (sql.NullFloat64, error) {
+	var avg sql.NullFloat64
+	err := db.QueryRowContext(ctx, "SELECT avg(age) FROM contacts").Scan(&avg)
+	return avg, err
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := sql.Open(driverName, "contacts.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := CreateSchema(ctx, db); err != nil {
+		log.Fatal(err)
+	}
+
+	c := &Contact{
+		Name:     "Ada Lovelace",
+		Email:    "Ada@Example.com",
+		Nickname: sql.NullString{String: "Ada", Valid: true},
+		Tags:     Tags{"math", "engines"},
+	}
+	if err := SaveContact(ctx, db, c); err != nil {
+		log.Fatal(err)
+	}
+	saved, err := GetContact(ctx, db, c.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s <%s> %v, age known: %v\n", saved.DisplayName(), saved.Email, saved.Tags, saved.Age.Valid)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// ErrNotFound is returned when no contact has the requested ID
+var ErrNotFound = errors.New("contact not found")
+
+const schema = `CREATE TABLE IF NOT EXISTS contacts (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	name      TEXT NOT NULL,
+	email     TEXT NOT NULL,
+	nickname  TEXT,
+	age       INTEGER,
+	last_seen DATETIME,
+	tags      TEXT NOT NULL DEFAULT '[]'
+)`
+
+// Contact is a row of the contacts table. The nullable columns use the
+// sql.Null types, so "no value" stays distinct from "" and 0.
+type Contact struct {
+	ID       int64
+	Name     string
+	Email    Email
+	Nickname sql.NullString
+	Age      sql.Null[int64]
+	LastSeen sql.NullTime
+	Tags     Tags
+}
+
+// DisplayName returns the nickname if there is one, and the name otherwise
+func (c Contact) DisplayName() string {
+	// TODO: Return the nickname if it is valid and not empty, else the name
+	return ""
+}
+
+// Tags is a list of labels, stored in a single column as a JSON array
+type Tags []string
+
+// Value implements driver.Valuer
+func (t Tags) Value() (driver.Value, error) {
+	// TODO: Encode the tags as a JSON array string; nil tags become "[]"
+	return nil, nil
+}
+
+// Scan implements sql.Scanner
+func (t *Tags) Scan(src interface{}) error {
+	// TODO: Decode a JSON array from a string or []byte
+	// TODO: A NULL (nil) leaves the tags nil; any other type is an error
+	return nil
+}
+
+// Email is an email address. It is stored trimmed and lower-cased, and
+// refuses to be stored if it has no @.
+type Email string
+
+// Value implements driver.Valuer
+func (e Email) Value() (driver.Value, error) {
+	// TODO: Trim and lower-case the address
+	// TODO: Return an error if it has no @, so it is never stored
+	return nil, nil
+}
+
+// Scan implements sql.Scanner
+func (e *Email) Scan(src interface{}) error {
+	// TODO: Accept a string or []byte; any other type is an error
+	return nil
+}
+
+// CreateSchema creates the contacts table if needed
+func CreateSchema(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, schema)
+	return err
+}
+
+// SaveContact inserts c and sets its ID
+func SaveContact(ctx context.Context, db *sql.DB, c *Contact) error {
+	// TODO: Insert every field; the sql.Null types and your Valuers
+	// handle the conversion, so pass them as they are
+	// TODO: Set c.ID from LastInsertId
+	return nil
+}
+
+// GetContact returns the contact with the given ID, or ErrNotFound
+func GetContact(ctx context.Context, db *sql.DB, id int64) (*Contact, error) {
+	// TODO: Scan every column, with the nullable ones into the sql.Null fields
+	// TODO: Map sql.ErrNoRows to ErrNotFound
+	return nil, nil
+}
+
+// NotSeenSince returns the IDs of the contacts last seen before t, or
+// never seen at all, in ID order
+func NotSeenSince(ctx context.Context, db *sql.DB, t time.Time) ([]int64, error) {
+	// TODO: Select the contacts with last_seen before t, and those never seen
+	// (remember that NULL < t is not true), ordered by ID
+	return nil, nil
+}
+
+// AverageAge returns the average of the known ages. It is not Valid when
+// no contact has an age.
+func AverageAge(ctx context.Context, db *sql.DB) (sql.NullFloat64, error) {
+	// TODO: Scan avg(age) into a sql.NullFloat64; it is NULL when no age is known
+	return sql.NullFloat64{}, nil
+}
+
+func main() {
+	ctx := context.Background()
+	db, err := sql.Open(driverName, "contacts.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	if err := CreateSchema(ctx, db); err != nil {
+		log.Fatal(err)
+	}
+
+	c := &Contact{
+		Name:     "Ada Lovelace",
+		Email:    "Ada@Example.com",
+		Nickname: sql.NullString{String: "Ada", Valid: true},
+		Tags:     Tags{"math", "engines"},
+	}
+	if err := SaveContact(ctx, db, c); err != nil {
+		log.Fatal(err)
+	}
+	saved, err := GetContact(ctx, db, c.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s <%s> %v, age known: %v\n", saved.DisplayName(), saved.Email, saved.Tags, saved.Age.Valid)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openContacts(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open(driverName, filepath.Join(t.TempDir(), "contacts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := CreateSchema(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func save(t *testing.T, db *sql.DB, c *Contact) int64 {
+	t.Helper()
+	if err := SaveContact(context.Background(), db, c); err != nil {
+		t.Fatalf("SaveContact(%s): %v", c.Name, err)
+	}
+	if c.ID == 0 {
+		t.Fatalf("SaveContact(%s) did not set the ID", c.Name)
+	}
+	return c.ID
+}
+
+func TestTagsValue(t *testing.T) {
+	for _, tc := range []struct {
+		tags Tags
+		want string
+	}{
+		{Tags{"go", "sql"}, `["go","sql"]`},
+		{Tags{}, `[]`},
+		{nil, `[]`},
+	} {
+		got, err := tc.tags.Value()
+		if err != nil || got != tc.want {
+			t.Errorf("%#v.Value() = %#v, %v; want %q", tc.tags, got, err, tc.want)
+		}
+	}
+}
+
+func TestTagsScan(t *testing.T) {
+	for _, src := range []interface{}{`["a","b"]`, []byte(`["a","b"]`)} {
+		var tags Tags
+		if err := tags.Scan(src); err != nil || fmt.Sprint(tags) != "[a b]" {
+			t.Errorf("Scan(%T) = %v, %v; want [a b]", src, tags, err)
+		}
+	}
+
+	tags := Tags{"stale"}
+	if err := tags.Scan(nil); err != nil || tags != nil {
+		t.Errorf("Scan(nil) = %v, %v; want nil tags", tags, err)
+	}
+	if err := tags.Scan(int64(42)); err == nil {
+		t.Error("Scan(int64) succeeded, want an error")
+	}
+	if err := tags.Scan(`not json`); err == nil {
+		t.Error("Scan of invalid JSON succeeded, want an error")
+	}
+}
+
+func TestEmail(t *testing.T) {
+	got, err := Email("  Ada@Example.COM ").Value()
+	if err != nil || got != "ada@example.com" {
+		t.Errorf("Value() = %#v, %v; want \"ada@example.com\"", got, err)
+	}
+	if _, err := Email("not-an-address").Value(); err == nil {
+		t.Error("Value() of an address without @ succeeded, want an error")
+	}
+
+	var e Email
+	if err := e.Scan([]byte("bob@example.com")); err != nil || e != "bob@example.com" {
+		t.Errorf("Scan([]byte) = %q, %v", e, err)
+	}
+	if err := e.Scan(3.14); err == nil {
+		t.Error("Scan(float64) succeeded, want an error")
+	}
+}
+
+func TestSaveAndGetContact(t *testing.T) {
+	db := openContacts(t)
+	seen := time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC)
+	id := save(t, db, &Contact{
+		Name:     "Grace Hopper",
+		Email:    "Grace@Navy.mil",
+		Nickname: sql.NullString{String: "Amazing Grace", Valid: true},
+		Age:      sql.Null[int64]{V: 85, Valid: true},
+		LastSeen: sql.NullTime{Time: seen, Valid: true},
+		Tags:     Tags{"cobol", "compilers"},
+	})
+
+	c, err := GetContact(context.Background(), db, id)
+	if err != nil || c == nil {
+		t.Fatalf("GetContact(%d) = %v, %v", id, c, err)
+	}
+	if c.Name != "Grace Hopper" || c.Email != "grace@navy.mil" {
+		t.Errorf("Name, Email = %q, %q; want the email normalized", c.Name, c.Email)
+	}
+	if c.Nickname != (sql.NullString{String: "Amazing Grace", Valid: true}) {
+		t.Errorf("Nickname = %+v", c.Nickname)
+	}
+	if c.Age != (sql.Null[int64]{V: 85, Valid: true}) {
+		t.Errorf("Age = %+v", c.Age)
+	}
+	if !c.LastSeen.Valid || !c.LastSeen.Time.Equal(seen) {
+		t.Errorf("LastSeen = %+v, want %v", c.LastSeen, seen)
+	}
+	if fmt.Sprint(c.Tags) != "[cobol compilers]" {
+		t.Errorf("Tags = %v", c.Tags)
+	}
+
+	if _, err := GetContact(context.Background(), db, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetContact(999) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNullsStayNull(t *testing.T) {
+	db := openContacts(t)
+	id := save(t, db, &Contact{Name: "Anonymous", Email: "anon@example.com"})
+
+	// The columns must hold NULL, not "" or 0
+	var nickNull, ageNull, seenNull bool
+	var tags string
+	err := db.QueryRow(`SELECT nickname IS NULL, age IS NULL, last_seen IS NULL, tags
+		FROM contacts WHERE id = ?`, id).Scan(&nickNull, &ageNull, &seenNull, &tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !nickNull || !ageNull || !seenNull {
+		t.Errorf("NULL columns: nickname %v, age %v, last_seen %v; want all true", nickNull, ageNull, seenNull)
+	}
+	if tags != "[]" {
+		t.Errorf("tags column = %q, want []", tags)
+	}
+
+	c, err := GetContact(context.Background(), db, id)
+	if err != nil || c == nil {
+		t.Fatalf("GetContact(%d) = %v, %v", id, c, err)
+	}
+	if c.Nickname.Valid || c.Age.Valid || c.LastSeen.Valid {
+		t.Errorf("nullable fields = %+v, %+v, %+v; want none Valid", c.Nickname, c.Age, c.LastSeen)
+	}
+	if len(c.Tags) != 0 {
+		t.Errorf("Tags = %v, want none", c.Tags)
+	}
+}
+
+func TestSaveRejectsInvalidEmail(t *testing.T) {
+	db := openContacts(t)
+	if err := SaveContact(context.Background(), db, &Contact{Name: "Nobody", Email: "nowhere"}); err == nil {
+		t.Error("SaveContact with an invalid email succeeded")
+	}
+	var n int
+	db.QueryRow("SELECT count(*) FROM contacts").Scan(&n)
+	if n != 0 {
+		t.Errorf("%d contacts stored, want 0", n)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	for _, tc := range []struct {
+		nickname sql.NullString
+		want     string
+	}{
+		{sql.NullString{String: "Bobby", Valid: true}, "Bobby"},
+		{sql.NullString{}, "Robert"},
+		{sql.NullString{String: "", Valid: true}, "Robert"},
+	} {
+		c := Contact{Name: "Robert", Nickname: tc.nickname}
+		if got := c.DisplayName(); got != tc.want {
+			t.Errorf("DisplayName with nickname %+v = %q, want %q", tc.nickname, got, tc.want)
+		}
+	}
+}
+
+func TestNotSeenSince(t *testing.T) {
+	db := openContacts(t)
+	cutoff := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	never := save(t, db, &Contact{Name: "Never", Email: "never@example.com"})
+	old := save(t, db, &Contact{Name: "Old", Email: "old@example.com",
+		LastSeen: sql.NullTime{Time: cutoff.AddDate(0, -2, 0), Valid: true}})
+	save(t, db, &Contact{Name: "Recent", Email: "recent@example.com",
+		LastSeen: sql.NullTime{Time: cutoff.AddDate(0, 0, 3), Valid: true}})
+
+	ids, err := NotSeenSince(context.Background(), db, cutoff)
+	if err != nil {
+		t.Fatalf("NotSeenSince: %v", err)
+	}
+	if fmt.Sprint(ids) != fmt.Sprint([]int64{never, old}) {
+		t.Errorf("NotSeenSince = %v, want [%d %d]: the old contact and the one never seen", ids, never, old)
+	}
+}
+
+func TestAverageAge(t *testing.T) {
+	db := openContacts(t)
+	ctx := context.Background()
+
+	avg, err := AverageAge(ctx, db)
+	if err != nil || avg.Valid {
+		t.Errorf("AverageAge with no contacts = %+v, %v; want not Valid", avg, err)
+	}
+
+	save(t, db, &Contact{Name: "A", Email: "a@example.com"})
+	avg, err = AverageAge(ctx, db)
+	if err != nil || avg.Valid {
+		t.Errorf("AverageAge with no known ages = %+v, %v; want not Valid", avg, err)
+	}
+
+	save(t, db, &Contact{Name: "B", Email: "b@example.com", Age: sql.Null[int64]{V: 30, Valid: true}})
+	save(t, db, &Contact{Name: "C", Email: "c@example.com", Age: sql.Null[int64]{V: 41, Valid: true}})
+	avg, err = AverageAge(ctx, db)
+	if err != nil || avg != (sql.NullFloat64{Float64: 35.5, Valid: true}) {
+		t.Errorf("AverageAge = %+v, %v; want 35.5, ignoring the unknown age", avg, err)
+	}
+}
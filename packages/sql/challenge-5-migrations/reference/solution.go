@@ -0,0 +1,254 @@
+//go:build reference
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// Migration is one numbered change to the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // empty if the migration cannot be reversed
+}
+
+// ErrIrreversible is returned by Down for a migration without a down script
+var ErrIrreversible = errors.New("migration cannot be reversed")
+
+// migrationFile matches names like 001_create_users.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads the migrations in the root of fsys, ordered by
+// version. Files that do not end in .sql are ignored.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, path := range paths {
+		m := migrationFile.FindStringSubmatch(path)
+		if m == nil {
+			return nil, fmt.Errorf("%s: want a name like 001_create_users.up.sql", path)
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%s: invalid version %q", path, m[1])
+		}
+		body, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		} else if migration.Name != m[2] {
+			return nil, fmt.Errorf("%s: version %d is also used by %q", path, version, migration.Name)
+		}
+		if m[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies migrations to a database and records them in the
+// schema_migrations table
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for migrations, which must be ordered by
+// version
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// ensureTable creates the schema_migrations table if needed
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// withTx runs fn in a transaction, committing if it returns nil
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applied returns the versions recorded in schema_migrations, oldest first
+func (m *Migrator) applied(ctx context.Context) ([]int, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.QueryContext(ctx, "SELECT version FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// Version returns the highest applied version, or 0 if none are applied
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	versions, err := m.applied(ctx)
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Pending returns the migrations that have not been applied, in order
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		done[v] = true
+	}
+
+	pending := []Migration{}
+	for _, migration := range m.migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order, each in its own
+// transaction, and returns the versions it applied. It stops at the first
+// failure; the migrations before it stay applied.
+func (m *Migrator) Up(ctx context.Context) ([]int, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedNow := []int{}
+	for _, migration := range pending {
+		err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name)
+			return err
+		})
+		if err != nil {
+			return appliedNow, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		appliedNow = append(appliedNow, migration.Version)
+	}
+	return appliedNow, nil
+}
+
+// Down reverts up to steps of the most recently applied migrations, newest
+// first, and returns the versions it reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int, error) {
+	versions, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	reverted := []int{}
+	for i := len(versions) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration, ok := known[versions[i]]
+		if !ok {
+			return reverted, fmt.Errorf("migration %d is applied but unknown", versions[i])
+		}
+		if migration.Down == "" {
+			return reverted, fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Name, ErrIrreversible)
+		}
+		err := withTx(ctx, m.db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("reverting migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		reverted = append(reverted, migration.Version)
+	}
+	return reverted, nil
+}
+
+func main() {
+	ctx := context.Background()
+	migrations, err := LoadMigrations(os.DirFS("migrations"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.Open(driverName, "app.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator := NewMigrator(db, migrations)
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	version, _ := migrator.Version(ctx)
+	fmt.Printf("applied %v, now at version %d\n", applied, version)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+
+	_ "modernc.org/sqlite"
+)
+
+// driverName is the name modernc.org/sqlite registers with database/sql
+const driverName = "sqlite"
+
+// Migration is one numbered change to the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string // empty if the migration cannot be reversed
+}
+
+// ErrIrreversible is returned by Down for a migration without a down script
+var ErrIrreversible = errors.New("migration cannot be reversed")
+
+// migrationFile matches names like 001_create_users.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads the migrations in the root of fsys, ordered by
+// version. Files that do not end in .sql are ignored.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	// TODO: Find the .sql files in the root of fsys with fs.Glob
+	// TODO: Parse each name with migrationFile; a .sql file that doesn't
+	// match, or has version 0, is an error
+	// TODO: Pair up and down scripts by version; two names for one version
+	// is an error, and so is a migration without an up script
+	// TODO: Return the migrations sorted by version
+	return nil, nil
+}
+
+// Migrator applies migrations to a database and records them in the
+// schema_migrations table
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for migrations, which must be ordered by
+// version
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// ensureTable creates the schema_migrations table if needed
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// withTx runs fn in a transaction, committing if it returns nil
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// applied returns the versions recorded in schema_migrations, oldest first
+func (m *Migrator) applied(ctx context.Context) ([]int, error) {
+	// TODO: Ensure the table exists, then select its versions in order
+	return nil, nil
+}
+
+// Version returns the highest applied version, or 0 if none are applied
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	// TODO: Return the highest applied version, or 0
+	return 0, nil
+}
+
+// Pending returns the migrations that have not been applied, in order
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	// TODO: Return the migrations whose version has not been applied
+	return nil, nil
+}
+
+// Up applies every pending migration in order, each in its own
+// transaction, and returns the versions it applied. It stops at the first
+// failure; the migrations before it stay applied.
+func (m *Migrator) Up(ctx context.Context) ([]int, error) {
+	// TODO: For each pending migration, in one transaction: run its Up
+	// script and record it in schema_migrations
+	// TODO: On failure, return the versions applied so far and an error
+	// naming the migration
+	return nil, nil
+}
+
+// Down reverts up to steps of the most recently applied migrations, newest
+// first, and returns the versions it reverted
+func (m *Migrator) Down(ctx context.Context, steps int) ([]int, error) {
+	// TODO: Walk the applied versions from newest to oldest, at most steps
+	// TODO: Return ErrIrreversible (wrapped) for a migration with no Down
+	// TODO: In one transaction each: run Down and delete its
+	// schema_migrations row
+	return nil, nil
+}
+
+func main() {
+	ctx := context.Background()
+	migrations, err := LoadMigrations(os.DirFS("migrations"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.Open(driverName, "app.db")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator := NewMigrator(db, migrations)
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	version, _ := migrator.Version(ctx)
+	fmt.Printf("applied %v, now at version %d\n", applied, version)
+}
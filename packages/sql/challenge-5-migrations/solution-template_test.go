@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// migrationFS is a migrations directory with three reversible migrations
+func migrationFS() fstest.MapFS {
+	file := func(sql string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(sql)} }
+	return fstest.MapFS{
+		"001_create_users.up.sql":   file("CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL);"),
+		"001_create_users.down.sql": file("DROP TABLE users;"),
+		"002_create_posts.up.sql": file(`CREATE TABLE posts (
+			id      INTEGER PRIMARY KEY,
+			user_id INTEGER NOT NULL REFERENCES users (id),
+			title   TEXT NOT NULL
+		);`),
+		"002_create_posts.down.sql": file("DROP TABLE posts;"),
+		"003_add_user_names.up.sql": file(`ALTER TABLE users ADD COLUMN name TEXT NOT NULL DEFAULT '';
+			CREATE INDEX idx_posts_user ON posts (user_id);`),
+		"003_add_user_names.down.sql": file(`DROP INDEX idx_posts_user;
+			ALTER TABLE users DROP COLUMN name;`),
+		"README.md": file("Migrations run in version order."),
+	}
+}
+
+func loadMigrations(t *testing.T, fsys fstest.MapFS) []Migration {
+	t.Helper()
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	return migrations
+}
+
+func newTestMigrator(t *testing.T, fsys fstest.MapFS) (*sql.DB, *Migrator) {
+	t.Helper()
+	db, err := sql.Open(driverName, filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, NewMigrator(db, loadMigrations(t, fsys))
+}
+
+// schemaHas reports whether the database has a table or index with the given name
+func schemaHas(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = ?", name).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+func checkVersion(t *testing.T, m *Migrator, want int) {
+	t.Helper()
+	got, err := m.Version(context.Background())
+	if err != nil || got != want {
+		t.Errorf("Version() = %d, %v; want %d", got, err, want)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations := loadMigrations(t, migrationFS())
+	if len(migrations) != 3 {
+		t.Fatalf("loaded %d migrations, want 3; files that aren't .sql are ignored", len(migrations))
+	}
+	for i, want := range []string{"create_users", "create_posts", "add_user_names"} {
+		if migrations[i].Version != i+1 || migrations[i].Name != want {
+			t.Errorf("migrations[%d] = %d %q, want %d %q", i, migrations[i].Version, migrations[i].Name, i+1, want)
+		}
+	}
+	if migrations[0].Up != "CREATE TABLE users (id INTEGER PRIMARY KEY, email TEXT NOT NULL);" ||
+		migrations[0].Down != "DROP TABLE users;" {
+		t.Errorf("migrations[0] scripts = %q / %q", migrations[0].Up, migrations[0].Down)
+	}
+
+	fsys := fstest.MapFS{"10_later.up.sql": {Data: []byte("SELECT 1;")}, "9_earlier.up.sql": {Data: []byte("SELECT 1;")}}
+	migrations = loadMigrations(t, fsys)
+	if len(migrations) != 2 || migrations[0].Version != 9 || migrations[1].Version != 10 {
+		t.Errorf("versions 9 and 10 loaded as %+v; sort by number, not by name", migrations)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"bad name":          {"create_users.sql": {Data: []byte("SELECT 1;")}},
+		"version zero":      {"000_nothing.up.sql": {Data: []byte("SELECT 1;")}},
+		"missing up":        {"001_users.down.sql": {Data: []byte("DROP TABLE users;")}},
+		"duplicate version": {"001_users.up.sql": {Data: []byte("SELECT 1;")}, "001_posts.up.sql": {Data: []byte("SELECT 1;")}},
+	} {
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Errorf("%s: LoadMigrations succeeded, want an error", name)
+		}
+	}
+}
+
+func TestUp(t *testing.T) {
+	db, m := newTestMigrator(t, migrationFS())
+	ctx := context.Background()
+	checkVersion(t, m, 0)
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if fmt.Sprint(applied) != "[1 2 3]" {
+		t.Errorf("Up applied %v, want [1 2 3]", applied)
+	}
+	checkVersion(t, m, 3)
+	for _, name := range []string{"users", "posts", "idx_posts_user"} {
+		if !schemaHas(t, db, name) {
+			t.Errorf("%s does not exist after Up", name)
+		}
+	}
+
+	var recorded string
+	db.QueryRow("SELECT name FROM schema_migrations WHERE version = 2").Scan(&recorded)
+	if recorded != "create_posts" {
+		t.Errorf("schema_migrations records %q for version 2, want create_posts", recorded)
+	}
+
+	applied, err = m.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("second Up = %v, %v; want nothing applied", applied, err)
+	}
+}
+
+func TestUpAppliesNewMigrations(t *testing.T) {
+	fsys := migrationFS()
+	db, m := newTestMigrator(t, fsys)
+	ctx := context.Background()
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys["004_create_tags.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE tags (name TEXT PRIMARY KEY);")}
+	m = NewMigrator(db, loadMigrations(t, fsys))
+	pending, err := m.Pending(ctx)
+	if err != nil || len(pending) != 1 || pending[0].Version != 4 {
+		t.Fatalf("Pending = %+v, %v; want only version 4", pending, err)
+	}
+	applied, err := m.Up(ctx)
+	if err != nil || fmt.Sprint(applied) != "[4]" {
+		t.Errorf("Up = %v, %v; want [4]", applied, err)
+	}
+	checkVersion(t, m, 4)
+}
+
+func TestUpStopsAtFailure(t *testing.T) {
+	fsys := migrationFS()
+	// The first statement succeeds and the second fails: the transaction
+	// must take the tags table away again
+	fsys["003_add_user_names.up.sql"] = &fstest.MapFile{Data: []byte(
+		"CREATE TABLE tags (name TEXT PRIMARY KEY);\nINSERT INTO no_such_table VALUES (1);")}
+	db, m := newTestMigrator(t, fsys)
+
+	applied, err := m.Up(context.Background())
+	if err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+	if fmt.Sprint(applied) != "[1 2]" {
+		t.Errorf("Up applied %v before failing, want [1 2]", applied)
+	}
+	checkVersion(t, m, 2)
+	if schemaHas(t, db, "tags") {
+		t.Error("the failed migration's tags table exists; run each migration in a transaction")
+	}
+}
+
+func TestDown(t *testing.T) {
+	db, m := newTestMigrator(t, migrationFS())
+	ctx := context.Background()
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if err != nil || fmt.Sprint(reverted) != "[3]" {
+		t.Fatalf("Down(1) = %v, %v; want [3]", reverted, err)
+	}
+	checkVersion(t, m, 2)
+	if schemaHas(t, db, "idx_posts_user") {
+		t.Error("idx_posts_user still exists after reverting migration 3")
+	}
+
+	reverted, err = m.Down(ctx, 10)
+	if err != nil || fmt.Sprint(reverted) != "[2 1]" {
+		t.Errorf("Down(10) = %v, %v; want [2 1], newest first", reverted, err)
+	}
+	checkVersion(t, m, 0)
+	if schemaHas(t, db, "users") {
+		t.Error("users still exists after reverting every migration")
+	}
+
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 3 {
+		t.Errorf("Up after Down = %v, %v; want all three applied again", applied, err)
+	}
+}
+
+func TestDownIrreversible(t *testing.T) {
+	fsys := migrationFS()
+	delete(fsys, "003_add_user_names.down.sql")
+	db, m := newTestMigrator(t, fsys)
+	ctx := context.Background()
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := m.Down(ctx, 1)
+	if !errors.Is(err, ErrIrreversible) {
+		t.Errorf("Down(1) error = %v, want ErrIrreversible", err)
+	}
+	if len(reverted) != 0 {
+		t.Errorf("Down(1) reverted %v, want nothing", reverted)
+	}
+	checkVersion(t, m, 3)
+	if !schemaHas(t, db, "idx_posts_user") {
+		t.Error("idx_posts_user was dropped by an irreversible migration")
+	}
+}
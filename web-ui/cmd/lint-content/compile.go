@@ -0,0 +1,91 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"web-ui/internal/services"
+)
+
+// blankTestTimeout stops a blank template whose tests block from stalling the
+// run; the tests fail on the timeout, which is all the check needs
+const blankTestTimeout = "60s"
+
+// compilerMessage matches "file.go:12:3: message" lines in go test output
+var compilerMessage = regexp.MustCompile(`(?m)^(?:\./)?([\w.-]+\.go):(\d+)(?::\d+)?: (.*)$`)
+
+// blankBuild runs a challenge's tests against its unmodified solution
+// template. The template must compile with the tests, and the tests must fail.
+type blankBuild struct {
+	template string
+	testFile string
+	// files maps the file names the go command reports to the files in the repo
+	files map[string]string
+	run   func() (output string, passed bool)
+}
+
+func runBlankBuilds(l *linter, builds []blankBuild, jobs int) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	queue := make(chan blankBuild)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for build := range queue {
+				checkBlankBuild(l, build)
+			}
+		}()
+	}
+	for _, build := range builds {
+		queue <- build
+	}
+	close(queue)
+	wg.Wait()
+}
+
+func checkBlankBuild(l *linter, build blankBuild) {
+	output, passed := build.run()
+
+	if passed {
+		l.errorf(build.testFile, 1, "tests pass against the blank solution template, so an empty submission is accepted")
+		return
+	}
+	// A run that got as far as the tests failed on them, which is the point
+	if services.TestsRan(output) {
+		return
+	}
+
+	reported := false
+	for _, m := range compilerMessage.FindAllStringSubmatch(output, -1) {
+		path, ok := build.files[m[1]]
+		if !ok || m[3] == "too many errors" {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		l.errorf(path, line, "does not compile with the blank template: %s", m[3])
+		reported = true
+	}
+	if reported {
+		return
+	}
+
+	// Dependencies that cannot be downloaded say nothing about the content
+	if strings.Contains(output, "Failed to install dependencies") || strings.Contains(output, "Timed out after") {
+		l.warnf(build.template, 1, "could not be checked:\n%s", firstLines(output, 10))
+		return
+	}
+	l.errorf(build.template, 1, "blank template and tests do not build:\n%s", firstLines(output, 10))
+}
+
+func firstLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = append(lines[:n], "...")
+	}
+	return strings.Join(lines, "\n")
+}
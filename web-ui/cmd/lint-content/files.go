@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"web-ui/internal/services"
+)
+
+var (
+	unknownFieldPattern = regexp.MustCompile(`unknown field "([^"]+)"`)
+	difficulties        = map[string]bool{"Beginner": true, "Intermediate": true, "Advanced": true}
+)
+
+// lineOf returns the first line of a file containing needle, or 1
+func lineOf(path, needle string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 1
+	}
+	if i := strings.Index(string(content), needle); i >= 0 {
+		return strings.Count(string(content[:i]), "\n") + 1
+	}
+	return 1
+}
+
+// keyLine returns the line of a JSON key
+func keyLine(path, key string) int {
+	return lineOf(path, `"`+key+`"`)
+}
+
+// exists reports whether a file or directory exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// lintJSON decodes a JSON file into v and reports syntax errors and keys
+// the loader would silently ignore. It returns false if the file could not
+// be read or parsed.
+func lintJSON(l *linter, path string, v interface{}) bool {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		l.errorf(path, 1, "cannot read: %v", err)
+		return false
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		line := 1
+		switch e := err.(type) {
+		case *json.SyntaxError:
+			line = bytes.Count(raw[:e.Offset], []byte("\n")) + 1
+		case *json.UnmarshalTypeError:
+			line = bytes.Count(raw[:e.Offset], []byte("\n")) + 1
+		}
+		l.errorf(path, line, "invalid JSON: %v", err)
+		return false
+	}
+
+	// DisallowUnknownFields stops at the first unknown key, so keep
+	// decoding with each one removed until the file is clean
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return true
+	}
+	for {
+		decoder := json.NewDecoder(bytes.NewReader(mustMarshal(fields)))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(newLike(v))
+		m := unknownFieldPattern.FindStringSubmatch(errString(err))
+		if m == nil {
+			return true
+		}
+		l.warnf(path, keyLine(path, m[1]), "unknown field %q is ignored by the loader", m[1])
+		delete(fields, m[1])
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// newLike returns a pointer to a new zero value of the type v points at
+func newLike(v interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(v).Elem()).Interface()
+}
+
+// lintChallengeMetadata checks the fields every track's challenge
+// metadata.json shares
+func lintChallengeMetadata(l *linter, path, title, difficulty string) {
+	if title == "" {
+		l.errorf(path, 1, "title is missing")
+	}
+	if difficulty != "" && !difficulties[difficulty] {
+		l.errorf(path, keyLine(path, "difficulty"), "difficulty %q is not Beginner, Intermediate or Advanced", difficulty)
+	}
+}
+
+// lintHints checks that hints.md uses "## Hint N: title" headings numbered
+// from 1 without gaps, which is how the site splits it into steps
+func lintHints(l *linter, path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		l.warnf(path, 1, "no hints.md")
+		return
+	}
+
+	headings := services.ParseHintHeadings(string(content))
+	if len(headings) == 0 {
+		l.errorf(path, 1, "no \"## Hint 1: ...\" headings; the hints cannot be revealed one at a time")
+		return
+	}
+	for i, h := range headings {
+		if h.Number != i+1 {
+			l.errorf(path, h.Line, "hint numbered %d, expected %d", h.Number, i+1)
+		}
+		if h.Title == "" {
+			l.warnf(path, h.Line, "hint %d has no title", h.Number)
+		}
+	}
+}
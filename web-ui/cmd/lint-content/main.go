@@ -0,0 +1,140 @@
+// Command lint-content checks the classic, package and release challenge
+// tracks for authoring mistakes that would otherwise only show up in the
+// browser: metadata that does not match its schema, references to challenges
+// or features that do not exist, hints.md files that break the "## Hint N:"
+// convention, solution templates that either do not compile against their
+// tests or already pass them, and a skills.json that points at missing
+// challenges or leaves some locked for good.
+//
+// Run it from the web-ui directory:
+//
+//	go run ./cmd/lint-content                    # everything
+//	go run ./cmd/lint-content -tracks packages   # one track
+//	go run ./cmd/lint-content -compile=false     # skip building templates
+//	go run ./cmd/lint-content -github            # GitHub Actions annotations
+//
+// Findings are printed as "file:line: severity: message" with paths relative
+// to the repository root. The exit status is 1 if there are any errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"web-ui/internal/utils"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// annotation is one finding, pointing at a line of a content file
+type annotation struct {
+	path     string // relative to web-ui, as the loaders see it
+	line     int
+	severity string
+	message  string
+}
+
+// linter collects annotations from every check
+type linter struct {
+	mutex       sync.Mutex
+	annotations []annotation
+}
+
+func (l *linter) add(severity, path string, line int, format string, args ...interface{}) {
+	if line < 1 {
+		line = 1
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.annotations = append(l.annotations, annotation{
+		path:     path,
+		line:     line,
+		severity: severity,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) errorf(path string, line int, format string, args ...interface{}) {
+	l.add(severityError, path, line, format, args...)
+}
+
+func (l *linter) warnf(path string, line int, format string, args ...interface{}) {
+	l.add(severityWarning, path, line, format, args...)
+}
+
+func main() {
+	tracks := flag.String("tracks", "classic,packages,releases,skills", "comma-separated tracks to check")
+	compile := flag.Bool("compile", true, "build each template against its tests and check the blank template fails")
+	jobs := flag.Int("j", 4, "templates to build in parallel")
+	github := flag.Bool("github", false, "print GitHub Actions workflow commands instead of file:line lines")
+	flag.Parse()
+
+	// The loaders log what they skip; the linter reports it itself
+	log.SetOutput(io.Discard)
+
+	l := &linter{}
+	var builds []blankBuild
+	for _, track := range strings.Split(*tracks, ",") {
+		switch strings.TrimSpace(track) {
+		case "classic":
+			builds = append(builds, lintClassic(l)...)
+		case "packages":
+			builds = append(builds, lintPackages(l)...)
+		case "releases":
+			builds = append(builds, lintReleases(l)...)
+		case "skills":
+			lintSkills(l)
+		default:
+			fmt.Fprintf(os.Stderr, "unknown track %q\n", track)
+			os.Exit(2)
+		}
+	}
+
+	if *compile {
+		runBlankBuilds(l, builds, *jobs)
+	}
+
+	os.Exit(report(l.annotations, *github))
+}
+
+// report prints the annotations sorted by file and line and returns the exit code
+func report(annotations []annotation, github bool) int {
+	sort.SliceStable(annotations, func(i, j int) bool {
+		if annotations[i].path != annotations[j].path {
+			return annotations[i].path < annotations[j].path
+		}
+		return annotations[i].line < annotations[j].line
+	})
+
+	errors, warnings := 0, 0
+	for _, a := range annotations {
+		path := utils.RepoPath(a.path)
+		if github {
+			// Workflow commands need newlines escaped
+			message := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(a.message)
+			fmt.Printf("::%s file=%s,line=%d::%s\n", a.severity, path, a.line, message)
+		} else {
+			fmt.Printf("%s:%d: %s: %s\n", path, a.line, a.severity, a.message)
+		}
+		if a.severity == severityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d error(s), %d warning(s)\n", errors, warnings)
+	if errors > 0 {
+		return 1
+	}
+	return 0
+}
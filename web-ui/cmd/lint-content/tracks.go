@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// lintClassic checks challenge-N directories through ChallengeService
+func lintClassic(l *linter) []blankBuild {
+	challengeService := services.NewChallengeService()
+	challengeService.LoadChallenges()
+	executionService := services.NewExecutionService()
+
+	for _, problem := range challengeService.ValidateMetadata() {
+		line := 1
+		if problem.Field != "" {
+			line = keyLine(problem.Path, problem.Field)
+		}
+		l.errorf(problem.Path, line, "%s", problem.Message)
+	}
+
+	dirs, _ := filepath.Glob("../challenge-*")
+	var builds []blankBuild
+	for _, dir := range dirs {
+		m := utils.ChallengeDirPattern.FindStringSubmatch(dir)
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+
+		readme := filepath.Join(dir, "README.md")
+		template := filepath.Join(dir, "solution-template.go")
+		testFile := filepath.Join(dir, "solution-template_test.go")
+
+		challenge, ok := challengeService.GetChallenge(id)
+		if !ok {
+			switch {
+			case !exists(readme):
+				l.errorf(readme, 1, "missing; challenge %d is not loaded", id)
+			case !exists(template):
+				l.errorf(template, 1, "missing; challenge %d is not loaded", id)
+			default:
+				l.errorf(readme, 1, "challenge %d is not loaded", id)
+			}
+			continue
+		}
+
+		if !hasTitleHeading(readme) {
+			l.errorf(readme, 1, "no \"# Challenge %d: Title\" heading; the title falls back to %q", id, fmt.Sprintf("Challenge %d", id))
+		}
+		if !exists(testFile) {
+			l.errorf(testFile, 1, "missing")
+			continue
+		}
+		lintHints(l, filepath.Join(dir, "hints.md"))
+		if challenge.WritesTests() {
+			continue // the template is a test file, with no solution to build
+		}
+
+		builds = append(builds, blankBuild{
+			template: template,
+			testFile: testFile,
+			// ExecutionService renames the test file in its temporary directory
+			files: map[string]string{
+				"solution-template.go": template,
+				"solution_test.go":     testFile,
+			},
+			run: func() (string, bool) {
+				result := executionService.RunCodeWithOptions(challenge.Template, challenge, services.RunOptions{
+					TestArgs: []string{"-timeout", blankTestTimeout},
+				})
+				return result.Output, result.Passed
+			},
+		})
+	}
+	return builds
+}
+
+// hasTitleHeading reports whether a README has a level-one heading
+func hasTitleHeading(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.HasPrefix(strings.TrimSpace(scanner.Text()), "# ") {
+			return true
+		}
+	}
+	return false
+}
+
+// lintPackages checks packages/<name>/ directories through PackageService
+func lintPackages(l *linter) []blankBuild {
+	packageService := services.NewPackageService()
+	packages := packageService.GetPackages()
+
+	dirs, _ := filepath.Glob("../packages/*")
+	var builds []blankBuild
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		name := filepath.Base(dir)
+		packageJSON := filepath.Join(dir, "package.json")
+		if !exists(packageJSON) {
+			l.errorf(dir, 1, "no package.json; the package is not listed")
+			continue
+		}
+
+		var metadata services.PackageMetadata
+		if !lintJSON(l, packageJSON, &metadata) {
+			continue
+		}
+		if packages[name] == nil {
+			l.errorf(packageJSON, 1, "package %s is not loaded", name)
+			continue
+		}
+		if metadata.Name != name {
+			l.errorf(packageJSON, keyLine(packageJSON, "name"), "name %q does not match the directory %q", metadata.Name, name)
+		}
+		if metadata.DisplayName == "" || metadata.Description == "" {
+			l.errorf(packageJSON, 1, "display_name and description are required")
+		}
+		if len(metadata.LearningPath) == 0 {
+			l.errorf(packageJSON, keyLine(packageJSON, "learning_path"), "learning_path is empty")
+		}
+
+		listed := make(map[string]bool)
+		for _, id := range metadata.LearningPath {
+			line := lineOf(packageJSON, `"`+id+`"`)
+			if listed[id] {
+				l.errorf(packageJSON, line, "%s is listed twice in learning_path", id)
+				continue
+			}
+			listed[id] = true
+
+			challengeDir := filepath.Join(dir, id)
+			if !exists(challengeDir) {
+				l.errorf(packageJSON, line, "learning_path entry %s has no directory", id)
+				continue
+			}
+			if build, ok := lintPackageChallenge(l, packageService, name, id, challengeDir); ok {
+				builds = append(builds, build)
+			}
+		}
+
+		challengeDirs, _ := filepath.Glob(filepath.Join(dir, "challenge-*"))
+		for _, challengeDir := range challengeDirs {
+			if id := filepath.Base(challengeDir); !listed[id] {
+				l.warnf(packageJSON, keyLine(packageJSON, "learning_path"), "%s is not in learning_path and is never shown", id)
+			}
+		}
+	}
+	return builds
+}
+
+func lintPackageChallenge(l *linter, packageService *services.PackageService, pkg, id, dir string) (blankBuild, bool) {
+	metadataPath := filepath.Join(dir, "metadata.json")
+	if exists(metadataPath) {
+		var metadata models.ChallengeMetadata
+		if lintJSON(l, metadataPath, &metadata) {
+			lintChallengeMetadata(l, metadataPath, metadata.Title, metadata.Difficulty)
+			lintPackageFiles(l, metadataPath, dir, metadata.Files)
+		}
+	} else {
+		l.warnf(metadataPath, 1, "missing; the title and difficulty are guessed from the directory name")
+	}
+
+	if !exists(filepath.Join(dir, "README.md")) {
+		l.errorf(filepath.Join(dir, "README.md"), 1, "missing")
+	}
+	lintHints(l, filepath.Join(dir, "hints.md"))
+
+	template := filepath.Join(dir, "solution-template.go")
+	testFile := filepath.Join(dir, "solution-template_test.go")
+	for _, path := range []string{template, testFile} {
+		if !exists(path) {
+			l.errorf(path, 1, "missing")
+			return blankBuild{}, false
+		}
+	}
+
+	challenge, err := packageService.GetPackageChallenge(pkg, id)
+	if err != nil {
+		l.errorf(dir, 1, "%v", err)
+		return blankBuild{}, false
+	}
+
+	return blankBuild{
+		template: template,
+		testFile: testFile,
+		files: map[string]string{
+			"solution-template.go":      template,
+			"solution-template_test.go": testFile,
+		},
+		run: func() (string, bool) {
+			files := map[string]string{
+				"go.mod":                    utils.ReadFile(filepath.Join(dir, "go.mod")),
+				"go.sum":                    utils.ReadFile(filepath.Join(dir, "go.sum")),
+				"solution-template.go":      challenge.Template,
+				"solution-template_test.go": challenge.TestFile,
+			}
+			for name, content := range challenge.FileContents {
+				files[name] = content
+			}
+			result := services.NewExecutionService().RunModule(files, services.RunOptions{TestArgs: []string{"-timeout", blankTestTimeout}})
+			return result.Output, result.Passed
+		},
+	}, true
+}
+
+// lintPackageFiles checks the files a package challenge lists in its
+// metadata: read-only files in the challenge directory itself, which the
+// runner and the CI workflows copy next to the solution
+func lintPackageFiles(l *linter, metadataPath, dir string, layout *models.FileLayout) {
+	if layout == nil {
+		return
+	}
+	line := keyLine(metadataPath, "files")
+	if len(layout.Editable) > 0 {
+		l.errorf(metadataPath, line, "package challenges take read-only files only; submissions are a single solution.go")
+	}
+	for _, p := range layout.ReadOnly {
+		if err := services.CheckSubmissionPath(p); err != nil {
+			l.errorf(metadataPath, line, "%v", err)
+			continue
+		}
+		switch {
+		case strings.Contains(p, "/"):
+			l.errorf(metadataPath, line, "%s is in a subdirectory; read-only files of a package challenge sit next to the solution", p)
+		case !exists(filepath.Join(dir, p)):
+			l.errorf(metadataPath, line, "%s does not exist in the challenge directory", p)
+		}
+	}
+}
+
+// lintReleases checks releases/<version>/ directories through ReleaseService
+func lintReleases(l *linter) []blankBuild {
+	releaseService := services.NewReleaseService()
+	releaseService.GetReleases()
+
+	dirs, _ := filepath.Glob("../releases/*")
+	var builds []blankBuild
+	for _, dir := range dirs {
+		releaseJSON := filepath.Join(dir, "release.json")
+		if !exists(releaseJSON) {
+			continue // the track README and other files
+		}
+
+		var release models.Release
+		if !lintJSON(l, releaseJSON, &release) {
+			continue
+		}
+		version := filepath.Base(dir)
+		if releaseService.GetRelease(version) == nil {
+			l.errorf(releaseJSON, keyLine(releaseJSON, "version"), "release %s is not loaded; the directory should be go%s", release.Version, release.Version)
+			continue
+		}
+
+		listed := make(map[string]bool)
+		for _, slug := range release.FeatureSlugs {
+			listed[slug] = true
+			featureDir := filepath.Join(dir, slug)
+			if !exists(featureDir) {
+				continue // listed ahead of time, shown as coming soon
+			}
+			featureJSON := filepath.Join(featureDir, "feature.json")
+			if !exists(featureJSON) {
+				l.errorf(releaseJSON, lineOf(releaseJSON, `"`+slug+`"`), "feature %s has a directory but no feature.json, so it shows as coming soon", slug)
+				continue
+			}
+			builds = append(builds, lintReleaseFeature(l, releaseService, version, slug, featureDir)...)
+		}
+
+		featureDirs, _ := filepath.Glob(filepath.Join(dir, "*", "feature.json"))
+		for _, featureJSON := range featureDirs {
+			if slug := filepath.Base(filepath.Dir(featureJSON)); !listed[slug] {
+				l.warnf(releaseJSON, keyLine(releaseJSON, "features"), "feature %s is not listed and is never shown", slug)
+			}
+		}
+	}
+	return builds
+}
+
+// lintSkills checks skills.json: its challenge references, and that every
+// challenge can be unlocked by passing others first
+func lintSkills(l *linter) {
+	path := "../skills.json"
+	var graph models.SkillGraph
+	if !lintJSON(l, path, &graph) {
+		return
+	}
+	for _, problem := range services.ValidateSkillGraph(&graph, "..") {
+		line := 1
+		if problem.Skill != "" {
+			line = lineOf(path, `"id": "`+problem.Skill+`"`)
+		}
+		l.errorf(path, line, "%s", problem.Message)
+	}
+
+	challengeService := services.NewChallengeService()
+	challengeService.LoadChallenges()
+	packageService := services.NewPackageService()
+	releaseService := services.NewReleaseService()
+	skillService := services.NewSkillService(challengeService, packageService,
+		services.NewPackageProgressService(packageService), releaseService, services.NewScoreboardService())
+	if err := skillService.Load(); err != nil {
+		return
+	}
+	for _, ref := range skillService.UnreachableChallenges() {
+		l.warnf(path, 1, "%s can never be unlocked: its prerequisites wait on each other", ref)
+	}
+}
+
+func lintReleaseFeature(l *linter, releaseService *services.ReleaseService, version, slug, dir string) []blankBuild {
+	featureJSON := filepath.Join(dir, "feature.json")
+	var feature models.ReleaseFeature
+	if !lintJSON(l, featureJSON, &feature) {
+		return nil
+	}
+	if !exists(filepath.Join(dir, "explainer.md")) {
+		l.warnf(featureJSON, 1, "no explainer.md next to it")
+	}
+
+	listed := make(map[string]bool)
+	var builds []blankBuild
+	for _, challengeSlug := range feature.ChallengeSlugs {
+		listed[challengeSlug] = true
+		line := lineOf(featureJSON, `"`+challengeSlug+`"`)
+		challengeDir := filepath.Join(dir, challengeSlug)
+		metadataPath := filepath.Join(challengeDir, "metadata.json")
+
+		switch {
+		case !exists(challengeDir):
+			l.errorf(featureJSON, line, "challenge %s has no directory", challengeSlug)
+			continue
+		case !exists(metadataPath):
+			l.errorf(featureJSON, line, "challenge %s has no metadata.json and is skipped", challengeSlug)
+			continue
+		}
+
+		var metadata models.ReleaseChallenge
+		if !lintJSON(l, metadataPath, &metadata) {
+			continue
+		}
+		lintChallengeMetadata(l, metadataPath, metadata.Title, metadata.Difficulty)
+
+		for _, name := range []string{"README.md", "learning.md", "go.mod"} {
+			if !exists(filepath.Join(challengeDir, name)) {
+				l.errorf(filepath.Join(challengeDir, name), 1, "missing")
+			}
+		}
+		lintHints(l, filepath.Join(challengeDir, "hints.md"))
+
+		template := filepath.Join(challengeDir, "solution-template.go")
+		testFile := filepath.Join(challengeDir, "solution-template_test.go")
+		challenge := releaseService.GetChallenge(version, slug, challengeSlug)
+		if challenge == nil || challenge.Template == "" || challenge.TestFile == "" {
+			l.errorf(challengeDir, 1, "missing solution-template.go or solution-template_test.go")
+			continue
+		}
+		if !releaseService.RunnerEnabled() {
+			continue
+		}
+		builds = append(builds, blankBuild{
+			template: template,
+			testFile: testFile,
+			files: map[string]string{
+				"solution-template.go":      template,
+				"solution-template_test.go": testFile,
+			},
+			run: func() (string, bool) {
+				result := releaseService.RunChallenge(challenge.Template, challenge)
+				return result.Output, result.Passed
+			},
+		})
+	}
+
+	challengeDirs, _ := filepath.Glob(filepath.Join(dir, "*", "metadata.json"))
+	for _, metadataPath := range challengeDirs {
+		if challengeSlug := filepath.Base(filepath.Dir(metadataPath)); !listed[challengeSlug] {
+			l.warnf(featureJSON, keyLine(featureJSON, "challenges"), "challenge %s is not listed and is never shown", challengeSlug)
+		}
+	}
+	return builds
+}
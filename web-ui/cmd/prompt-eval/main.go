@@ -0,0 +1,222 @@
+// Command prompt-eval renders every AI prompt against saved community
+// submissions and sends them to the fake provider, so prompt changes can be
+// checked and compared without an API key or network access.
+//
+// Run it from the web-ui directory:
+//
+//	go run ./cmd/prompt-eval                       # the embedded prompts
+//	go run ./cmd/prompt-eval -prompts ./my-prompts # with overrides, as AI_PROMPTS_DIR would apply them
+//	go run ./cmd/prompt-eval -compare ./my-prompts # embedded prompts against the overrides
+//
+// For each prompt version it reports how many calls were made, how many failed
+// (the template did not render, or the response could not be used) and the
+// prompt size, which is what drives cost.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+// promptStats aggregates the calls made through one prompt version
+type promptStats struct {
+	version  string
+	calls    int
+	failures int
+	chars    int
+	maxChars int
+}
+
+func main() {
+	promptsDir := flag.String("prompts", "", "directory of prompt overrides for the baseline set (default: embedded prompts only)")
+	compareDir := flag.String("compare", "", "directory of prompt overrides to compare against the baseline")
+	challengeList := flag.String("challenges", "", "comma-separated challenge IDs (default: all)")
+	perChallenge := flag.Int("per-challenge", 3, "saved submissions to evaluate per challenge")
+	flag.Parse()
+
+	challengeService := services.NewChallengeService()
+	if err := challengeService.LoadChallenges(); err != nil {
+		log.Fatalf("Failed to load challenges (run from the web-ui directory): %v", err)
+	}
+	challenges := selectChallenges(challengeService.GetChallenges(), *challengeList)
+	if len(challenges) == 0 {
+		log.Fatal("No challenges selected")
+	}
+
+	baseline := evaluate(*promptsDir, challenges, *perChallenge)
+	if *compareDir == "" {
+		printStats(baseline)
+		return
+	}
+
+	candidate := evaluate(*compareDir, challenges, *perChallenge)
+	printComparison(baseline, candidate)
+}
+
+// evaluate runs every prompt against the saved submissions of each challenge
+func evaluate(dir string, challenges []*models.Challenge, perChallenge int) map[string]*promptStats {
+	prompts, err := services.LoadPromptSet(dir)
+	if err != nil {
+		log.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	stats := make(map[string]*promptStats)
+	ai := services.NewAIServiceWith(services.LLMConfig{
+		Provider: services.ProviderFake,
+		Model:    "fake",
+	}, prompts, nil)
+
+	// A failure is either the call itself failing or, below, a response the
+	// service could not use. lastVersion ties the latter back to its prompt.
+	var lastVersion string
+	ai.SetRecorder(func(call services.AICall) {
+		s := stats[call.PromptVersion]
+		if s == nil {
+			s = &promptStats{version: call.PromptVersion}
+			stats[call.PromptVersion] = s
+		}
+		s.calls++
+		s.chars += call.PromptChars
+		if call.PromptChars > s.maxChars {
+			s.maxChars = call.PromptChars
+		}
+		if call.Err != nil {
+			s.failures++
+		}
+		lastVersion = call.PromptVersion
+	})
+	unusable := func() {
+		if s := stats[lastVersion]; s != nil {
+			s.failures++
+		}
+	}
+
+	for _, challenge := range challenges {
+		for _, code := range savedSubmissions(challenge.ID, perChallenge) {
+			review, _ := ai.ReviewCode(code, challenge, "prompt evaluation")
+			if len(review.Issues) > 0 && review.Issues[0].Type == "parsing" {
+				unusable()
+			}
+
+			ai.GetInterviewerQuestions(code, challenge, "prompt evaluation")
+
+			for level := 1; level <= 4; level++ {
+				ai.GetLadderHint(code, challenge, []string{"Authored hint"}, "--- FAIL: TestExample (0.00s)", level)
+			}
+
+			if _, err := ai.SuggestTests(code, challenge); err != nil {
+				unusable()
+			}
+		}
+	}
+
+	return stats
+}
+
+// savedSubmissions returns up to limit community solutions of a challenge,
+// in a stable order
+func savedSubmissions(challengeID, limit int) []string {
+	paths, _ := filepath.Glob(filepath.Join("..", "challenge-"+strconv.Itoa(challengeID), "submissions", "*", "solution-template.go"))
+	sort.Strings(paths)
+
+	var submissions []string
+	for _, path := range paths {
+		if len(submissions) == limit {
+			break
+		}
+		if content, err := os.ReadFile(path); err == nil {
+			submissions = append(submissions, string(content))
+		}
+	}
+	return submissions
+}
+
+func selectChallenges(all models.ChallengeMap, list string) []*models.Challenge {
+	var selected []*models.Challenge
+	if list == "" {
+		for _, challenge := range all {
+			selected = append(selected, challenge)
+		}
+	} else {
+		for _, field := range strings.Split(list, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				log.Fatalf("Invalid challenge ID %q", field)
+			}
+			if challenge, ok := all[id]; ok {
+				selected = append(selected, challenge)
+			}
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ID < selected[j].ID })
+	return selected
+}
+
+func sortedStats(stats map[string]*promptStats) []*promptStats {
+	list := make([]*promptStats, 0, len(stats))
+	for _, s := range stats {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].version < list[j].version })
+	return list
+}
+
+func (s *promptStats) avgChars() int {
+	if s.calls == 0 {
+		return 0
+	}
+	return s.chars / s.calls
+}
+
+func printStats(stats map[string]*promptStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROMPT\tCALLS\tFAILED\tAVG CHARS\tMAX CHARS\t~AVG TOKENS")
+	for _, s := range sortedStats(stats) {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", s.version, s.calls, s.failures, s.avgChars(), s.maxChars, s.avgChars()/4)
+	}
+	w.Flush()
+}
+
+// printComparison lines up the two sets by prompt name
+func printComparison(baseline, candidate map[string]*promptStats) {
+	byName := func(stats map[string]*promptStats) map[string]*promptStats {
+		named := make(map[string]*promptStats)
+		for _, s := range stats {
+			named[strings.SplitN(s.version, "@", 2)[0]] = s
+		}
+		return named
+	}
+	a, b := byName(baseline), byName(candidate)
+
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROMPT\tBASELINE\tCANDIDATE\tFAILED\tAVG CHARS\tCHANGE")
+	for _, name := range names {
+		base, cand := a[name], b[name]
+		if cand == nil {
+			continue
+		}
+		change := "unchanged"
+		if base.version != cand.version {
+			change = fmt.Sprintf("%+d chars", cand.avgChars()-base.avgChars())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d -> %d\t%d -> %d\t%s\n",
+			name, base.version, cand.version, base.failures, cand.failures, base.avgChars(), cand.avgChars(), change)
+	}
+	w.Flush()
+}
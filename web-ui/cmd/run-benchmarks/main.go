@@ -0,0 +1,103 @@
+// Command run-benchmarks scores the submissions to a benchmark challenge
+// against its reference solution, and can save each score as
+// submissions/<username>/benchmark.json for the performance leaderboard.
+// Run it from the web-ui directory:
+//
+//	go run ./cmd/run-benchmarks -challenge 16 -user alice   # one submission
+//	go run ./cmd/run-benchmarks -challenge 16 -write        # every submission
+//
+// Scores are only comparable when measured on the same machine, so -write
+// re-measures every submission rather than adding to old results.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"web-ui/internal/services"
+)
+
+func main() {
+	challengeID := flag.Int("challenge", 0, "benchmark challenge to score")
+	user := flag.String("user", "", "score only this user's submission and print the full report")
+	write := flag.Bool("write", false, "save each score as submissions/<username>/benchmark.json")
+	flag.Parse()
+
+	// The services log as they load and install; keep the output to scores
+	log.SetOutput(io.Discard)
+
+	challengeService := services.NewChallengeService()
+	challengeService.LoadChallenges()
+	challenge, ok := challengeService.GetChallenge(*challengeID)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "challenge %d is not loaded\n", *challengeID)
+		os.Exit(2)
+	}
+	if challenge.Benchmark == nil || challenge.Reference == "" {
+		fmt.Fprintf(os.Stderr, "challenge %d has no benchmark section or no reference solution\n", *challengeID)
+		os.Exit(2)
+	}
+
+	challengeDir := filepath.Join("..", "challenge-"+strconv.Itoa(challenge.ID))
+	usernames := []string{*user}
+	if *user == "" {
+		dirs, _ := filepath.Glob(filepath.Join(challengeDir, "submissions", "*"))
+		usernames = usernames[:0]
+		for _, dir := range dirs {
+			usernames = append(usernames, filepath.Base(dir))
+		}
+	}
+
+	executionService := services.NewExecutionService()
+	failed := false
+	for _, username := range usernames {
+		submissionDir := filepath.Join(challengeDir, "submissions", username)
+		code, err := os.ReadFile(filepath.Join(submissionDir, challenge.SubmissionFile()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", username, err)
+			failed = true
+			continue
+		}
+
+		result := executionService.RunBenchmarks(string(code), challenge)
+		if *user != "" {
+			fmt.Println(result.Output)
+		}
+
+		report := result.Benchmarks
+		if report == nil {
+			fmt.Printf("%s: not scored\n", username)
+			failed = true
+			// A stale score must not outlive a submission that no longer passes
+			if *write {
+				os.Remove(filepath.Join(submissionDir, services.BenchmarkResultFile))
+			}
+			continue
+		}
+		fmt.Printf("%s: time %.2fx [%.2f-%.2f], memory %.2fx, allocs %.2fx\n", username,
+			report.TimeScore.Median, report.TimeScore.Low, report.TimeScore.High,
+			report.MemoryScore.Median, report.AllocsScore.Median)
+
+		if *write {
+			report.Username = username
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err == nil {
+				err = os.WriteFile(filepath.Join(submissionDir, services.BenchmarkResultFile), append(data, '\n'), 0644)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", username, err)
+				failed = true
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
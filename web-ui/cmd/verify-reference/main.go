@@ -0,0 +1,303 @@
+// Command verify-reference checks challenge test suites against the
+// challenge's reference solution (reference/solution.go). For every challenge
+// that has one it confirms that
+//
+//   - the reference passes every test,
+//   - the unmodified solution-template.go fails at least one, and
+//   - small mutations of the reference (a "<" for a "<=", a "+" for a "-")
+//     make some test fail.
+//
+// A mutant that survives is a bug the tests would let through. Run it from
+// the web-ui directory:
+//
+//	go run ./cmd/verify-reference                   # every challenge with a reference
+//	go run ./cmd/verify-reference -only challenge-21
+//	go run ./cmd/verify-reference -min-score 80     # fail below 80% of mutants killed
+//
+// Findings are printed as "file:line: severity: message" with paths relative
+// to the repository root. The exit status is 1 if there are any errors.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"web-ui/internal/mutation"
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// testTimeout keeps a mutant that loops forever from stalling the run; it
+// fails its tests on the timeout, which counts as killed
+const testTimeout = "30s"
+
+// target is one challenge that has a reference solution
+type target struct {
+	name      string // e.g. "challenge-21", "packages/gin/challenge-1-basic-routing"
+	reference string // path of reference/solution.go
+	testFile  string
+	template  string // blank solution-template.go source
+	run       func(code string) services.ExecutionResult
+}
+
+// outcome of running the tests against one mutant
+type outcome int
+
+const (
+	killed outcome = iota
+	survived
+	invalid // did not build
+)
+
+func main() {
+	only := flag.String("only", "", "comma-separated challenges to verify, e.g. challenge-1,packages/gin/challenge-1-basic-routing")
+	maxMutants := flag.Int("max-mutants", 60, "mutants to run per challenge, spread evenly over the file (0 for all)")
+	minScore := flag.Int("min-score", 0, "percentage of mutants that must be killed")
+	jobs := flag.Int("j", 4, "mutants to test in parallel")
+	github := flag.Bool("github", false, "print GitHub Actions workflow commands instead of file:line lines")
+	flag.Parse()
+
+	// ExecutionService and the loaders log as they go; keep the output to findings
+	log.SetOutput(io.Discard)
+
+	targets := findTargets()
+	if *only != "" {
+		wanted := make(map[string]bool)
+		for _, name := range strings.Split(*only, ",") {
+			wanted[strings.TrimSpace(name)] = true
+		}
+		var filtered []target
+		for _, t := range targets {
+			if wanted[t.name] {
+				filtered = append(filtered, t)
+			}
+		}
+		targets = filtered
+	}
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "no challenges with a reference solution found")
+		os.Exit(2)
+	}
+
+	errors := 0
+	for _, t := range targets {
+		errors += verify(t, *maxMutants, *minScore, *jobs, *github)
+	}
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// verify checks one challenge and returns the number of errors reported
+func verify(t target, maxMutants, minScore, jobs int, github bool) int {
+	errors := 0
+	annotate := func(severity, path string, line int, format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		if github {
+			message = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(message)
+			fmt.Printf("::%s file=%s,line=%d::%s\n", severity, utils.RepoPath(path), line, message)
+		} else {
+			fmt.Printf("%s:%d: %s: %s\n", utils.RepoPath(path), line, severity, message)
+		}
+		if severity == "error" {
+			errors++
+		}
+	}
+
+	reference, err := readReference(t.reference)
+	if err != nil {
+		annotate("error", t.reference, 1, "%v", err)
+		return errors
+	}
+
+	if result := t.run(reference); !result.Passed {
+		annotate("error", t.reference, 1, "reference solution fails the tests:\n%s", failures(result.Output))
+		return errors
+	}
+	if result := t.run(t.template); result.Passed {
+		annotate("error", t.testFile, 1, "tests pass against the blank solution template")
+	}
+
+	mutants, err := mutation.Generate(reference)
+	if err != nil {
+		annotate("error", t.reference, 1, "cannot parse: %v", err)
+		return errors
+	}
+	mutants = mutation.Sample(mutants, maxMutants)
+
+	outcomes := make([]outcome, len(mutants))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				result := t.run(mutants[i].Source)
+				switch {
+				case result.Passed:
+					outcomes[i] = survived
+				case services.TestsRan(result.Output):
+					outcomes[i] = killed
+				default:
+					outcomes[i] = invalid
+				}
+			}
+		}()
+	}
+	for i := range mutants {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	counts := make(map[outcome]int)
+	for i, o := range outcomes {
+		counts[o]++
+		if o == survived {
+			annotate("warning", t.reference, mutants[i].Line, "mutant survived, no test fails for %s", mutants[i].Description())
+		}
+	}
+
+	score := 100
+	if scored := counts[killed] + counts[survived]; scored > 0 {
+		score = counts[killed] * 100 / scored
+	}
+	fmt.Fprintf(os.Stderr, "%s: reference passes, %d/%d mutants killed (%d%%), %d did not build\n",
+		t.name, counts[killed], counts[killed]+counts[survived], score, counts[invalid])
+	if score < minScore {
+		annotate("error", t.testFile, 1, "mutation score %d%% is below %d%%", score, minScore)
+	}
+	return errors
+}
+
+// readReference reads a reference solution with its build constraint removed
+func readReference(path string) (string, error) {
+	return services.ReadReference(filepath.Dir(filepath.Dir(path)))
+}
+
+// failures picks the failing test lines out of go test output
+func failures(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "--- FAIL") || strings.Contains(line, ".go:") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	if len(lines) == 0 {
+		lines = strings.Split(strings.TrimSpace(output), "\n")
+	}
+	if len(lines) > 15 {
+		lines = append(lines[:15], "...")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findTargets lists every challenge in the three tracks that has a reference
+// solution, loading each through its track's service
+func findTargets() []target {
+	executionService := services.NewExecutionService()
+	timeout := services.RunOptions{TestArgs: []string{"-timeout", testTimeout}}
+	var targets []target
+
+	challengeService := services.NewChallengeService()
+	challengeService.LoadChallenges()
+	references, _ := filepath.Glob(filepath.Join("..", "challenge-*", services.ReferenceFile))
+	for _, reference := range references {
+		dir := filepath.Dir(filepath.Dir(reference))
+		m := utils.ChallengeDirPattern.FindStringSubmatch(dir)
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+		challenge, ok := challengeService.GetChallenge(id)
+		if !ok || challenge.WritesTests() {
+			continue // in a write-tests challenge the reference is the code under test
+		}
+		targets = append(targets, target{
+			name:      filepath.Base(dir),
+			reference: reference,
+			testFile:  filepath.Join(dir, "solution-template_test.go"),
+			template:  challenge.Template,
+			run: func(code string) services.ExecutionResult {
+				// The reference has to pass the hidden tests as well
+				opts := timeout
+				opts.ExtraFiles = challenge.HiddenTests
+				return executionService.RunCodeWithOptions(code, challenge, opts)
+			},
+		})
+	}
+
+	packageService := services.NewPackageService()
+	references, _ = filepath.Glob(filepath.Join("..", "packages", "*", "*", services.ReferenceFile))
+	// and the challenge sets kept for other versions, in versions/<version>/
+	kept, _ := filepath.Glob(filepath.Join("..", "packages", "*", "versions", "*", "*", services.ReferenceFile))
+	for _, reference := range append(references, kept...) {
+		dir := filepath.Dir(filepath.Dir(reference))
+		rel, _ := filepath.Rel(filepath.Join("..", "packages"), dir)
+		parts := strings.Split(filepath.ToSlash(rel), "/") // gin/challenge-1-basic-routing, or gin/versions/v1.9.1/challenge-1-basic-routing
+		pkg, version, id := parts[0], "", parts[len(parts)-1]
+		if len(parts) == 4 {
+			version = parts[2]
+		}
+		challenge, err := packageService.GetPackageChallengeVersion(pkg, version, id)
+		if err != nil {
+			continue
+		}
+		goMod, goSum := utils.ReadFile(filepath.Join(dir, "go.mod")), utils.ReadFile(filepath.Join(dir, "go.sum"))
+		targets = append(targets, target{
+			name:      filepath.Join("packages", rel),
+			reference: reference,
+			testFile:  filepath.Join(dir, "solution-template_test.go"),
+			template:  challenge.Template,
+			run: func(code string) services.ExecutionResult {
+				files := map[string]string{
+					"go.mod":                    goMod,
+					"go.sum":                    goSum,
+					"solution-template.go":      code,
+					"solution-template_test.go": challenge.TestFile,
+				}
+				for name, content := range challenge.HiddenTests {
+					files[name] = content
+				}
+				for name, content := range challenge.FileContents {
+					files[name] = content
+				}
+				return executionService.RunModule(files, timeout)
+			},
+		})
+	}
+
+	releaseService := services.NewReleaseService()
+	references, _ = filepath.Glob(filepath.Join("..", "releases", "*", "*", "*", services.ReferenceFile))
+	for _, reference := range references {
+		dir := filepath.Dir(filepath.Dir(reference))
+		feature := filepath.Dir(dir)
+		version := filepath.Base(filepath.Dir(feature))
+		challenge := releaseService.GetChallenge(version, filepath.Base(feature), filepath.Base(dir))
+		if challenge == nil {
+			continue
+		}
+		targets = append(targets, target{
+			name:      filepath.Join("releases", version, filepath.Base(feature), challenge.Slug),
+			reference: reference,
+			testFile:  filepath.Join(dir, "solution-template_test.go"),
+			template:  challenge.Template,
+			run: func(code string) services.ExecutionResult {
+				result := releaseService.RunChallenge(code, challenge)
+				return services.ExecutionResult{Passed: result.Passed, Output: result.Output}
+			},
+		})
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].name < targets[j].name })
+	return targets
+}
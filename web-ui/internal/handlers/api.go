@@ -7,11 +7,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
-	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"web-ui/internal/models"
@@ -37,111 +34,10 @@ type GitHubSponsorsResponse struct {
 	} `json:"errors"`
 }
 
-// SponsorCache caches sponsor data to avoid hitting API limits
-type SponsorCache struct {
-	sponsors    map[string]bool
-	lastUpdated time.Time
-	mutex       sync.RWMutex
-}
-
-var sponsorCache = &SponsorCache{
-	sponsors: make(map[string]bool),
-}
-
-// LoadSponsors loads sponsor list by scraping the public GitHub sponsors page
+// LoadSponsors returns the sponsors scraped from the public GitHub sponsors
+// page, as last cached
 func (h *APIHandler) LoadSponsors() map[string]bool {
-	sponsorCache.mutex.RLock()
-	// Return cached data if it's less than 1 hour old
-	if time.Since(sponsorCache.lastUpdated) < time.Hour && len(sponsorCache.sponsors) > 0 {
-		defer sponsorCache.mutex.RUnlock()
-		return sponsorCache.sponsors
-	}
-	sponsorCache.mutex.RUnlock()
-
-	// Scrape sponsors from the public GitHub sponsors page
-	sponsors := h.scrapeSponsorsFromGitHub()
-
-	// Update cache
-	sponsorCache.mutex.Lock()
-	sponsorCache.sponsors = sponsors
-	sponsorCache.lastUpdated = time.Now()
-	sponsorCache.mutex.Unlock()
-	return sponsors
-}
-
-// scrapeSponsorsFromGitHub scrapes the public GitHub sponsors page
-func (h *APIHandler) scrapeSponsorsFromGitHub() map[string]bool {
-	sponsorMap := make(map[string]bool)
-
-	// Create HTTP client with timeout
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	// Fetch the public sponsors page
-	url := "https://github.com/sponsors/RezaSi"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
-		return sponsorMap
-	}
-
-	// Set user agent to avoid being blocked
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; GoSponsorScraper/1.0)")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("Error fetching sponsors page: %v\n", err)
-		return sponsorMap
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		fmt.Printf("GitHub sponsors page returned status %d\n", resp.StatusCode)
-		return sponsorMap
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Printf("Error reading response body: %v\n", err)
-		return sponsorMap
-	}
-
-	html := string(body)
-
-	// Extract usernames from the HTML using regex - look for avatar images with alt="@username"
-	avatarRegex := regexp.MustCompile(`alt="@([a-zA-Z0-9][a-zA-Z0-9\-]*)"`)
-	matches := avatarRegex.FindAllStringSubmatch(html, -1)
-
-	for _, match := range matches {
-		if len(match) >= 2 {
-			username := match[1]
-			// Filter out the repository owner from sponsors list
-			if username != "RezaSi" {
-				sponsorMap[username] = true
-			}
-		}
-	}
-
-	// Fallback: if no sponsors found with avatar method, try href patterns
-	if len(sponsorMap) == 0 {
-		// Look for href="/username" patterns that aren't common GitHub paths
-		linkRegex := regexp.MustCompile(`href="/([a-zA-Z0-9][a-zA-Z0-9\-]+)"`)
-		linkMatches := linkRegex.FindAllStringSubmatch(html, -1)
-
-		for _, match := range linkMatches {
-			if len(match) >= 2 {
-				username := match[1]
-				// Filter out common GitHub paths that aren't usernames
-				if username != "sponsors" && username != "github" && username != "RezaSi" &&
-					!strings.HasPrefix(username, "orgs/") &&
-					!strings.Contains(username, "/") &&
-					len(username) > 2 { // reasonable username length
-					sponsorMap[username] = true
-				}
-			}
-		}
-	}
-
-	return sponsorMap
+	return h.github.Sponsors()
 }
 
 // APIHandler handles all API endpoints
@@ -152,6 +48,9 @@ type APIHandler struct {
 	executionService  *services.ExecutionService
 	packageService    *services.PackageService
 	aiService         *services.AIService
+	hintService       *services.HintService
+	progressService   *services.PackageProgressService
+	github            *services.GitHubData
 	submissions       []models.Submission
 }
 
@@ -163,6 +62,9 @@ func NewAPIHandler(
 	executionService *services.ExecutionService,
 	packageService *services.PackageService,
 	aiService *services.AIService,
+	hintService *services.HintService,
+	progressService *services.PackageProgressService,
+	github *services.GitHubData,
 ) *APIHandler {
 	return &APIHandler{
 		challengeService:  challengeService,
@@ -171,6 +73,9 @@ func NewAPIHandler(
 		executionService:  executionService,
 		packageService:    packageService,
 		aiService:         aiService,
+		hintService:       hintService,
+		progressService:   progressService,
+		github:            github,
 		submissions:       make([]models.Submission, 0),
 	}
 }
@@ -250,11 +155,23 @@ func (h *APIHandler) createSubmission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Run the code
-	result := h.executionService.RunCode(submission.Code, challenge)
+	files, err := services.SubmissionFiles(challenge, submission.Files)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Run the code, with the hidden tests
+	result := h.executionService.SubmitCode(submission.Code, files, challenge)
 	submission.Passed = result.Passed
 	submission.TestOutput = result.Output
 	submission.ExecutionMs = result.ExecutionMs
+	submission.Mutation = result.Mutation
+	submission.Concurrency = result.Concurrency
+	submission.Coverage = result.Coverage
+	submission.Hidden = result.Hidden
+	submission.Fuzz = result.Fuzz
+	submission.HintsUsed = h.hintService.HintsUsed(submission.Username, services.ClassicHintKey(submission.ChallengeID))
 
 	// Store submission
 	h.submissions = append(h.submissions, submission)
@@ -306,8 +223,9 @@ func (h *APIHandler) RunCode(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var request struct {
-		ChallengeID int    `json:"challengeId"`
-		Code        string `json:"code"`
+		ChallengeID int               `json:"challengeId"`
+		Code        string            `json:"code"`
+		Files       map[string]string `json:"files"` // Multi-file challenges only
 	}
 
 	err := json.NewDecoder(r.Body).Decode(&request)
@@ -322,7 +240,13 @@ func (h *APIHandler) RunCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := h.executionService.RunCode(request.Code, challenge)
+	files, err := services.SubmissionFiles(challenge, request.Files)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := h.executionService.RunFiles(request.Code, files, challenge)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -351,11 +275,19 @@ func (h *APIHandler) SaveSubmissionToFilesystem(w http.ResponseWriter, r *http.R
 	h.setUsernameCookie(w, request.Username)
 
 	// Validate challenge exists
-	_, exists := h.challengeService.GetChallenge(request.ChallengeID)
+	challenge, exists := h.challengeService.GetChallenge(request.ChallengeID)
 	if !exists {
 		http.Error(w, "Challenge not found", http.StatusNotFound)
 		return
 	}
+	request.FileName = challenge.SubmissionFile()
+	// Only editable files are saved; read-only ones stay in the challenge
+	for p := range request.Files {
+		if !challenge.Files.IsEditable(p) {
+			http.Error(w, fmt.Sprintf("%s is not an editable file of this challenge", p), http.StatusBadRequest)
+			return
+		}
+	}
 
 	response := h.executionService.SaveSubmissionToFilesystem(request)
 
@@ -585,27 +517,12 @@ func (h *APIHandler) GetPackageLeaderboard(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Load package and its challenges
-	pkg, err := h.packageService.GetPackage(packageName)
+	pkg, challenges, err := h.progressService.LearningPath(packageName)
 	if err != nil {
 		http.Error(w, "Package not found", http.StatusNotFound)
 		return
 	}
-
-	// Build package challenge list in learning path order
-	challengesMap, err := h.packageService.GetPackageChallenges(packageName)
-	if err != nil {
-		challengesMap = make(map[string]*models.PackageChallenge)
-	}
-	var challenges []*models.PackageChallenge
-	for _, id := range pkg.LearningPath {
-		if ch, ok := challengesMap[id]; ok {
-			challenges = append(challenges, ch)
-		}
-	}
-
-	// Reuse existing creator to gather leaderboard
-	leaderboard := h.createPackageLeaderboard(packageName, challenges)
+	leaderboard := h.progressService.Leaderboard(packageName, challenges, h.LoadSponsors())
 
 	response := map[string]interface{}{
 		"success":         true,
@@ -619,86 +536,28 @@ func (h *APIHandler) GetPackageLeaderboard(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(response)
 }
 
-// createPackageLeaderboard builds the package leaderboard based on filesystem submissions
-func (h *APIHandler) createPackageLeaderboard(packageName string, challenges []*models.PackageChallenge) []models.PackageScoreboardEntry {
-	var leaderboard []models.PackageScoreboardEntry
-	type userPackageStats struct {
-		username            string
-		completedCount      int
-		lastSubmission      time.Time
-		challengesCompleted map[string]bool
+// GetPackageProgress returns a user's progress along a package learning path
+func (h *APIHandler) GetPackageProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	userStats := make(map[string]*userPackageStats)
-
-	// Load sponsors for package leaderboard
-	sponsors := h.LoadSponsors()
 
-	for _, challenge := range challenges {
-		submissionsDir := filepath.Join("..", "packages", packageName, challenge.ID, "submissions")
-		if _, err := os.Stat(submissionsDir); os.IsNotExist(err) {
-			continue
-		}
-		entries, err := ioutil.ReadDir(submissionsDir)
-		if err != nil {
-			continue
-		}
-		for _, entry := range entries {
-			if entry.IsDir() {
-				username := entry.Name()
-				userDir := filepath.Join(submissionsDir, username)
-				solutionPath := filepath.Join(userDir, "solution.go")
-				altSolutionPath := filepath.Join(userDir, "solution-template.go")
-
-				var modTime time.Time
-				if stat, err := os.Stat(solutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else if stat, err := os.Stat(altSolutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else {
-					continue
-				}
-
-				if userStats[username] == nil {
-					userStats[username] = &userPackageStats{
-						username:            username,
-						completedCount:      0,
-						lastSubmission:      modTime,
-						challengesCompleted: make(map[string]bool),
-					}
-				}
-				if !userStats[username].challengesCompleted[challenge.ID] {
-					userStats[username].completedCount++
-					userStats[username].challengesCompleted[challenge.ID] = true
-					if modTime.After(userStats[username].lastSubmission) {
-						userStats[username].lastSubmission = modTime
-					}
-				}
-			}
-		}
+	packageName := r.URL.Query().Get("package")
+	username := r.URL.Query().Get("username")
+	if packageName == "" || username == "" {
+		http.Error(w, "package and username parameters required", http.StatusBadRequest)
+		return
 	}
 
-	for username, stats := range userStats {
-		if stats.completedCount > 0 {
-			leaderboard = append(leaderboard, models.PackageScoreboardEntry{
-				Username:    username,
-				PackageName: packageName,
-				ChallengeID: "",
-				SubmittedAt: stats.lastSubmission,
-				TestsPassed: stats.completedCount,
-				TestsTotal:  len(challenges),
-				IsSponsor:   sponsors[username],
-			})
-		}
+	progress, err := h.progressService.GetProgress(username, packageName)
+	if err != nil {
+		http.Error(w, "Package not found", http.StatusNotFound)
+		return
 	}
 
-	sort.Slice(leaderboard, func(i, j int) bool {
-		if leaderboard[i].TestsPassed != leaderboard[j].TestsPassed {
-			return leaderboard[i].TestsPassed > leaderboard[j].TestsPassed
-		}
-		return leaderboard[i].SubmittedAt.Before(leaderboard[j].SubmittedAt)
-	})
-
-	return leaderboard
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
 }
 
 // LeaderboardUser represents a user in the leaderboard
@@ -869,33 +728,66 @@ func (h *APIHandler) HandlePackageChallenge(w http.ResponseWriter, r *http.Reque
 	// Use the existing package service
 	packageService := h.packageService
 
-	challenge, err := packageService.GetPackageChallenge(packageName, challengeId)
+	// ?version= selects one of the package's other challenge sets
+	version := r.URL.Query().Get("version")
+	challenge, err := packageService.GetPackageChallengeVersion(packageName, version, challengeId)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Challenge not found: %v", err), http.StatusNotFound)
 		return
 	}
 
 	// Convert PackageChallenge to Challenge format for ExecutionService
-	challengeForExecution := &models.Challenge{
-		ID:       0, // Package challenges don't use numeric IDs
-		Title:    challenge.Title,
-		TestFile: challenge.TestFile,
+	challengeForExecution := challenge.ForExecution()
+	// Package challenges take no submitted files, only their read-only ones
+	files, err := services.SubmissionFiles(challengeForExecution, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// Run the actual tests using ExecutionService
-	result := h.executionService.RunCode(request.Code, challengeForExecution)
+	// Run the actual tests using ExecutionService; the hidden tests only on submit
+	var result services.ExecutionResult
+	if action == "submit" {
+		result = h.executionService.SubmitCode(request.Code, files, challengeForExecution)
+	} else {
+		result = h.executionService.RunFiles(request.Code, files, challengeForExecution)
+	}
 
 	// Format response
 	response := map[string]interface{}{
 		"success":      result.Passed,
 		"execution_ms": result.ExecutionMs,
 		"output":       result.Output,
+		"coverage":     result.Coverage,
+		"hidden":       result.Hidden,
 	}
 
 	// Count passed tests from output for display
 	testsPassed, testsTotal := h.parseTestResults(result.Output)
 	response["tests_passed"] = testsPassed
 	response["tests_total"] = testsTotal
+	// Tests needing a database server the web UI has none of skip, not fail
+	if skipped := services.SkippedForDatabase(result.Output); skipped > 0 {
+		response["skipped_for_database"] = skipped
+		response["database"] = challenge.Database
+	}
+
+	// Progress follows the default challenge set only: another set's
+	// challenges share its IDs but not its code
+	if action == "submit" && h.isDefaultVersion(packageName, version) {
+		h.progressService.RecordSubmission(models.PackageSubmission{
+			Username:    request.Username,
+			PackageName: packageName,
+			ChallengeID: challengeId,
+			Code:        request.Code,
+			SubmittedAt: time.Now(),
+			Passed:      result.Passed,
+			TestOutput:  result.Output,
+			ExecutionMs: result.ExecutionMs,
+			TestsPassed: testsPassed,
+			TestsTotal:  testsTotal,
+		})
+	}
 
 	if action == "submit" && result.Passed {
 		response["message"] = "Solution submitted successfully!"
@@ -911,6 +803,16 @@ func (h *APIHandler) HandlePackageChallenge(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(response)
 }
 
+// isDefaultVersion reports whether version selects a package's default
+// challenge set, as an empty ?version= does
+func (h *APIHandler) isDefaultVersion(packageName, version string) bool {
+	if version == "" {
+		return true
+	}
+	pkg, err := h.packageService.GetPackage(packageName)
+	return err == nil && version == pkg.DefaultVersion
+}
+
 // parseTestResults parses Go test output to count passed and total tests
 func (h *APIHandler) parseTestResults(output string) (passed int, total int) {
 	lines := strings.Split(output, "\n")
@@ -1113,19 +1015,23 @@ func (h *APIHandler) AIInterviewerQuestions(w http.ResponseWriter, r *http.Reque
 	}
 
 	response := struct {
-		Questions []string `json:"questions"`
-		Success   bool     `json:"success"`
+		Questions     []string `json:"questions"`
+		Success       bool     `json:"success"`
+		PromptVersion string   `json:"promptVersion"`
 	}{
-		Questions: questions,
-		Success:   true,
+		Questions:     questions,
+		Success:       true,
+		PromptVersion: h.aiService.PromptVersion(services.PromptQuestions),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// AICodeHint provides AI-powered code hints
-func (h *APIHandler) AICodeHint(w http.ResponseWriter, r *http.Request) {
+// AISuggestTests asks the AI for extra test cases aimed at the submitted code
+// and runs them against it. The suggested tests run in a temporary directory
+// next to the official ones and are never saved with the challenge.
+func (h *APIHandler) AISuggestTests(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -1134,7 +1040,6 @@ func (h *APIHandler) AICodeHint(w http.ResponseWriter, r *http.Request) {
 	var request struct {
 		ChallengeID int    `json:"challengeId"`
 		Code        string `json:"code"`
-		HintLevel   int    `json:"hintLevel"`
 	}
 
 	err := json.NewDecoder(r.Body).Decode(&request)
@@ -1149,31 +1054,74 @@ func (h *APIHandler) AICodeHint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate hint level
-	if request.HintLevel < 1 || request.HintLevel > 4 {
-		request.HintLevel = 1
+	response := struct {
+		Success       bool                         `json:"success"`
+		Error         string                       `json:"error,omitempty"`
+		TestCode      string                       `json:"testCode"`
+		Cases         []services.SuggestedTestCase `json:"cases"`
+		Failed        int                          `json:"failed"`
+		CompileError  string                       `json:"compileError,omitempty"`
+		PromptVersion string                       `json:"promptVersion"`
+	}{
+		Cases:         []services.SuggestedTestCase{},
+		PromptVersion: h.aiService.PromptVersion(services.PromptSuggestTests),
+	}
+
+	// In a write-tests challenge the submission is the tests
+	if challenge.WritesTests() {
+		response.Error = "This challenge grades the tests you write, so there is no solution to suggest tests for"
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
 	}
 
-	hint, err := h.aiService.GetCodeHint(request.Code, challenge, request.HintLevel)
+	testCode, err := h.aiService.SuggestTests(request.Code, challenge)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("AI hint failed: %v", err), http.StatusInternalServerError)
+		response.Error = err.Error()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
 		return
 	}
 
-	response := struct {
-		Hint      string `json:"hint"`
-		HintLevel int    `json:"hintLevel"`
-		Success   bool   `json:"success"`
-	}{
-		Hint:      hint,
-		HintLevel: request.HintLevel,
-		Success:   true,
+	result := h.executionService.RunCodeWithOptions(request.Code, challenge, services.RunOptions{
+		ExtraFiles: map[string]string{services.SuggestedTestFile: testCode},
+		TestArgs:   []string{"-run", "^" + services.SuggestedTestPrefix},
+	})
+
+	response.Success = true
+	response.TestCode = testCode
+	response.Cases = append(response.Cases, services.ParseSuggestedTestResults(result.Output)...)
+	for _, c := range response.Cases {
+		if !c.Passed {
+			response.Failed++
+		}
+	}
+	// No cases at all on a failed run means the tests never compiled
+	if len(response.Cases) == 0 && !result.Passed {
+		response.CompileError = result.Output
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// AIStatus reports whether AI features are configured and working. It never
+// includes the API key. With ?ping=1 it also checks that the provider accepts
+// the key; pings are cached for a minute.
+func (h *APIHandler) AIStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Query().Get("ping") == "1" {
+		h.aiService.Ping(time.Minute)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.aiService.Status())
+}
+
 // AIDebugResponse provides raw AI response for debugging
 func (h *APIHandler) AIDebugResponse(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -1200,18 +1148,23 @@ func (h *APIHandler) AIDebugResponse(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get raw AI response for debugging
-	prompt := h.aiService.BuildCodeReviewPrompt(request.Code, challenge, request.Context)
-	rawResponse, err := h.aiService.CallLLMRaw(prompt)
+	prompt, promptVersion, err := h.aiService.BuildCodeReviewPrompt(request.Code, challenge, request.Context)
+	rawResponse := ""
+	if err == nil {
+		rawResponse, err = h.aiService.CallLLMRaw(prompt)
+	}
 
 	response := struct {
-		RawResponse string `json:"raw_response"`
-		Prompt      string `json:"prompt"`
-		Success     bool   `json:"success"`
-		Error       string `json:"error,omitempty"`
+		RawResponse   string `json:"raw_response"`
+		Prompt        string `json:"prompt"`
+		PromptVersion string `json:"prompt_version"`
+		Success       bool   `json:"success"`
+		Error         string `json:"error,omitempty"`
 	}{
-		RawResponse: rawResponse,
-		Prompt:      prompt,
-		Success:     err == nil,
+		RawResponse:   rawResponse,
+		Prompt:        prompt,
+		PromptVersion: promptVersion,
+		Success:       err == nil,
 	}
 
 	if err != nil {
@@ -1245,13 +1198,10 @@ func (h *APIHandler) GitHubWebhookHandler(w http.ResponseWriter, r *http.Request
 	// Check if this is a sponsorship event
 	eventType := r.Header.Get("X-GitHub-Event")
 	if eventType == "sponsorship" {
-		// Clear the sponsor cache to force a refresh on next request
-		sponsorCache.mutex.Lock()
-		sponsorCache.sponsors = make(map[string]bool)
-		sponsorCache.lastUpdated = time.Time{} // Reset to zero time to force refresh
-		sponsorCache.mutex.Unlock()
+		// Fetch the sponsors again rather than wait for the cache to expire
+		h.github.RefreshSponsors()
 
-		fmt.Printf("Sponsor cache cleared due to webhook event: %s\n", eventType)
+		fmt.Printf("Sponsor refresh started due to webhook event: %s\n", eventType)
 	}
 
 	// Respond with 200 OK to acknowledge receipt
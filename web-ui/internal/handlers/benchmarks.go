@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/services"
+)
+
+// BenchmarkHandler runs the benchmarks of performance-scored challenges and
+// serves their performance leaderboards
+type BenchmarkHandler struct {
+	challengeService   *services.ChallengeService
+	executionService   *services.ExecutionService
+	performanceService *services.PerformanceService
+	// running holds a token while benchmarks run: runs side by side would
+	// skew each other's timings, and each can take minutes
+	running chan struct{}
+}
+
+// NewBenchmarkHandler creates a new benchmark handler
+func NewBenchmarkHandler(
+	challengeService *services.ChallengeService,
+	executionService *services.ExecutionService,
+	performanceService *services.PerformanceService,
+) *BenchmarkHandler {
+	return &BenchmarkHandler{
+		challengeService:   challengeService,
+		executionService:   executionService,
+		performanceService: performanceService,
+		running:            make(chan struct{}, 1),
+	}
+}
+
+// RunBenchmarks measures submitted code against the challenge's reference
+// solution. It takes a while: the benchmarks run several rounds each. One
+// run goes at a time; a request made during another gets a 503.
+//
+//	POST /api/benchmark {"challengeId": 16, "code": "..."}
+func (h *BenchmarkHandler) RunBenchmarks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		ChallengeID int    `json:"challengeId"`
+		Code        string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	challenge, exists := h.challengeService.GetChallenge(request.ChallengeID)
+	if !exists {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+	if challenge.Benchmark == nil {
+		http.Error(w, "Challenge is not scored on performance", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	select {
+	case h.running <- struct{}{}:
+		defer func() { <-h.running }()
+	default:
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(services.ExecutionResult{
+			Output: "Another benchmark run is in progress. Benchmarks run one at a time so their timings stay comparable; try again in a minute.",
+		})
+		return
+	}
+
+	result := h.executionService.RunBenchmarks(request.Code, challenge)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetLeaderboard returns a challenge's performance leaderboard
+//
+//	GET /api/performance/16
+func (h *BenchmarkHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/performance/"))
+	if err != nil {
+		http.Error(w, "Invalid challenge ID", http.StatusBadRequest)
+		return
+	}
+	challenge, exists := h.challengeService.GetChallenge(id)
+	if !exists {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+
+	entries, err := h.performanceService.Leaderboard(challenge)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+	"web-ui/internal/services"
+)
+
+// HintHandler serves the hint ladder for challenges on all three tracks.
+type HintHandler struct {
+	challengeService *services.ChallengeService
+	packageService   *services.PackageService
+	releaseService   *services.ReleaseService
+	hintService      *services.HintService
+}
+
+// NewHintHandler creates a new hint handler
+func NewHintHandler(
+	challengeService *services.ChallengeService,
+	packageService *services.PackageService,
+	releaseService *services.ReleaseService,
+	hintService *services.HintService,
+) *HintHandler {
+	return &HintHandler{
+		challengeService: challengeService,
+		packageService:   packageService,
+		releaseService:   releaseService,
+		hintService:      hintService,
+	}
+}
+
+type hintRequest struct {
+	Key        string `json:"key"`
+	Username   string `json:"username"`
+	Level      int    `json:"level"`
+	Code       string `json:"code"`
+	TestOutput string `json:"testOutput"`
+}
+
+// HandleHints returns a challenge's ladder on GET and reveals one level on POST.
+//
+//	GET  /api/hints?key=classic/1&username=alice
+//	POST /api/hints {"key": "package/gin/challenge-1-basic-routing", "level": 3, "code": "...", "testOutput": "..."}
+func (h *HintHandler) HandleHints(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.getLadder(w, r)
+	case "POST":
+		h.revealHint(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HintHandler) getLadder(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.target(r.URL.Query().Get("key"))
+	if !ok {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+
+	username := h.username(r, r.URL.Query().Get("username"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.hintService.Ladder(username, target))
+}
+
+func (h *HintHandler) revealHint(w http.ResponseWriter, r *http.Request) {
+	var request hintRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request data", http.StatusBadRequest)
+		return
+	}
+
+	target, ok := h.target(request.Key)
+	if !ok {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+
+	username := h.username(r, request.Username)
+	step, err := h.hintService.Reveal(username, target, request.Level, request.Code, request.TestOutput)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := struct {
+		Step  models.HintStep `json:"step"`
+		Total int             `json:"total"`
+	}{
+		Step:  step,
+		Total: h.hintService.Total(target),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// target resolves a ladder key to a challenge on one of the three tracks:
+//
+//	classic/<id>
+//	package/<package>/<challenge>
+//	release/<version>/<feature>/<challenge>
+func (h *HintHandler) target(key string) (services.HintTarget, bool) {
+	parts := strings.Split(strings.Trim(key, "/"), "/")
+
+	switch {
+	case parts[0] == "classic" && len(parts) == 2:
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return services.HintTarget{}, false
+		}
+		challenge, exists := h.challengeService.GetChallenge(id)
+		if !exists {
+			return services.HintTarget{}, false
+		}
+		return services.NewHintTarget(services.ClassicHintKey(id), challenge), true
+
+	case parts[0] == "package" && len(parts) == 3:
+		challenge, err := h.packageService.GetPackageChallenge(parts[1], parts[2])
+		if err != nil {
+			return services.HintTarget{}, false
+		}
+		// Convert PackageChallenge to Challenge format for the AI prompt
+		return services.NewHintTarget(services.PackageHintKey(parts[1], parts[2]), &models.Challenge{
+			Title: challenge.Title,
+			Hints: challenge.Hints,
+		}), true
+
+	case parts[0] == "release" && len(parts) == 4:
+		challenge := h.releaseService.GetChallenge(parts[1], parts[2], parts[3])
+		if challenge == nil {
+			return services.HintTarget{}, false
+		}
+		return services.HintTarget{
+			Key:       services.ReleaseHintKey(challenge.ReleaseVersion, challenge.FeatureSlug, challenge.Slug),
+			Challenge: &models.Challenge{Title: challenge.Title},
+			Authored:  challenge.Hints,
+		}, true
+	}
+
+	return services.HintTarget{}, false
+}
+
+// username prefers an explicit username and falls back to the username cookie.
+func (h *HintHandler) username(r *http.Request, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if cookie, err := r.Cookie("username"); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"web-ui/internal/services"
+)
+
+// RecommendationHandler serves the next-challenge queue worked out from the
+// skill graph
+type RecommendationHandler struct {
+	skillService *services.SkillService
+}
+
+// NewRecommendationHandler creates a new recommendation handler
+func NewRecommendationHandler(skillService *services.SkillService) *RecommendationHandler {
+	return &RecommendationHandler{skillService: skillService}
+}
+
+// GetRecommendations returns what a user should try next across the classic,
+// package and release tracks, and what is still locked for them. The user
+// comes from the username parameter or cookie.
+//
+//	GET /api/recommendations?username=alice&limit=5
+func (h *RecommendationHandler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		if cookie, err := r.Cookie("username"); err == nil {
+			username = cookie.Value
+		}
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.skillService.Recommend(username, limit))
+}
@@ -110,6 +110,7 @@ func (h *ReleaseHandler) challengePage(w http.ResponseWriter, r *http.Request, v
 		"PrevChallenge": prev,
 		"NextChallenge": next,
 		"RunnerEnabled": h.releaseService.RunnerEnabled(),
+		"HintKey":       services.ReleaseHintKey(rel.Version, feature.Slug, challenge.Slug),
 	})
 }
 
@@ -158,5 +159,8 @@ func (h *ReleaseHandler) RunChallenge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result := h.releaseService.RunChallenge(req.Code, challenge)
+	if cookie, err := r.Cookie("username"); err == nil && result.Passed {
+		h.releaseService.MarkPassed(cookie.Value, challenge)
+	}
 	json.NewEncoder(w).Encode(result)
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"web-ui/internal/services"
+	"web-ui/internal/utils"
+)
+
+// SolutionsHandler serves the "ways people solved this" view of a challenge
+type SolutionsHandler struct {
+	challengeService  *services.ChallengeService
+	scoreboardService *services.ScoreboardService
+	explainerService  *services.SolutionExplainerService
+}
+
+// NewSolutionsHandler creates a new solutions handler
+func NewSolutionsHandler(
+	challengeService *services.ChallengeService,
+	scoreboardService *services.ScoreboardService,
+	explainerService *services.SolutionExplainerService,
+) *SolutionsHandler {
+	return &SolutionsHandler{
+		challengeService:  challengeService,
+		scoreboardService: scoreboardService,
+		explainerService:  explainerService,
+	}
+}
+
+// GetApproaches returns the common approaches to a challenge. It is locked
+// until the user has passed the challenge, so nobody sees other people's
+// solutions before writing their own. The user is the one the server runs
+// as, from git, never a name the request supplies.
+//
+//	GET /api/ai/approaches?challengeId=25
+func (h *SolutionsHandler) GetApproaches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	challengeID, err := strconv.Atoi(r.URL.Query().Get("challengeId"))
+	if err != nil {
+		http.Error(w, "Invalid challenge ID", http.StatusBadRequest)
+		return
+	}
+	challenge, exists := h.challengeService.GetChallenge(challengeID)
+	if !exists {
+		http.Error(w, "Challenge not found", http.StatusNotFound)
+		return
+	}
+
+	username := utils.GetGitUsername().Username
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !h.scoreboardService.HasPassed(username, challengeID) {
+		message := "Pass this challenge to see how others solved it."
+		if username == "" {
+			message = "Set up git with your GitHub username, then pass this challenge to see how others solved it."
+		}
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"locked":  true,
+			"message": message,
+		})
+		return
+	}
+
+	explanation, err := h.explainerService.Explain(challenge)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"locked":  false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(explanation)
+}
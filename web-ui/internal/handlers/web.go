@@ -25,6 +25,8 @@ type WebHandler struct {
 	scoreboardService *services.ScoreboardService
 	userService       *services.UserService
 	packageService    *services.PackageService
+	progressService   *services.PackageProgressService
+	github            *services.GitHubData
 }
 
 // NewWebHandler creates a new web handler
@@ -34,6 +36,8 @@ func NewWebHandler(
 	scoreboardService *services.ScoreboardService,
 	userService *services.UserService,
 	packageService *services.PackageService,
+	progressService *services.PackageProgressService,
+	github *services.GitHubData,
 ) *WebHandler {
 	return &WebHandler{
 		content:           content,
@@ -41,6 +45,8 @@ func NewWebHandler(
 		scoreboardService: scoreboardService,
 		userService:       userService,
 		packageService:    packageService,
+		progressService:   progressService,
+		github:            github,
 	}
 }
 
@@ -170,7 +176,7 @@ func (h *WebHandler) ChallengePage(w http.ResponseWriter, r *http.Request) {
 	hasAttempted := false
 
 	if username != "" {
-		existingSolution = h.userService.GetExistingSolution(username, id)
+		existingSolution = h.userService.GetExistingSolution(username, challenge)
 		// Check if user has attempted this challenge
 		userAttempts := h.userService.GetUserAttempts(username, h.challengeService.GetChallenges())
 		hasAttempted = userAttempts.AttemptedIDs[id]
@@ -188,11 +194,13 @@ func (h *WebHandler) ChallengePage(w http.ResponseWriter, r *http.Request) {
 		Username         string
 		ExistingSolution string
 		HasAttempted     bool
+		HintKey          string
 	}{
 		Challenge:        challenge,
 		Username:         username,
 		ExistingSolution: existingSolution,
 		HasAttempted:     hasAttempted,
+		HintKey:          services.ClassicHintKey(id),
 	}
 
 	err = tmpl.ExecuteTemplate(w, "base", data)
@@ -281,28 +289,12 @@ func (h *WebHandler) PackageScoreboardPage(w http.ResponseWriter, r *http.Reques
 
 	packageName := parts[1]
 
-	// Get package and challenges
-	pkg, err := h.packageService.GetPackage(packageName)
+	pkg, challenges, err := h.progressService.LearningPath(packageName)
 	if err != nil {
 		http.NotFound(w, r)
 		return
 	}
-
-	challengesMap, err := h.packageService.GetPackageChallenges(packageName)
-	if err != nil {
-		challengesMap = make(map[string]*models.PackageChallenge)
-	}
-
-	// Build list of challenges in learning path order
-	var challenges []*models.PackageChallenge
-	for _, id := range pkg.LearningPath {
-		if ch, ok := challengesMap[id]; ok {
-			challenges = append(challenges, ch)
-		}
-	}
-
-	// Create leaderboard
-	leaderboard := h.createPackageLeaderboard(packageName, challenges)
+	leaderboard := h.progressService.Leaderboard(packageName, challenges, h.loadSponsors())
 
 	tmpl, err := template.New("").Funcs(utils.GetTemplateFuncs()).ParseFS(h.content, "templates/base.html", "templates/package_scoreboard.html")
 	if err != nil {
@@ -393,9 +385,10 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	packageName := parts[1]
+	version := r.URL.Query().Get("version") // "" for the default challenge set
 
 	// Get package data
-	pkg, err := h.packageService.GetPackage(packageName)
+	pkg, err := h.packageService.GetPackageVersion(packageName, version)
 	if err != nil {
 		log.Printf("Package not found: %v", err)
 		http.NotFound(w, r)
@@ -403,7 +396,7 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get challenges for this package
-	challengesMap, err := h.packageService.GetPackageChallenges(packageName)
+	challengesMap, err := h.packageService.GetPackageChallengesVersion(packageName, version)
 	if err != nil {
 		log.Printf("Error getting package challenges: %v", err)
 		challengesMap = make(map[string]*models.PackageChallenge)
@@ -436,16 +429,15 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 	// Get the username from cookie if available
 	username := h.getUsernameFromCookie(r)
 
-	// Check which package challenges the user has attempted
+	// Work out the user's progress along the learning path
 	packageAttempts := make(map[string]bool)
-	completedCount := 0
+	progress := &models.PackageProgress{}
 	if username != "" {
-		for _, challenge := range challenges {
-			attempted := h.hasUserAttemptedPackageChallenge(username, packageName, challenge.ID)
-			packageAttempts[challenge.ID] = attempted
-			if attempted {
-				completedCount++
-			}
+		if p, err := h.progressService.GetProgress(username, packageName); err == nil {
+			progress = p
+		}
+		for _, challengeID := range progress.CompletedChallenges {
+			packageAttempts[challengeID] = true
 		}
 	}
 
@@ -453,9 +445,13 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 	userProgress := struct {
 		CompletedCount     int
 		ProgressPercentage float64
+		InProgress         string
+		Achievements       []string
 	}{
-		CompletedCount:     completedCount,
-		ProgressPercentage: float64(completedCount) / float64(len(challenges)) * 100,
+		CompletedCount:     len(progress.CompletedChallenges),
+		ProgressPercentage: float64(len(progress.CompletedChallenges)) / float64(len(challenges)) * 100,
+		InProgress:         progress.InProgress,
+		Achievements:       progress.Achievements,
 	}
 
 	// Create submission counts map for each challenge
@@ -465,7 +461,7 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create actual leaderboard using submission data
-	leaderboard := h.createPackageLeaderboard(packageName, challenges)
+	leaderboard := h.progressService.Leaderboard(packageName, challenges, h.loadSponsors())
 
 	data := struct {
 		Package          *models.Package
@@ -476,6 +472,7 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 		Leaderboard      []models.PackageScoreboardEntry
 		PackageAttempts  map[string]bool
 		SubmissionCounts map[string]int
+		Version          string // Selected challenge set, "" for the default
 	}{
 		Package:          pkg,
 		Challenges:       challenges,
@@ -485,6 +482,7 @@ func (h *WebHandler) PackageDetailPage(w http.ResponseWriter, r *http.Request) {
 		Leaderboard:      leaderboard,
 		PackageAttempts:  packageAttempts,
 		SubmissionCounts: submissionCounts,
+		Version:          selectedVersion(pkg),
 	}
 
 	err = tmpl.ExecuteTemplate(w, "base", data)
@@ -505,9 +503,10 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 
 	packageName := parts[1]
 	challengeID := parts[2]
+	version := r.URL.Query().Get("version") // "" for the default challenge set
 
 	// Get package data
-	pkg, err := h.packageService.GetPackage(packageName)
+	pkg, err := h.packageService.GetPackageVersion(packageName, version)
 	if err != nil {
 		log.Printf("Package not found: %v", err)
 		http.Error(w, "Package not found", http.StatusNotFound)
@@ -515,7 +514,7 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get challenge data
-	challenge, err := h.packageService.GetPackageChallenge(packageName, challengeID)
+	challenge, err := h.packageService.GetPackageChallengeVersion(packageName, version, challengeID)
 	if err != nil {
 		log.Printf("Challenge not found: %v", err)
 		http.Error(w, "Challenge not found", http.StatusNotFound)
@@ -557,6 +556,9 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 		SubmissionCount  int
 		HasAttempted     bool
 		ExistingSolution string
+		HintKey          string
+		DatabaseMissing  bool
+		Version          string // Selected challenge set, "" for the default
 	}{
 		Package:          pkg,
 		Challenge:        challenge,
@@ -564,6 +566,9 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 		SubmissionCount:  0,
 		HasAttempted:     hasAttempted,
 		ExistingSolution: existingSolution,
+		HintKey:          services.PackageHintKey(packageName, challengeID),
+		DatabaseMissing:  !services.DatabaseAvailable(challenge.Database),
+		Version:          selectedVersion(pkg),
 	}
 
 	err = tmpl.ExecuteTemplate(w, "base", data)
@@ -573,6 +578,15 @@ func (h *WebHandler) PackageChallengePage(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// selectedVersion returns the version of a package's selected challenge set
+// for links and API calls to carry in ?version=, or "" for the default set
+func selectedVersion(pkg *models.Package) string {
+	if pkg.Version == pkg.DefaultVersion {
+		return ""
+	}
+	return pkg.Version
+}
+
 // hasUserAttemptedPackageChallenge checks if a user has attempted a package challenge
 func (h *WebHandler) hasUserAttemptedPackageChallenge(username, packageName, challengeID string) bool {
 	// Check if submission file exists in ../packages/{packageName}/{challengeID}/submissions/{username}/solution.go
@@ -647,102 +661,7 @@ func (h *WebHandler) countPackageChallengeSubmissions(packageName, challengeID s
 	return count
 }
 
-// createPackageLeaderboard creates a leaderboard for package challenges similar to classic challenges
-func (h *WebHandler) createPackageLeaderboard(packageName string, challenges []*models.PackageChallenge) []models.PackageScoreboardEntry {
-	var leaderboard []models.PackageScoreboardEntry
-	userStats := make(map[string]*userPackageStats)
-	
-	// Load sponsors for package leaderboard (reuse from API handler)
-	// Create a temporary API handler instance to access LoadSponsors
-	tempHandler := &APIHandler{}
-	sponsors := tempHandler.LoadSponsors()
-
-	// Collect submission data for each challenge
-	for _, challenge := range challenges {
-		submissionsDir := filepath.Join("..", "packages", packageName, challenge.ID, "submissions")
-
-		// Check if submissions directory exists
-		if _, err := os.Stat(submissionsDir); os.IsNotExist(err) {
-			continue
-		}
-
-		// Read the submissions directory
-		entries, err := ioutil.ReadDir(submissionsDir)
-		if err != nil {
-			continue
-		}
-
-		for _, entry := range entries {
-			if entry.IsDir() {
-				username := entry.Name()
-				userDir := filepath.Join(submissionsDir, username)
-				solutionPath := filepath.Join(userDir, "solution.go")
-				altSolutionPath := filepath.Join(userDir, "solution-template.go")
-
-				// Check if user has a solution file (either solution.go or solution-template.go)
-				var modTime time.Time
-				if stat, err := os.Stat(solutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else if stat, err := os.Stat(altSolutionPath); err == nil {
-					modTime = stat.ModTime()
-				} else {
-					continue
-				}
-
-				if userStats[username] == nil {
-					userStats[username] = &userPackageStats{
-						username:            username,
-						completedCount:      0,
-						lastSubmission:      modTime,
-						challengesCompleted: make(map[string]bool),
-					}
-				}
-
-				// Only count if not already counted
-				if !userStats[username].challengesCompleted[challenge.ID] {
-					userStats[username].completedCount++
-					userStats[username].challengesCompleted[challenge.ID] = true
-
-					// Update last submission time if this is more recent
-					if modTime.After(userStats[username].lastSubmission) {
-						userStats[username].lastSubmission = modTime
-					}
-				}
-			}
-		}
-	}
-
-	// Convert to leaderboard entries and sort
-	for username, stats := range userStats {
-		if stats.completedCount > 0 {
-			entry := models.PackageScoreboardEntry{
-				Username:    username,
-				PackageName: packageName,
-				ChallengeID: "", // Not specific to one challenge
-				SubmittedAt: stats.lastSubmission,
-				TestsPassed: stats.completedCount,
-				TestsTotal:  len(challenges),
-				IsSponsor:   sponsors[username],
-			}
-			leaderboard = append(leaderboard, entry)
-		}
-	}
-
-	// Sort by completed count (descending), then by submission time (ascending for earliest)
-	sort.Slice(leaderboard, func(i, j int) bool {
-		if leaderboard[i].TestsPassed != leaderboard[j].TestsPassed {
-			return leaderboard[i].TestsPassed > leaderboard[j].TestsPassed
-		}
-		return leaderboard[i].SubmittedAt.Before(leaderboard[j].SubmittedAt)
-	})
-
-	return leaderboard
-}
-
-// userPackageStats helper struct for collecting user statistics
-type userPackageStats struct {
-	username            string
-	completedCount      int
-	lastSubmission      time.Time
-	challengesCompleted map[string]bool
+// loadSponsors returns the cached sponsor list
+func (h *WebHandler) loadSponsors() map[string]bool {
+	return h.github.Sponsors()
 }
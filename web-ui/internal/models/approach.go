@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// SolutionApproach is one way the community solved a challenge: a cluster of
+// passing submissions with similar structure, described by a representative.
+type SolutionApproach struct {
+	Name      string   `json:"name"`
+	Summary   string   `json:"summary"`
+	Tradeoffs string   `json:"tradeoffs"`
+	Features  []string `json:"features"` // AST features shared by the cluster, e.g. "recursion"
+	Count     int      `json:"count"`    // passing submissions that took this approach
+	Author    string   `json:"author"`   // username of the representative solution
+	Code      string   `json:"code"`
+}
+
+// SolutionExplanation is the "N ways people solved this" view of a challenge.
+type SolutionExplanation struct {
+	ChallengeID   int                `json:"challengeId"`
+	Sampled       int                `json:"sampled"` // passing submissions analysed
+	Approaches    []SolutionApproach `json:"approaches"`
+	AISummary     bool               `json:"aiSummary"` // names and tradeoffs were written by the AI
+	PromptVersion string             `json:"promptVersion,omitempty"`
+	GeneratedAt   time.Time          `json:"generatedAt"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Benchmark defaults used when metadata.json leaves a field out
+const (
+	DefaultBenchmarkCount     = 6
+	DefaultBenchmarkBenchtime = "50ms"
+)
+
+// BenchmarkConfig turns on benchmark scoring for a classic challenge. The
+// challenge's own Benchmark functions are run against the submission and
+// against reference/solution.go, alternating, on the same machine.
+type BenchmarkConfig struct {
+	Pattern   string `json:"pattern"`             // -bench regexp, e.g. "Optimized"
+	Count     int    `json:"count,omitempty"`     // rounds per side; 6 or more gives 95% bounds
+	Benchtime string `json:"benchtime,omitempty"` // -benchtime per benchmark per round
+}
+
+// BenchmarkStat summarises repeated measurements of one metric by their
+// median and a distribution-free confidence interval around it
+type BenchmarkStat struct {
+	Median  float64 `json:"median"`
+	Low     float64 `json:"low"`
+	High    float64 `json:"high"`
+	Samples int     `json:"samples"`
+}
+
+// BenchmarkResult is one benchmark measured for a submission and for the
+// reference. Ratios are submission over reference, so below 1 is better.
+type BenchmarkResult struct {
+	Name string `json:"name"` // e.g. "BenchmarkOptimizedSort/1000", without the -GOMAXPROCS suffix
+
+	NsPerOp     BenchmarkStat `json:"nsPerOp"`
+	BytesPerOp  BenchmarkStat `json:"bytesPerOp"`
+	AllocsPerOp BenchmarkStat `json:"allocsPerOp"`
+
+	ReferenceNsPerOp     BenchmarkStat `json:"referenceNsPerOp"`
+	ReferenceBytesPerOp  BenchmarkStat `json:"referenceBytesPerOp"`
+	ReferenceAllocsPerOp BenchmarkStat `json:"referenceAllocsPerOp"`
+
+	TimeRatio   BenchmarkStat `json:"timeRatio"`
+	MemoryRatio BenchmarkStat `json:"memoryRatio"`
+	AllocsRatio BenchmarkStat `json:"allocsRatio"`
+}
+
+// BenchmarkReport scores a submission's performance against the reference.
+// The scores are geometric means of the per-benchmark ratios, so 1.0 is as
+// fast as the reference and 2.0 is twice as slow.
+type BenchmarkReport struct {
+	Username    string            `json:"username,omitempty"`
+	ChallengeID int               `json:"challengeId"`
+	Benchmarks  []BenchmarkResult `json:"benchmarks"`
+	Confidence  float64           `json:"confidence"` // coverage of every Low..High interval, e.g. 0.97
+
+	TimeScore   BenchmarkStat `json:"timeScore"`
+	MemoryScore BenchmarkStat `json:"memoryScore"`
+	AllocsScore BenchmarkStat `json:"allocsScore"`
+
+	CPU        string    `json:"cpu,omitempty"` // as printed by go test
+	GoVersion  string    `json:"goVersion,omitempty"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// PerformanceEntry is one row of a challenge's performance leaderboard.
+// Entries whose time intervals overlap share a rank, since the measurements
+// cannot tell them apart.
+type PerformanceEntry struct {
+	Rank        int           `json:"rank"`
+	Username    string        `json:"username"`
+	Passed      bool          `json:"passed"` // on the correctness scoreboard
+	TimeScore   BenchmarkStat `json:"timeScore"`
+	MemoryScore BenchmarkStat `json:"memoryScore"`
+	AllocsScore BenchmarkStat `json:"allocsScore"`
+	RecordedAt  time.Time     `json:"recordedAt"`
+}
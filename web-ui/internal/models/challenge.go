@@ -2,8 +2,19 @@ package models
 
 import (
 	"time"
+
+	"web-ui/internal/mutation"
 )
 
+// ChallengeTypeWriteTests marks a challenge whose deliverable is a test file.
+// The user tests the code in Subject and is graded by how many of its mutants
+// the tests catch.
+const ChallengeTypeWriteTests = "write-tests"
+
+// DefaultMinMutationScore is the percentage of mutants a write-tests
+// submission must catch when metadata.json does not set min_mutation_score
+const DefaultMinMutationScore = 80
+
 // Challenge represents a coding challenge
 type Challenge struct {
 	ID                int    `json:"id"`
@@ -14,6 +25,77 @@ type Challenge struct {
 	TestFile          string `json:"testFile"`
 	LearningMaterials string `json:"learningMaterials"`
 	Hints             string `json:"hints"`
+
+	// Optional fields from challenge-N/metadata.json
+	Summary             string   `json:"summary,omitempty"`
+	ShortDescription    string   `json:"shortDescription,omitempty"`
+	EstimatedTime       string   `json:"estimatedTime,omitempty"`
+	LearningObjectives  []string `json:"learningObjectives,omitempty"`
+	Prerequisites       []string `json:"prerequisites,omitempty"`
+	Tags                []string `json:"tags,omitempty"`
+	RealWorldConnection string   `json:"realWorldConnection,omitempty"`
+	Requirements        []string `json:"requirements,omitempty"`
+	BonusPoints         []string `json:"bonusPoints,omitempty"`
+	Icon                string   `json:"icon,omitempty"`
+
+	// Type is empty or ChallengeTypeWriteTests. Write-tests challenges also
+	// carry the code under test, from reference/solution.go, and the
+	// mutation score needed to pass.
+	Type             string `json:"type,omitempty"`
+	Subject          string `json:"subject,omitempty"`
+	MinMutationScore int    `json:"minMutationScore,omitempty"`
+
+	// Benchmark is set for challenges that are also scored on performance.
+	// Reference is the baseline they are measured against; unlike Subject
+	// it is never sent to the browser.
+	Benchmark *BenchmarkConfig `json:"benchmark,omitempty"`
+	Reference string           `json:"-"`
+
+	// ConcurrencyChecks are graded alongside the tests when set
+	ConcurrencyChecks *ConcurrencyChecks `json:"concurrencyChecks,omitempty"`
+
+	// Fuzz is set for challenges that are also graded by fuzzing against the
+	// reference; FuzzTests is the fuzz_test.go holding the targets
+	Fuzz      *FuzzConfig `json:"fuzz,omitempty"`
+	FuzzTests string      `json:"-"`
+
+	// Files lays out a multi-file challenge; FileContents holds the starting
+	// contents of its editable and read-only files, keyed by path
+	Files        *FileLayout       `json:"files,omitempty"`
+	FileContents map[string]string `json:"fileContents,omitempty"`
+
+	// HiddenTests are run on submit only and never sent to the browser,
+	// keyed by the file name they are written under; see ReadHiddenTests
+	HiddenTests map[string]string `json:"-"`
+
+	// Module holds the go.mod and go.sum of a challenge that ships its own
+	// module, as package challenges do, keyed by file name. Its tests run in
+	// that module with the versions it pins; see ReadModule.
+	Module map[string]string `json:"-"`
+}
+
+// WritesTests reports whether the user submits tests rather than a solution
+func (c *Challenge) WritesTests() bool {
+	return c.Type == ChallengeTypeWriteTests
+}
+
+// SubmissionFile is the name a submission is saved under in
+// challenge-N/submissions/<username>/
+func (c *Challenge) SubmissionFile() string {
+	if c.WritesTests() {
+		return "solution-template_test.go"
+	}
+	return "solution-template.go"
+}
+
+// MutationReport grades a submitted test suite by the mutants of the code
+// under test that it catches
+type MutationReport struct {
+	Killed    int               `json:"killed"`
+	Total     int               `json:"total"` // mutants that built; the score is Killed/Total
+	Score     int               `json:"score"` // percentage
+	MinScore  int               `json:"minScore"`
+	Survivors []mutation.Mutant `json:"survivors"`
 }
 
 // Submission represents a user's submitted solution
@@ -25,13 +107,25 @@ type Submission struct {
 	Passed      bool      `json:"passed"`
 	TestOutput  string    `json:"testOutput"`
 	ExecutionMs int64     `json:"executionMs"`
+	HintsUsed   int       `json:"hintsUsed"` // Hint ladder levels revealed before submitting
+
+	// Files are the other editable files of a multi-file challenge, by path
+	Files map[string]string `json:"files,omitempty"`
+
+	Mutation    *MutationReport    `json:"mutation,omitempty"`    // Write-tests challenges only
+	Concurrency *ConcurrencyReport `json:"concurrency,omitempty"` // Challenges with concurrency checks only
+	Coverage    *CoverageReport    `json:"coverage,omitempty"`
+	Hidden      *HiddenTestSummary `json:"hidden,omitempty"` // Challenges with hidden tests only
+	Fuzz        *FuzzReport        `json:"fuzz,omitempty"`   // Challenges with fuzz targets only
 }
 
 // ScoreboardEntry represents an entry in the scoreboard
 type ScoreboardEntry struct {
-	Username    string    `json:"username"`
-	ChallengeID int       `json:"challengeId"`
-	SubmittedAt time.Time `json:"submittedAt"`
+	Username     string    `json:"username"`
+	ChallengeID  int       `json:"challengeId"`
+	SubmittedAt  time.Time `json:"submittedAt"`
+	WithoutHints bool      `json:"withoutHints"` // Solved without revealing any hints
+	RaceClean    bool      `json:"raceClean"`    // Passed the challenge's concurrency checks too
 }
 
 // UserAttemptedChallenges tracks attempted challenges by username
@@ -0,0 +1,78 @@
+package models
+
+// ConcurrencyChecks turns on extra grading runs for a concurrency challenge.
+// They run once the tests pass, and each is reported on its own.
+type ConcurrencyChecks struct {
+	Race  bool `json:"race"`  // run the tests under the race detector
+	Leaks bool `json:"leaks"` // fail if goroutines are still running after the tests
+}
+
+// Outcomes of a concurrency check
+const (
+	CheckClean   = "clean"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped" // could not be run here; Message says why
+)
+
+// StackFrame is one call in a goroutine stack. File is relative to the
+// submission for its own files.
+type StackFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// GoroutineStack is a goroutine as the runtime or the race detector prints it
+type GoroutineStack struct {
+	ID     int          `json:"id"`
+	State  string       `json:"state"` // e.g. "chan receive", "running"
+	Frames []StackFrame `json:"frames"`
+}
+
+// RaceAccess is one of the conflicting memory accesses in a data race
+type RaceAccess struct {
+	Description string       `json:"description"` // e.g. "Write at 0x00c000012345 by goroutine 8"
+	Goroutine   int          `json:"goroutine"`   // 0 for the main goroutine
+	Frames      []StackFrame `json:"frames"`
+}
+
+// DataRace is one report from the race detector
+type DataRace struct {
+	Accesses   []RaceAccess     `json:"accesses"`   // current access first
+	Goroutines []GoroutineStack `json:"goroutines"` // where the goroutines involved were started
+}
+
+// RaceCheck is the race detector's verdict on a submission
+type RaceCheck struct {
+	Status  string     `json:"status"`
+	Message string     `json:"message,omitempty"`
+	Races   []DataRace `json:"races"`
+}
+
+// LeakCheck lists the goroutines still running once the tests finished
+type LeakCheck struct {
+	Status  string           `json:"status"`
+	Message string           `json:"message,omitempty"`
+	Leaked  []GoroutineStack `json:"leaked"`
+}
+
+// ConcurrencyReport holds the concurrency checks a challenge enables. A nil
+// field was not enabled.
+type ConcurrencyReport struct {
+	Race  *RaceCheck `json:"race,omitempty"`
+	Leaks *LeakCheck `json:"leaks,omitempty"`
+}
+
+// Clean reports whether every enabled check ran and found nothing
+func (r *ConcurrencyReport) Clean() bool {
+	if r == nil {
+		return false
+	}
+	if r.Race != nil && r.Race.Status != CheckClean {
+		return false
+	}
+	if r.Leaks != nil && r.Leaks.Status != CheckClean {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+package models
+
+// CoverageReport says which lines of the submitted solution the tests ran.
+// A line can appear in both lists when it holds several statements, for
+// example an if with its body on the same line, and only some of them ran.
+type CoverageReport struct {
+	File           string  `json:"file"` // the submitted file, e.g. "solution-template.go"
+	Statements     int     `json:"statements"`
+	Covered        int     `json:"covered"` // statements run at least once
+	Percent        float64 `json:"percent"`
+	CoveredLines   []int   `json:"coveredLines"`
+	UncoveredLines []int   `json:"uncoveredLines"`
+}
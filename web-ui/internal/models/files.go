@@ -0,0 +1,23 @@
+package models
+
+// FileLayout declares the files of a multi-file challenge besides
+// solution-template.go, which is always the editable main file. Paths are
+// slash-separated and relative to the challenge directory; a file in a
+// subdirectory is in its own package, imported as "challenge-N/<dir>".
+type FileLayout struct {
+	Editable []string `json:"editable,omitempty"` // submitted by the user, starting from the file in the challenge
+	ReadOnly []string `json:"readonly,omitempty"` // always taken from the challenge
+}
+
+// IsEditable reports whether the user may submit the file at path
+func (l *FileLayout) IsEditable(path string) bool {
+	if l == nil {
+		return false
+	}
+	for _, p := range l.Editable {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package models
+
+// DefaultFuzztime is how long each fuzz target runs when metadata.json does
+// not set fuzztime
+const DefaultFuzztime = "10s"
+
+// FuzzConfig turns on fuzz grading for a classic challenge. The targets are
+// declared in the challenge's fuzz_test.go, which compares the submission
+// with reference/solution.go imported as the oracle package.
+type FuzzConfig struct {
+	Targets  []string `json:"targets"`            // e.g. "FuzzReverseString"
+	Fuzztime string   `json:"fuzztime,omitempty"` // -fuzztime per target, e.g. "10s"
+}
+
+// FuzzFailure is an input on which the submission disagreed with the oracle,
+// minimized by the fuzzer
+type FuzzFailure struct {
+	// Path is where go test wrote the input, e.g.
+	// "testdata/fuzz/FuzzReverseString/8b0f2c...", relative to the tests
+	Path string `json:"path"`
+	// Input is the corpus file: a "go test fuzz v1" line, then one Go
+	// literal per argument of the fuzz function
+	Input   string `json:"input"`
+	Message string `json:"message"` // what the fuzz target reported
+}
+
+// FuzzTargetResult is one fuzz target's verdict. Status is one of the
+// concurrency check outcomes, CheckClean, CheckFailed or CheckSkipped.
+type FuzzTargetResult struct {
+	Name    string       `json:"name"`
+	Status  string       `json:"status"`
+	Message string       `json:"message,omitempty"`
+	Failure *FuzzFailure `json:"failure,omitempty"`
+}
+
+// FuzzReport holds the results of a challenge's fuzz targets
+type FuzzReport struct {
+	Fuzztime string             `json:"fuzztime"`
+	Targets  []FuzzTargetResult `json:"targets"`
+}
+
+// Failed reports whether any target found an input the submission gets wrong
+func (r *FuzzReport) Failed() bool {
+	if r == nil {
+		return false
+	}
+	for _, target := range r.Targets {
+		if target.Status == CheckFailed {
+			return true
+		}
+	}
+	return false
+}
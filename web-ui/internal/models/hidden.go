@@ -0,0 +1,15 @@
+package models
+
+// HiddenTestSummary is all a user learns about a challenge's hidden tests:
+// how many cases ran and how many passed. Names, inputs and output are never
+// sent back, so a solution cannot be fitted to them.
+type HiddenTestSummary struct {
+	Passed  int    `json:"passed"`
+	Total   int    `json:"total"`
+	Message string `json:"message,omitempty"` // set when the hidden tests did not run, e.g. did not build
+}
+
+// AllPassed reports whether every hidden case ran and passed
+func (s *HiddenTestSummary) AllPassed() bool {
+	return s != nil && s.Message == "" && s.Total > 0 && s.Passed == s.Total
+}
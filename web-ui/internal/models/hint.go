@@ -0,0 +1,39 @@
+package models
+
+import (
+	"html/template"
+	"time"
+)
+
+// HintStep is one rung of a challenge's hint ladder. The first rungs are the
+// authored hints from hints.md; once those run out the ladder escalates to AI
+// hints that look at the user's code and failing tests.
+type HintStep struct {
+	Level  int           `json:"level"`  // 1-based position on the ladder
+	Source string        `json:"source"` // "authored" or "ai"
+	Title  string        `json:"title"`
+	HTML   template.HTML `json:"html"`
+	// PromptVersion names the prompt template behind an AI hint
+	PromptVersion string `json:"promptVersion,omitempty"`
+}
+
+// HintLadder describes the full ladder for one challenge and what a user has
+// already revealed from it.
+type HintLadder struct {
+	Key       string     `json:"key"`
+	Total     int        `json:"total"`
+	Authored  int        `json:"authored"`
+	AIEnabled bool       `json:"aiEnabled"`
+	Revealed  []HintStep `json:"revealed"`
+}
+
+// HintProgress records how far up a challenge's ladder a user has climbed.
+type HintProgress struct {
+	Username  string     `json:"username"`
+	Key       string     `json:"key"`
+	Steps     []HintStep `json:"steps"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// HintProgressMap is username -> challenge key -> progress
+type HintProgressMap map[string]map[string]*HintProgress
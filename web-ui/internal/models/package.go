@@ -21,6 +21,23 @@ type Package struct {
 	EstimatedTime    string                    `json:"estimated_time"`
 	RealWorldUsage   []string                  `json:"real_world_usage"`
 	ChallengeDetails map[string]*ChallengeInfo `json:"challenge_details,omitempty"` // Dynamic challenge metadata
+
+	// Versions lists challenge sets kept for older (or newer) releases of
+	// the package, beside the default set for Version at the top of the
+	// package directory
+	Versions []PackageVersion `json:"versions,omitempty"`
+	// DefaultVersion is the version of the default challenge set. It differs
+	// from Version when another set was selected with GetPackageVersion.
+	DefaultVersion string `json:"default_version,omitempty"`
+	// MigrationNotes is the markdown of the notes for moving to Version from
+	// the release before it, if cmd/bump-package wrote any
+	MigrationNotes string `json:"migration_notes,omitempty"`
+}
+
+// PackageVersion is a challenge set for one release of a package
+type PackageVersion struct {
+	Version string `json:"version"`
+	Path    string `json:"path"` // Relative to the package directory, e.g. "versions/v1.9.1"
 }
 
 // ChallengeInfo contains metadata about each challenge in the learning path
@@ -54,6 +71,22 @@ type ChallengeMetadata struct {
 	BonusPoints         []string `json:"bonus_points"`
 	Icon                string   `json:"icon,omitempty"`
 	Order               int      `json:"order"`
+
+	// Classic challenges only; see ChallengeTypeWriteTests
+	Type             string `json:"type,omitempty"`
+	MinMutationScore int    `json:"min_mutation_score,omitempty"`
+	// Scores the challenge on performance as well; see BenchmarkConfig
+	Benchmark *BenchmarkConfig `json:"benchmark,omitempty"`
+	// Grades concurrency challenges on races and leaked goroutines too
+	ConcurrencyChecks *ConcurrencyChecks `json:"concurrency_checks,omitempty"`
+	// Fuzzes the submission against the reference solution; see FuzzConfig
+	Fuzz *FuzzConfig `json:"fuzz,omitempty"`
+	// Splits a challenge into several files; see FileLayout. Package
+	// challenges take read-only files only, such as generated code.
+	Files *FileLayout `json:"files,omitempty"`
+	// Names the database server some of a package challenge's tests need,
+	// e.g. "mongodb"; see services.DatabaseAvailable
+	Database string `json:"database,omitempty"`
 }
 
 // PackageChallenge represents a challenge specific to a package
@@ -78,6 +111,30 @@ type PackageChallenge struct {
 	Icon                string   `json:"icon,omitempty"`
 	Order               int      `json:"order"`
 	Status              string   `json:"status,omitempty"` // "available", "coming-soon", etc.
+
+	// HiddenTests are run on submit only; see Challenge.HiddenTests
+	HiddenTests map[string]string `json:"-"`
+	// Files lists the read-only files that ship with the challenge, such as
+	// generated gRPC stubs; FileContents holds them, keyed by path
+	Files        *FileLayout       `json:"files,omitempty"`
+	FileContents map[string]string `json:"fileContents,omitempty"`
+	// Database is the server some tests need, from ChallengeMetadata
+	Database string `json:"database,omitempty"`
+	// Module is the challenge's go.mod and go.sum; see Challenge.Module
+	Module map[string]string `json:"-"`
+}
+
+// ForExecution returns the challenge in the form ExecutionService runs
+func (c *PackageChallenge) ForExecution() *Challenge {
+	return &Challenge{
+		ID:           0, // Package challenges don't use numeric IDs
+		Title:        c.Title,
+		TestFile:     c.TestFile,
+		HiddenTests:  c.HiddenTests,
+		Files:        c.Files,
+		FileContents: c.FileContents,
+		Module:       c.Module,
+	}
 }
 
 // PackageSubmission represents a user's submitted solution for a package challenge
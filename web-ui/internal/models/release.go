@@ -72,7 +72,6 @@ type ReleaseFeature struct {
 	HasProposal          bool                `json:"has_proposal"`
 	DiagramSVG           template.HTML       `json:"-"`
 	HasDiagram           bool                `json:"has_diagram"`
-	DiagramCaption       string              `json:"diagram_caption"`
 	Challenges           []*ReleaseChallenge `json:"challenge_details,omitempty"`
 }
 
@@ -0,0 +1,60 @@
+package models
+
+// Challenge tracks, as used in challenge references
+const (
+	TrackClassic = "classic"
+	TrackPackage = "package"
+	TrackRelease = "release"
+)
+
+// SkillGraph maps the free-text prerequisites of challenges to the
+// challenges that teach them. It is loaded from skills.json at the root of
+// the repository.
+type SkillGraph struct {
+	Skills []Skill `json:"skills"`
+}
+
+// Skill is one prerequisite tag. A challenge that lists the skill's ID or
+// one of its aliases as a prerequisite is unlocked once the user has passed
+// any of the skill's challenges.
+//
+// Challenges are referenced by their path from the repository root:
+// "challenge-5", "packages/gin/challenge-1-basic-routing" or
+// "releases/go1.25/synctest/challenge-1-a-cache-that-expires".
+type Skill struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Aliases    []string `json:"aliases,omitempty"`
+	Challenges []string `json:"challenges"`
+}
+
+// ChallengeRef is a challenge on any track, as shown in recommendations
+type ChallengeRef struct {
+	Ref        string `json:"ref"`
+	Track      string `json:"track"`
+	Title      string `json:"title"`
+	Difficulty string `json:"difficulty,omitempty"`
+	URL        string `json:"url"`
+}
+
+// Recommendation is one entry of a user's next-challenge queue
+type Recommendation struct {
+	Challenge  ChallengeRef `json:"challenge"`
+	Reason     string       `json:"reason"`
+	UnlockedBy []string     `json:"unlockedBy,omitempty"` // passed challenges that met its prerequisites
+	Unlocks    int          `json:"unlocks,omitempty"`    // locked challenges that passing it would help unlock
+}
+
+// LockedChallenge is a challenge whose prerequisites the user has not met
+type LockedChallenge struct {
+	Challenge ChallengeRef `json:"challenge"`
+	Missing   []string     `json:"missing"` // skills and challenges still needed
+}
+
+// Recommendations is the response of the recommendations API
+type Recommendations struct {
+	Username string            `json:"username"`
+	Passed   []string          `json:"passed"`
+	Next     []Recommendation  `json:"next"`
+	Locked   []LockedChallenge `json:"locked"`
+}
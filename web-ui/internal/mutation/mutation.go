@@ -0,0 +1,197 @@
+// Package mutation makes small, single-token changes to Go source code, the
+// kind of slip a test suite should notice: an off-by-one comparison, a flipped
+// operator, a wrong constant. A test suite that still passes against a mutant
+// has a gap.
+package mutation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// Mutant is a copy of a source file with one change applied
+type Mutant struct {
+	ID          int    `json:"id"`
+	Operator    string `json:"operator"` // which kind of change, e.g. "boundary"
+	Func        string `json:"func"`     // enclosing function, "Recv.Method" for methods
+	Line        int    `json:"line"`
+	Original    string `json:"original"`
+	Replacement string `json:"replacement"`
+	Source      string `json:"-"`
+}
+
+// Description says what the mutant changed, e.g. `boundary: "<" -> "<=" in BinarySearch`
+func (m Mutant) Description() string {
+	replacement := fmt.Sprintf("%q", m.Replacement)
+	if m.Replacement == "" {
+		replacement = "nothing"
+	}
+	return fmt.Sprintf("%s: %q -> %s in %s", m.Operator, m.Original, replacement, m.Func)
+}
+
+// edit is one mutation point: replace length bytes at offset
+type edit struct {
+	offset      int
+	length      int
+	operator    string
+	original    string
+	replacement string
+	fn          string
+	line        int
+}
+
+var (
+	arithmetic = map[token.Token]token.Token{
+		token.ADD: token.SUB, token.SUB: token.ADD,
+		token.MUL: token.QUO, token.QUO: token.MUL,
+		token.REM: token.MUL,
+	}
+	boundary = map[token.Token]token.Token{
+		token.LSS: token.LEQ, token.LEQ: token.LSS,
+		token.GTR: token.GEQ, token.GEQ: token.GTR,
+	}
+	negation = map[token.Token]token.Token{
+		token.EQL: token.NEQ, token.NEQ: token.EQL,
+	}
+	logical = map[token.Token]token.Token{
+		token.LAND: token.LOR, token.LOR: token.LAND,
+	}
+	assignment = map[token.Token]token.Token{
+		token.ADD_ASSIGN: token.SUB_ASSIGN, token.SUB_ASSIGN: token.ADD_ASSIGN,
+		token.MUL_ASSIGN: token.QUO_ASSIGN, token.QUO_ASSIGN: token.MUL_ASSIGN,
+		token.INC: token.DEC, token.DEC: token.INC,
+	}
+)
+
+// Generate returns every mutant of a Go source file, in source order. Only
+// function bodies are mutated; declarations, imports and types are left
+// alone, and so is func main, which tests never call. Mutants that do not
+// compile (a "-" between strings, say) are not filtered out here, since that
+// needs type information; callers should treat a build failure as neither
+// killed nor survived.
+func Generate(src string) ([]Mutant, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "solution.go", src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []edit
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || (fn.Recv == nil && fn.Name.Name == "main") {
+			continue
+		}
+		name := funcName(fn)
+
+		add := func(pos token.Pos, original, replacement, operator string) {
+			position := fset.Position(pos)
+			edits = append(edits, edit{
+				offset:      position.Offset,
+				length:      len(original),
+				operator:    operator,
+				original:    original,
+				replacement: replacement,
+				fn:          name,
+				line:        position.Line,
+			})
+		}
+		swap := func(pos token.Pos, op token.Token, operator string, table map[token.Token]token.Token) bool {
+			if to, ok := table[op]; ok {
+				add(pos, op.String(), to.String(), operator)
+				return true
+			}
+			return false
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.BinaryExpr:
+				_ = swap(node.OpPos, node.Op, "arithmetic", arithmetic) ||
+					swap(node.OpPos, node.Op, "boundary", boundary) ||
+					swap(node.OpPos, node.Op, "negation", negation) ||
+					swap(node.OpPos, node.Op, "logical", logical)
+			case *ast.AssignStmt:
+				swap(node.TokPos, node.Tok, "assignment", assignment)
+			case *ast.IncDecStmt:
+				swap(node.TokPos, node.Tok, "assignment", assignment)
+			case *ast.UnaryExpr:
+				if node.Op == token.NOT || node.Op == token.SUB {
+					add(node.OpPos, node.Op.String(), "", "negation")
+				}
+			case *ast.BasicLit:
+				if node.Kind == token.INT {
+					add(node.ValuePos, node.Value, mutateInt(node.Value), "constant")
+				}
+			case *ast.Ident:
+				switch node.Name {
+				case "true":
+					add(node.NamePos, "true", "false", "constant")
+				case "false":
+					add(node.NamePos, "false", "true", "constant")
+				}
+			}
+			return true
+		})
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset < edits[j].offset })
+
+	mutants := make([]Mutant, 0, len(edits))
+	for i, e := range edits {
+		mutants = append(mutants, Mutant{
+			ID:          i + 1,
+			Operator:    e.operator,
+			Func:        e.fn,
+			Line:        e.line,
+			Original:    e.original,
+			Replacement: e.replacement,
+			Source:      src[:e.offset] + e.replacement + src[e.offset+e.length:],
+		})
+	}
+	return mutants, nil
+}
+
+// Sample keeps at most n mutants, spread evenly over the file so that every
+// function keeps some. n <= 0 keeps them all.
+func Sample(mutants []Mutant, n int) []Mutant {
+	if n <= 0 || len(mutants) <= n {
+		return mutants
+	}
+	out := make([]Mutant, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, mutants[i*len(mutants)/n])
+	}
+	return out
+}
+
+// mutateInt turns an integer literal n into n+1, or into 0 if it does not
+// fit in an int64
+func mutateInt(value string) string {
+	n, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return "0"
+	}
+	return strconv.FormatInt(n+1, 10)
+}
+
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
@@ -0,0 +1,109 @@
+package mutation
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const src = `package main
+
+import "fmt"
+
+const limit = 10
+
+type Counter struct{ n int }
+
+func (c *Counter) Add(delta int) bool {
+	if c.n+delta >= limit && !c.full() {
+		return false
+	}
+	c.n += delta
+	return true
+}
+
+func (c *Counter) full() bool { return c.n == limit }
+
+func main() {
+	fmt.Println(1 < 2)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	mutants, err := Generate(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// In source order; nothing from the const, the type or func main
+	want := []string{
+		`10 arithmetic: "+" -> "-" in Counter.Add`,
+		`10 boundary: ">=" -> ">" in Counter.Add`,
+		`10 logical: "&&" -> "||" in Counter.Add`,
+		`10 negation: "!" -> nothing in Counter.Add`,
+		`11 constant: "false" -> "true" in Counter.Add`,
+		`13 assignment: "+=" -> "-=" in Counter.Add`,
+		`14 constant: "true" -> "false" in Counter.Add`,
+		`17 negation: "==" -> "!=" in Counter.full`,
+	}
+	var got []string
+	for _, m := range mutants {
+		got = append(got, strconv.Itoa(m.Line)+" "+m.Description())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("mutants:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for i, m := range mutants {
+		if m.ID != i+1 {
+			t.Errorf("mutant %d has ID %d", i+1, m.ID)
+		}
+	}
+	if len(mutants) > 1 {
+		m := mutants[1]
+		wantSource := strings.Replace(src, "c.n+delta >= limit", "c.n+delta > limit", 1)
+		if m.Source != wantSource {
+			t.Errorf("source of %s:\n%s", m.Description(), m.Source)
+		}
+	}
+}
+
+func TestGenerateConstants(t *testing.T) {
+	mutants, err := Generate("package p\n\nfunc f() int { return 0x10 + 99999999999999999999 }\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var constants []string
+	for _, m := range mutants {
+		if m.Operator == "constant" {
+			constants = append(constants, m.Original+" -> "+m.Replacement)
+		}
+	}
+	// Hex literals are bumped by value; literals too big for an int64 become 0
+	if got, want := strings.Join(constants, ", "), "0x10 -> 17, 99999999999999999999 -> 0"; got != want {
+		t.Errorf("constants = %s, want %s", got, want)
+	}
+}
+
+func TestGenerateInvalidSource(t *testing.T) {
+	if _, err := Generate("package p\n\nfunc {"); err == nil {
+		t.Error("no error for source that does not parse")
+	}
+}
+
+func TestSample(t *testing.T) {
+	mutants := make([]Mutant, 10)
+	for i := range mutants {
+		mutants[i].ID = i + 1
+	}
+	var ids []string
+	for _, m := range Sample(mutants, 4) {
+		ids = append(ids, strconv.Itoa(m.ID))
+	}
+	if got, want := strings.Join(ids, ","), "1,3,6,8"; got != want {
+		t.Errorf("Sample(10 mutants, 4) kept %s, want %s", got, want)
+	}
+	if got := Sample(mutants, 0); len(got) != 10 {
+		t.Errorf("Sample(n=0) kept %d, want all 10", len(got))
+	}
+}
@@ -3,11 +3,9 @@ package server
 import (
 	"embed"
 	"encoding/json"
-	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
 	"web-ui/internal/handlers"
@@ -23,6 +21,8 @@ type Server struct {
 	executionService  *services.ExecutionService
 	packageService    *services.PackageService
 	aiService         *services.AIService
+	hintService       *services.HintService
+	github            *services.GitHubData
 }
 
 // NewServer creates a new server instance
@@ -34,6 +34,8 @@ func NewServer(
 	executionService *services.ExecutionService,
 	packageService *services.PackageService,
 	aiService *services.AIService,
+	hintService *services.HintService,
+	github *services.GitHubData,
 ) *Server {
 	return &Server{
 		content:           content,
@@ -43,6 +45,8 @@ func NewServer(
 		executionService:  executionService,
 		packageService:    packageService,
 		aiService:         aiService,
+		hintService:       hintService,
+		github:            github,
 	}
 }
 
@@ -53,6 +57,10 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	// Setup static file handling
 	s.setupStaticFiles(mux)
 
+	// Package progress and leaderboards are shared by the API and the pages.
+	// Like the release service it is built here, from the package service.
+	progressService := services.NewPackageProgressService(s.packageService)
+
 	// Initialize handlers
 	apiHandler := handlers.NewAPIHandler(
 		s.challengeService,
@@ -61,6 +69,9 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 		s.executionService,
 		s.packageService,
 		s.aiService,
+		s.hintService,
+		progressService,
+		s.github,
 	)
 
 	webHandler := handlers.NewWebHandler(
@@ -69,6 +80,8 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 		s.scoreboardService,
 		s.userService,
 		s.packageService,
+		progressService,
+		s.github,
 	)
 
 	// "New in Go" release track. The service is self-contained (it only reads the
@@ -80,20 +93,45 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	}
 	releaseHandler := handlers.NewReleaseHandler(s.content, releaseService)
 
+	// The hint ladder resolves challenges on all three tracks.
+	hintHandler := handlers.NewHintHandler(s.challengeService, s.packageService, releaseService, s.hintService)
+
+	// The solution explainer only reads submissions and scoreboards, so like the
+	// release service it is built here from services the server already has.
+	explainerService := services.NewSolutionExplainerService(s.aiService, s.scoreboardService)
+	solutionsHandler := handlers.NewSolutionsHandler(s.challengeService, s.scoreboardService, explainerService)
+
+	// Performance leaderboards are read from the benchmark.json files saved
+	// with submissions, so they need nothing beyond the scoreboards either.
+	performanceService := services.NewPerformanceService(s.scoreboardService)
+	benchmarkHandler := handlers.NewBenchmarkHandler(s.challengeService, s.executionService, performanceService)
+
+	// Recommendations evaluate prerequisites on all three tracks against the
+	// skill graph in skills.json.
+	skillService := services.NewSkillService(s.challengeService, s.packageService, progressService, releaseService, s.scoreboardService)
+	if err := skillService.Load(); err != nil {
+		log.Printf("skills: %v", err)
+	}
+	recommendationHandler := handlers.NewRecommendationHandler(skillService)
+
 	// API routes
 	mux.HandleFunc("/api/challenges", apiHandler.GetAllChallenges)
 	mux.HandleFunc("/api/challenges/", apiHandler.GetChallengeByID)
 	mux.HandleFunc("/api/submissions", apiHandler.HandleSubmissions)
 	mux.HandleFunc("/api/scoreboard/", apiHandler.GetScoreboard)
 	mux.HandleFunc("/api/run", apiHandler.RunCode)
+	mux.HandleFunc("/api/benchmark", benchmarkHandler.RunBenchmarks)
+	mux.HandleFunc("/api/performance/", benchmarkHandler.GetLeaderboard)
 	mux.HandleFunc("/api/save-to-filesystem", apiHandler.SaveSubmissionToFilesystem)
 	mux.HandleFunc("/api/refresh-attempts", apiHandler.RefreshUserAttempts)
 	mux.HandleFunc("/api/git-username", apiHandler.GetGitUsername)
 	mux.HandleFunc("/api/main-scoreboard-rank", apiHandler.GetMainScoreboardRank)
 	mux.HandleFunc("/api/main-leaderboard", apiHandler.GetMainLeaderboard)
+	mux.HandleFunc("/api/recommendations", recommendationHandler.GetRecommendations)
 
 	// Package challenge API routes
 	mux.HandleFunc("/api/package-leaderboard", apiHandler.GetPackageLeaderboard)
+	mux.HandleFunc("/api/package-progress", apiHandler.GetPackageProgress)
 	mux.HandleFunc("/api/packages/", apiHandler.HandlePackageChallenge)
 	mux.HandleFunc("/api/packages-save-to-filesystem", apiHandler.SavePackageChallengeToFilesystem)
 
@@ -103,9 +141,13 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	// AI-powered API routes
 	mux.HandleFunc("/api/ai/code-review", apiHandler.AICodeReview)
 	mux.HandleFunc("/api/ai/interviewer-questions", apiHandler.AIInterviewerQuestions)
-	mux.HandleFunc("/api/ai/code-hint", apiHandler.AICodeHint)
+	mux.HandleFunc("/api/ai/suggest-tests", apiHandler.AISuggestTests)
+	mux.HandleFunc("/api/ai/approaches", solutionsHandler.GetApproaches)
 	mux.HandleFunc("/api/ai/debug", apiHandler.AIDebugResponse)
 
+	// Hint ladder: authored hints first, then AI hints
+	mux.HandleFunc("/api/hints", hintHandler.HandleHints)
+
 	// GitHub webhook route
 	mux.HandleFunc("/webhook/github", apiHandler.GitHubWebhookHandler)
 
@@ -122,42 +164,7 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 
 	// Debug route for sponsors
 	mux.HandleFunc("/api/debug/sponsors", apiHandler.GetSponsorsDebug)
-	mux.HandleFunc("/api/ai/status", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		provider := os.Getenv("AI_PROVIDER")
-		if provider == "" {
-			provider = "gemini"
-		}
-		var apiKey string
-		switch strings.ToLower(provider) {
-		case "openai":
-			apiKey = os.Getenv("OPENAI_API_KEY")
-		case "claude":
-			apiKey = os.Getenv("CLAUDE_API_KEY")
-		default:
-			apiKey = os.Getenv("GEMINI_API_KEY")
-		}
-
-		// Check if API key looks valid
-		hasValidKey := apiKey != "" && !strings.Contains(apiKey, "Example") && len(apiKey) > 30
-
-		response := map[string]interface{}{
-			"provider":    provider,
-			"status":      "ready",
-			"message":     fmt.Sprintf("AI provider set to: %s", provider),
-			"has_api_key": apiKey != "",
-			"key_length":  len(apiKey),
-			"key_preview": func() string {
-				if len(apiKey) > 10 {
-					return apiKey[:10] + "..."
-				}
-				return apiKey + "..."
-			}(),
-			"is_example_key": strings.Contains(apiKey, "Example"),
-			"has_valid_key":  hasValidKey,
-		}
-		json.NewEncoder(w).Encode(response)
-	})
+	mux.HandleFunc("/api/ai/status", apiHandler.AIStatus)
 
 	// Web routes
 	mux.HandleFunc("/", webHandler.HomePage)
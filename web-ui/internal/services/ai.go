@@ -3,11 +3,14 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"web-ui/internal/models"
@@ -20,108 +23,65 @@ const (
 	ProviderGemini LLMProvider = "gemini"
 	ProviderOpenAI LLMProvider = "openai"
 	ProviderClaude LLMProvider = "claude"
+	// ProviderFake answers locally with canned, well-formed responses. It
+	// needs no key and is used for development and prompt evaluation.
+	ProviderFake LLMProvider = "fake"
 )
 
 // LLMConfig holds configuration for different LLM providers
 type LLMConfig struct {
-	Provider    LLMProvider
-	APIKey      string
-	Model       string
-	BaseURL     string
-	MaxTokens   int
-	Temperature float64
+	Provider      LLMProvider
+	APIKey        string
+	Model         string
+	FeatureModels map[AIFeature]string // per-feature overrides of Model
+	BaseURL       string
+	MaxTokens     int
+	Temperature   float64
 }
 
 // AIService handles AI-powered code review and interview simulation
 type AIService struct {
 	config     LLMConfig
+	prompts    *PromptSet
+	problems   []string
 	httpClient *http.Client
+	record     func(AICall)
+
+	pingMutex sync.Mutex
+	lastPing  *AIPingResult
+	pinging   chan struct{} // Closed when the ping in flight finishes; nil if none is
 }
 
-// NewAIService creates a new AI service with the specified provider
+// NewAIService creates a new AI service with the provider configured in the
+// environment. See LoadLLMConfig for the variables it reads. Prompt templates
+// are embedded; AI_PROMPTS_DIR names a directory whose *.tmpl files replace
+// the embedded ones of the same name.
 func NewAIService() *AIService {
-	cfgProvider := getProviderFromEnv()
-	config := LLMConfig{
-		Provider:    cfgProvider,
-		APIKey:      getAPIKeyFromEnvFor(cfgProvider),
-		Model:       getModelFromEnv(),
-		MaxTokens:   4000, // Increased for longer responses
-		Temperature: 0.3,
-	}
+	config, problems := LoadLLMConfig()
 
-	// Set provider-specific defaults
-	switch config.Provider {
-	case ProviderGemini:
-		config.BaseURL = "https://generativelanguage.googleapis.com/v1beta/models"
-		if config.Model == "" {
-			config.Model = "gemini-2.5-flash"
-		}
-	case ProviderOpenAI:
-		config.BaseURL = "https://api.openai.com/v1/chat/completions"
-		if config.Model == "" {
-			// Use a modern default that supports structured outputs well
-			config.Model = "gpt-4o-mini"
-		}
-	case ProviderClaude:
-		config.BaseURL = "https://api.anthropic.com/v1/messages"
-		if config.Model == "" {
-			config.Model = "claude-3-sonnet-20240229"
-		}
-	default:
-		// Default to Gemini if provider is not recognized
-		config.Provider = ProviderGemini
-		config.BaseURL = "https://generativelanguage.googleapis.com/v1beta/models"
-		if config.Model == "" {
-			config.Model = "gemini-2.5-flash"
-		}
+	prompts, err := LoadPromptSet(os.Getenv("AI_PROMPTS_DIR"))
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("AI_PROMPTS_DIR: %v; using the embedded prompts", err))
+		prompts, _ = LoadPromptSet("")
 	}
 
+	return NewAIServiceWith(config, prompts, problems)
+}
+
+// NewAIServiceWith creates an AI service from an explicit config and prompt
+// set, e.g. the fake provider in the prompt evaluation command.
+func NewAIServiceWith(config LLMConfig, prompts *PromptSet, problems []string) *AIService {
 	return &AIService{
-		config: config,
+		config:   config,
+		prompts:  prompts,
+		problems: problems,
+		record:   logAICall,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
-// Helper functions to get configuration from environment
-func getProviderFromEnv() LLMProvider {
-	provider := strings.ToLower(os.Getenv("AI_PROVIDER"))
-	switch provider {
-	case "gemini":
-		return ProviderGemini
-	case "openai":
-		return ProviderOpenAI
-	case "claude":
-		return ProviderClaude
-	default:
-		return ProviderGemini // Default to Gemini
-	}
-}
-
-func getAPIKeyFromEnvFor(provider LLMProvider) string {
-	switch provider {
-	case ProviderGemini:
-		if key := os.Getenv("GEMINI_API_KEY"); key != "" {
-			return key
-		}
-	case ProviderOpenAI:
-		if key := os.Getenv("OPENAI_API_KEY"); key != "" {
-			return key
-		}
-	case ProviderClaude:
-		if key := os.Getenv("CLAUDE_API_KEY"); key != "" {
-			return key
-		}
-	}
-	// Fall back to generic AI_API_KEY
-	return os.Getenv("AI_API_KEY")
-}
-
-func getModelFromEnv() string {
-	return os.Getenv("AI_MODEL")
-}
-
 // AICodeReview represents the response from AI code review
 type AICodeReview struct {
 	OverallScore        float64            `json:"overall_score"`        // 0-100 score
@@ -132,6 +92,7 @@ type AICodeReview struct {
 	Complexity          ComplexityAnalysis `json:"complexity"`           // Time/space complexity analysis
 	ReadabilityScore    float64            `json:"readability_score"`    // 0-100 readability score
 	TestCoverage        string             `json:"test_coverage"`        // Coverage assessment
+	PromptVersion       string             `json:"prompt_version,omitempty"`
 }
 
 // CodeIssue represents a specific issue in the code
@@ -260,7 +221,7 @@ type OpenAIError struct {
 // ReviewCode performs AI-powered code review
 func (ai *AIService) ReviewCode(code string, challenge *models.Challenge, context string) (*AICodeReview, error) {
 
-	if ai.config.APIKey == "" {
+	if !ai.Enabled() {
 		return &AICodeReview{
 			OverallScore:        0,
 			Issues:              []CodeIssue{},
@@ -278,9 +239,11 @@ func (ai *AIService) ReviewCode(code string, challenge *models.Challenge, contex
 		}, nil
 	}
 
-	prompt := ai.buildCodeReviewPrompt(code, challenge, context)
-
-	response, err := ai.callLLMWithOpts(prompt, true /* expectJSON */)
+	response, err := ai.complete(FeatureReview, PromptCodeReview, PromptData{
+		Title:   challenge.Title,
+		Context: context,
+		Code:    code,
+	}, true /* expectJSON */)
 	if err != nil {
 		return &AICodeReview{
 			OverallScore:        0,
@@ -302,21 +265,24 @@ func (ai *AIService) ReviewCode(code string, challenge *models.Challenge, contex
 	review, err := ai.parseAIResponse(response)
 	if err != nil {
 		// This shouldn't happen anymore since parseAIResponse returns fallback instead of error
-		return ai.createFallbackReview("Unexpected parsing error", response), nil
+		review = ai.createFallbackReview("Unexpected parsing error", response)
 	}
+	review.PromptVersion = ai.PromptVersion(PromptCodeReview)
 
 	return review, nil
 }
 
 // GetInterviewerQuestions generates follow-up questions based on code
 func (ai *AIService) GetInterviewerQuestions(code string, challenge *models.Challenge, userProgress string) ([]string, error) {
-	if ai.config.APIKey == "" {
+	if !ai.Enabled() {
 		return []string{"⚠️ AI features require an API key. Get your free key at: https://makersuite.google.com/app/apikey"}, nil
 	}
 
-	prompt := ai.buildQuestionPrompt(code, challenge, userProgress)
-
-	response, err := ai.callLLMWithOpts(prompt, true /* expectJSON */)
+	response, err := ai.complete(FeatureQuestions, PromptQuestions, PromptData{
+		Title:        challenge.Title,
+		UserProgress: userProgress,
+		Code:         code,
+	}, true /* expectJSON */)
 	if err != nil {
 		return []string{fmt.Sprintf("❌ AI service unavailable: %v", err)}, nil
 	}
@@ -325,15 +291,22 @@ func (ai *AIService) GetInterviewerQuestions(code string, challenge *models.Chal
 	return questions, nil
 }
 
-// GetCodeHint provides context-aware hints
-func (ai *AIService) GetCodeHint(code string, challenge *models.Challenge, hintLevel int) (string, error) {
-	if ai.config.APIKey == "" {
+// GetLadderHint provides an AI hint for the rungs of a hint ladder that sit
+// above the challenge's authored hints. The prompt includes the titles of the
+// hints already shown and the failing tests, so the AI builds on them.
+func (ai *AIService) GetLadderHint(code string, challenge *models.Challenge, shownHints []string, failingTests string, hintLevel int) (string, error) {
+	if !ai.Enabled() {
 		return "⚠️ AI features require an API key. Get your free key at: https://makersuite.google.com/app/apikey", nil
 	}
 
-	prompt := ai.buildHintPrompt(code, challenge, hintLevel)
-
-	response, err := ai.callLLMWithOpts(prompt, false /* expectJSON */)
+	response, err := ai.complete(FeatureHint, PromptLadderHint, PromptData{
+		Title:        challenge.Title,
+		Code:         code,
+		HintLevel:    hintLevel,
+		HintType:     hintTypes[hintLevel],
+		ShownHints:   shownHints,
+		FailingTests: strings.TrimSpace(failingTests),
+	}, false /* expectJSON */)
 	if err != nil {
 		return fmt.Sprintf("❌ AI service unavailable: %v", err), nil
 	}
@@ -341,96 +314,168 @@ func (ai *AIService) GetCodeHint(code string, challenge *models.Challenge, hintL
 	return ai.parseHint(response), nil
 }
 
-// BuildCodeReviewPrompt exposes the prompt builder for debugging
-func (ai *AIService) BuildCodeReviewPrompt(code string, challenge *models.Challenge, context string) string {
-	return ai.buildCodeReviewPrompt(code, challenge, context)
+// Enabled reports whether a usable API key is configured for the AI provider
+func (ai *AIService) Enabled() bool {
+	return ai.config.APIKey != "" || ai.config.Provider == ProviderFake
+}
+
+// Problems returns what LoadLLMConfig found wrong with the configuration
+func (ai *AIService) Problems() []string {
+	return ai.problems
+}
+
+// Status reports the AI configuration and the result of the last ping,
+// without exposing the API key.
+func (ai *AIService) Status() AIStatus {
+	status := AIStatus{
+		Status:        "ready",
+		Provider:      ai.config.Provider,
+		Model:         ai.config.Model,
+		FeatureModels: make(map[string]string),
+		Enabled:       ai.Enabled(),
+		HasAPIKey:     ai.config.APIKey != "",
+		Problems:      append([]string{}, ai.problems...),
+	}
+	for _, feature := range aiFeatures {
+		status.FeatureModels[string(feature)] = ai.config.ModelFor(feature)
+	}
+
+	ai.pingMutex.Lock()
+	status.Ping = ai.lastPing
+	ai.pingMutex.Unlock()
+
+	switch {
+	case !ai.Enabled():
+		status.Status = "disabled"
+	case len(status.Problems) > 0:
+		status.Status = "misconfigured"
+	case status.Ping != nil && !status.Ping.OK:
+		status.Status = "unreachable"
+	}
+	return status
+}
+
+// Ping sends the smallest possible request to the provider to check that the
+// key and model are accepted. A ping younger than maxAge is reused rather than
+// sent again, so the status endpoint cannot be used to run up the bill.
+func (ai *AIService) Ping(maxAge time.Duration) AIPingResult {
+	// The lock guards lastPing only; the request is sent without it, so
+	// Status answers while a slow provider is being pinged
+	ai.pingMutex.Lock()
+	if ai.lastPing != nil && time.Since(ai.lastPing.CheckedAt) < maxAge {
+		result := *ai.lastPing
+		ai.pingMutex.Unlock()
+		return result
+	}
+	if inFlight := ai.pinging; inFlight != nil {
+		// Share the ping already on its way rather than send another
+		ai.pingMutex.Unlock()
+		<-inFlight
+		ai.pingMutex.Lock()
+		defer ai.pingMutex.Unlock()
+		return *ai.lastPing
+	}
+	done := make(chan struct{})
+	ai.pinging = done
+	ai.pingMutex.Unlock()
+
+	result := AIPingResult{CheckedAt: time.Now()}
+	if !ai.Enabled() {
+		result.Error = "no API key configured"
+	} else {
+		start := time.Now()
+		_, err := ai.callModel(ai.config.Model, "Reply with the single word OK.", false, 16)
+		result.LatencyMS = time.Since(start).Milliseconds()
+		result.OK = err == nil
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+
+	ai.pingMutex.Lock()
+	ai.lastPing = &result
+	ai.pinging = nil
+	ai.pingMutex.Unlock()
+	close(done)
+	return result
+}
+
+// BuildCodeReviewPrompt exposes the code review prompt for debugging, along
+// with the version of the template that produced it
+func (ai *AIService) BuildCodeReviewPrompt(code string, challenge *models.Challenge, context string) (string, string, error) {
+	return ai.prompts.Render(PromptCodeReview, PromptData{
+		Title:   challenge.Title,
+		Context: context,
+		Code:    code,
+	})
 }
 
 // CallLLMRaw calls the LLM and returns raw response for debugging
 func (ai *AIService) CallLLMRaw(prompt string) (string, error) {
-	return ai.callLLMWithOpts(prompt, true)
+	return ai.callFeature(FeatureReview, prompt, true)
 }
 
-// buildCodeReviewPrompt creates the prompt for code review
-func (ai *AIService) buildCodeReviewPrompt(code string, challenge *models.Challenge, context string) string {
-	return fmt.Sprintf(`You are a senior Go interviewer. Respond ONLY with a single JSON object. Do NOT include markdown or code fences. All numeric fields must be JSON numbers, not strings.
-
-SCHEMA:
-{
-  "overall_score": integer (0..100),
-  "issues": [
-    {
-      "type": "bug|performance|style|logic",
-      "severity": "low|medium|high|critical",
-      "line_number": integer,
-      "description": string,
-      "solution": string
-    }
-  ],
-  "suggestions": [
-    {
-      "category": "optimization|best_practice|alternative",
-      "priority": "low|medium|high",
-      "description": string,
-      "example": string
-    }
-  ],
-  "interviewer_feedback": string,
-  "follow_up_questions": [string],
-  "complexity": {
-    "time_complexity": string,
-    "space_complexity": string,
-    "can_optimize": boolean,
-    "optimized_approach": string
-  },
-  "readability_score": integer (0..100),
-  "test_coverage": string
+// PromptVersion returns the version ID of the named prompt template, so
+// responses can record which prompt produced them
+func (ai *AIService) PromptVersion(name string) string {
+	return ai.prompts.Version(name)
 }
 
-CHALLENGE: %s
-CONTEXT: %s
-
-CODE (Go):
-BEGIN_CODE
-%s
-END_CODE
-
-Focus on: (1) correctness and edge cases, (2) Go idioms, (3) performance, (4) readability, (5) interviewer follow-ups.`, challenge.Title, context, code)
+// Prompts lists the prompt templates in use
+func (ai *AIService) Prompts() []Prompt {
+	return ai.prompts.Prompts()
 }
 
-// buildQuestionPrompt creates the prompt for generating interview questions
-func (ai *AIService) buildQuestionPrompt(code string, challenge *models.Challenge, userProgress string) string {
-	return fmt.Sprintf(`You are a technical interviewer. Respond ONLY with a JSON array of strings. No markdown, no prose outside the array.
-
-CHALLENGE: %s
-USER PROGRESS: %s
-
-CODE (Go):
-BEGIN_CODE
-%s
-END_CODE
+// hintTypes describes how direct a hint should be at each level
+var hintTypes = map[int]string{
+	1: "a subtle nudge in the right direction",
+	2: "a more direct hint about the approach",
+	3: "a specific suggestion about implementation",
+	4: "a detailed explanation with partial code example",
+}
 
-Generate 3-5 follow-up questions that probe: deeper understanding, edge cases, optimizations, Go-specific concepts, and trade-offs.`, challenge.Title, userProgress, code)
+// AICall describes one request made through a prompt template
+type AICall struct {
+	Feature       AIFeature
+	PromptVersion string
+	Model         string
+	PromptChars   int
+	ResponseChars int
+	Duration      time.Duration
+	Err           error
 }
 
-// buildHintPrompt creates the prompt for generating hints
-func (ai *AIService) buildHintPrompt(code string, challenge *models.Challenge, hintLevel int) string {
-	hintTypes := map[int]string{
-		1: "a subtle nudge in the right direction",
-		2: "a more direct hint about the approach",
-		3: "a specific suggestion about implementation",
-		4: "a detailed explanation with partial code example",
-	}
+// SetRecorder replaces the function told about every templated AI call. The
+// default logs it.
+func (ai *AIService) SetRecorder(record func(AICall)) {
+	ai.record = record
+}
 
-	return fmt.Sprintf(`You are a helpful coding mentor. Return only the hint text as plain text. No JSON, no code fences.
+func logAICall(call AICall) {
+	log.Printf("AI %s: prompt %s, model %s, %d ms, ok=%t",
+		call.Feature, call.PromptVersion, call.Model, call.Duration.Milliseconds(), call.Err == nil)
+}
 
-CHALLENGE: %s
-CURRENT CODE:
-%s
+// complete renders a prompt template, sends it with the feature's model and
+// records which prompt version and model produced the response.
+func (ai *AIService) complete(feature AIFeature, promptName string, data PromptData, expectJSON bool) (string, error) {
+	call := AICall{Feature: feature, Model: ai.config.ModelFor(feature)}
+	defer func() { ai.record(call) }()
 
-Provide %s (level %d/4). Be encouraging and educational, not just giving the answer.
+	prompt, version, err := ai.prompts.Render(promptName, data)
+	call.PromptVersion = version
+	if err != nil {
+		call.Err = err
+		return "", err
+	}
+	call.PromptChars = len(prompt)
 
-Return only the hint text.`, challenge.Title, code, hintTypes[hintLevel], hintLevel)
+	start := time.Now()
+	response, err := ai.callFeature(feature, prompt, expectJSON)
+	call.Duration = time.Since(start)
+	call.ResponseChars = len(response)
+	call.Err = err
+	return response, err
 }
 
 // callLLM makes a request to the configured LLM provider
@@ -440,21 +485,43 @@ func (ai *AIService) callLLM(prompt string) (string, error) {
 
 // callLLMWithOpts allows specifying whether JSON output is expected (to enforce provider features)
 func (ai *AIService) callLLMWithOpts(prompt string, expectJSON bool) (string, error) {
+	return ai.callModel(ai.config.Model, prompt, expectJSON, ai.config.MaxTokens)
+}
+
+// callFeature calls the LLM with the model configured for a feature
+func (ai *AIService) callFeature(feature AIFeature, prompt string, expectJSON bool) (string, error) {
+	return ai.callModel(ai.config.ModelFor(feature), prompt, expectJSON, ai.config.MaxTokens)
+}
+
+// callModel sends the prompt to the configured provider using the given model.
+// Errors are scrubbed of the API key: Gemini takes it as a query parameter, so
+// a failed request would otherwise echo it back to the user.
+func (ai *AIService) callModel(model, prompt string, expectJSON bool, maxTokens int) (string, error) {
+	var response string
+	var err error
+
 	switch ai.config.Provider {
 	case ProviderGemini:
-		return ai.callGeminiWithOpts(prompt, expectJSON)
+		response, err = ai.callGeminiWithOpts(model, prompt, expectJSON, maxTokens)
 	case ProviderOpenAI:
-		return ai.callOpenAIWithOpts(prompt, expectJSON)
+		response, err = ai.callOpenAIWithOpts(model, prompt, expectJSON, maxTokens)
 	case ProviderClaude:
-		return ai.callClaudeWithOpts(prompt, expectJSON)
+		response, err = ai.callClaudeWithOpts(model, prompt, expectJSON, maxTokens)
+	case ProviderFake:
+		response = fakeCompletion(prompt, expectJSON)
 	default:
 		return "", fmt.Errorf("unsupported provider: %s", ai.config.Provider)
 	}
+
+	if err != nil && ai.config.APIKey != "" {
+		err = errors.New(strings.ReplaceAll(err.Error(), ai.config.APIKey, "[redacted]"))
+	}
+	return response, err
 }
 
 // callGemini makes a request to the Gemini API
-func (ai *AIService) callGeminiWithOpts(prompt string, expectJSON bool) (string, error) {
-	url := fmt.Sprintf("%s/%s:generateContent?key=%s", ai.config.BaseURL, ai.config.Model, ai.config.APIKey)
+func (ai *AIService) callGeminiWithOpts(model, prompt string, expectJSON bool, maxTokens int) (string, error) {
+	url := fmt.Sprintf("%s/%s:generateContent?key=%s", ai.config.BaseURL, model, ai.config.APIKey)
 
 	requestBody := GeminiRequest{
 		Contents: []GeminiContent{
@@ -466,7 +533,7 @@ func (ai *AIService) callGeminiWithOpts(prompt string, expectJSON bool) (string,
 		},
 		GenerationConfig: &GeminiGenerationConfig{
 			Temperature:     &ai.config.Temperature,
-			MaxOutputTokens: &ai.config.MaxTokens,
+			MaxOutputTokens: &maxTokens,
 			ResponseMIME: func() string {
 				if expectJSON {
 					return "application/json"
@@ -528,7 +595,7 @@ type claudeMessage struct {
 	Content []claudeContentBlock `json:"content"`
 }
 
-func (ai *AIService) callClaudeWithOpts(prompt string, expectJSON bool) (string, error) {
+func (ai *AIService) callClaudeWithOpts(model, prompt string, expectJSON bool, maxTokens int) (string, error) {
 	systemText := "You are a senior Go interviewer. Be concise."
 	if expectJSON {
 		systemText += " Respond ONLY with strict JSON. No markdown."
@@ -539,12 +606,12 @@ func (ai *AIService) callClaudeWithOpts(prompt string, expectJSON bool) (string,
 		MaxTokens   int             `json:"max_tokens"`
 		Temperature float64         `json:"temperature"`
 	}{
-		Model: ai.config.Model,
+		Model: model,
 		Messages: []claudeMessage{
 			{Role: "system", Content: []claudeContentBlock{{Type: "text", Text: systemText}}},
 			{Role: "user", Content: []claudeContentBlock{{Type: "text", Text: prompt}}},
 		},
-		MaxTokens:   ai.config.MaxTokens,
+		MaxTokens:   maxTokens,
 		Temperature: ai.config.Temperature,
 	}
 
@@ -595,7 +662,7 @@ type OpenAIResponseFormat struct {
 	Type string `json:"type"`
 }
 
-func (ai *AIService) callOpenAIWithOpts(prompt string, expectJSON bool) (string, error) {
+func (ai *AIService) callOpenAIWithOpts(model, prompt string, expectJSON bool, maxTokens int) (string, error) {
 	// Add a system message to better steer responses
 	messages := []Message{
 		{Role: "system", Content: func() string {
@@ -608,9 +675,9 @@ func (ai *AIService) callOpenAIWithOpts(prompt string, expectJSON bool) (string,
 	}
 
 	requestBody := OpenAIRequest{
-		Model:       ai.config.Model,
+		Model:       model,
 		Messages:    messages,
-		MaxTokens:   ai.config.MaxTokens,
+		MaxTokens:   maxTokens,
 		Temperature: ai.config.Temperature,
 	}
 	if expectJSON {
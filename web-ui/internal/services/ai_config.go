@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// AIFeature names a part of the app that calls the LLM. Each feature can run
+// on its own model, e.g. a cheap one for hints and a stronger one for reviews.
+type AIFeature string
+
+const (
+	FeatureHint      AIFeature = "hint"
+	FeatureReview    AIFeature = "review"
+	FeatureQuestions AIFeature = "questions"
+	FeatureTests     AIFeature = "tests"
+	FeatureExplain   AIFeature = "explain"
+)
+
+// aiFeatures lists every feature that can be given its own model.
+var aiFeatures = []AIFeature{FeatureHint, FeatureReview, FeatureQuestions, FeatureTests, FeatureExplain}
+
+// providerDefaults holds the base URL and default model of each provider
+var providerDefaults = map[LLMProvider]struct {
+	BaseURL string
+	Model   string
+}{
+	ProviderGemini: {"https://generativelanguage.googleapis.com/v1beta/models", "gemini-2.5-flash"},
+	ProviderOpenAI: {"https://api.openai.com/v1/chat/completions", "gpt-4o-mini"},
+	ProviderClaude: {"https://api.anthropic.com/v1/messages", "claude-3-sonnet-20240229"},
+	ProviderFake:   {"", "fake"},
+}
+
+// providerModelPrefixes is used to catch a model configured for the wrong
+// provider, e.g. AI_MODEL=gpt-4o with AI_PROVIDER=gemini.
+var providerModelPrefixes = map[LLMProvider][]string{
+	ProviderGemini: {"gemini", "models/", "learnlm"},
+	ProviderOpenAI: {"gpt", "o1", "o3", "o4", "chatgpt", "ft:"},
+	ProviderClaude: {"claude"},
+}
+
+// providerKeyEnv is the provider-specific variable holding the API key
+var providerKeyEnv = map[LLMProvider]string{
+	ProviderGemini: "GEMINI_API_KEY",
+	ProviderOpenAI: "OPENAI_API_KEY",
+	ProviderClaude: "CLAUDE_API_KEY",
+}
+
+// AIStatus is the health report served at /api/ai/status. It never contains
+// the API key or anything derived from it.
+type AIStatus struct {
+	Status        string            `json:"status"` // "ready", "disabled", "misconfigured" or "unreachable"
+	Provider      LLMProvider       `json:"provider"`
+	Model         string            `json:"model"`
+	FeatureModels map[string]string `json:"feature_models"`
+	Enabled       bool              `json:"enabled"`
+	HasAPIKey     bool              `json:"has_api_key"`
+	Problems      []string          `json:"problems"`
+	Ping          *AIPingResult     `json:"ping,omitempty"`
+}
+
+// AIPingResult is the outcome of a minimal request to the provider
+type AIPingResult struct {
+	OK        bool      `json:"ok"`
+	LatencyMS int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// LoadLLMConfig reads the AI configuration from the environment:
+//
+//	AI_PROVIDER          gemini (default), openai, claude, or fake for canned offline responses
+//	GEMINI_API_KEY, OPENAI_API_KEY, CLAUDE_API_KEY, or AI_API_KEY as a fallback
+//	AI_MODEL             default model for every feature
+//	AI_MODEL_HINT, AI_MODEL_REVIEW, AI_MODEL_QUESTIONS, AI_MODEL_TESTS, AI_MODEL_EXPLAIN
+//	                     per-feature overrides
+//
+// It returns the config along with any problems found in it. A problem with
+// the key leaves APIKey empty so AI features stay off instead of failing on
+// every request.
+func LoadLLMConfig() (LLMConfig, []string) {
+	var problems []string
+
+	rawProvider := strings.ToLower(strings.TrimSpace(os.Getenv("AI_PROVIDER")))
+	if rawProvider == "mock" {
+		rawProvider = string(ProviderFake) // the name older docs used
+	}
+	provider := LLMProvider(rawProvider)
+	if _, ok := providerDefaults[provider]; !ok {
+		if rawProvider != "" {
+			problems = append(problems, fmt.Sprintf("unknown AI_PROVIDER %q, falling back to gemini", rawProvider))
+		}
+		provider = ProviderGemini
+	}
+
+	config := LLMConfig{
+		Provider:      provider,
+		BaseURL:       providerDefaults[provider].BaseURL,
+		Model:         strings.TrimSpace(os.Getenv("AI_MODEL")),
+		FeatureModels: make(map[AIFeature]string),
+		MaxTokens:     4000, // Increased for longer responses
+		Temperature:   0.3,
+	}
+	if config.Model == "" {
+		config.Model = providerDefaults[provider].Model
+	}
+	problems = append(problems, checkModel(provider, "AI_MODEL", config.Model)...)
+
+	for _, feature := range aiFeatures {
+		name := featureModelEnv(feature)
+		if model := strings.TrimSpace(os.Getenv(name)); model != "" {
+			config.FeatureModels[feature] = model
+			problems = append(problems, checkModel(provider, name, model)...)
+		}
+	}
+
+	if provider == ProviderFake {
+		return config, problems
+	}
+
+	keyEnv := providerKeyEnv[provider]
+	key := os.Getenv(keyEnv)
+	if key == "" {
+		keyEnv, key = "AI_API_KEY", os.Getenv("AI_API_KEY")
+	}
+	if problem := checkAPIKey(keyEnv, key); problem != "" {
+		problems = append(problems, problem)
+		key = ""
+	}
+	config.APIKey = key
+
+	return config, problems
+}
+
+// ModelFor returns the model a feature should use
+func (c LLMConfig) ModelFor(feature AIFeature) string {
+	if model := c.FeatureModels[feature]; model != "" {
+		return model
+	}
+	return c.Model
+}
+
+func featureModelEnv(feature AIFeature) string {
+	return "AI_MODEL_" + strings.ToUpper(string(feature))
+}
+
+func checkModel(provider LLMProvider, name, model string) []string {
+	if provider == ProviderFake {
+		return nil
+	}
+	for _, prefix := range providerModelPrefixes[provider] {
+		if strings.HasPrefix(strings.ToLower(model), prefix) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("%s=%q does not look like a %s model", name, model, provider)}
+}
+
+// checkAPIKey catches keys that are present but cannot work. A missing key is
+// not a problem: it just means AI features are off.
+func checkAPIKey(name, key string) string {
+	switch {
+	case key == "":
+		return ""
+	case strings.Contains(key, "Example"), strings.HasPrefix(key, "your_"), strings.HasSuffix(key, "_here"):
+		return fmt.Sprintf("%s still holds the placeholder value from env.example", name)
+	case strings.ContainsAny(key, " \t\r\n"):
+		return fmt.Sprintf("%s contains whitespace", name)
+	}
+	return ""
+}
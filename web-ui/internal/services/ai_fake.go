@@ -0,0 +1,72 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	fakePackagePattern = regexp.MustCompile(`Use "package (\w+)"`)
+	fakeLevelPattern   = regexp.MustCompile(`level (\d)/4`)
+)
+
+// fakeCompletion answers a prompt without calling a provider. The response is
+// deterministic and shaped like what each prompt asks for, so everything that
+// parses AI output can be exercised offline.
+func fakeCompletion(prompt string, expectJSON bool) string {
+	switch {
+	case strings.Contains(prompt, `"overall_score"`):
+		review := AICodeReview{
+			OverallScore:        float64(60 + len(prompt)%40),
+			Issues:              []CodeIssue{{Type: "style", Severity: "low", LineNumber: 1, Description: "Fake issue.", Solution: "Fake fix."}},
+			Suggestions:         []CodeSuggestion{{Category: "best_practice", Priority: "low", Description: "Fake suggestion."}},
+			InterviewerFeedback: "Fake feedback from the offline provider.",
+			FollowUpQuestions:   []string{"What is the time complexity?"},
+			Complexity:          ComplexityAnalysis{TimeComplexity: "O(n)", SpaceComplexity: "O(1)"},
+			ReadabilityScore:    75,
+			TestCoverage:        "Fake coverage assessment.",
+		}
+		out, _ := json.Marshal(review)
+		return string(out)
+
+	case strings.Contains(prompt, `{"approaches"`):
+		var approaches []map[string]string
+		for i := 1; i <= strings.Count(prompt, "\nAPPROACH "); i++ {
+			approaches = append(approaches, map[string]string{
+				"name":      fmt.Sprintf("Fake approach %d", i),
+				"summary":   "Fake summary.",
+				"tradeoffs": "Fake tradeoffs.",
+			})
+		}
+		out, _ := json.Marshal(map[string]interface{}{"approaches": approaches})
+		return string(out)
+
+	case strings.Contains(prompt, "Return only a Go test file"):
+		pkg := "main"
+		if m := fakePackagePattern.FindStringSubmatch(prompt); m != nil {
+			pkg = m[1]
+		}
+		return fmt.Sprintf(`package %s
+
+import "testing"
+
+func %sCases(t *testing.T) {
+	cases := []struct{ name string }{{"fake_case"}}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {})
+	}
+}
+`, pkg, SuggestedTestPrefix)
+
+	case expectJSON:
+		return `["Fake question one?", "Fake question two?", "Fake question three?"]`
+	}
+
+	level := "1"
+	if m := fakeLevelPattern.FindStringSubmatch(prompt); m != nil {
+		level = m[1]
+	}
+	return fmt.Sprintf("Fake hint at level %s from the offline provider.", level)
+}
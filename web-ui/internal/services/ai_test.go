@@ -0,0 +1,72 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A slow provider must not hold up Status, and pings sent while one is in
+// flight share its request
+func TestPingDoesNotBlockStatus(t *testing.T) {
+	release := make(chan struct{})
+	var requests int64
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "OK"}}]}`))
+	}))
+	defer provider.Close()
+	// Release the provider's handlers however the test ends, before Close
+	// waits for them
+	var releaseOnce sync.Once
+	unblock := func() { releaseOnce.Do(func() { close(release) }) }
+	defer unblock()
+
+	ai := &AIService{
+		config:     LLMConfig{Provider: ProviderOpenAI, APIKey: "key", Model: "model", BaseURL: provider.URL},
+		httpClient: provider.Client(),
+	}
+
+	var wg sync.WaitGroup
+	results := make([]AIPingResult, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = ai.Ping(time.Minute)
+		}(i)
+	}
+	for atomic.LoadInt64(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	status := make(chan AIStatus)
+	go func() { status <- ai.Status() }()
+	select {
+	case s := <-status:
+		if s.Ping != nil {
+			t.Errorf("Status during the first ping reported a ping: %+v", s.Ping)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Status blocked while the provider was being pinged")
+	}
+
+	unblock()
+	wg.Wait()
+	for i, result := range results {
+		if !result.OK {
+			t.Errorf("ping %d: %+v, want OK", i, result)
+		}
+	}
+	if n := atomic.LoadInt64(&requests); n != 1 {
+		t.Errorf("provider got %d requests, want 1 shared by the concurrent pings", n)
+	}
+	if s := ai.Status(); s.Ping == nil || !s.Ping.OK {
+		t.Errorf("Status after the ping = %+v, want the OK ping", s.Ping)
+	}
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+const (
+	// benchmarkConfidence is the coverage aimed for by the intervals in a
+	// BenchmarkReport
+	benchmarkConfidence = 0.95
+	// benchmarkTimeout bounds a whole RunBenchmarks call, builds included
+	benchmarkTimeout = 5 * time.Minute
+)
+
+// benchmarkSuffixPattern matches the -GOMAXPROCS suffix go test appends to
+// benchmark names
+var benchmarkSuffixPattern = regexp.MustCompile(`-\d+$`)
+
+// benchmarkSample is one benchmark's line from one run
+type benchmarkSample struct {
+	nsPerOp, bytesPerOp, allocsPerOp float64
+}
+
+// RunBenchmarks measures a solution against the challenge's reference. The
+// tests must pass first. The benchmarks matching the challenge's pattern are
+// then run in rounds, one run of the submission and one of the reference
+// per round, alternating which goes first so that drift in the machine's
+// speed affects both alike. Each ratio is taken within a round.
+func (es *ExecutionService) RunBenchmarks(code string, challenge *models.Challenge) ExecutionResult {
+	start := time.Now()
+	result := func(passed bool, output string, report *models.BenchmarkReport) ExecutionResult {
+		return ExecutionResult{
+			Passed:      passed,
+			Output:      output,
+			ExecutionMs: time.Since(start).Milliseconds(),
+			Benchmarks:  report,
+		}
+	}
+
+	config := challenge.Benchmark
+	if config == nil || challenge.Reference == "" {
+		return result(false, fmt.Sprintf("Challenge %d is not scored on performance.", challenge.ID), nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), benchmarkTimeout)
+	defer cancel()
+
+	submissionDir, output, err := es.buildBenchmarkBinary(ctx, code, challenge)
+	if submissionDir != "" {
+		defer os.RemoveAll(submissionDir)
+	}
+	if err != nil {
+		return result(false, output, nil)
+	}
+	referenceDir, output, err := es.buildBenchmarkBinary(ctx, challenge.Reference, challenge)
+	if referenceDir != "" {
+		defer os.RemoveAll(referenceDir)
+	}
+	if err != nil {
+		return result(false, "The reference solution does not build:\n"+output, nil)
+	}
+
+	output, err = runBenchmarkBinary(ctx, submissionDir, "-test.v", "-test.count=1", "-test.timeout=1m")
+	if err != nil {
+		return result(false, "Benchmarks run once every test passes.\n\n"+output, nil)
+	}
+
+	args := []string{
+		"-test.run=^$",
+		"-test.bench=" + config.Pattern,
+		"-test.benchmem",
+		"-test.benchtime=" + config.Benchtime,
+		"-test.count=1",
+	}
+	var submissionRounds, referenceRounds []map[string]benchmarkSample
+	cpu := ""
+	for round := 0; round < config.Count; round++ {
+		dirs := []string{submissionDir, referenceDir}
+		if round%2 == 1 {
+			dirs[0], dirs[1] = dirs[1], dirs[0]
+		}
+		for _, dir := range dirs {
+			output, err := runBenchmarkBinary(ctx, dir, args...)
+			if err != nil {
+				who := "Your solution"
+				if dir == referenceDir {
+					who = "The reference solution"
+				}
+				return result(false, fmt.Sprintf("%s failed in benchmark round %d of %d:\n%s", who, round+1, config.Count, output), nil)
+			}
+			samples, machine := parseBenchmarkOutput(output)
+			if machine != "" {
+				cpu = machine
+			}
+			if dir == submissionDir {
+				submissionRounds = append(submissionRounds, samples)
+			} else {
+				referenceRounds = append(referenceRounds, samples)
+			}
+		}
+	}
+
+	report, err := buildBenchmarkReport(submissionRounds, referenceRounds)
+	if err != nil {
+		return result(false, err.Error(), nil)
+	}
+	report.ChallengeID = challenge.ID
+	report.CPU = cpu
+	report.RecordedAt = time.Now().UTC()
+	if version, err := exec.Command("go", "env", "GOVERSION").Output(); err == nil {
+		report.GoVersion = strings.TrimSpace(string(version))
+	}
+
+	return result(true, formatBenchmarkReport(report, config.Count), report)
+}
+
+// buildBenchmarkBinary compiles the challenge's tests with code as the
+// solution into bench.test in a new temporary directory. The directory is
+// returned even on error so the caller can remove it.
+func (es *ExecutionService) buildBenchmarkBinary(ctx context.Context, code string, challenge *models.Challenge) (string, string, error) {
+	dir, err := ioutil.TempDir("", "challenge-bench")
+	if err != nil {
+		return "", fmt.Sprintf("Failed to create temporary directory: %v", err), err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "solution-template.go"), []byte(code), 0644); err != nil {
+		return dir, fmt.Sprintf("Failed to write code file: %v", err), err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "solution_test.go"), []byte(challenge.TestFile), 0644); err != nil {
+		return dir, fmt.Sprintf("Failed to write test file: %v", err), err
+	}
+	if err := es.initGoModule(dir, challenge.ID); err != nil {
+		return dir, fmt.Sprintf("Failed to initialize Go module: %v", err), err
+	}
+	if err := es.installDependencies(dir, code, challenge.ID); err != nil {
+		return dir, fmt.Sprintf("Failed to install dependencies: %v", err), err
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-c", "-o", "bench.test")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return dir, string(output), err
+}
+
+// runBenchmarkBinary runs the test binary built by buildBenchmarkBinary
+func runBenchmarkBinary(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, filepath.Join(dir, "bench.test"), args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(output) + "\n\nTimed out after " + benchmarkTimeout.String() + ".", ctx.Err()
+	}
+	return string(output), err
+}
+
+// parseBenchmarkOutput reads the result lines of one benchmark run, keyed by
+// benchmark name without the -GOMAXPROCS suffix, and the cpu: line
+func parseBenchmarkOutput(output string) (map[string]benchmarkSample, string) {
+	samples := make(map[string]benchmarkSample)
+	cpu := ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "cpu: ") {
+			cpu = strings.TrimPrefix(line, "cpu: ")
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue // a benchmark's log output, not its result
+		}
+
+		var sample benchmarkSample
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				sample.nsPerOp = value
+			case "B/op":
+				sample.bytesPerOp = value
+			case "allocs/op":
+				sample.allocsPerOp = value
+			}
+		}
+		samples[benchmarkSuffixPattern.ReplaceAllString(fields[0], "")] = sample
+	}
+	return samples, cpu
+}
+
+// buildBenchmarkReport pairs each submission round with the reference round
+// run next to it. Memory ratios add one to both sides so that benchmarks
+// that allocate nothing still compare.
+func buildBenchmarkReport(submissionRounds, referenceRounds []map[string]benchmarkSample) (*models.BenchmarkReport, error) {
+	if len(referenceRounds) == 0 || len(referenceRounds[0]) == 0 {
+		return nil, fmt.Errorf("No benchmarks matched the challenge's benchmark pattern.")
+	}
+
+	names := make([]string, 0, len(referenceRounds[0]))
+	for name := range referenceRounds[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rounds := len(referenceRounds)
+	report := &models.BenchmarkReport{Benchmarks: []models.BenchmarkResult{}}
+	timeLogs := make([]float64, rounds)
+	memoryLogs := make([]float64, rounds)
+	allocsLogs := make([]float64, rounds)
+	for _, name := range names {
+		metrics := make([][]float64, 9)
+		for i := range metrics {
+			metrics[i] = make([]float64, rounds)
+		}
+		for r := 0; r < rounds; r++ {
+			sub, ok := submissionRounds[r][name]
+			ref, refOK := referenceRounds[r][name]
+			if !ok || !refOK {
+				return nil, fmt.Errorf("%s did not report a result in every round.", name)
+			}
+			metrics[0][r], metrics[1][r], metrics[2][r] = sub.nsPerOp, sub.bytesPerOp, sub.allocsPerOp
+			metrics[3][r], metrics[4][r], metrics[5][r] = ref.nsPerOp, ref.bytesPerOp, ref.allocsPerOp
+			metrics[6][r] = sub.nsPerOp / math.Max(ref.nsPerOp, 1e-9)
+			metrics[7][r] = (sub.bytesPerOp + 1) / (ref.bytesPerOp + 1)
+			metrics[8][r] = (sub.allocsPerOp + 1) / (ref.allocsPerOp + 1)
+
+			timeLogs[r] += math.Log(metrics[6][r])
+			memoryLogs[r] += math.Log(metrics[7][r])
+			allocsLogs[r] += math.Log(metrics[8][r])
+		}
+
+		stats := make([]models.BenchmarkStat, len(metrics))
+		for i, values := range metrics {
+			stats[i], report.Confidence = medianInterval(values)
+		}
+		report.Benchmarks = append(report.Benchmarks, models.BenchmarkResult{
+			Name:                 name,
+			NsPerOp:              stats[0],
+			BytesPerOp:           stats[1],
+			AllocsPerOp:          stats[2],
+			ReferenceNsPerOp:     stats[3],
+			ReferenceBytesPerOp:  stats[4],
+			ReferenceAllocsPerOp: stats[5],
+			TimeRatio:            stats[6],
+			MemoryRatio:          stats[7],
+			AllocsRatio:          stats[8],
+		})
+	}
+
+	// Each round's score is the geometric mean of its ratios
+	geometricMeans := func(logs []float64) models.BenchmarkStat {
+		means := make([]float64, len(logs))
+		for r, sum := range logs {
+			means[r] = math.Exp(sum / float64(len(names)))
+		}
+		stat, _ := medianInterval(means)
+		return stat
+	}
+	report.TimeScore = geometricMeans(timeLogs)
+	report.MemoryScore = geometricMeans(memoryLogs)
+	report.AllocsScore = geometricMeans(allocsLogs)
+	return report, nil
+}
+
+// medianInterval returns the median of values and a distribution-free
+// confidence interval for it: the order statistics k places in from either
+// end, for the largest k whose coverage, 1 - 2·P(Binomial(n, ½) < k), is at
+// least benchmarkConfidence. Below six values no k gets there and the
+// interval is the whole range. The coverage reached is returned too.
+func medianInterval(values []float64) (models.BenchmarkStat, float64) {
+	n := len(values)
+	if n == 0 {
+		return models.BenchmarkStat{}, 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	// tail accumulates P(Binomial(n, ½) < k)
+	k, coverage := 1, 1-2*math.Pow(0.5, float64(n))
+	tail := math.Pow(0.5, float64(n))
+	for next := 2; next <= (n+1)/2; next++ {
+		tail += binomial(n, next-1) * math.Pow(0.5, float64(n))
+		if 1-2*tail < benchmarkConfidence {
+			break
+		}
+		k, coverage = next, 1-2*tail
+	}
+
+	return models.BenchmarkStat{
+		Median:  median,
+		Low:     sorted[k-1],
+		High:    sorted[n-k],
+		Samples: n,
+	}, coverage
+}
+
+// binomial returns n choose k
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// formatBenchmarkReport renders a report as the text shown in the output pane
+func formatBenchmarkReport(report *models.BenchmarkReport, rounds int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Benchmarks against the reference solution, %d rounds each", rounds)
+	if report.CPU != "" {
+		fmt.Fprintf(&b, " on %s", report.CPU)
+	}
+	fmt.Fprintf(&b, ".\nRatios are yours over the reference (below 1.00x is faster), as medians with %.0f%% intervals.\n\n", report.Confidence*100)
+
+	width := 0
+	for _, result := range report.Benchmarks {
+		if len(result.Name) > width {
+			width = len(result.Name)
+		}
+	}
+	for _, result := range report.Benchmarks {
+		fmt.Fprintf(&b, "%-*s  %12s ns/op  %s time  %s memory  %s allocs\n",
+			width, result.Name,
+			strconv.FormatFloat(result.NsPerOp.Median, 'f', 1, 64),
+			formatRatio(result.TimeRatio), formatRatio(result.MemoryRatio), formatRatio(result.AllocsRatio))
+	}
+
+	fmt.Fprintf(&b, "\nTime score:   %s\n", formatRatio(report.TimeScore))
+	fmt.Fprintf(&b, "Memory score: %s\n", formatRatio(report.MemoryScore))
+	fmt.Fprintf(&b, "Allocs score: %s\n", formatRatio(report.AllocsScore))
+	return b.String()
+}
+
+func formatRatio(stat models.BenchmarkStat) string {
+	return fmt.Sprintf("%.2fx [%.2f-%.2f]", stat.Median, stat.Low, stat.High)
+}
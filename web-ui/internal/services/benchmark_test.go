@@ -0,0 +1,85 @@
+package services
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+// benchOutput is go test -bench output with -benchmem, on a machine with
+// GOMAXPROCS 8, and a benchmark that logs
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: challenge
+cpu: Intel(R) Xeon(R) Processor @ 2.10GHz
+BenchmarkOptimizedSort/100-8         	  239718	       501.8 ns/op	     896 B/op	       1 allocs/op
+BenchmarkOptimizedSort/1000-8        	   26052	      4601 ns/op	    8192 B/op	       1 allocs/op
+BenchmarkExpensiveCalculation/fib-8
+    solution_test.go:40: warming the cache
+BenchmarkExpensiveCalculation/fib-8  	 1000000	      1052 ns/op	       0 B/op	       0 allocs/op
+PASS
+ok  	challenge	4.210s
+`
+
+func TestParseBenchmarkOutput(t *testing.T) {
+	samples, cpu := parseBenchmarkOutput(benchOutput)
+
+	if want := "Intel(R) Xeon(R) Processor @ 2.10GHz"; cpu != want {
+		t.Errorf("cpu = %q, want %q", cpu, want)
+	}
+	want := map[string]benchmarkSample{
+		"BenchmarkOptimizedSort/100":        {nsPerOp: 501.8, bytesPerOp: 896, allocsPerOp: 1},
+		"BenchmarkOptimizedSort/1000":       {nsPerOp: 4601, bytesPerOp: 8192, allocsPerOp: 1},
+		"BenchmarkExpensiveCalculation/fib": {nsPerOp: 1052, bytesPerOp: 0, allocsPerOp: 0},
+	}
+	if !reflect.DeepEqual(samples, want) {
+		t.Errorf("samples:\n got %+v\nwant %+v", samples, want)
+	}
+}
+
+func TestParseBenchmarkOutputWithoutSuffix(t *testing.T) {
+	// GOMAXPROCS 1 prints no -N suffix
+	samples, _ := parseBenchmarkOutput("BenchmarkSlowSort/100 \t     100\t       501.8 ns/op\n")
+	if _, ok := samples["BenchmarkSlowSort/100"]; !ok || len(samples) != 1 {
+		t.Errorf("samples = %+v, want BenchmarkSlowSort/100", samples)
+	}
+}
+
+func TestMedianInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		want     models.BenchmarkStat
+		coverage float64
+	}{
+		{"no values", nil, models.BenchmarkStat{}, 0},
+		{"one value", []float64{5}, models.BenchmarkStat{Median: 5, Low: 5, High: 5, Samples: 1}, 0},
+		// Below six values the whole range is the best interval, short of 95%
+		{"five values", []float64{5, 1, 4, 2, 3}, models.BenchmarkStat{Median: 3, Low: 1, High: 5, Samples: 5}, 1 - 2.0/32},
+		{"six values", []float64{6, 1, 5, 2, 4, 3}, models.BenchmarkStat{Median: 3.5, Low: 1, High: 6, Samples: 6}, 1 - 2.0/64},
+		// P(Binomial(20, ½) <= 5) = 21700/2^20, so k = 6
+		{"twenty values", seq(20), models.BenchmarkStat{Median: 10.5, Low: 6, High: 15, Samples: 20}, 1 - 2*21700.0/(1<<20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, coverage := medianInterval(tt.values)
+			if got != tt.want {
+				t.Errorf("medianInterval = %+v, want %+v", got, tt.want)
+			}
+			if math.Abs(coverage-tt.coverage) > 1e-9 {
+				t.Errorf("coverage = %v, want %v", coverage, tt.coverage)
+			}
+		})
+	}
+}
+
+// seq returns n, n-1, ..., 1: reversed, so medianInterval must sort
+func seq(n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = float64(n - i)
+	}
+	return values
+}
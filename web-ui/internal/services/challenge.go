@@ -15,12 +15,14 @@ import (
 // ChallengeService handles challenge-related operations
 type ChallengeService struct {
 	challenges models.ChallengeMap
+	metadata   map[int]*challengeMetadataSource // challenges that have a metadata.json
 }
 
 // NewChallengeService creates a new challenge service
 func NewChallengeService() *ChallengeService {
 	return &ChallengeService{
 		challenges: make(models.ChallengeMap),
+		metadata:   make(map[int]*challengeMetadataSource),
 	}
 }
 
@@ -54,6 +56,10 @@ func (cs *ChallengeService) LoadChallenges() error {
 		cs.challenges[id] = challenge
 	}
 
+	for _, problem := range cs.ValidateMetadata() {
+		log.Printf("Warning: %s", problem)
+	}
+
 	log.Printf("Loaded %d challenges", len(cs.challenges))
 	return nil
 }
@@ -73,18 +79,48 @@ func (cs *ChallengeService) loadSingleChallenge(id int, dir string) (*models.Cha
 	// Determine difficulty level
 	difficulty := cs.determineDifficulty(id)
 
-	// Read solution template
-	templatePath := filepath.Join(dir, "solution-template.go")
-	templateContent, err := ioutil.ReadFile(templatePath)
-	if err != nil {
-		return nil, fmt.Errorf("could not read solution template: %v", err)
+	// metadata.json, when present, overrides the title and difficulty above.
+	// A broken file is reported by ValidateMetadata rather than failing the load.
+	metadata, rawMetadata, metadataErr := readChallengeMetadata(dir)
+	if rawMetadata != nil {
+		cs.metadata[id] = &challengeMetadataSource{
+			path:        filepath.Join(dir, "metadata.json"),
+			raw:         rawMetadata,
+			metadata:    metadata,
+			err:         metadataErr,
+			readmeTitle: title,
+		}
+	} else if metadataErr != nil {
+		log.Printf("Warning: Could not read metadata for challenge %d: %v", id, metadataErr)
 	}
 
-	// Read test file
-	testPath := filepath.Join(dir, "solution-template_test.go")
-	testContent, err := ioutil.ReadFile(testPath)
-	if err != nil {
-		log.Printf("Warning: Could not read test file for challenge %d: %v", id, err)
+	// A write-tests challenge has no solution to write: the template is the
+	// test file and the code under test is the reference solution
+	var templateContent, testContent []byte
+	var subject string
+	if metadata != nil && metadata.Type == models.ChallengeTypeWriteTests {
+		templateContent, err = ioutil.ReadFile(filepath.Join(dir, "solution-template_test.go"))
+		if err != nil {
+			return nil, fmt.Errorf("could not read test template: %v", err)
+		}
+		subject, err = ReadReference(dir)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the code under test: %v", err)
+		}
+	} else {
+		// Read solution template
+		templatePath := filepath.Join(dir, "solution-template.go")
+		templateContent, err = ioutil.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read solution template: %v", err)
+		}
+
+		// Read test file
+		testPath := filepath.Join(dir, "solution-template_test.go")
+		testContent, err = ioutil.ReadFile(testPath)
+		if err != nil {
+			log.Printf("Warning: Could not read test file for challenge %d: %v", id, err)
+		}
 	}
 
 	// Read learning materials if available
@@ -111,27 +147,65 @@ func (cs *ChallengeService) loadSingleChallenge(id int, dir string) (*models.Cha
 		TestFile:          string(testContent),
 		LearningMaterials: string(learningContent),
 		Hints:             string(hintsContent),
+		Subject:           subject,
+	}
+	if metadata != nil {
+		applyChallengeMetadata(challenge, metadata)
+	}
+
+	// Benchmark scores are relative to the reference solution, and fuzz
+	// targets compare the submission with it
+	if challenge.Benchmark != nil || challenge.Fuzz != nil {
+		if reference, err := ReadReference(dir); err == nil {
+			challenge.Reference = reference
+		}
+	}
+	if challenge.Fuzz != nil {
+		if fuzzTests, err := ioutil.ReadFile(filepath.Join(dir, FuzzTestFile)); err == nil {
+			challenge.FuzzTests = string(fuzzTests)
+		}
+	}
+
+	// A multi-file challenge starts from its files in the challenge directory
+	if challenge.Files != nil {
+		contents, err := ReadFileLayout(dir, challenge.Files)
+		if err != nil {
+			log.Printf("Warning: Could not read the files of challenge %d: %v", id, err)
+			challenge.Files = nil
+		}
+		challenge.FileContents = contents
+	}
+
+	// Hidden tests grade submissions without being served with the challenge
+	if !challenge.WritesTests() {
+		hidden, err := ReadHiddenTests(dir)
+		if err != nil {
+			log.Printf("Warning: Could not read hidden tests for challenge %d: %v", id, err)
+		}
+		challenge.HiddenTests = hidden
 	}
 
 	return challenge, nil
 }
 
-// extractTitle extracts the title from README content
+// extractTitle extracts the title from README content: the first level-one
+// heading, skipping anything before it such as the scoreboard link
 func (cs *ChallengeService) extractTitle(readmeContent string, id int) string {
-	titleRe := regexp.MustCompile(`#\s+(.+)`)
-	titleMatch := titleRe.FindStringSubmatch(readmeContent)
-
-	if len(titleMatch) >= 2 {
-		title := titleMatch[1]
+	for _, line := range strings.Split(readmeContent, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
 		// Clean up the title - remove "Challenge X: " prefix if present
-		cleanTitle := regexp.MustCompile(`^Challenge\s+\d+:\s+`).ReplaceAllString(title, "")
-		return cleanTitle
+		title := strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		return regexp.MustCompile(`^Challenge\s+\d+:\s+`).ReplaceAllString(title, "")
 	}
 
 	return fmt.Sprintf("Challenge %d", id)
 }
 
-// determineDifficulty determines the difficulty level based on challenge ID
+// determineDifficulty determines the difficulty level based on challenge ID.
+// It is the fallback for challenges without a difficulty in metadata.json.
 func (cs *ChallengeService) determineDifficulty(id int) string {
 	switch {
 	case id <= 3 || id == 6 || id == 18 || id == 21 || id == 22:
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+// challengeDifficulties are the difficulty levels, easiest first
+var challengeDifficulties = []string{"Beginner", "Intermediate", "Advanced"}
+
+var (
+	estimatedTimePattern = regexp.MustCompile(`^\d+(-\d+)? (min|hours?)$`)
+	tagPattern           = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	challengeRefPattern  = regexp.MustCompile(`^Challenge (\d+)(?::\s*(.+))?$`)
+	unknownFieldPattern  = regexp.MustCompile(`unknown field "([^"]+)"`)
+	benchtimePattern     = regexp.MustCompile(`^\d+(\.\d+)?(ns|us|µs|ms|s)$`)
+	fuzztimePattern      = regexp.MustCompile(`^(\d+x|\d+(\.\d+)?(ms|s|m))$`)
+	fuzzTargetPattern    = regexp.MustCompile(`^Fuzz[A-Z0-9_]\w*$`)
+)
+
+// MetadataProblem is one inconsistency found in a challenge's metadata.json
+type MetadataProblem struct {
+	ChallengeID int    `json:"challengeId"`
+	Path        string `json:"path"`
+	Field       string `json:"field"` // JSON key the problem is about, "" for the whole file
+	Message     string `json:"message"`
+}
+
+func (p MetadataProblem) String() string {
+	if p.Field == "" {
+		return fmt.Sprintf("%s: %s", p.Path, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Field, p.Message)
+}
+
+// challengeMetadataSource keeps what a challenge was loaded from, so the
+// metadata can be checked against the README and the other challenges once
+// every challenge is loaded
+type challengeMetadataSource struct {
+	path        string
+	raw         []byte
+	metadata    *models.ChallengeMetadata
+	err         error // why metadata is nil
+	readmeTitle string
+}
+
+// readChallengeMetadata reads challenge-N/metadata.json. A missing file is not
+// an error and returns nil metadata.
+func readChallengeMetadata(dir string) (*models.ChallengeMetadata, []byte, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, "metadata.json"))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var metadata models.ChallengeMetadata
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, raw, err
+	}
+	return &metadata, raw, nil
+}
+
+// applyChallengeMetadata copies metadata onto a challenge. Fields the
+// metadata leaves empty keep the values derived from the README and ID.
+func applyChallengeMetadata(challenge *models.Challenge, metadata *models.ChallengeMetadata) {
+	if metadata.Title != "" {
+		challenge.Title = metadata.Title
+	}
+	if metadata.Difficulty != "" {
+		challenge.Difficulty = metadata.Difficulty
+	}
+	challenge.Summary = metadata.Description
+	challenge.ShortDescription = metadata.ShortDescription
+	challenge.EstimatedTime = metadata.EstimatedTime
+	challenge.LearningObjectives = metadata.LearningObjectives
+	challenge.Prerequisites = metadata.Prerequisites
+	challenge.Tags = metadata.Tags
+	challenge.RealWorldConnection = metadata.RealWorldConnection
+	challenge.Requirements = metadata.Requirements
+	challenge.BonusPoints = metadata.BonusPoints
+	challenge.Icon = metadata.Icon
+	challenge.Type = metadata.Type
+	if challenge.WritesTests() {
+		challenge.MinMutationScore = metadata.MinMutationScore
+		if challenge.MinMutationScore == 0 {
+			challenge.MinMutationScore = models.DefaultMinMutationScore
+		}
+	}
+	if metadata.Benchmark != nil {
+		config := *metadata.Benchmark
+		if config.Count == 0 {
+			config.Count = models.DefaultBenchmarkCount
+		}
+		if config.Benchtime == "" {
+			config.Benchtime = models.DefaultBenchmarkBenchtime
+		}
+		challenge.Benchmark = &config
+	}
+	challenge.ConcurrencyChecks = metadata.ConcurrencyChecks
+	if metadata.Fuzz != nil {
+		config := *metadata.Fuzz
+		if config.Fuzztime == "" {
+			config.Fuzztime = models.DefaultFuzztime
+		}
+		challenge.Fuzz = &config
+	}
+	challenge.Files = metadata.Files
+}
+
+// ValidateMetadata checks every loaded metadata.json for fields the loader
+// would ignore, values outside the schema, and disagreements with the README
+// or with the challenges it lists as prerequisites.
+func (cs *ChallengeService) ValidateMetadata() []MetadataProblem {
+	ids := make([]int, 0, len(cs.metadata))
+	for id := range cs.metadata {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var problems []MetadataProblem
+	for _, id := range ids {
+		problems = append(problems, cs.validateChallengeMetadata(id, cs.metadata[id])...)
+	}
+	return problems
+}
+
+func (cs *ChallengeService) validateChallengeMetadata(id int, source *challengeMetadataSource) []MetadataProblem {
+	var problems []MetadataProblem
+	report := func(field, format string, args ...interface{}) {
+		problems = append(problems, MetadataProblem{
+			ChallengeID: id,
+			Path:        source.path,
+			Field:       field,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	if source.metadata == nil {
+		report("", "invalid JSON: %v", source.err)
+		return problems
+	}
+	metadata := source.metadata
+
+	// A misspelt key is silently ignored by the loader, so look for them
+	decoder := json.NewDecoder(bytes.NewReader(source.raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&models.ChallengeMetadata{}); err != nil {
+		if m := unknownFieldPattern.FindStringSubmatch(err.Error()); m != nil {
+			report(m[1], "unknown field")
+		}
+	}
+
+	if metadata.Title != "" && source.readmeTitle != "" && metadata.Title != source.readmeTitle {
+		report("title", "%q does not match the README heading %q", metadata.Title, source.readmeTitle)
+	}
+
+	if metadata.Difficulty != "" && difficultyRank(metadata.Difficulty) < 0 {
+		report("difficulty", "%q is not one of %s", metadata.Difficulty, strings.Join(challengeDifficulties, ", "))
+	}
+
+	if metadata.EstimatedTime != "" && !estimatedTimePattern.MatchString(metadata.EstimatedTime) {
+		report("estimated_time", "%q should look like \"20-30 min\" or \"2 hours\"", metadata.EstimatedTime)
+	}
+
+	if metadata.Order != 0 && metadata.Order != id {
+		report("order", "is %d, but classic challenges are ordered by ID (%d)", metadata.Order, id)
+	}
+
+	switch metadata.Type {
+	case "", models.ChallengeTypeWriteTests:
+	default:
+		report("type", "%q is not a challenge type; leave it out, or use %q", metadata.Type, models.ChallengeTypeWriteTests)
+	}
+	if metadata.MinMutationScore != 0 && metadata.Type != models.ChallengeTypeWriteTests {
+		report("min_mutation_score", "only applies to %q challenges", models.ChallengeTypeWriteTests)
+	}
+	if metadata.MinMutationScore < 0 || metadata.MinMutationScore > 100 {
+		report("min_mutation_score", "%d is not a percentage", metadata.MinMutationScore)
+	}
+	if benchmark := metadata.Benchmark; benchmark != nil {
+		if metadata.Type == models.ChallengeTypeWriteTests {
+			report("benchmark", "does not apply to %q challenges", models.ChallengeTypeWriteTests)
+		}
+		if benchmark.Pattern == "" {
+			report("benchmark", "pattern is required; use \".\" to run every benchmark")
+		} else if _, err := regexp.Compile(benchmark.Pattern); err != nil {
+			report("benchmark", "pattern %q is not a regular expression: %v", benchmark.Pattern, err)
+		}
+		if benchmark.Count < 0 || benchmark.Count > 50 {
+			report("benchmark", "count %d should be between 1 and 50", benchmark.Count)
+		}
+		if benchmark.Benchtime != "" && !benchtimePattern.MatchString(benchmark.Benchtime) {
+			report("benchmark", "benchtime %q should be a duration such as \"50ms\"", benchmark.Benchtime)
+		}
+		if challenge := cs.challenges[id]; challenge != nil && challenge.Reference == "" {
+			report("benchmark", "needs a reference solution in %s to measure against", ReferenceFile)
+		}
+	}
+
+	if checks := metadata.ConcurrencyChecks; checks != nil {
+		if !checks.Race && !checks.Leaks {
+			report("concurrency_checks", "enables neither race nor leaks; leave it out instead")
+		}
+		if metadata.Type == models.ChallengeTypeWriteTests {
+			report("concurrency_checks", "does not apply to %q challenges", models.ChallengeTypeWriteTests)
+		}
+		if challenge := cs.challenges[id]; checks.Leaks && challenge != nil && strings.Contains(challenge.TestFile, "func TestMain(") {
+			report("concurrency_checks", "the leak check adds its own TestMain, and the tests already have one")
+		}
+	}
+
+	if fuzz := metadata.Fuzz; fuzz != nil {
+		if metadata.Type == models.ChallengeTypeWriteTests {
+			report("fuzz", "does not apply to %q challenges", models.ChallengeTypeWriteTests)
+		}
+		if len(fuzz.Targets) == 0 {
+			report("fuzz", "targets is required")
+		}
+		if fuzz.Fuzztime != "" && !fuzztimePattern.MatchString(fuzz.Fuzztime) {
+			report("fuzz", "fuzztime %q should be a duration such as \"10s\" or a count such as \"5000x\"", fuzz.Fuzztime)
+		}
+		if challenge := cs.challenges[id]; challenge != nil {
+			if challenge.Reference == "" {
+				report("fuzz", "needs a reference solution in %s to use as the oracle", ReferenceFile)
+			}
+			if challenge.FuzzTests == "" {
+				report("fuzz", "needs the fuzz targets in %s", FuzzTestFile)
+			} else if !strings.HasPrefix(strings.TrimSpace(challenge.FuzzTests), "//go:build "+FuzzBuildTag) {
+				report("fuzz", "%s should start with \"//go:build %s\" so go test skips it", FuzzTestFile, FuzzBuildTag)
+			}
+			for _, target := range fuzz.Targets {
+				if !fuzzTargetPattern.MatchString(target) {
+					report("fuzz", "target %q is not a fuzz function name", target)
+				} else if challenge.FuzzTests != "" && !strings.Contains(challenge.FuzzTests, "func "+target+"(") {
+					report("fuzz", "target %s is not declared in %s", target, FuzzTestFile)
+				}
+			}
+		}
+	}
+
+	if layout := metadata.Files; layout != nil {
+		if metadata.Type == models.ChallengeTypeWriteTests {
+			report("files", "does not apply to %q challenges", models.ChallengeTypeWriteTests)
+		}
+		if len(layout.Editable)+len(layout.ReadOnly) == 0 {
+			report("files", "lists no files; leave it out instead")
+		}
+		seenPaths := make(map[string]bool)
+		for _, p := range append(append([]string{}, layout.Editable...), layout.ReadOnly...) {
+			if err := CheckSubmissionPath(p); err != nil {
+				report("files", "%v", err)
+				continue
+			}
+			if seenPaths[p] {
+				report("files", "%s is listed twice", p)
+			}
+			seenPaths[p] = true
+			if _, err := os.Stat(filepath.Join(filepath.Dir(source.path), filepath.FromSlash(p))); err != nil {
+				report("files", "%s does not exist in the challenge directory", p)
+			}
+		}
+	}
+
+	seenTags := make(map[string]bool)
+	for _, tag := range metadata.Tags {
+		if !tagPattern.MatchString(tag) {
+			report("tags", "%q should be lowercase words joined by hyphens", tag)
+		}
+		if seenTags[tag] {
+			report("tags", "%q is listed twice", tag)
+		}
+		seenTags[tag] = true
+	}
+
+	// Prerequisites are free text, except "Challenge N" or "Challenge N: Title",
+	// which must point at an existing, no harder challenge
+	challenge := cs.challenges[id]
+	for _, prerequisite := range metadata.Prerequisites {
+		m := challengeRefPattern.FindStringSubmatch(prerequisite)
+		if m == nil {
+			continue
+		}
+		refID, _ := strconv.Atoi(m[1])
+		ref, exists := cs.challenges[refID]
+		switch {
+		case !exists:
+			report("prerequisites", "challenge %d does not exist", refID)
+		case refID == id:
+			report("prerequisites", "challenge %d lists itself", refID)
+		default:
+			if m[2] != "" && m[2] != ref.Title {
+				report("prerequisites", "challenge %d is titled %q, not %q", refID, ref.Title, m[2])
+			}
+			if challenge != nil && difficultyRank(challenge.Difficulty) >= 0 && difficultyRank(ref.Difficulty) > difficultyRank(challenge.Difficulty) {
+				report("prerequisites", "challenge %d (%s) is harder than this %s challenge", refID, ref.Difficulty, challenge.Difficulty)
+			}
+		}
+	}
+
+	return problems
+}
+
+// difficultyRank orders difficulty levels, or returns -1 for an unknown one
+func difficultyRank(difficulty string) int {
+	for i, d := range challengeDifficulties {
+		if d == difficulty {
+			return i
+		}
+	}
+	return -1
+}
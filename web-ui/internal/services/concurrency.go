@@ -0,0 +1,353 @@
+package services
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+const (
+	// leakCheckFile is added next to the tests when the leak check is on
+	leakCheckFile = "leakcheck_test.go"
+	// leakCheckFunc is the helper leakCheckSource declares beside TestMain.
+	// The double underscore keeps it clear of names a solution would use.
+	leakCheckFunc = "__leakCheckGoroutines"
+
+	leakReportStart = "--- GOROUTINE LEAK CHECK ---"
+	leakReportEnd   = "--- END GOROUTINE LEAK CHECK ---"
+	raceReportRule  = "=================="
+
+	// maxRaceReports caps the races kept from one run; a race in a hot loop
+	// is reported again for every pair of goroutines that hits it
+	maxRaceReports = 10
+)
+
+// leakCheckSource is a TestMain that runs the tests, gives the goroutines
+// they started two seconds to finish, and prints the stacks of any that are
+// left between the report markers. Goroutines the runtime and os/signal
+// start for themselves are not counted.
+const leakCheckSource = `package %s
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if leaked := %s(); len(leaked) > 0 {
+		fmt.Println(%q)
+		for _, stack := range leaked {
+			fmt.Println(stack)
+			fmt.Println()
+		}
+		fmt.Println(%q)
+		code = 1
+	}
+	os.Exit(code)
+}
+
+func %s() []string {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		buf := make([]byte, 1<<20)
+		buf = buf[:runtime.Stack(buf, true)]
+
+		var leaked []string
+		// The first stack is this goroutine
+		for _, stack := range strings.Split(string(buf), "\n\n")[1:] {
+			if strings.Contains(stack, "os/signal.") || strings.Contains(stack, "runtime.ensureSigM") {
+				continue
+			}
+			leaked = append(leaked, stack)
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+`
+
+var (
+	goroutineHeaderPattern = regexp.MustCompile(`^goroutine (\d+) \[([^\]]+)\]:$`)
+	raceGoroutinePattern   = regexp.MustCompile(`^Goroutine (\d+) \(([^)]+)\) created at:$`)
+	raceAccessPattern      = regexp.MustCompile(`by (?:goroutine (\d+)|main goroutine):$`)
+	frameLocationPattern   = regexp.MustCompile(`^(\S+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	// Frames in the submission's files are shown relative to its directory
+	tempDirPattern = regexp.MustCompile(`^.*/challenge-exec[^/]*/`)
+)
+
+// CheckConcurrency runs a challenge's concurrency checks against code whose
+// tests already pass. The race detector and the leak check share one run.
+func (es *ExecutionService) CheckConcurrency(code string, files map[string]string, challenge *models.Challenge) *models.ConcurrencyReport {
+	checks := challenge.ConcurrencyChecks
+	if checks == nil {
+		return nil
+	}
+	report := &models.ConcurrencyReport{}
+
+	opts := RunOptions{TestArgs: []string{"-count=1", "-timeout", "2m"}, Files: files}
+	if checks.Leaks {
+		report.Leaks = &models.LeakCheck{Leaked: []models.GoroutineStack{}}
+		pkg, err := parser.ParseFile(token.NewFileSet(), "solution_test.go", challenge.TestFile, parser.PackageClauseOnly)
+		switch {
+		case err != nil:
+			report.Leaks.Status = models.CheckSkipped
+			report.Leaks.Message = fmt.Sprintf("Could not read the test package: %v", err)
+		case declaresFunc(challenge.TestFile, "TestMain", leakCheckFunc):
+			report.Leaks.Status = models.CheckSkipped
+			report.Leaks.Message = "The tests have their own TestMain."
+		case submissionDeclaresFunc(code, files, "TestMain", leakCheckFunc):
+			report.Leaks.Status = models.CheckSkipped
+			report.Leaks.Message = "The solution declares its own TestMain, which the leak check needs to add."
+		default:
+			opts.ExtraFiles = map[string]string{
+				leakCheckFile: fmt.Sprintf(leakCheckSource, pkg.Name.Name, leakCheckFunc, leakReportStart, leakReportEnd, leakCheckFunc),
+			}
+		}
+	}
+	if checks.Race {
+		report.Race = &models.RaceCheck{Races: []models.DataRace{}}
+		opts.TestArgs = append(opts.TestArgs, "-race")
+	}
+
+	result := es.RunCodeWithOptions(code, challenge, opts)
+	if checks.Race && raceUnavailable(result.Output) {
+		// The race detector needs cgo; still run the leak check without it
+		report.Race.Status = models.CheckSkipped
+		report.Race.Message = "The race detector needs cgo and a C compiler, which this machine does not have."
+		opts.TestArgs = opts.TestArgs[:len(opts.TestArgs)-1]
+		if opts.ExtraFiles == nil {
+			return report
+		}
+		result = es.RunCodeWithOptions(code, challenge, opts)
+	}
+
+	if report.Race != nil && report.Race.Status == "" {
+		report.Race.Races = ParseRaceReports(result.Output)
+		report.Race.Status = models.CheckClean
+		if len(report.Race.Races) > 0 {
+			report.Race.Status = models.CheckFailed
+		}
+		if len(report.Race.Races) > maxRaceReports {
+			report.Race.Message = fmt.Sprintf("%d data races; the first %d are shown.", len(report.Race.Races), maxRaceReports)
+			report.Race.Races = report.Race.Races[:maxRaceReports]
+		}
+	}
+	if report.Leaks != nil && report.Leaks.Status == "" {
+		report.Leaks.Leaked = parseLeakReport(result.Output)
+		report.Leaks.Status = models.CheckClean
+		if len(report.Leaks.Leaked) > 0 {
+			report.Leaks.Status = models.CheckFailed
+		}
+	}
+
+	// Tests that passed a moment ago and now fail for some other reason, such
+	// as timing out under the race detector, leave the checks unproven
+	if !result.Passed && !problemFound(report) {
+		message := "The tests did not pass in the checking run:\n" + lastLines(result.Output, 20)
+		if report.Race != nil && report.Race.Status == models.CheckClean {
+			report.Race.Status, report.Race.Message = models.CheckFailed, message
+		}
+		if report.Leaks != nil && report.Leaks.Status == models.CheckClean {
+			report.Leaks.Status, report.Leaks.Message = models.CheckFailed, message
+		}
+	}
+	return report
+}
+
+// declaresFunc reports whether a Go file declares a package-level function
+// with one of the names. A file that does not parse declares nothing.
+func declaresFunc(src string, names ...string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return false
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Recv == nil && containsString(names, fn.Name.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// submissionDeclaresFunc is declaresFunc over the solution and every file
+// submitted with it
+func submissionDeclaresFunc(code string, files map[string]string, names ...string) bool {
+	if declaresFunc(code, names...) {
+		return true
+	}
+	for _, content := range files {
+		if declaresFunc(content, names...) {
+			return true
+		}
+	}
+	return false
+}
+
+// problemFound reports whether either check found a race or a leak
+func problemFound(r *models.ConcurrencyReport) bool {
+	return (r.Race != nil && len(r.Race.Races) > 0) || (r.Leaks != nil && len(r.Leaks.Leaked) > 0)
+}
+
+// raceUnavailable reports whether go test refused -race on this machine
+func raceUnavailable(output string) bool {
+	return strings.Contains(output, "-race requires cgo") ||
+		strings.Contains(output, "C compiler \"gcc\" not found") ||
+		strings.Contains(output, "-race is not supported")
+}
+
+// FormatConcurrencyReport summarises a report for the test output
+func FormatConcurrencyReport(report *models.ConcurrencyReport) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	if race := report.Race; race != nil {
+		switch {
+		case race.Status == models.CheckClean:
+			b.WriteString("Race detector: no data races found\n")
+		case len(race.Races) > 0:
+			fmt.Fprintf(&b, "Race detector: %d data race(s) found\n", len(race.Races))
+			if race.Message != "" {
+				fmt.Fprintf(&b, "  %s\n", race.Message)
+			}
+			for _, r := range race.Races {
+				for _, access := range r.Accesses {
+					location := ""
+					if len(access.Frames) > 0 {
+						frame := access.Frames[0]
+						location = fmt.Sprintf(" in %s (%s:%d)", frame.Func, frame.File, frame.Line)
+					}
+					fmt.Fprintf(&b, "  %s%s\n", access.Description, location)
+				}
+			}
+		default:
+			fmt.Fprintf(&b, "Race detector: %s. %s\n", race.Status, race.Message)
+		}
+	}
+	if leaks := report.Leaks; leaks != nil {
+		switch {
+		case leaks.Status == models.CheckClean:
+			b.WriteString("Goroutine leak check: every goroutine finished\n")
+		case len(leaks.Leaked) > 0:
+			fmt.Fprintf(&b, "Goroutine leak check: %d goroutine(s) still running after the tests\n", len(leaks.Leaked))
+			for _, g := range leaks.Leaked {
+				top := ""
+				if len(g.Frames) > 0 {
+					top = fmt.Sprintf(" in %s (%s:%d)", g.Frames[0].Func, g.Frames[0].File, g.Frames[0].Line)
+				}
+				fmt.Fprintf(&b, "  goroutine %d [%s]%s\n", g.ID, g.State, top)
+			}
+		default:
+			fmt.Fprintf(&b, "Goroutine leak check: %s. %s\n", leaks.Status, leaks.Message)
+		}
+	}
+	return b.String()
+}
+
+// ParseRaceReports reads the WARNING: DATA RACE blocks in go test -race
+// output. Each block is a series of sections separated by blank lines: the
+// conflicting accesses, then where each goroutine involved was created.
+func ParseRaceReports(output string) []models.DataRace {
+	races := []models.DataRace{}
+	lines := strings.Split(output, "\n")
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "WARNING: DATA RACE" {
+			continue
+		}
+		var race models.DataRace
+		for i++; i < len(lines) && strings.TrimSpace(lines[i]) != raceReportRule; i++ {
+			header := strings.TrimSpace(lines[i])
+			if header == "" {
+				continue
+			}
+			var body []string
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], " ") {
+				i++
+				body = append(body, lines[i])
+			}
+			frames := parseFrames(body)
+
+			if m := raceGoroutinePattern.FindStringSubmatch(header); m != nil {
+				id, _ := strconv.Atoi(m[1])
+				race.Goroutines = append(race.Goroutines, models.GoroutineStack{ID: id, State: m[2], Frames: frames})
+				continue
+			}
+			access := models.RaceAccess{Description: strings.TrimSuffix(header, ":"), Frames: frames}
+			if m := raceAccessPattern.FindStringSubmatch(header); m != nil && m[1] != "" {
+				access.Goroutine, _ = strconv.Atoi(m[1])
+			}
+			race.Accesses = append(race.Accesses, access)
+		}
+		races = append(races, race)
+	}
+	return races
+}
+
+// parseLeakReport reads the goroutine stacks the leak check printed
+func parseLeakReport(output string) []models.GoroutineStack {
+	leaked := []models.GoroutineStack{}
+	start := strings.Index(output, leakReportStart)
+	end := strings.Index(output, leakReportEnd)
+	if start < 0 || end < start {
+		return leaked
+	}
+
+	for _, stack := range strings.Split(output[start+len(leakReportStart):end], "\n\n") {
+		lines := strings.Split(strings.TrimSpace(stack), "\n")
+		m := goroutineHeaderPattern.FindStringSubmatch(lines[0])
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+		leaked = append(leaked, models.GoroutineStack{ID: id, State: m[2], Frames: parseFrames(lines[1:])})
+	}
+	return leaked
+}
+
+// parseFrames reads a stack trace of function lines, each followed by an
+// indented file:line. Arguments and program counter offsets are dropped.
+func parseFrames(lines []string) []models.StackFrame {
+	var frames []models.StackFrame
+	for i := 0; i+1 < len(lines); i++ {
+		m := frameLocationPattern.FindStringSubmatch(strings.TrimSpace(lines[i+1]))
+		if m == nil {
+			continue
+		}
+		fn := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(fn, "created by ") {
+			// "created by main.Start in goroutine 6"
+			fn = strings.TrimSpace(strings.SplitN(fn, " in goroutine ", 2)[0])
+		} else if open := strings.LastIndex(fn, "("); open > 0 && strings.HasSuffix(fn, ")") {
+			fn = fn[:open]
+		}
+		line, _ := strconv.Atoi(m[2])
+		frames = append(frames, models.StackFrame{
+			Func: fn,
+			File: tempDirPattern.ReplaceAllString(m[1], ""),
+			Line: line,
+		})
+		i++
+	}
+	return frames
+}
+
+// lastLines returns up to n lines from the end of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
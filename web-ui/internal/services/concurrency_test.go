@@ -0,0 +1,208 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+// raceOutput is go test -race output for two goroutines incrementing a
+// counter, as run in the execution service's temporary directory
+const raceOutput = `=== RUN   TestRace
+==================
+WARNING: DATA RACE
+Read at 0x000000834528 by goroutine 8:
+  main.increment()
+      /tmp/challenge-exec1234567/solution-template.go:13 +0x74
+  main.TestRace.gowrap1()
+      /tmp/challenge-exec1234567/solution-template_test.go:19 +0x2e
+
+Previous write at 0x000000834528 by goroutine 9:
+  main.increment()
+      /tmp/challenge-exec1234567/solution-template.go:13 +0x8c
+  main.TestRace.gowrap2()
+      /tmp/challenge-exec1234567/solution-template_test.go:20 +0x2e
+
+Goroutine 8 (running) created at:
+  main.TestRace()
+      /tmp/challenge-exec1234567/solution-template_test.go:19 +0xbe
+  testing.tRunner()
+      /usr/local/go/src/testing/testing.go:2193 +0x21c
+
+Goroutine 9 (finished) created at:
+  main.TestRace()
+      /tmp/challenge-exec1234567/solution-template_test.go:20 +0x124
+  testing.tRunner()
+      /usr/local/go/src/testing/testing.go:2193 +0x21c
+==================
+==================
+WARNING: DATA RACE
+Write at 0x00c000014100 by main goroutine:
+  main.(*Cache).Set()
+      /tmp/challenge-exec1234567/solution-template.go:31 +0x4c
+
+Previous read at 0x00c000014100 by goroutine 12:
+  main.(*Cache).Get()
+      /tmp/challenge-exec1234567/solution-template.go:25 +0x3a
+
+Goroutine 12 (running) created at:
+  main.TestCache()
+      /tmp/challenge-exec1234567/solution-template_test.go:44 +0x90
+==================
+--- FAIL: TestRace (0.00s)
+    testing.go:1865: race detected during execution of test
+FAIL
+exit status 1
+FAIL	challenge	0.012s
+`
+
+func TestParseRaceReports(t *testing.T) {
+	frame := func(fn, file string, line int) models.StackFrame {
+		return models.StackFrame{Func: fn, File: file, Line: line}
+	}
+	want := []models.DataRace{
+		{
+			Accesses: []models.RaceAccess{
+				{Description: "Read at 0x000000834528 by goroutine 8", Goroutine: 8, Frames: []models.StackFrame{
+					frame("main.increment", "solution-template.go", 13),
+					frame("main.TestRace.gowrap1", "solution-template_test.go", 19),
+				}},
+				{Description: "Previous write at 0x000000834528 by goroutine 9", Goroutine: 9, Frames: []models.StackFrame{
+					frame("main.increment", "solution-template.go", 13),
+					frame("main.TestRace.gowrap2", "solution-template_test.go", 20),
+				}},
+			},
+			Goroutines: []models.GoroutineStack{
+				{ID: 8, State: "running", Frames: []models.StackFrame{
+					frame("main.TestRace", "solution-template_test.go", 19),
+					frame("testing.tRunner", "/usr/local/go/src/testing/testing.go", 2193),
+				}},
+				{ID: 9, State: "finished", Frames: []models.StackFrame{
+					frame("main.TestRace", "solution-template_test.go", 20),
+					frame("testing.tRunner", "/usr/local/go/src/testing/testing.go", 2193),
+				}},
+			},
+		},
+		{
+			Accesses: []models.RaceAccess{
+				{Description: "Write at 0x00c000014100 by main goroutine", Goroutine: 0, Frames: []models.StackFrame{
+					frame("main.(*Cache).Set", "solution-template.go", 31),
+				}},
+				{Description: "Previous read at 0x00c000014100 by goroutine 12", Goroutine: 12, Frames: []models.StackFrame{
+					frame("main.(*Cache).Get", "solution-template.go", 25),
+				}},
+			},
+			Goroutines: []models.GoroutineStack{
+				{ID: 12, State: "running", Frames: []models.StackFrame{
+					frame("main.TestCache", "solution-template_test.go", 44),
+				}},
+			},
+		},
+	}
+
+	got := ParseRaceReports(raceOutput)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRaceReports:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseRaceReportsNone(t *testing.T) {
+	got := ParseRaceReports("=== RUN   TestCounter\n--- PASS: TestCounter (0.01s)\nPASS\nok  \tchallenge\t1.02s\n")
+	if got == nil || len(got) != 0 {
+		t.Errorf("ParseRaceReports without races = %#v, want an empty slice", got)
+	}
+}
+
+func TestLeakCheckSource(t *testing.T) {
+	src := fmt.Sprintf(leakCheckSource, "main", leakCheckFunc, leakReportStart, leakReportEnd, leakCheckFunc)
+	if strings.Contains(src, "%!") {
+		t.Fatalf("leakCheckSource is missing arguments:\n%s", src)
+	}
+	if !declaresFunc(src, "TestMain") || !declaresFunc(src, leakCheckFunc) {
+		t.Errorf("leakCheckSource does not declare TestMain and %s:\n%s", leakCheckFunc, src)
+	}
+}
+
+func TestDeclaresFunc(t *testing.T) {
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"package main\n\nfunc TestMain(m *testing.M) {}\n", true},
+		{"package main\n\nfunc " + leakCheckFunc + "() []string { return nil }\n", true},
+		// Methods, variables and mentions do not clash
+		{"package main\n\ntype suite struct{}\n\nfunc (suite) TestMain() {}\n", false},
+		{"package main\n\nvar TestMainCalled bool\n", false},
+		{"package main\n\n// func TestMain(m *testing.M) is not needed\nfunc Solve() {}\n", false},
+		{"package main\n\nfunc TestMain(", false},
+	}
+	for _, tt := range tests {
+		if got := declaresFunc(tt.src, "TestMain", leakCheckFunc); got != tt.want {
+			t.Errorf("declaresFunc(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+
+	files := map[string]string{"worker.go": "package main\n\nfunc TestMain(m *testing.M) {}\n"}
+	if !submissionDeclaresFunc("package main\n", files, "TestMain") {
+		t.Error("submissionDeclaresFunc missed TestMain in a submitted file")
+	}
+}
+
+func TestCheckConcurrencyLeaks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	const testFile = `package main
+
+import "testing"
+
+func TestStart(t *testing.T) { Start() }
+`
+	tests := []struct {
+		name   string
+		code   string
+		status string
+		leaked int
+	}{
+		{"clean", `package main
+
+func Start() {}
+`, models.CheckClean, 0},
+		{"leak", `package main
+
+func Start() {
+	go func() { select {} }()
+}
+`, models.CheckFailed, 1},
+		// A submission's own TestMain would clash with the leak check's
+		{"TestMain in the solution", `package main
+
+import (
+	"os"
+	"testing"
+)
+
+func Start() {}
+
+func TestMain(m *testing.M) { os.Exit(m.Run()) }
+`, models.CheckSkipped, 0},
+	}
+
+	es := NewExecutionService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := &models.Challenge{
+				TestFile:          testFile,
+				ConcurrencyChecks: &models.ConcurrencyChecks{Leaks: true},
+			}
+			leaks := es.CheckConcurrency(tt.code, nil, challenge).Leaks
+			if leaks.Status != tt.status || len(leaks.Leaked) != tt.leaked {
+				t.Errorf("leak check = %s with %d leaked, want %s with %d: %s", leaks.Status, len(leaks.Leaked), tt.status, tt.leaked, leaks.Message)
+			}
+		})
+	}
+}
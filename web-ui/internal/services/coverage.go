@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+// coverageProfileFile is where RunCodeWithOptions asks go test to write the
+// profile, inside the temporary directory
+const coverageProfileFile = "coverage.out"
+
+// ReadCoverageProfile reads a go test -coverprofile file and reports the
+// statements of one source file, named by its base name. Blocks of other
+// files, such as extra files written next to the solution, are skipped. It
+// returns nil if the profile has no blocks for the file.
+func ReadCoverageProfile(profilePath, fileName string) *models.CoverageReport {
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	report := &models.CoverageReport{File: fileName}
+	covered := make(map[int]bool)
+	uncovered := make(map[int]bool)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// name.go:startLine.startCol,endLine.endCol numStmt count
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 || path.Base(line[:colon]) != fileName {
+			continue
+		}
+		fields := strings.Fields(line[colon+1:])
+		if len(fields) != 3 {
+			continue
+		}
+		span := strings.Split(fields[0], ",")
+		if len(span) != 2 {
+			continue
+		}
+		startLine, err1 := strconv.Atoi(strings.Split(span[0], ".")[0])
+		endLine, err2 := strconv.Atoi(strings.Split(span[1], ".")[0])
+		statements, err3 := strconv.Atoi(fields[1])
+		count, err4 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || statements == 0 {
+			continue
+		}
+
+		report.Statements += statements
+		if count > 0 {
+			report.Covered += statements
+		}
+		for l := startLine; l <= endLine; l++ {
+			if count > 0 {
+				covered[l] = true
+			} else {
+				uncovered[l] = true
+			}
+		}
+	}
+	if report.Statements == 0 {
+		return nil
+	}
+
+	report.Percent = float64(report.Covered*1000/report.Statements) / 10
+	report.CoveredLines = sortedLines(covered)
+	report.UncoveredLines = sortedLines(uncovered)
+	return report
+}
+
+func sortedLines(set map[int]bool) []int {
+	lines := make([]int, 0, len(set))
+	for l := range set {
+		lines = append(lines, l)
+	}
+	sort.Ints(lines)
+	return lines
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Some package challenges have tests that run against a real database server
+// as well as tests against a driver-level mock. The tests find the server
+// from the environment the web UI was started in, which the runs inherit; the
+// MongoDB track, for example, uses MONGODB_TEST_URI or starts MONGOD_BINARY.
+// Without one they skip with a message starting with needsDatabaseMarker.
+const needsDatabaseMarker = "needs database:"
+
+// DatabaseAvailable reports whether tests needing the named database, as in
+// a challenge's metadata, will find a server. No database is always there.
+func DatabaseAvailable(database string) bool {
+	switch database {
+	case "":
+		return true
+	case "mongodb":
+		if os.Getenv("MONGODB_TEST_URI") != "" || os.Getenv("MONGOD_BINARY") != "" {
+			return true
+		}
+		_, err := exec.LookPath("mongod")
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// SkippedForDatabase counts the tests in `go test -v` output that skipped
+// because no database server was available
+func SkippedForDatabase(output string) int {
+	skipped := 0
+	for _, line := range strings.Split(output, "\n") {
+		// The t.Skip message is logged under the test, as "    file.go:12: needs database: ..."
+		if _, msg, ok := strings.Cut(strings.TrimSpace(line), ": "); ok && strings.HasPrefix(msg, needsDatabaseMarker) {
+			skipped++
+		}
+	}
+	return skipped
+}
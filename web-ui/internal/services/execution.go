@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -25,10 +26,83 @@ type ExecutionResult struct {
 	Passed      bool   `json:"passed"`
 	Output      string `json:"output"`
 	ExecutionMs int64  `json:"executionMs"`
+
+	Mutation   *models.MutationReport  `json:"mutation,omitempty"`   // Set by GradeTests
+	Benchmarks *models.BenchmarkReport `json:"benchmarks,omitempty"` // Set by RunBenchmarks
+
+	Concurrency *models.ConcurrencyReport `json:"concurrency,omitempty"` // Set by RunCode when the challenge has checks
+	Coverage    *models.CoverageReport    `json:"coverage,omitempty"`    // Set when RunOptions.Coverage is
+	Hidden      *models.HiddenTestSummary `json:"hidden,omitempty"`      // Set by SubmitCode when the challenge has hidden tests
+	Fuzz        *models.FuzzReport        `json:"fuzz,omitempty"`        // Set by SubmitCode when the challenge has fuzz targets
 }
 
-// RunCode executes the provided code against a challenge's tests
+// RunOptions adjusts a test run beyond the challenge's own files
+type RunOptions struct {
+	// ExtraFiles are written next to the solution and test file, keyed by
+	// file name. They only ever live in the temporary directory.
+	ExtraFiles map[string]string
+	// TestArgs are appended to `go test -v`, e.g. "-run", "^TestFoo$".
+	TestArgs []string
+	// Coverage collects a coverage profile and reports the lines of the
+	// solution file that the tests ran.
+	Coverage bool
+	// Files are the other files of a multi-file submission, keyed by
+	// slash-separated path; see SubmissionFiles
+	Files map[string]string
+}
+
+// RunCode executes the provided code against a challenge's tests. For a
+// write-tests challenge the code is the user's test file, and it is graded
+// by GradeTests instead. Passing code is then put through the challenge's
+// concurrency checks, if it has any; they do not change Passed.
 func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) ExecutionResult {
+	return es.RunFiles(code, nil, challenge)
+}
+
+// RunFiles is RunCode for a multi-file submission: code is the solution
+// file, and files the rest of the challenge's layout, from SubmissionFiles.
+func (es *ExecutionService) RunFiles(code string, files map[string]string, challenge *models.Challenge) ExecutionResult {
+	if challenge.WritesTests() {
+		return es.GradeTests(code, challenge)
+	}
+
+	result := es.RunCodeWithOptions(code, challenge, RunOptions{Coverage: true, Files: files})
+	if result.Passed && challenge.ConcurrencyChecks != nil {
+		start := time.Now()
+		result.Concurrency = es.CheckConcurrency(code, files, challenge)
+		result.Output += FormatConcurrencyReport(result.Concurrency)
+		result.ExecutionMs += time.Since(start).Milliseconds()
+	}
+	return result
+}
+
+// SubmitCode grades a submission: RunCode, then the challenge's hidden tests
+// and, once everything passes, its fuzz targets. Code passes only if it
+// passes the hidden tests too and the fuzzer finds no input it gets wrong.
+func (es *ExecutionService) SubmitCode(code string, files map[string]string, challenge *models.Challenge) ExecutionResult {
+	result := es.RunFiles(code, files, challenge)
+	if challenge.WritesTests() || !TestsRan(result.Output) {
+		return result
+	}
+
+	start := time.Now()
+	if len(challenge.HiddenTests) > 0 {
+		result.Hidden = es.RunHiddenTests(code, files, challenge)
+		result.Passed = result.Passed && result.Hidden.AllPassed()
+		result.Output += FormatHiddenTestSummary(result.Hidden)
+	}
+	if result.Passed && challenge.Fuzz != nil {
+		result.Fuzz = es.RunFuzz(code, files, challenge)
+		result.Passed = !result.Fuzz.Failed()
+		result.Output += FormatFuzzReport(result.Fuzz)
+	}
+	result.ExecutionMs += time.Since(start).Milliseconds()
+	return result
+}
+
+// RunCodeWithOptions executes the provided code against a challenge's tests
+// plus any extra files, passing extra arguments to `go test`.
+func (es *ExecutionService) RunCodeWithOptions(code string, challenge *models.Challenge, opts RunOptions) ExecutionResult {
 	start := time.Now()
 
 	// Create temporary directory for execution
@@ -61,27 +135,73 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 		}
 	}
 
-	// Initialize Go module
-	err = es.initGoModule(tempDir, challenge.ID)
-	if err != nil {
-		return ExecutionResult{
-			Passed: false,
-			Output: fmt.Sprintf("Failed to initialize Go module: %v", err),
+	// Write any extra files, refusing names that would escape the directory
+	// or replace the solution or the official tests
+	for name, content := range opts.ExtraFiles {
+		if name != filepath.Base(name) || name == "solution-template.go" || name == "solution_test.go" {
+			return ExecutionResult{
+				Passed: false,
+				Output: fmt.Sprintf("Invalid extra file name: %s", name),
+			}
+		}
+		err = ioutil.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+		if err != nil {
+			return ExecutionResult{
+				Passed: false,
+				Output: fmt.Sprintf("Failed to write %s: %v", name, err),
+			}
 		}
 	}
 
-	// Automatically detect and install dependencies based on imports
-	err = es.installDependencies(tempDir, code, challenge.ID)
-	if err != nil {
+	// Write the rest of a multi-file submission
+	if err := writeSubmissionFiles(tempDir, opts.Files); err != nil {
 		return ExecutionResult{
 			Passed: false,
-			Output: fmt.Sprintf("Failed to install dependencies: %v", err),
+			Output: fmt.Sprintf("Failed to write submission files: %v", err),
+		}
+	}
+
+	if challenge.Module != nil {
+		// Run in the challenge's own module, as RunModule does, so the
+		// versions are the ones its go.sum pins
+		for name, content := range challenge.Module {
+			if err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+				return ExecutionResult{
+					Passed: false,
+					Output: fmt.Sprintf("Failed to write %s: %v", name, err),
+				}
+			}
+		}
+	} else {
+		// Initialize Go module
+		err = es.initGoModule(tempDir, challenge.ID)
+		if err != nil {
+			return ExecutionResult{
+				Passed: false,
+				Output: fmt.Sprintf("Failed to initialize Go module: %v", err),
+			}
+		}
+
+		// Automatically detect and install dependencies based on imports
+		err = es.installDependencies(tempDir, joinSources(code, opts.Files), challenge.ID)
+		if err != nil {
+			return ExecutionResult{
+				Passed: false,
+				Output: fmt.Sprintf("Failed to install dependencies: %v", err),
+			}
 		}
 	}
 
 	// Run tests
-	cmd := exec.Command("go", "test", "-v")
+	args := append([]string{"test", "-v"}, opts.TestArgs...)
+	if opts.Coverage {
+		args = append(args, "-coverprofile="+coverageProfileFile)
+	}
+	cmd := exec.Command("go", args...)
 	cmd.Dir = tempDir
+	if challenge.Module != nil {
+		cmd.Env = append(os.Environ(), moduleGoFlags)
+	}
 
 	output, err := cmd.CombinedOutput()
 	executionTime := time.Since(start).Milliseconds()
@@ -91,6 +211,9 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 		Output:      outputStr,
 		ExecutionMs: executionTime,
 	}
+	if opts.Coverage {
+		result.Coverage = ReadCoverageProfile(filepath.Join(tempDir, coverageProfileFile), "solution-template.go")
+	}
 
 	if err == nil {
 		result.Passed = true
@@ -109,6 +232,71 @@ func (es *ExecutionService) RunCode(code string, challenge *models.Challenge) Ex
 	return result
 }
 
+// RunModule runs `go test -v` in a temporary directory holding files, keyed
+// by file name. It is for challenges that ship their own go.mod, such as
+// package and release challenges; dependencies are resolved from that go.mod.
+func (es *ExecutionService) RunModule(files map[string]string, opts RunOptions) ExecutionResult {
+	start := time.Now()
+
+	tempDir, err := ioutil.TempDir("", "module-exec")
+	if err != nil {
+		return ExecutionResult{Output: fmt.Sprintf("Failed to create temporary directory: %v", err)}
+	}
+	defer os.RemoveAll(tempDir)
+
+	for name, content := range files {
+		if name != filepath.Base(name) {
+			return ExecutionResult{Output: fmt.Sprintf("Invalid file name: %s", name)}
+		}
+		if err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			return ExecutionResult{Output: fmt.Sprintf("Failed to write %s: %v", name, err)}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", append([]string{"test", "-v", "-count=1"}, opts.TestArgs...)...)
+	cmd.Dir = tempDir
+	cmd.Env = append(os.Environ(), moduleGoFlags)
+
+	output, err := cmd.CombinedOutput()
+	result := ExecutionResult{
+		Passed:      err == nil,
+		Output:      string(output),
+		ExecutionMs: time.Since(start).Milliseconds(),
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		result.Passed = false
+		result.Output += "\n\nTimed out after 3 minutes."
+	}
+	return result
+}
+
+// moduleGoFlags lets go test fill in go.sum entries a challenge module is
+// missing, from the module cache when it has them
+const moduleGoFlags = "GOFLAGS=-mod=mod"
+
+// ReadModule returns the go.mod and go.sum in a challenge directory, keyed
+// by file name, or nil if it has no go.mod
+func ReadModule(challengeDir string) map[string]string {
+	goMod, err := ioutil.ReadFile(filepath.Join(challengeDir, "go.mod"))
+	if err != nil {
+		return nil
+	}
+	module := map[string]string{"go.mod": string(goMod)}
+	if goSum, err := ioutil.ReadFile(filepath.Join(challengeDir, "go.sum")); err == nil {
+		module["go.sum"] = string(goSum)
+	}
+	return module
+}
+
+// TestsRan reports whether `go test -v` output got as far as running a test,
+// as opposed to failing to build
+func TestsRan(output string) bool {
+	return strings.Contains(output, "=== RUN")
+}
+
 // initGoModule initializes a Go module in the temporary directory
 func (es *ExecutionService) initGoModule(tempDir string, challengeID int) error {
 	// Initialize go.mod
@@ -154,7 +342,7 @@ func (es *ExecutionService) detectRequiredPackages(code string, challengeID int)
 	knownPackages := map[string][]string{
 		"github.com/mattn/go-sqlite3": {"github.com/mattn/go-sqlite3"},
 		"database/sql":                {"github.com/mattn/go-sqlite3"}, // SQLite driver needed for database/sql
-		"google.golang.org/grpc":      {"google.golang.org/grpc", "google.golang.org/grpc/codes", "google.golang.org/grpc/status"},
+		"google.golang.org/grpc":      {"google.golang.org/grpc"},      // codes, status and the rest come with the module
 		"github.com/google/uuid":      {"github.com/google/uuid"},
 		"github.com/gorilla/mux":      {"github.com/gorilla/mux"},
 		"github.com/gin-gonic/gin":    {"github.com/gin-gonic/gin"},
@@ -166,9 +354,8 @@ func (es *ExecutionService) detectRequiredPackages(code string, challengeID int)
 
 	// Challenge-specific package requirements
 	challengePackages := map[int][]string{
-		13: {"github.com/mattn/go-sqlite3"},                                                             // SQL Database Operations
-		14: {"google.golang.org/grpc", "google.golang.org/grpc/codes", "google.golang.org/grpc/status"}, // gRPC
-		9:  {"github.com/google/uuid"},                                                                  // RESTful API with UUID
+		13: {"github.com/mattn/go-sqlite3"}, // SQL Database Operations
+		9:  {"github.com/google/uuid"},      // RESTful API with UUID
 	}
 
 	// Add challenge-specific packages
@@ -280,6 +467,11 @@ type SaveSubmissionRequest struct {
 	Username    string `json:"username"`
 	ChallengeID int    `json:"challengeId"`
 	Code        string `json:"code"`
+	// Files are the other editable files of a multi-file challenge, by path
+	Files map[string]string `json:"files,omitempty"`
+	// FileName is set by the handler from the challenge, defaulting to
+	// solution-template.go
+	FileName string `json:"-"`
 }
 
 // SaveSubmissionResponse represents the response from saving a submission
@@ -295,6 +487,11 @@ func (es *ExecutionService) SaveSubmissionToFilesystem(request SaveSubmissionReq
 	// Get working directory for correct relative paths
 	workDir, _ := os.Getwd()
 
+	fileName := request.FileName
+	if fileName == "" {
+		fileName = "solution-template.go"
+	}
+
 	// Try different path approaches to handle potential path issues
 	var submissionDir string
 	var fileSaved bool
@@ -315,11 +512,17 @@ func (es *ExecutionService) SaveSubmissionToFilesystem(request SaveSubmissionReq
 			continue
 		}
 
-		solutionFile := filepath.Join(dirPath, "solution-template.go")
+		solutionFile := filepath.Join(dirPath, fileName)
 		err = ioutil.WriteFile(solutionFile, []byte(request.Code), 0644)
 		if err != nil {
 			continue
 		}
+		if err := writeSubmissionFiles(dirPath, request.Files); err != nil {
+			return SaveSubmissionResponse{
+				Success: false,
+				Message: fmt.Sprintf("Failed to save submission files: %v", err),
+			}
+		}
 
 		submissionDir = dirPath
 		fileSaved = true
@@ -333,14 +536,20 @@ func (es *ExecutionService) SaveSubmissionToFilesystem(request SaveSubmissionReq
 		}
 	}
 
+	// A multi-file submission is added as a whole directory
+	addPath := filepath.Join(fmt.Sprintf("challenge-%d", request.ChallengeID), "submissions", request.Username)
+	if len(request.Files) == 0 {
+		addPath = filepath.Join(addPath, fileName)
+	}
+
 	// Return success response with git commands
 	return SaveSubmissionResponse{
 		Success:  true,
 		Message:  "Solution saved to filesystem",
-		FilePath: filepath.Join(submissionDir, "solution-template.go"),
+		FilePath: filepath.Join(submissionDir, fileName),
 		GitCommands: []string{
 			"cd " + filepath.Join(workDir, ".."),
-			fmt.Sprintf("git add %s", filepath.Join(fmt.Sprintf("challenge-%d", request.ChallengeID), "submissions", request.Username, "solution-template.go")),
+			fmt.Sprintf("git add %s", addPath),
 			fmt.Sprintf("git commit -m \"Add solution for Challenge %d\"", request.ChallengeID),
 			"git push origin main",
 		},
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExternalDataCacheDir is where ExternalData keeps its cache,
+// relative to the web-ui directory
+const DefaultExternalDataCacheDir = ".cache/external-data"
+
+// externalRetryDelay is how long a failed fetch is not retried, so that pages
+// do not start a new request each time while GitHub is unreachable
+const externalRetryDelay = 5 * time.Minute
+
+// ErrOffline is returned when a refresh is asked for in offline mode
+var ErrOffline = errors.New("external data is offline")
+
+// Fetcher fetches one kind of external data, such as the star count of a
+// GitHub repository. The value returned for a name is stored as JSON.
+type Fetcher interface {
+	Fetch(name string) (interface{}, error)
+}
+
+// FetcherFunc adapts a function to the Fetcher interface
+type FetcherFunc func(name string) (interface{}, error)
+
+// Fetch calls f(name)
+func (f FetcherFunc) Fetch(name string) (interface{}, error) {
+	return f(name)
+}
+
+// ExternalData caches data fetched from outside services so that pages never
+// wait on the network. Values are kept in memory and in one JSON file per
+// kind and name under the cache directory, and so survive restarts.
+//
+// Get only ever returns what is cached. A missing or expired value is fetched
+// in the background and shows up on a later Get; until then the caller falls
+// back to what it had before. In offline mode nothing is fetched and cached
+// values are served whatever their age.
+type ExternalData struct {
+	cacheDir   string
+	offline    bool
+	sources    map[string]externalSource // kind -> source
+	entries    map[string]*externalEntry // kind/name -> entry
+	failedAt   map[string]time.Time      // kind/name -> last failed fetch
+	refreshing map[string]bool
+	mutex      sync.Mutex
+	wg         sync.WaitGroup
+}
+
+type externalSource struct {
+	fetcher Fetcher
+	ttl     time.Duration
+}
+
+// externalEntry is one cached value, as stored on disk
+type externalEntry struct {
+	Value     json.RawMessage `json:"value"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+}
+
+// NewExternalData creates an external data cache stored under cacheDir
+func NewExternalData(cacheDir string) *ExternalData {
+	return &ExternalData{
+		cacheDir:   cacheDir,
+		sources:    make(map[string]externalSource),
+		entries:    make(map[string]*externalEntry),
+		failedAt:   make(map[string]time.Time),
+		refreshing: make(map[string]bool),
+	}
+}
+
+// SetOffline turns offline mode on or off
+func (d *ExternalData) SetOffline(offline bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.offline = offline
+}
+
+// Offline reports whether the cache is in offline mode
+func (d *ExternalData) Offline() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.offline
+}
+
+// Register sets the fetcher for a kind of data and how long its values are
+// served before they are fetched again
+func (d *ExternalData) Register(kind string, ttl time.Duration, fetcher Fetcher) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.sources[kind] = externalSource{fetcher: fetcher, ttl: ttl}
+}
+
+// Get decodes the cached value for kind and name into v and reports whether
+// there was one. It never waits on a fetch: a missing or expired value is
+// refreshed in the background.
+func (d *ExternalData) Get(kind, name string, v interface{}) bool {
+	key := externalKey(kind, name)
+
+	d.mutex.Lock()
+	entry := d.entries[key]
+	if entry == nil {
+		entry = d.readEntry(kind, name)
+		if entry != nil {
+			d.entries[key] = entry
+		}
+	}
+	source, registered := d.sources[kind]
+	stale := entry == nil || time.Since(entry.FetchedAt) >= source.ttl
+	if registered && stale && !d.offline && !d.refreshing[key] &&
+		time.Since(d.failedAt[key]) >= externalRetryDelay {
+		d.refreshing[key] = true
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			if err := d.Refresh(kind, name); err != nil {
+				fmt.Printf("Error refreshing %s: %v\n", key, err)
+			}
+		}()
+	}
+	d.mutex.Unlock()
+
+	if entry == nil {
+		return false
+	}
+	return json.Unmarshal(entry.Value, v) == nil
+}
+
+// Refresh fetches the value for kind and name now and caches it. A failed
+// fetch leaves the cached value in place.
+func (d *ExternalData) Refresh(kind, name string) error {
+	key := externalKey(kind, name)
+
+	d.mutex.Lock()
+	source, registered := d.sources[kind]
+	offline := d.offline
+	d.refreshing[key] = true
+	d.mutex.Unlock()
+
+	if !registered {
+		d.finishRefresh(key, nil)
+		return fmt.Errorf("no fetcher registered for %s", kind)
+	}
+	if offline {
+		d.finishRefresh(key, nil)
+		return ErrOffline
+	}
+
+	entry, err := fetchEntry(source.fetcher, name)
+	d.finishRefresh(key, entry)
+	if err != nil {
+		return err
+	}
+	// The value is served from memory even if the disk cache cannot be written
+	if err := d.writeEntry(kind, name, entry); err != nil {
+		return fmt.Errorf("caching %s: %v", key, err)
+	}
+	return nil
+}
+
+// finishRefresh stores a fetched entry, or records a failed fetch when entry
+// is nil
+func (d *ExternalData) finishRefresh(key string, entry *externalEntry) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.refreshing, key)
+	if entry == nil {
+		d.failedAt[key] = time.Now()
+		return
+	}
+	d.entries[key] = entry
+	delete(d.failedAt, key)
+}
+
+func fetchEntry(fetcher Fetcher, name string) (*externalEntry, error) {
+	value, err := fetcher.Fetch(name)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return &externalEntry{Value: raw, FetchedAt: time.Now()}, nil
+}
+
+// RefreshInBackground fetches the value for kind and name again without
+// waiting for it to expire, for example when a webhook says it has changed
+func (d *ExternalData) RefreshInBackground(kind, name string) {
+	if d.Offline() {
+		return
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if err := d.Refresh(kind, name); err != nil {
+			fmt.Printf("Error refreshing %s: %v\n", externalKey(kind, name), err)
+		}
+	}()
+}
+
+// Wait blocks until background refreshes started so far have finished
+func (d *ExternalData) Wait() {
+	d.wg.Wait()
+}
+
+// readEntry loads a cached value from disk. The caller holds the lock.
+func (d *ExternalData) readEntry(kind, name string) *externalEntry {
+	if d.cacheDir == "" {
+		return nil
+	}
+	content, err := os.ReadFile(d.entryPath(kind, name))
+	if err != nil {
+		return nil
+	}
+	var entry externalEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+func (d *ExternalData) writeEntry(kind, name string, entry *externalEntry) error {
+	if d.cacheDir == "" {
+		return nil
+	}
+	path := d.entryPath(kind, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename so a reader never sees half a file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// entryPath is <cacheDir>/<kind>/<name>.json, with slashes in the name
+// replaced so that "gin-gonic/gin" stays one file
+func (d *ExternalData) entryPath(kind, name string) string {
+	file := strings.NewReplacer("/", "__", "\\", "__", "..", "_").Replace(name)
+	return filepath.Join(d.cacheDir, kind, file+".json")
+}
+
+func externalKey(kind, name string) string {
+	return kind + "/" + name
+}
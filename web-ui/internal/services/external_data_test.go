@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// githubStub serves the two GitHub endpoints ExternalData fetches from and
+// counts the requests it gets
+type githubStub struct {
+	*httptest.Server
+	stars    int64
+	requests int64
+	fail     int32
+}
+
+func newGitHubStub(t *testing.T) *githubStub {
+	stub := &githubStub{stars: 42}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&stub.requests, 1)
+		if atomic.LoadInt32(&stub.fail) != 0 {
+			http.Error(w, "rate limited", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/gin-gonic/gin":
+			fmt.Fprintf(w, `{"full_name":"gin-gonic/gin","stargazers_count":%d}`, atomic.LoadInt64(&stub.stars))
+		case "/sponsors/" + SponsorsMaintainer:
+			fmt.Fprintf(w, `<img alt="@%s"><img alt="@alice"><img alt="@bob-2">`, SponsorsMaintainer)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *githubStub) fetcher() *GitHubFetcher {
+	return &GitHubFetcher{APIURL: s.URL, WebURL: s.URL, Client: s.Client()}
+}
+
+func (s *githubStub) requestCount() int64 {
+	return atomic.LoadInt64(&s.requests)
+}
+
+const ginURL = "https://github.com/gin-gonic/gin"
+
+func TestExternalDataFetchesInBackgroundAndCachesOnDisk(t *testing.T) {
+	stub := newGitHubStub(t)
+	dir := t.TempDir()
+
+	github := NewGitHubData(NewExternalData(dir), stub.fetcher())
+	if _, ok := github.Stars(ginURL); ok {
+		t.Fatal("stars served before they were fetched")
+	}
+	github.data.Wait()
+	if stars, ok := github.Stars(ginURL); !ok || stars != 42 {
+		t.Fatalf("stars = %d, %v; want 42, true", stars, ok)
+	}
+
+	// A new process reads the disk cache and, within the TTL, does not fetch
+	before := stub.requestCount()
+	restarted := NewGitHubData(NewExternalData(dir), stub.fetcher())
+	if stars, ok := restarted.Stars(ginURL); !ok || stars != 42 {
+		t.Fatalf("stars after restart = %d, %v; want 42, true", stars, ok)
+	}
+	restarted.data.Wait()
+	if stub.requestCount() != before {
+		t.Errorf("fresh cached value was fetched again")
+	}
+}
+
+func TestExternalDataServesStaleValueWhileRefreshing(t *testing.T) {
+	stub := newGitHubStub(t)
+	data := NewExternalData(t.TempDir())
+	data.Register(GitHubStarsKind, time.Millisecond, FetcherFunc(stub.fetcher().FetchStars))
+
+	if err := data.Refresh(GitHubStarsKind, "gin-gonic/gin"); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt64(&stub.stars, 43)
+	time.Sleep(5 * time.Millisecond)
+
+	var stars int
+	if !data.Get(GitHubStarsKind, "gin-gonic/gin", &stars) || stars != 42 {
+		t.Fatalf("stale stars = %d; want 42 while the refresh runs", stars)
+	}
+	data.Wait()
+	if !data.Get(GitHubStarsKind, "gin-gonic/gin", &stars) || stars != 43 {
+		t.Errorf("refreshed stars = %d; want 43", stars)
+	}
+}
+
+func TestExternalDataKeepsValueWhenFetchFails(t *testing.T) {
+	stub := newGitHubStub(t)
+	data := NewExternalData(t.TempDir())
+	data.Register(GitHubStarsKind, time.Millisecond, FetcherFunc(stub.fetcher().FetchStars))
+
+	if err := data.Refresh(GitHubStarsKind, "gin-gonic/gin"); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&stub.fail, 1)
+	if err := data.Refresh(GitHubStarsKind, "gin-gonic/gin"); err == nil {
+		t.Fatal("refresh against a 403 succeeded")
+	}
+
+	var stars int
+	if !data.Get(GitHubStarsKind, "gin-gonic/gin", &stars) || stars != 42 {
+		t.Errorf("stars after failed refresh = %d; want the cached 42", stars)
+	}
+	data.Wait()
+}
+
+func TestExternalDataOfflineNeverFetches(t *testing.T) {
+	stub := newGitHubStub(t)
+	dir := t.TempDir()
+
+	online := NewGitHubData(NewExternalData(dir), stub.fetcher())
+	if err := online.data.Refresh(GitHubStarsKind, "gin-gonic/gin"); err != nil {
+		t.Fatal(err)
+	}
+	before := stub.requestCount()
+
+	data := NewExternalData(dir)
+	data.SetOffline(true)
+	// A TTL of zero makes every cached value stale
+	data.Register(GitHubStarsKind, 0, FetcherFunc(stub.fetcher().FetchStars))
+	offline := &GitHubData{data: data}
+
+	if stars, ok := offline.Stars(ginURL); !ok || stars != 42 {
+		t.Errorf("offline stars = %d, %v; want the cached 42", stars, ok)
+	}
+	if sponsors := offline.Sponsors(); len(sponsors) != 0 {
+		t.Errorf("offline sponsors = %v; want none, nothing was cached", sponsors)
+	}
+	offline.RefreshSponsors()
+	data.Wait()
+	if err := data.Refresh(GitHubStarsKind, "gin-gonic/gin"); err != ErrOffline {
+		t.Errorf("offline refresh error = %v; want ErrOffline", err)
+	}
+	if stub.requestCount() != before {
+		t.Errorf("offline mode made %d request(s)", stub.requestCount()-before)
+	}
+}
+
+func TestGitHubDataSponsors(t *testing.T) {
+	stub := newGitHubStub(t)
+	github := NewGitHubData(NewExternalData(t.TempDir()), stub.fetcher())
+
+	github.Sponsors()
+	github.data.Wait()
+	sponsors := github.Sponsors()
+	if len(sponsors) != 2 || !sponsors["alice"] || !sponsors["bob-2"] {
+		t.Errorf("sponsors = %v; want alice and bob-2 without the maintainer", sponsors)
+	}
+
+	var nilData *GitHubData
+	if len(nilData.Sponsors()) != 0 {
+		t.Error("nil GitHubData has sponsors")
+	}
+	if _, ok := nilData.Stars(ginURL); ok {
+		t.Error("nil GitHubData has stars")
+	}
+}
+
+// Packages are served with the live star counts on copies, so handlers can
+// read them while other requests get the packages again
+func TestPackageStarsDoNotChangeCachedPackages(t *testing.T) {
+	stub := newGitHubStub(t)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "gin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	packageJSON := fmt.Sprintf(`{"name": "gin", "github_url": %q, "stars": 100}`, ginURL)
+	if err := os.WriteFile(filepath.Join(dir, "gin", "package.json"), []byte(packageJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	github := NewGitHubData(NewExternalData(t.TempDir()), stub.fetcher())
+	github.Stars(ginURL) // starts the fetch
+	github.data.Wait()
+	service := &PackageService{packagesPath: dir}
+	service.UseGitHubData(github)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if stars := service.GetPackages()["gin"].Stars; stars != 42 {
+				t.Errorf("stars = %d; want the fetched 42", stars)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stars := service.cachedPackages["gin"].Stars; stars != 100 {
+		t.Errorf("cached package stars = %d; want package.json's 100", stars)
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+// reservedSubmissionFiles are written by the runner itself and cannot be
+// part of a challenge's file layout
+var reservedSubmissionFiles = map[string]bool{
+	"solution-template.go":      true,
+	"solution-template_test.go": true,
+	"solution_test.go":          true,
+	"go.mod":                    true,
+	"go.sum":                    true,
+	leakCheckFile:               true,
+}
+
+// CheckSubmissionPath returns an error unless p is a clean, slash-separated
+// path inside the submission that the runner does not write itself
+func CheckSubmissionPath(p string) error {
+	switch {
+	case p == "" || p == "." || p != path.Clean(p) || path.IsAbs(p) || strings.Contains(p, `\`):
+		return fmt.Errorf("%q is not a clean relative path", p)
+	case p == ".." || strings.HasPrefix(p, "../"):
+		return fmt.Errorf("%q is outside the submission", p)
+	case reservedSubmissionFiles[p]:
+		return fmt.Errorf("%q is written by the test runner", p)
+	}
+	return nil
+}
+
+// ReadFileLayout reads the starting contents of a challenge's editable and
+// read-only files from its directory
+func ReadFileLayout(challengeDir string, layout *models.FileLayout) (map[string]string, error) {
+	contents := make(map[string]string)
+	for _, p := range append(append([]string{}, layout.Editable...), layout.ReadOnly...) {
+		if err := CheckSubmissionPath(p); err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadFile(filepath.Join(challengeDir, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		contents[p] = string(content)
+	}
+	return contents, nil
+}
+
+// SubmissionFiles combines the editable files a user submitted with the
+// rest of the challenge's layout: read-only files and editable ones left
+// out come from the challenge. Submitting any other path is an error.
+func SubmissionFiles(challenge *models.Challenge, submitted map[string]string) (map[string]string, error) {
+	if challenge.Files == nil {
+		if len(submitted) > 0 {
+			return nil, fmt.Errorf("challenge %d takes a single file", challenge.ID)
+		}
+		return nil, nil
+	}
+	for p := range submitted {
+		if !challenge.Files.IsEditable(p) {
+			return nil, fmt.Errorf("%s is not an editable file of this challenge", p)
+		}
+	}
+
+	files := make(map[string]string, len(challenge.FileContents))
+	for p, content := range challenge.FileContents {
+		files[p] = content
+		if submittedContent, ok := submitted[p]; ok {
+			files[p] = submittedContent
+		}
+	}
+	return files, nil
+}
+
+// writeSubmissionFiles writes a multi-file submission's files under dir,
+// creating their directories
+func writeSubmissionFiles(dir string, files map[string]string) error {
+	for p, content := range files {
+		if err := CheckSubmissionPath(p); err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(target, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinSources returns code followed by every file, for scanning imports
+func joinSources(code string, files map[string]string) string {
+	var b strings.Builder
+	b.WriteString(code)
+	for _, content := range files {
+		b.WriteString("\n")
+		b.WriteString(content)
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+func TestCheckSubmissionPath(t *testing.T) {
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"store.go", true},
+		{"internal/store/store.go", true},
+		{"proto/user.pb.go", true},
+		{"..go", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../x", false},
+		{"../../etc/passwd", false},
+		{"a/../b", false},
+		{"a/../../b", false},
+		{"./a.go", false},
+		{"a//b.go", false},
+		{"a/", false},
+		{"/abs", false},
+		{"/tmp/x.go", false},
+		{`a\b.go`, false},
+		{`..\x.go`, false},
+		{`C:\x.go`, false},
+		{"solution-template.go", false},
+		{"solution-template_test.go", false},
+		{"solution_test.go", false},
+		{"go.mod", false},
+		{"go.sum", false},
+		// Reserved at the top of the submission only
+		{"sub/go.mod", true},
+	}
+
+	for _, tt := range tests {
+		if err := CheckSubmissionPath(tt.path); (err == nil) != tt.ok {
+			t.Errorf("CheckSubmissionPath(%q) = %v, want ok %v", tt.path, err, tt.ok)
+		}
+	}
+}
+
+func TestSubmissionFiles(t *testing.T) {
+	challenge := &models.Challenge{
+		ID: 14,
+		Files: &models.FileLayout{
+			Editable: []string{"store.go", "handlers/users.go"},
+			ReadOnly: []string{"proto/user.pb.go"},
+		},
+		FileContents: map[string]string{
+			"store.go":          "package main // store template\n",
+			"handlers/users.go": "package handlers // users template\n",
+			"proto/user.pb.go":  "package proto // generated\n",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		challenge *models.Challenge
+		submitted map[string]string
+		want      map[string]string // nil with wantErr
+		wantErr   bool
+	}{
+		{
+			name:      "nothing submitted",
+			challenge: challenge,
+			want:      challenge.FileContents,
+		},
+		{
+			name:      "editable file replaced",
+			challenge: challenge,
+			submitted: map[string]string{"store.go": "package main // mine\n"},
+			want: map[string]string{
+				"store.go":          "package main // mine\n",
+				"handlers/users.go": "package handlers // users template\n",
+				"proto/user.pb.go":  "package proto // generated\n",
+			},
+		},
+		{"read-only file", challenge, map[string]string{"proto/user.pb.go": "package proto\n"}, nil, true},
+		{"path outside the layout", challenge, map[string]string{"extra.go": "package main\n"}, nil, true},
+		{"parent directory", challenge, map[string]string{"../x": "package main\n"}, nil, true},
+		{"absolute path", challenge, map[string]string{"/abs": "package main\n"}, nil, true},
+		{"unclean path to an editable file", challenge, map[string]string{"a/../store.go": "package main\n"}, nil, true},
+		{"backslashes", challenge, map[string]string{`handlers\users.go`: "package handlers\n"}, nil, true},
+		{"reserved name", challenge, map[string]string{"go.mod": "module evil\n"}, nil, true},
+		{"runner's solution file", challenge, map[string]string{"solution-template.go": "package main\n"}, nil, true},
+		{"single-file challenge", &models.Challenge{ID: 1}, nil, nil, false},
+		{"files for a single-file challenge", &models.Challenge{ID: 1}, map[string]string{"store.go": "package main\n"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SubmissionFiles(tt.challenge, tt.submitted)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("SubmissionFiles = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SubmissionFiles: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SubmissionFiles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+const (
+	// FuzzTestFile holds a challenge's fuzz targets, relative to the
+	// challenge directory. It starts with a "//go:build fuzzoracle" line, as
+	// it imports the reference solution, which only exists in a fuzz run.
+	FuzzTestFile = "fuzz_test.go"
+	FuzzBuildTag = "fuzzoracle"
+
+	// fuzzOracleDir is the package the reference is written to in a fuzz
+	// run. The targets import it as "challenge-N/oracle".
+	fuzzOracleDir = "oracle"
+	// fuzzTimeout bounds a whole RunFuzz call, builds and minimizing included
+	fuzzTimeout = 5 * time.Minute
+	// fuzzMinimizeTime caps how long the fuzzer shrinks a failing input
+	fuzzMinimizeTime = "10s"
+)
+
+var (
+	failingInputPattern = regexp.MustCompile(`Failing input written to (testdata/fuzz/\S+)`)
+	seedFailurePattern  = regexp.MustCompile(`failure while testing seed corpus entry: (\S+)`)
+	testLogLinePattern  = regexp.MustCompile(`^\s+\S+\.go:\d+: `)
+)
+
+// RunFuzz runs each of a challenge's fuzz targets against code whose tests
+// already pass, with the reference solution as the oracle. A target stops at
+// the first input the submission gets wrong, which the fuzzer minimizes.
+func (es *ExecutionService) RunFuzz(code string, files map[string]string, challenge *models.Challenge) *models.FuzzReport {
+	config := challenge.Fuzz
+	if config == nil {
+		return nil
+	}
+	report := &models.FuzzReport{Fuzztime: config.Fuzztime, Targets: []models.FuzzTargetResult{}}
+	skipAll := func(message string) *models.FuzzReport {
+		for _, target := range config.Targets {
+			report.Targets = append(report.Targets, models.FuzzTargetResult{
+				Name:    target,
+				Status:  models.CheckSkipped,
+				Message: message,
+			})
+		}
+		return report
+	}
+
+	if challenge.Reference == "" || challenge.FuzzTests == "" {
+		return skipAll("The challenge has no reference solution or fuzz targets to fuzz with.")
+	}
+	oracle, err := oracleSource(challenge.Reference)
+	if err != nil {
+		return skipAll(fmt.Sprintf("Could not read the reference solution: %v", err))
+	}
+
+	dir, err := ioutil.TempDir("", "challenge-fuzz")
+	if err != nil {
+		return skipAll(fmt.Sprintf("Failed to create temporary directory: %v", err))
+	}
+	defer os.RemoveAll(dir)
+
+	sources := map[string]string{
+		"solution-template.go": code,
+		"solution_test.go":     challenge.TestFile,
+		FuzzTestFile:           challenge.FuzzTests,
+		filepath.Join(fuzzOracleDir, "solution.go"): oracle,
+	}
+	for name, content := range sources {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return skipAll(fmt.Sprintf("Failed to write %s: %v", name, err))
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			return skipAll(fmt.Sprintf("Failed to write %s: %v", name, err))
+		}
+	}
+	if err := writeSubmissionFiles(dir, files); err != nil {
+		return skipAll(fmt.Sprintf("Failed to write submission files: %v", err))
+	}
+	if err := es.initGoModule(dir, challenge.ID); err != nil {
+		return skipAll(fmt.Sprintf("Failed to initialize Go module: %v", err))
+	}
+	if err := es.installDependencies(dir, joinSources(code, files), challenge.ID); err != nil {
+		return skipAll(fmt.Sprintf("Failed to install dependencies: %v", err))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fuzzTimeout)
+	defer cancel()
+
+	for _, target := range config.Targets {
+		result := models.FuzzTargetResult{Name: target}
+		cmd := exec.CommandContext(ctx, "go", "test",
+			"-tags", FuzzBuildTag,
+			"-run", "^$",
+			"-fuzz", "^"+target+"$",
+			"-fuzztime", config.Fuzztime,
+			"-fuzzminimizetime", fuzzMinimizeTime,
+		)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+
+		switch {
+		case ctx.Err() != nil:
+			result.Status = models.CheckSkipped
+			result.Message = "Timed out after " + fuzzTimeout.String() + "."
+		case err == nil:
+			result.Status = models.CheckClean
+		case strings.Contains(string(output), "--- FAIL:"):
+			result.Status = models.CheckFailed
+			result.Failure = readFuzzFailure(dir, string(output))
+		default:
+			// Tests that pass can still fail to build with the targets
+			result.Status = models.CheckSkipped
+			result.Message = "The fuzz target did not run:\n" + lastLines(string(output), 20)
+		}
+		report.Targets = append(report.Targets, result)
+	}
+	return report
+}
+
+// oracleSource turns the reference solution into the oracle package
+func oracleSource(reference string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "solution.go", reference, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	offset := fset.Position(file.Name.Pos()).Offset
+	return reference[:offset] + fuzzOracleDir + reference[offset+len(file.Name.Name):], nil
+}
+
+// readFuzzFailure reads the input a failed fuzz run wrote under testdata/fuzz
+// and what the target reported about it. A failing seed from f.Add is not
+// written anywhere, so only its name is known.
+func readFuzzFailure(dir, output string) *models.FuzzFailure {
+	failure := &models.FuzzFailure{}
+	if m := failingInputPattern.FindStringSubmatch(output); m != nil {
+		failure.Path = m[1]
+		if content, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(m[1]))); err == nil {
+			failure.Input = string(content)
+		}
+	} else if m := seedFailurePattern.FindStringSubmatch(output); m != nil {
+		failure.Input = "seed corpus entry " + m[1]
+	}
+
+	var messages []string
+	for _, line := range strings.Split(output, "\n") {
+		if testLogLinePattern.MatchString(line) {
+			messages = append(messages, strings.TrimSpace(line))
+		}
+	}
+	failure.Message = strings.Join(messages, "\n")
+	if failure.Message == "" {
+		// A panic rather than a reported error
+		failure.Message = lastLines(strings.Split(output, "Failing input written to")[0], 20)
+	}
+	return failure
+}
+
+// FormatFuzzReport summarises a report for the test output
+func FormatFuzzReport(report *models.FuzzReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nFuzzing against the reference solution (%s per target):\n", report.Fuzztime)
+	for _, target := range report.Targets {
+		switch {
+		case target.Status == models.CheckClean:
+			fmt.Fprintf(&b, "  %s: no failing input found\n", target.Name)
+		case target.Failure != nil:
+			fmt.Fprintf(&b, "  %s: found an input your solution gets wrong\n", target.Name)
+			if target.Failure.Path != "" {
+				fmt.Fprintf(&b, "    %s\n", target.Failure.Path)
+			}
+			for _, line := range strings.Split(strings.TrimSpace(target.Failure.Input), "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+			for _, line := range strings.Split(target.Failure.Message, "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		default:
+			fmt.Fprintf(&b, "  %s: %s. %s\n", target.Name, target.Status, target.Message)
+		}
+	}
+	return b.String()
+}
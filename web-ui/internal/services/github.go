@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of GitHub data kept in ExternalData, and how long each is served
+// before it is fetched again
+const (
+	GitHubStarsKind    = "github-stars"
+	GitHubSponsorsKind = "github-sponsors"
+
+	githubStarsTTL    = 6 * time.Hour
+	githubSponsorsTTL = time.Hour
+)
+
+// SponsorsMaintainer is the account whose sponsors are marked on the
+// leaderboards
+const SponsorsMaintainer = "RezaSi"
+
+// GitHubFetcher fetches repository stars from the GitHub API and sponsors
+// from the public sponsors page. The base URLs can be pointed at a stub.
+type GitHubFetcher struct {
+	APIURL string // https://api.github.com
+	WebURL string // https://github.com
+	Client *http.Client
+}
+
+// NewGitHubFetcher creates a fetcher for github.com
+func NewGitHubFetcher() *GitHubFetcher {
+	return &GitHubFetcher{
+		APIURL: "https://api.github.com",
+		WebURL: "https://github.com",
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// FetchStars returns the star count of a repository given as "owner/repo"
+func (f *GitHubFetcher) FetchStars(repo string) (interface{}, error) {
+	req, err := http.NewRequest("GET", f.APIURL+"/repos/"+repo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Required header for GitHub API
+	req.Header.Set("User-Agent", "go-interview-practice-web-ui/1.0")
+
+	// Optional GitHub token for higher rate limits
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		// Most likely rate limited or missing/invalid auth
+		return nil, fmt.Errorf("GitHub API returned status 403 for %s (rate limited? set GITHUB_TOKEN)", repo)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d for %s", resp.StatusCode, repo)
+	}
+
+	var repoData struct {
+		StargazersCount int `json:"stargazers_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repoData); err != nil {
+		return nil, err
+	}
+	return repoData.StargazersCount, nil
+}
+
+// FetchSponsors returns the sponsors of a maintainer, scraped from their
+// public sponsors page
+func (f *GitHubFetcher) FetchSponsors(maintainer string) (interface{}, error) {
+	req, err := http.NewRequest("GET", f.WebURL+"/sponsors/"+maintainer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set user agent to avoid being blocked
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; GoSponsorScraper/1.0)")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub sponsors page returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseSponsors(string(body), maintainer), nil
+}
+
+var (
+	sponsorAvatarRegex = regexp.MustCompile(`alt="@([a-zA-Z0-9][a-zA-Z0-9\-]*)"`)
+	sponsorLinkRegex   = regexp.MustCompile(`href="/([a-zA-Z0-9][a-zA-Z0-9\-]+)"`)
+)
+
+// parseSponsors extracts sponsor usernames from a sponsors page, sorted
+func parseSponsors(html, maintainer string) []string {
+	sponsorMap := make(map[string]bool)
+
+	// Look for avatar images with alt="@username"
+	for _, match := range sponsorAvatarRegex.FindAllStringSubmatch(html, -1) {
+		// Filter out the repository owner from sponsors list
+		if match[1] != maintainer {
+			sponsorMap[match[1]] = true
+		}
+	}
+
+	// Fallback: if no sponsors found with avatar method, try href patterns
+	if len(sponsorMap) == 0 {
+		for _, match := range sponsorLinkRegex.FindAllStringSubmatch(html, -1) {
+			username := match[1]
+			// Filter out common GitHub paths that aren't usernames
+			if username != "sponsors" && username != "github" && username != maintainer &&
+				len(username) > 2 { // reasonable username length
+				sponsorMap[username] = true
+			}
+		}
+	}
+
+	sponsors := make([]string, 0, len(sponsorMap))
+	for username := range sponsorMap {
+		sponsors = append(sponsors, username)
+	}
+	sort.Strings(sponsors)
+	return sponsors
+}
+
+// GitHubData serves GitHub stars and sponsors from ExternalData. A nil
+// GitHubData has no data, which is what the offline tools want.
+type GitHubData struct {
+	data *ExternalData
+}
+
+// NewGitHubData registers the GitHub fetchers with data
+func NewGitHubData(data *ExternalData, fetcher *GitHubFetcher) *GitHubData {
+	data.Register(GitHubStarsKind, githubStarsTTL, FetcherFunc(fetcher.FetchStars))
+	data.Register(GitHubSponsorsKind, githubSponsorsTTL, FetcherFunc(fetcher.FetchSponsors))
+	return &GitHubData{data: data}
+}
+
+// Stars returns the cached star count of a repository, given by its GitHub
+// URL, and whether there was one
+func (g *GitHubData) Stars(githubURL string) (int, bool) {
+	repo := githubRepo(githubURL)
+	if g == nil || repo == "" {
+		return 0, false
+	}
+	var stars int
+	if !g.data.Get(GitHubStarsKind, repo, &stars) || stars <= 0 {
+		return 0, false
+	}
+	return stars, true
+}
+
+// Sponsors returns the cached sponsors of SponsorsMaintainer
+func (g *GitHubData) Sponsors() map[string]bool {
+	sponsorMap := make(map[string]bool)
+	if g == nil {
+		return sponsorMap
+	}
+	var sponsors []string
+	g.data.Get(GitHubSponsorsKind, SponsorsMaintainer, &sponsors)
+	for _, username := range sponsors {
+		sponsorMap[username] = true
+	}
+	return sponsorMap
+}
+
+// RefreshSponsors fetches the sponsors again in the background
+func (g *GitHubData) RefreshSponsors() {
+	if g != nil {
+		g.data.RefreshInBackground(GitHubSponsorsKind, SponsorsMaintainer)
+	}
+}
+
+// Offline reports whether GitHub data is served from the cache only
+func (g *GitHubData) Offline() bool {
+	return g == nil || g.data.Offline()
+}
+
+// githubRepo turns https://github.com/owner/repo into "owner/repo"
+func githubRepo(githubURL string) string {
+	parts := strings.Split(strings.TrimSuffix(githubURL, "/"), "/")
+	if githubURL == "" || len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+}
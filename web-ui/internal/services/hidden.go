@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+const (
+	// HiddenTestFile holds tests that grade a submission without being shown
+	// to the user, relative to the challenge directory. It sits next to the
+	// public tests, so the scoreboard workflows' go test runs include it.
+	HiddenTestFile = "hidden_test.go"
+	// HiddenTestDir holds further hidden *_test.go files. They are written
+	// next to the solution with a "hidden_" prefix when a submission is graded.
+	HiddenTestDir = "hidden"
+)
+
+var testResultPattern = regexp.MustCompile(`^\s*--- (PASS|FAIL): (\S+)`)
+
+// ReadHiddenTests returns a challenge's hidden test files keyed by the name
+// they are written under next to the solution, or nil if it has none
+func ReadHiddenTests(challengeDir string) (map[string]string, error) {
+	var files map[string]string
+	add := func(name, path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if files == nil {
+			files = make(map[string]string)
+		}
+		files[name] = string(content)
+		return nil
+	}
+
+	if err := add(HiddenTestFile, filepath.Join(challengeDir, HiddenTestFile)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	paths, _ := filepath.Glob(filepath.Join(challengeDir, HiddenTestDir, "*_test.go"))
+	for _, path := range paths {
+		if err := add("hidden_"+filepath.Base(path), path); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// RunHiddenTests runs only the hidden tests of a challenge against code. The
+// public test file is still compiled with them, so they can share helpers.
+func (es *ExecutionService) RunHiddenTests(code string, files map[string]string, challenge *models.Challenge) *models.HiddenTestSummary {
+	names := hiddenTestNames(challenge.HiddenTests)
+	summary := &models.HiddenTestSummary{}
+	if len(names) == 0 {
+		summary.Message = "The hidden tests could not be read."
+		return summary
+	}
+
+	result := es.RunCodeWithOptions(code, challenge, RunOptions{
+		ExtraFiles: challenge.HiddenTests,
+		Files:      files,
+		TestArgs:   []string{"-count=1", "-timeout", "2m", "-run", "^(" + strings.Join(names, "|") + ")$"},
+	})
+	if !TestsRan(result.Output) {
+		summary.Total = len(names)
+		summary.Message = "The hidden tests did not build with this solution."
+		return summary
+	}
+
+	summary.Passed, summary.Total = countTestCases(result.Output)
+	if !result.Passed && summary.Passed == summary.Total {
+		// A panic or a timeout can stop the run before a failure is reported
+		summary.Message = "The hidden tests did not finish."
+	}
+	return summary
+}
+
+// FormatHiddenTestSummary is the line the hidden tests add to the output
+func FormatHiddenTestSummary(summary *models.HiddenTestSummary) string {
+	if summary.Message != "" {
+		return fmt.Sprintf("\nHidden tests: %s\n", summary.Message)
+	}
+	return fmt.Sprintf("\nHidden tests: %d/%d passed\n", summary.Passed, summary.Total)
+}
+
+// hiddenTestNames lists the test functions declared in the hidden files,
+// sorted, leaving out TestMain
+func hiddenTestNames(files map[string]string) []string {
+	var names []string
+	fset := token.NewFileSet()
+	for name, content := range files {
+		file, err := parser.ParseFile(fset, name, content, 0)
+		if err != nil {
+			return nil
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") && fn.Name.Name != "TestMain" {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// countTestCases counts the results in `go test -v` output, taking each
+// subtest as a case in place of the test that runs it
+func countTestCases(output string) (passed, total int) {
+	results := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if m := testResultPattern.FindStringSubmatch(line); m != nil {
+			results[m[2]] = m[1] == "PASS"
+		}
+	}
+	for name, ok := range results {
+		leaf := true
+		for other := range results {
+			if strings.HasPrefix(other, name+"/") {
+				leaf = false
+				break
+			}
+		}
+		if leaf {
+			total++
+			if ok {
+				passed++
+			}
+		}
+	}
+	return passed, total
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+func TestCountTestCases(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		passed, total int
+	}{
+		{"no results", "ok  \tchallenge\t0.002s\n", 0, 0},
+		{"top-level tests", `=== RUN   TestA
+--- PASS: TestA (0.00s)
+=== RUN   TestB
+--- FAIL: TestB (0.00s)
+FAIL
+`, 1, 2},
+		{"subtests replace their parent", `=== RUN   TestSearch
+=== RUN   TestSearch/empty
+=== RUN   TestSearch/found
+--- FAIL: TestSearch (0.00s)
+    --- PASS: TestSearch/empty (0.00s)
+    --- FAIL: TestSearch/found (0.00s)
+=== RUN   TestOther
+--- PASS: TestOther (0.00s)
+FAIL
+`, 2, 3},
+		{"nested subtests count at the leaves", `--- PASS: TestTree (0.00s)
+    --- PASS: TestTree/a (0.00s)
+        --- PASS: TestTree/a/1 (0.00s)
+        --- PASS: TestTree/a/2 (0.00s)
+    --- PASS: TestTree/b (0.00s)
+`, 3, 3},
+		// A failing parent whose subtests all pass (a t.Error after the
+		// t.Run calls) has no leaf of its own
+		{"parent fails after passing subtests", `--- FAIL: TestParent (0.00s)
+    --- PASS: TestParent/only (0.00s)
+`, 1, 1},
+		{"similar names are not subtests", `--- PASS: TestSort (0.00s)
+--- FAIL: TestSortStable (0.00s)
+`, 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passed, total := countTestCases(tt.output)
+			if passed != tt.passed || total != tt.total {
+				t.Errorf("countTestCases = %d/%d, want %d/%d", passed, total, tt.passed, tt.total)
+			}
+		})
+	}
+}
+
+func TestHiddenTestNames(t *testing.T) {
+	files := map[string]string{
+		HiddenTestFile: `package main
+
+import "testing"
+
+func TestMain(m *testing.M) {}
+func TestHiddenB(t *testing.T) {}
+func helper() {}
+`,
+		"hidden_extra_test.go": `package main
+
+import "testing"
+
+type suite struct{}
+
+func (suite) TestMethod(t *testing.T) {}
+func TestHiddenA(t *testing.T) {}
+`,
+	}
+	want := []string{"TestHiddenA", "TestHiddenB"}
+	if got := hiddenTestNames(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("hiddenTestNames = %q, want %q", got, want)
+	}
+
+	files["hidden_broken_test.go"] = "package main\n\nfunc TestBroken("
+	if got := hiddenTestNames(files); got != nil {
+		t.Errorf("hiddenTestNames with a file that does not parse = %q, want nil", got)
+	}
+}
+
+func TestReadHiddenTests(t *testing.T) {
+	dir := t.TempDir()
+	if files, err := ReadHiddenTests(dir); err != nil || files != nil {
+		t.Errorf("ReadHiddenTests without hidden tests = %v, %v, want nil, nil", files, err)
+	}
+
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(dir, HiddenTestFile), "package main // top\n")
+	write(filepath.Join(dir, HiddenTestDir, "edge_test.go"), "package main // edge\n")
+	write(filepath.Join(dir, HiddenTestDir, "notes.md"), "not a test\n")
+
+	files, err := ReadHiddenTests(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		HiddenTestFile:        "package main // top\n",
+		"hidden_edge_test.go": "package main // edge\n",
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ReadHiddenTests = %q, want %q", files, want)
+	}
+}
+
+func TestRunHiddenTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	const code = `package main
+
+func Double(n int) int { return 2 * n }
+`
+	const publicTests = `package main
+
+import "testing"
+
+func TestDouble(t *testing.T) {
+	if Double(2) != 4 {
+		t.Error("Double(2) != 4")
+	}
+}
+`
+	tests := []struct {
+		name   string
+		hidden string
+		want   models.HiddenTestSummary
+	}{
+		{"pass and fail", `package main
+
+import "testing"
+
+func TestHiddenDouble(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		if Double(0) != 0 {
+			t.Error("Double(0) != 0")
+		}
+	})
+	t.Run("wrong", func(t *testing.T) {
+		if Double(3) != 7 {
+			t.Error("Double(3) != 7")
+		}
+	})
+}
+`, models.HiddenTestSummary{Passed: 1, Total: 2}},
+		// The public test is compiled but not run
+		{"only hidden tests run", `package main
+
+import "testing"
+
+func TestHiddenNegative(t *testing.T) {
+	if Double(-1) != -2 {
+		t.Error("Double(-1) != -2")
+	}
+}
+`, models.HiddenTestSummary{Passed: 1, Total: 1}},
+		// The panic ends the run with every reported case passing
+		{"did not finish", `package main
+
+import "testing"
+
+func TestHiddenPanic(t *testing.T) {
+	t.Run("before", func(t *testing.T) {})
+	panic("stop")
+}
+`, models.HiddenTestSummary{Passed: 1, Total: 1, Message: "The hidden tests did not finish."}},
+		{"does not build", `package main
+
+import "testing"
+
+func TestHiddenTriple(t *testing.T) {
+	Triple(1)
+}
+`, models.HiddenTestSummary{Total: 1, Message: "The hidden tests did not build with this solution."}},
+	}
+
+	es := NewExecutionService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := &models.Challenge{
+				TestFile:    publicTests,
+				HiddenTests: map[string]string{HiddenTestFile: tt.hidden},
+			}
+			if got := es.RunHiddenTests(code, nil, challenge); *got != tt.want {
+				t.Errorf("RunHiddenTests = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHiddenTestsWithoutNames(t *testing.T) {
+	challenge := &models.Challenge{HiddenTests: map[string]string{HiddenTestFile: "package main\n"}}
+	got := NewExecutionService().RunHiddenTests("package main\n", nil, challenge)
+	if got.Message != "The hidden tests could not be read." {
+		t.Errorf("RunHiddenTests without tests = %+v", *got)
+	}
+}
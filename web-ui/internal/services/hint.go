@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// aiHintLevels is how many AI rungs sit above a challenge's authored hints.
+const aiHintLevels = 4
+
+// HintService serves one hint ladder per challenge across the classic, package
+// and release tracks. The ladder reveals the authored hints from hints.md
+// first, then escalates to AI hints conditioned on the user's code and failing
+// tests. It remembers, per user, how far up each ladder they have climbed.
+type HintService struct {
+	aiService *AIService
+	progress  models.HintProgressMap
+	mutex     sync.RWMutex
+}
+
+// NewHintService creates a new hint service
+func NewHintService(aiService *AIService) *HintService {
+	return &HintService{
+		aiService: aiService,
+		progress:  make(models.HintProgressMap),
+	}
+}
+
+// HintTarget is a challenge from any track, reduced to what the ladder needs.
+type HintTarget struct {
+	Key       string
+	Challenge *models.Challenge // used for the AI prompt
+	Authored  []models.Hint
+}
+
+// ClassicHintKey returns the ladder key for a classic challenge.
+func ClassicHintKey(id int) string {
+	return fmt.Sprintf("classic/%d", id)
+}
+
+// PackageHintKey returns the ladder key for a package challenge.
+func PackageHintKey(packageName, challengeID string) string {
+	return "package/" + packageName + "/" + challengeID
+}
+
+// ReleaseHintKey returns the ladder key for a release challenge.
+func ReleaseHintKey(version, feature, slug string) string {
+	return "release/" + version + "/" + feature + "/" + slug
+}
+
+// NewHintTarget builds a target from a challenge whose hints are still a
+// single markdown blob, as classic and package challenges store them.
+func NewHintTarget(key string, challenge *models.Challenge) HintTarget {
+	return HintTarget{
+		Key:       key,
+		Challenge: challenge,
+		Authored:  splitHints(challenge.Hints),
+	}
+}
+
+// Total reports how many rungs the target's ladder has.
+func (hs *HintService) Total(target HintTarget) int {
+	total := len(target.Authored)
+	if hs.aiService != nil && hs.aiService.Enabled() {
+		total += aiHintLevels
+	}
+	return total
+}
+
+// Ladder returns the shape of the target's ladder and the hints the user has
+// already revealed, so a page can restore them on load.
+func (hs *HintService) Ladder(username string, target HintTarget) models.HintLadder {
+	ladder := models.HintLadder{
+		Key:       target.Key,
+		Total:     hs.Total(target),
+		Authored:  len(target.Authored),
+		AIEnabled: hs.aiService != nil && hs.aiService.Enabled(),
+		Revealed:  []models.HintStep{},
+	}
+
+	hs.mutex.RLock()
+	defer hs.mutex.RUnlock()
+	if p := hs.progress[username][target.Key]; p != nil {
+		ladder.Revealed = append(ladder.Revealed, p.Steps...)
+	}
+	return ladder
+}
+
+// Reveal returns the hint at the given level. Levels the user has already
+// reached are replayed as they were first shown; the next level up is
+// generated and recorded. Skipping ahead is not allowed.
+//
+// An empty username is served but not tracked.
+func (hs *HintService) Reveal(username string, target HintTarget, level int, code, testOutput string) (models.HintStep, error) {
+	total := hs.Total(target)
+	if level < 1 || level > total {
+		return models.HintStep{}, fmt.Errorf("hint level %d out of range (1-%d)", level, total)
+	}
+
+	hs.mutex.RLock()
+	var reached []models.HintStep
+	if p := hs.progress[username][target.Key]; p != nil {
+		reached = p.Steps
+	}
+	hs.mutex.RUnlock()
+
+	if level <= len(reached) {
+		return reached[level-1], nil
+	}
+	if username != "" && level > len(reached)+1 {
+		return models.HintStep{}, fmt.Errorf("reveal hint %d first", len(reached)+1)
+	}
+
+	step := hs.buildStep(target, level, code, testOutput)
+	if username != "" {
+		hs.record(username, target.Key, step)
+	}
+	return step, nil
+}
+
+// HintsUsed reports how many hints the user has revealed for a challenge.
+func (hs *HintService) HintsUsed(username, key string) int {
+	hs.mutex.RLock()
+	defer hs.mutex.RUnlock()
+	if p := hs.progress[username][key]; p != nil {
+		return len(p.Steps)
+	}
+	return 0
+}
+
+// buildStep produces the hint for one level: an authored hint while there are
+// any left, an AI hint after that.
+func (hs *HintService) buildStep(target HintTarget, level int, code, testOutput string) models.HintStep {
+	if level <= len(target.Authored) {
+		h := target.Authored[level-1]
+		return models.HintStep{Level: level, Source: "authored", Title: h.Title, HTML: h.HTML}
+	}
+
+	shown := make([]string, 0, len(target.Authored))
+	for _, h := range target.Authored {
+		shown = append(shown, h.Title)
+	}
+
+	aiLevel := level - len(target.Authored)
+	text, err := hs.aiService.GetLadderHint(code, target.Challenge, shown, failingTests(testOutput), aiLevel)
+	if err != nil {
+		text = fmt.Sprintf("❌ AI service unavailable: %v", err)
+	}
+	html, _ := RenderMarkdown(text)
+
+	return models.HintStep{
+		Level:         level,
+		Source:        "ai",
+		Title:         fmt.Sprintf("AI hint %d of %d", aiLevel, aiHintLevels),
+		HTML:          html,
+		PromptVersion: hs.aiService.PromptVersion(PromptLadderHint),
+	}
+}
+
+func (hs *HintService) record(username, key string, step models.HintStep) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+
+	if hs.progress[username] == nil {
+		hs.progress[username] = make(map[string]*models.HintProgress)
+	}
+	p := hs.progress[username][key]
+	if p == nil {
+		p = &models.HintProgress{Username: username, Key: key}
+		hs.progress[username][key] = p
+	}
+	// A concurrent request may already have recorded this level.
+	if step.Level == len(p.Steps)+1 {
+		p.Steps = append(p.Steps, step)
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// failingTests trims `go test -v` output down to the failing tests and the
+// messages they printed, which is all the AI needs to see.
+func failingTests(output string) string {
+	const maxLines = 40
+
+	var kept []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "--- FAIL") || strings.Contains(trimmed, "_test.go:") ||
+			strings.HasPrefix(trimmed, "panic:") || strings.Contains(trimmed, "undefined:") {
+			kept = append(kept, trimmed)
+		}
+		if len(kept) == maxLines {
+			break
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+const testHints = `# Hints for Sum
+
+## Hint 1: Start small
+Add the two numbers.
+
+## Hint 2: Return it
+Return the sum.
+`
+
+func newFakeAIService(t *testing.T) *AIService {
+	prompts, err := LoadPromptSet("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai := NewAIServiceWith(LLMConfig{Provider: ProviderFake}, prompts, nil)
+	ai.SetRecorder(func(AICall) {})
+	return ai
+}
+
+func TestHintLadder(t *testing.T) {
+	hs := NewHintService(newFakeAIService(t))
+	target := NewHintTarget(ClassicHintKey(1), &models.Challenge{ID: 1, Title: "Sum", Hints: testHints})
+	if got := hs.Total(target); got != 2+aiHintLevels {
+		t.Fatalf("Total = %d, want %d", got, 2+aiHintLevels)
+	}
+
+	if _, err := hs.Reveal("alice", target, 2, "", ""); err == nil {
+		t.Error("Reveal skipped ahead to level 2")
+	}
+	for _, level := range []int{0, 2 + aiHintLevels + 1} {
+		if _, err := hs.Reveal("alice", target, level, "", ""); err == nil {
+			t.Errorf("Reveal accepted level %d", level)
+		}
+	}
+	if used := hs.HintsUsed("alice", target.Key); used != 0 {
+		t.Errorf("HintsUsed after failed reveals = %d, want 0", used)
+	}
+
+	// The authored hints come first, in order, then the AI hints
+	want := []struct {
+		source, title string
+	}{
+		{"authored", "Start small"},
+		{"authored", "Return it"},
+		{"ai", "AI hint 1 of 4"},
+		{"ai", "AI hint 2 of 4"},
+	}
+	var steps []models.HintStep
+	for i, w := range want {
+		step, err := hs.Reveal("alice", target, i+1, "func Sum(a, b int) int { return 0 }", "--- FAIL: TestSum (0.00s)")
+		if err != nil {
+			t.Fatalf("Reveal(%d): %v", i+1, err)
+		}
+		if step.Level != i+1 || step.Source != w.source || step.Title != w.title {
+			t.Errorf("Reveal(%d) = level %d, %s %q, want %s %q", i+1, step.Level, step.Source, step.Title, w.source, w.title)
+		}
+		if used := hs.HintsUsed("alice", target.Key); used != i+1 {
+			t.Errorf("HintsUsed after level %d = %d", i+1, used)
+		}
+		steps = append(steps, step)
+	}
+	if !strings.Contains(string(steps[0].HTML), "Add the two numbers.") || steps[0].PromptVersion != "" {
+		t.Errorf("authored step = %+v", steps[0])
+	}
+	// The AI rungs are numbered from 1 above the authored hints
+	if !strings.Contains(string(steps[3].HTML), "level 2") || !strings.HasPrefix(steps[3].PromptVersion, PromptLadderHint+"@") {
+		t.Errorf("second AI step = %+v", steps[3])
+	}
+
+	// Levels already reached are replayed, not regenerated or recounted
+	for i, step := range steps {
+		replayed, err := hs.Reveal("alice", target, i+1, "", "")
+		if err != nil || replayed != step {
+			t.Errorf("replay of level %d = %+v, %v, want %+v", i+1, replayed, err, step)
+		}
+	}
+	if used := hs.HintsUsed("alice", target.Key); used != len(steps) {
+		t.Errorf("HintsUsed after replays = %d, want %d", used, len(steps))
+	}
+	if got := hs.Ladder("alice", target).Revealed; len(got) != len(steps) {
+		t.Errorf("Ladder revealed %d steps, want %d", len(got), len(steps))
+	}
+
+	// Progress is per user and per challenge
+	if used := hs.HintsUsed("bob", target.Key); used != 0 {
+		t.Errorf("HintsUsed for another user = %d", used)
+	}
+	if used := hs.HintsUsed("alice", ClassicHintKey(2)); used != 0 {
+		t.Errorf("HintsUsed for another challenge = %d", used)
+	}
+}
+
+func TestHintLadderWithoutAI(t *testing.T) {
+	hs := NewHintService(nil)
+	target := NewHintTarget(ClassicHintKey(1), &models.Challenge{ID: 1, Hints: testHints})
+
+	ladder := hs.Ladder("alice", target)
+	if ladder.Total != 2 || ladder.Authored != 2 || ladder.AIEnabled {
+		t.Errorf("Ladder = %+v, want 2 authored hints and no AI", ladder)
+	}
+	if _, err := hs.Reveal("alice", target, 3, "", ""); err == nil {
+		t.Error("Reveal went past the authored hints without AI")
+	}
+}
+
+// Without a username any level can be read, and nothing is recorded
+func TestHintLadderAnonymous(t *testing.T) {
+	hs := NewHintService(nil)
+	target := NewHintTarget(ClassicHintKey(1), &models.Challenge{ID: 1, Hints: testHints})
+
+	step, err := hs.Reveal("", target, 2, "", "")
+	if err != nil || step.Title != "Return it" {
+		t.Errorf("anonymous Reveal(2) = %+v, %v", step, err)
+	}
+	if used := hs.HintsUsed("", target.Key); used != 0 {
+		t.Errorf("HintsUsed without a username = %d", used)
+	}
+}
+
+// Requests that race to reveal the same level record it once
+func TestHintRevealConcurrent(t *testing.T) {
+	hs := NewHintService(nil)
+	target := NewHintTarget(ClassicHintKey(1), &models.Challenge{ID: 1, Hints: testHints})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hs.Reveal("alice", target, 1, "", "")
+		}()
+	}
+	wg.Wait()
+	if used := hs.HintsUsed("alice", target.Key); used != 1 {
+		t.Errorf("HintsUsed = %d, want 1", used)
+	}
+}
@@ -3,33 +3,36 @@ package services
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
+	"sync"
 
 	"web-ui/internal/models"
 )
 
 type PackageService struct {
-	httpClient   *http.Client
 	packagesPath string
-	// In-memory cache to avoid repeated GitHub API calls (no TTL; load once per process)
+	// In-memory cache of package.json files (no TTL; load once per process)
 	cachedPackages map[string]*models.Package
+	cacheMutex     sync.Mutex
+	github         *GitHubData
 }
 
 func NewPackageService() *PackageService {
 	return &PackageService{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
 		packagesPath:   "../packages", // Relative to web-ui directory
 		cachedPackages: nil,
 	}
 }
 
+// UseGitHubData sets where live GitHub star counts come from. Without it the
+// star counts in package.json are used as they are, as the offline tools do.
+func (s *PackageService) UseGitHubData(github *GitHubData) {
+	s.github = github
+}
+
 type PackageMetadata struct {
 	Name             string   `json:"name"`
 	DisplayName      string   `json:"display_name"`
@@ -45,31 +48,46 @@ type PackageMetadata struct {
 	Tags             []string `json:"tags"`
 	EstimatedTime    string   `json:"estimated_time"`
 	RealWorldUsage   []string `json:"real_world_usage"`
+
+	Versions []models.PackageVersion `json:"versions"`
 }
 
 func (s *PackageService) LoadPackages() error {
 	// This method is called to ensure packages are loaded
 	// Load packages and count them for logging
 	packages := s.GetPackages()
-	fmt.Printf("Loaded %d packages with real-time GitHub stars\n", len(packages))
+	switch {
+	case s.github == nil:
+		fmt.Printf("Loaded %d packages\n", len(packages))
+	case s.github.Offline():
+		fmt.Printf("Loaded %d packages (offline: cached GitHub stars)\n", len(packages))
+	default:
+		fmt.Printf("Loaded %d packages (GitHub stars refresh in the background)\n", len(packages))
+	}
 	return nil
 }
 
 func (s *PackageService) GetPackages() map[string]*models.Package {
-	// Serve from cache if already populated
-	if s.cachedPackages != nil {
-		return s.cachedPackages
-	}
+	// Serve from cache, loading it on first use, with the latest star counts
+	s.cacheMutex.Lock()
+	if s.cachedPackages == nil {
+		s.cachedPackages = s.loadPackages()
+	}
+	cached := s.cachedPackages
+	s.cacheMutex.Unlock()
+	return s.withStars(cached)
+}
 
+// loadPackages reads every package directory
+func (s *PackageService) loadPackages() map[string]*models.Package {
 	packages := make(map[string]*models.Package)
 
 	// Read packages directory
 	entries, err := os.ReadDir(s.packagesPath)
 	if err != nil {
 		fmt.Printf("Error reading packages directory: %v\n", err)
-		// Populate cache (empty) to prevent repeated attempts this run
-		s.cachedPackages = packages
-		return s.cachedPackages
+		// Cache the empty map to prevent repeated attempts this run
+		return packages
 	}
 
 	for _, entry := range entries {
@@ -81,19 +99,26 @@ func (s *PackageService) GetPackages() map[string]*models.Package {
 		}
 	}
 
-	// Populate cache once per process lifetime
-	s.cachedPackages = packages
-	return s.cachedPackages
+	return packages
 }
 
-func (s *PackageService) loadPackage(packagePath, packageName string) *models.Package {
-	// Ensure httpClient is initialized
-	if s.httpClient == nil {
-		s.httpClient = &http.Client{
-			Timeout: 30 * time.Second,
+// withStars returns copies of packages with the cached GitHub star counts
+// in place of the counts from package.json, where there are any yet. The
+// cached packages themselves are never written, as handlers read them
+// concurrently.
+func (s *PackageService) withStars(packages map[string]*models.Package) map[string]*models.Package {
+	starred := make(map[string]*models.Package, len(packages))
+	for name, pkg := range packages {
+		copied := *pkg
+		if stars, ok := s.github.Stars(pkg.GitHubURL); ok {
+			copied.Stars = stars
 		}
+		starred[name] = &copied
 	}
+	return starred
+}
 
+func (s *PackageService) loadPackage(packagePath, packageName string) *models.Package {
 	// Load package.json
 	metadataPath := filepath.Join(packagePath, "package.json")
 	metadataBytes, err := os.ReadFile(metadataPath)
@@ -108,12 +133,6 @@ func (s *PackageService) loadPackage(packagePath, packageName string) *models.Pa
 		return nil
 	}
 
-	// Fetch real-time GitHub stars
-	stars := s.fetchGitHubStars(metadata.GitHubURL)
-	if stars > 0 {
-		metadata.Stars = stars
-	}
-
 	// Load challenge details dynamically
 	challengeDetails := s.loadChallengeDetails(packagePath, metadata.LearningPath)
 
@@ -133,7 +152,20 @@ func (s *PackageService) loadPackage(packagePath, packageName string) *models.Pa
 		EstimatedTime:    metadata.EstimatedTime,
 		RealWorldUsage:   metadata.RealWorldUsage,
 		ChallengeDetails: challengeDetails,
+		Versions:         metadata.Versions,
+		DefaultVersion:   metadata.Version,
+		MigrationNotes:   s.readMigrationNotes(packagePath, metadata.Version),
+	}
+}
+
+// readMigrationNotes returns the notes cmd/bump-package wrote for moving a
+// package to version, as migrations/<from>-to-<version>.md, or "" if none
+func (s *PackageService) readMigrationNotes(packagePath, version string) string {
+	matches, _ := filepath.Glob(filepath.Join(packagePath, "migrations", "*-to-"+version+".md"))
+	if len(matches) == 0 {
+		return ""
 	}
+	return s.readFileContent(matches[len(matches)-1])
 }
 
 // loadChallengeDetails dynamically loads metadata for each challenge in the learning path
@@ -380,6 +412,26 @@ func (s *PackageService) loadChallenge(challengePath, challengeName string) *mod
 		}
 	}
 
+	// Hidden tests are loaded for grading but never shown with the challenge
+	hiddenTests, _ := ReadHiddenTests(challengePath)
+
+	// Read-only files, such as generated code, are run with every submission
+	var files *models.FileLayout
+	var fileContents map[string]string
+	if metadata != nil && metadata.Files != nil && len(metadata.Files.ReadOnly) > 0 {
+		files = &models.FileLayout{ReadOnly: metadata.Files.ReadOnly}
+		contents, err := ReadFileLayout(challengePath, files)
+		if err != nil {
+			fmt.Printf("Warning: Could not read the files of %s: %v\n", challengeName, err)
+			files = nil
+		}
+		fileContents = contents
+	}
+	var database string
+	if metadata != nil {
+		database = metadata.Database
+	}
+
 	return &models.PackageChallenge{
 		ID:                challengeName,
 		Title:             title,
@@ -389,6 +441,11 @@ func (s *PackageService) loadChallenge(challengePath, challengeName string) *mod
 		TestFile:          testFile,
 		Hints:             hints,
 		LearningMaterials: learningMaterials, // Use learning.md for learning materials tab
+		HiddenTests:       hiddenTests,
+		Files:             files,
+		FileContents:      fileContents,
+		Database:          database,
+		Module:            ReadModule(challengePath),
 	}
 }
 
@@ -400,66 +457,6 @@ func (s *PackageService) readFileContent(filePath string) string {
 	return string(content)
 }
 
-func (s *PackageService) fetchGitHubStars(githubURL string) int {
-	// Add more robust nil checking
-	if s == nil || s.httpClient == nil || githubURL == "" {
-		return 0
-	}
-
-	// Extract owner/repo from GitHub URL
-	parts := strings.Split(githubURL, "/")
-	if len(parts) < 2 {
-		return 0
-	}
-
-	repo := fmt.Sprintf("%s/%s", parts[len(parts)-2], parts[len(parts)-1])
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s", repo)
-
-	// Build request with optional auth and proper headers
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return 0
-	}
-
-	// Required header for GitHub API
-	req.Header.Set("User-Agent", "go-interview-practice-web-ui/1.0")
-
-	// Optional GitHub token for higher rate limits
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		token = os.Getenv("GH_TOKEN")
-	}
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return 0
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 403 {
-		// Most likely rate limited or missing/invalid auth
-		fmt.Printf("GitHub API returned status 403 for %s\n", githubURL)
-		return 0
-	}
-
-	if resp.StatusCode != 200 {
-		return 0
-	}
-
-	var repoData struct {
-		StargazersCount int `json:"stargazers_count"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&repoData); err != nil {
-		return 0
-	}
-
-	return repoData.StargazersCount
-}
-
 func (s *PackageService) GetPackage(packageID string) (*models.Package, error) {
 	packages := s.GetPackages()
 	if pkg, exists := packages[packageID]; exists {
@@ -482,6 +479,62 @@ func (s *PackageService) GetChallenge(packageID, challengeID string) *models.Pac
 }
 
 func (s *PackageService) GetPackageChallenges(packageID string) (map[string]*models.PackageChallenge, error) {
+	return s.GetPackageChallengesVersion(packageID, "")
+}
+
+func (s *PackageService) GetPackageChallenge(packageID, challengeID string) (*models.PackageChallenge, error) {
+	return s.GetPackageChallengeVersion(packageID, "", challengeID)
+}
+
+// challengeSetPath returns the directory holding a package's challenges for
+// version: the package directory itself for the default version (or ""),
+// otherwise the path of the matching entry in its package.json "versions"
+func (s *PackageService) challengeSetPath(packageID, version string) (string, error) {
+	packagePath := filepath.Join(s.packagesPath, packageID)
+	if version == "" {
+		return packagePath, nil
+	}
+
+	pkg, err := s.GetPackage(packageID)
+	if err != nil {
+		return "", err
+	}
+	if version == pkg.DefaultVersion {
+		return packagePath, nil
+	}
+	for _, v := range pkg.Versions {
+		if v.Version == version {
+			return filepath.Join(packagePath, filepath.FromSlash(v.Path)), nil
+		}
+	}
+	return "", fmt.Errorf("package %s has no challenges for version %s", packageID, version)
+}
+
+// GetPackageVersion returns a package with the challenge set for version
+// selected: Version, ChallengeDetails and MigrationNotes describe that set.
+// An empty or default version returns the package as GetPackage does.
+func (s *PackageService) GetPackageVersion(packageID, version string) (*models.Package, error) {
+	pkg, err := s.GetPackage(packageID)
+	if err != nil || version == "" || version == pkg.DefaultVersion {
+		return pkg, err
+	}
+
+	setPath, err := s.challengeSetPath(packageID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy, so the cached package keeps its default set
+	selected := *pkg
+	selected.Version = version
+	selected.ChallengeDetails = s.loadChallengeDetails(setPath, pkg.LearningPath)
+	selected.MigrationNotes = s.readMigrationNotes(filepath.Join(s.packagesPath, packageID), version)
+	return &selected, nil
+}
+
+// GetPackageChallengesVersion is GetPackageChallenges for the challenge set
+// of a version
+func (s *PackageService) GetPackageChallengesVersion(packageID, version string) (map[string]*models.PackageChallenge, error) {
 	packagePath := filepath.Join(s.packagesPath, packageID)
 
 	// Check if package directory exists
@@ -489,7 +542,12 @@ func (s *PackageService) GetPackageChallenges(packageID string) (map[string]*mod
 		return nil, fmt.Errorf("package %s not found", packageID)
 	}
 
-	challengesList := s.loadChallenges(packagePath)
+	setPath, err := s.challengeSetPath(packageID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	challengesList := s.loadChallenges(setPath)
 	challenges := make(map[string]*models.PackageChallenge)
 
 	for _, challenge := range challengesList {
@@ -501,10 +559,16 @@ func (s *PackageService) GetPackageChallenges(packageID string) (map[string]*mod
 	return challenges, nil
 }
 
-func (s *PackageService) GetPackageChallenge(packageID, challengeID string) (*models.PackageChallenge, error) {
+// GetPackageChallengeVersion is GetPackageChallenge for the challenge set of
+// a version
+func (s *PackageService) GetPackageChallengeVersion(packageID, version, challengeID string) (*models.PackageChallenge, error) {
+	setPath, err := s.challengeSetPath(packageID, version)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load challenge directly from filesystem
-	packagePath := filepath.Join(s.packagesPath, packageID)
-	challengePath := filepath.Join(packagePath, challengeID)
+	challengePath := filepath.Join(setPath, challengeID)
 
 	// Check if challenge directory exists
 	if _, err := os.Stat(challengePath); os.IsNotExist(err) {
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+// Package learning path achievements, in the order they are listed
+const (
+	AchievementFirstSolve   = "🌱 First Solve"
+	AchievementFirstTry     = "🎯 First Try"
+	AchievementHalfway      = "🚀 Halfway There"
+	AchievementPathComplete = "🏆 Path Complete"
+)
+
+// PackageProgressService records package challenge submissions and works out
+// each user's progress along a package's learning path. Progress is kept in
+// memory and only counts for the user's own view; a solution saved under
+// packages/<package>/<challenge>/submissions/<username>/ also counts as a
+// completed challenge, and only saved solutions count on the leaderboards.
+type PackageProgressService struct {
+	packageService *PackageService
+	packagesPath   string
+	firstTries     map[userPackage]map[string]bool // challenge ID -> passed with the first submission
+	progress       models.PackageProgressMap
+	mutex          sync.RWMutex
+}
+
+// userPackage keys what is recorded of one user's submissions to a package
+type userPackage struct {
+	username, packageName string
+}
+
+// NewPackageProgressService creates a new package progress service
+func NewPackageProgressService(packageService *PackageService) *PackageProgressService {
+	return &PackageProgressService{
+		packageService: packageService,
+		packagesPath:   packageService.packagesPath,
+		firstTries:     make(map[userPackage]map[string]bool),
+		progress:       make(models.PackageProgressMap),
+	}
+}
+
+// LearningPath returns a package's challenges in learning path order
+func (ps *PackageProgressService) LearningPath(packageName string) (*models.Package, []*models.PackageChallenge, error) {
+	pkg, err := ps.packageService.GetPackage(packageName)
+	if err != nil {
+		return nil, nil, err
+	}
+	challengesMap, err := ps.packageService.GetPackageChallenges(packageName)
+	if err != nil {
+		challengesMap = make(map[string]*models.PackageChallenge)
+	}
+
+	var challenges []*models.PackageChallenge
+	for _, id := range pkg.LearningPath {
+		if ch, ok := challengesMap[id]; ok {
+			challenges = append(challenges, ch)
+		}
+	}
+	return pkg, challenges, nil
+}
+
+// RecordSubmission updates the user's progress in a submission's package.
+// Of the submission itself only whether it passed is kept, and only if it is
+// the user's first to the challenge.
+func (ps *PackageProgressService) RecordSubmission(submission models.PackageSubmission) {
+	if submission.Username == "" {
+		return
+	}
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	key := userPackage{submission.Username, submission.PackageName}
+	if ps.firstTries[key] == nil {
+		ps.firstTries[key] = make(map[string]bool)
+	}
+	if _, attempted := ps.firstTries[key][submission.ChallengeID]; !attempted {
+		ps.firstTries[key][submission.ChallengeID] = submission.Passed
+	}
+
+	if ps.progress[submission.Username] == nil {
+		ps.progress[submission.Username] = make(map[string]*models.PackageProgress)
+	}
+	progress := ps.progress[submission.Username][submission.PackageName]
+	if progress == nil {
+		progress = &models.PackageProgress{
+			Username:            submission.Username,
+			PackageName:         submission.PackageName,
+			CompletedChallenges: []string{},
+			StartedAt:           submission.SubmittedAt,
+			Achievements:        []string{},
+		}
+		ps.progress[submission.Username][submission.PackageName] = progress
+	}
+	progress.LastActivity = submission.SubmittedAt
+	progress.TotalTime = progress.LastActivity.Sub(progress.StartedAt)
+
+	if !submission.Passed {
+		progress.InProgress = submission.ChallengeID
+		return
+	}
+	if progress.InProgress == submission.ChallengeID {
+		progress.InProgress = ""
+	}
+	if !containsString(progress.CompletedChallenges, submission.ChallengeID) {
+		progress.CompletedChallenges = append(progress.CompletedChallenges, submission.ChallengeID)
+	}
+}
+
+// GetProgress returns a user's progress along a package's learning path,
+// with the score as the percentage of the path completed
+func (ps *PackageProgressService) GetProgress(username, packageName string) (*models.PackageProgress, error) {
+	_, challenges, err := ps.LearningPath(packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.mutex.RLock()
+	progress := &models.PackageProgress{
+		Username:     username,
+		PackageName:  packageName,
+		Achievements: []string{},
+	}
+	recorded := ps.progress[username][packageName]
+	if recorded != nil {
+		*progress = *recorded
+		progress.Achievements = []string{}
+	}
+	firstTry := ps.passedFirstTry(username, packageName)
+	ps.mutex.RUnlock()
+
+	// Completed challenges are listed in learning path order
+	completed := []string{}
+	for _, challenge := range challenges {
+		_, saved := ps.savedSolutionTime(packageName, challenge.ID, username)
+		if saved || (recorded != nil && containsString(recorded.CompletedChallenges, challenge.ID)) {
+			completed = append(completed, challenge.ID)
+		}
+	}
+	progress.CompletedChallenges = completed
+	if containsString(completed, progress.InProgress) {
+		progress.InProgress = ""
+	}
+
+	if len(challenges) > 0 {
+		progress.Score = len(completed) * 100 / len(challenges)
+	}
+	if len(completed) > 0 {
+		progress.Achievements = append(progress.Achievements, AchievementFirstSolve)
+	}
+	if firstTry {
+		progress.Achievements = append(progress.Achievements, AchievementFirstTry)
+	}
+	if len(challenges) > 0 && len(completed)*2 >= len(challenges) {
+		progress.Achievements = append(progress.Achievements, AchievementHalfway)
+	}
+	if len(challenges) > 0 && len(completed) == len(challenges) {
+		progress.Achievements = append(progress.Achievements, AchievementPathComplete)
+	}
+	return progress, nil
+}
+
+// passedFirstTry reports whether the user passed a challenge of the package
+// with their first submission to it. The caller holds the read lock.
+func (ps *PackageProgressService) passedFirstTry(username, packageName string) bool {
+	for _, passed := range ps.firstTries[userPackage{username, packageName}] {
+		if passed {
+			return true
+		}
+	}
+	return false
+}
+
+// Leaderboard ranks the users of a package by the solutions to the challenges
+// of its learning path they have saved, earliest last save first on a tie,
+// then by username. Submissions recorded in memory are left out: their
+// username is whatever the submitter sent.
+func (ps *PackageProgressService) Leaderboard(packageName string, challenges []*models.PackageChallenge, sponsors map[string]bool) []models.PackageScoreboardEntry {
+	completed := make(map[string]int) // username -> saved solutions
+	lastCompleted := make(map[string]time.Time)
+	for _, challenge := range challenges {
+		for _, username := range ps.submittedUsers(packageName, challenge.ID) {
+			modTime, ok := ps.savedSolutionTime(packageName, challenge.ID, username)
+			if !ok {
+				continue
+			}
+			completed[username]++
+			if modTime.After(lastCompleted[username]) {
+				lastCompleted[username] = modTime
+			}
+		}
+	}
+
+	var leaderboard []models.PackageScoreboardEntry
+	for username, count := range completed {
+		leaderboard = append(leaderboard, models.PackageScoreboardEntry{
+			Username:    username,
+			PackageName: packageName,
+			ChallengeID: "", // Not specific to one challenge
+			SubmittedAt: lastCompleted[username],
+			TestsPassed: count,
+			TestsTotal:  len(challenges),
+			IsSponsor:   sponsors[username],
+		})
+	}
+
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].TestsPassed != leaderboard[j].TestsPassed {
+			return leaderboard[i].TestsPassed > leaderboard[j].TestsPassed
+		}
+		if !leaderboard[i].SubmittedAt.Equal(leaderboard[j].SubmittedAt) {
+			return leaderboard[i].SubmittedAt.Before(leaderboard[j].SubmittedAt)
+		}
+		return leaderboard[i].Username < leaderboard[j].Username
+	})
+	return leaderboard
+}
+
+// submittedUsers lists the users with a submissions directory for a challenge
+func (ps *PackageProgressService) submittedUsers(packageName, challengeID string) []string {
+	entries, err := ioutil.ReadDir(filepath.Join(ps.packagesPath, packageName, challengeID, "submissions"))
+	if err != nil {
+		return nil
+	}
+	var users []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			users = append(users, entry.Name())
+		}
+	}
+	return users
+}
+
+// savedSolutionTime returns when a user's saved solution to a package
+// challenge was last written, as solution.go or solution-template.go
+func (ps *PackageProgressService) savedSolutionTime(packageName, challengeID, username string) (time.Time, bool) {
+	if username == "" {
+		return time.Time{}, false
+	}
+	userDir := filepath.Join(ps.packagesPath, packageName, challengeID, "submissions", username)
+	for _, name := range []string{"solution.go", "solution-template.go"} {
+		if stat, err := os.Stat(filepath.Join(userDir, name)); err == nil {
+			return stat.ModTime(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
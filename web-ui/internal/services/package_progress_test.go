@@ -0,0 +1,206 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+var testLearningPath = []string{
+	"challenge-1-basic-routing",
+	"challenge-2-middleware",
+	"challenge-3-validation-errors",
+	"challenge-4-authentication",
+}
+
+// newTestProgressService returns a progress service for a "demo" package
+// with testLearningPath in a temporary packages directory
+func newTestProgressService(t *testing.T) *PackageProgressService {
+	dir := t.TempDir()
+	packagePath := filepath.Join(dir, "demo")
+	for _, id := range testLearningPath {
+		if err := os.MkdirAll(filepath.Join(packagePath, id), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packageJSON := `{"name": "demo", "learning_path": ["challenge-1-basic-routing", "challenge-2-middleware", "challenge-3-validation-errors", "challenge-4-authentication"]}`
+	if err := os.WriteFile(filepath.Join(packagePath, "package.json"), []byte(packageJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return NewPackageProgressService(&PackageService{packagesPath: dir})
+}
+
+// saveSolution writes a user's solution to a challenge of the demo package,
+// last modified at modTime
+func saveSolution(t *testing.T, ps *PackageProgressService, challengeID, username string, modTime time.Time) {
+	userDir := filepath.Join(ps.packagesPath, "demo", challengeID, "submissions", username)
+	if err := os.MkdirAll(userDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(userDir, "solution.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	ps := newTestProgressService(t)
+	_, challenges, err := ps.LearningPath("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	saveSolution(t, ps, testLearningPath[0], "bob", early)
+	saveSolution(t, ps, testLearningPath[1], "bob", late)
+	saveSolution(t, ps, testLearningPath[0], "carol", early)
+	saveSolution(t, ps, testLearningPath[1], "carol", early)
+	saveSolution(t, ps, testLearningPath[2], "alice", early)
+	saveSolution(t, ps, testLearningPath[3], "alice", early)
+	saveSolution(t, ps, testLearningPath[0], "dave", late)
+	// A submissions directory without a solution does not count
+	os.MkdirAll(filepath.Join(ps.packagesPath, "demo", testLearningPath[1], "submissions", "dave"), 0755)
+
+	// Submissions in memory carry whatever username was sent, so they only
+	// count towards that user's progress
+	for _, id := range testLearningPath {
+		ps.RecordSubmission(models.PackageSubmission{Username: "mallory", PackageName: "demo", ChallengeID: id, Passed: true, SubmittedAt: early})
+	}
+	ps.RecordSubmission(models.PackageSubmission{Username: "dave", PackageName: "demo", ChallengeID: testLearningPath[2], Passed: true, SubmittedAt: early})
+
+	leaderboard := ps.Leaderboard("demo", challenges, map[string]bool{"carol": true})
+
+	type rank struct {
+		Username string
+		Solved   int
+		At       time.Time
+		Sponsor  bool
+	}
+	var got []rank
+	for _, entry := range leaderboard {
+		if entry.TestsTotal != len(testLearningPath) {
+			t.Errorf("%s: TestsTotal = %d, want %d", entry.Username, entry.TestsTotal, len(testLearningPath))
+		}
+		got = append(got, rank{entry.Username, entry.TestsPassed, entry.SubmittedAt.UTC(), entry.IsSponsor})
+	}
+	// Ties on solved challenges go to the earliest last save, then by username
+	want := []rank{
+		{"alice", 2, early, false},
+		{"carol", 2, early, true},
+		{"bob", 2, late, false},
+		{"dave", 1, late, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Leaderboard = %+v,\nwant %+v", got, want)
+	}
+}
+
+func TestPassedFirstTry(t *testing.T) {
+	first, second := testLearningPath[0], testLearningPath[1]
+	tests := []struct {
+		name        string
+		submissions []models.PackageSubmission
+		want        bool
+	}{
+		{"no submissions", nil, false},
+		{"passed first", []models.PackageSubmission{
+			{Username: "alice", ChallengeID: first, Passed: true},
+		}, true},
+		{"failed then passed", []models.PackageSubmission{
+			{Username: "alice", ChallengeID: first},
+			{Username: "alice", ChallengeID: first, Passed: true},
+		}, false},
+		{"failed one, passed another first", []models.PackageSubmission{
+			{Username: "alice", ChallengeID: first},
+			{Username: "alice", ChallengeID: first, Passed: true},
+			{Username: "alice", ChallengeID: second, Passed: true},
+		}, true},
+		{"another user's first try", []models.PackageSubmission{
+			{Username: "bob", ChallengeID: first, Passed: true},
+			{Username: "alice", ChallengeID: first},
+		}, false},
+		{"another package", []models.PackageSubmission{
+			{Username: "alice", PackageName: "other", ChallengeID: first, Passed: true},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := newTestProgressService(t)
+			for _, submission := range tt.submissions {
+				if submission.PackageName == "" {
+					submission.PackageName = "demo"
+				}
+				ps.RecordSubmission(submission)
+			}
+			if got := ps.passedFirstTry("alice", "demo"); got != tt.want {
+				t.Errorf("passedFirstTry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetProgressAchievements(t *testing.T) {
+	tests := []struct {
+		completed int
+		score     int
+		want      []string
+	}{
+		{0, 0, []string{}},
+		{1, 25, []string{AchievementFirstSolve}},
+		{2, 50, []string{AchievementFirstSolve, AchievementHalfway}},
+		{3, 75, []string{AchievementFirstSolve, AchievementHalfway}},
+		{4, 100, []string{AchievementFirstSolve, AchievementHalfway, AchievementPathComplete}},
+	}
+
+	for _, tt := range tests {
+		ps := newTestProgressService(t)
+		// Fail every challenge first, so First Try is not earned
+		for _, id := range testLearningPath[:tt.completed] {
+			ps.RecordSubmission(models.PackageSubmission{Username: "alice", PackageName: "demo", ChallengeID: id})
+			ps.RecordSubmission(models.PackageSubmission{Username: "alice", PackageName: "demo", ChallengeID: id, Passed: true})
+		}
+
+		progress, err := ps.GetProgress("alice", "demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if progress.Score != tt.score {
+			t.Errorf("%d completed: Score = %d, want %d", tt.completed, progress.Score, tt.score)
+		}
+		if !reflect.DeepEqual(progress.Achievements, tt.want) {
+			t.Errorf("%d completed: Achievements = %q, want %q", tt.completed, progress.Achievements, tt.want)
+		}
+		if want := testLearningPath[:tt.completed]; !reflect.DeepEqual(progress.CompletedChallenges, want) {
+			t.Errorf("%d completed: CompletedChallenges = %q, want %q", tt.completed, progress.CompletedChallenges, want)
+		}
+	}
+}
+
+// A saved solution counts towards a user's progress without any submission
+func TestGetProgressCountsSavedSolutions(t *testing.T) {
+	ps := newTestProgressService(t)
+	saveSolution(t, ps, testLearningPath[3], "alice", time.Now())
+	ps.RecordSubmission(models.PackageSubmission{Username: "alice", PackageName: "demo", ChallengeID: testLearningPath[1], Passed: true})
+
+	progress, err := ps.GetProgress("alice", "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{testLearningPath[1], testLearningPath[3]}
+	if !reflect.DeepEqual(progress.CompletedChallenges, want) {
+		t.Errorf("CompletedChallenges = %q, want %q", progress.CompletedChallenges, want)
+	}
+	wantAchievements := []string{AchievementFirstSolve, AchievementFirstTry, AchievementHalfway}
+	if !reflect.DeepEqual(progress.Achievements, wantAchievements) {
+		t.Errorf("Achievements = %q, want %q", progress.Achievements, wantAchievements)
+	}
+}
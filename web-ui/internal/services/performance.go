@@ -0,0 +1,86 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"web-ui/internal/models"
+)
+
+// BenchmarkResultFile is where cmd/run-benchmarks saves a submission's
+// BenchmarkReport, next to the solution in challenge-N/submissions/<username>/
+const BenchmarkResultFile = "benchmark.json"
+
+// PerformanceService builds the performance leaderboards of benchmark
+// challenges from the reports saved with their submissions
+type PerformanceService struct {
+	scoreboardService *ScoreboardService
+}
+
+// NewPerformanceService creates a new performance service
+func NewPerformanceService(scoreboardService *ScoreboardService) *PerformanceService {
+	return &PerformanceService{scoreboardService: scoreboardService}
+}
+
+// Leaderboard ranks a challenge's saved benchmark reports by time score,
+// fastest first. Reports are read on every call, so scores written by
+// cmd/run-benchmarks show up without a restart.
+func (ps *PerformanceService) Leaderboard(challenge *models.Challenge) ([]models.PerformanceEntry, error) {
+	if challenge.Benchmark == nil {
+		return nil, fmt.Errorf("challenge %d is not scored on performance", challenge.ID)
+	}
+
+	pattern := filepath.Join("..", fmt.Sprintf("challenge-%d", challenge.ID), "submissions", "*", BenchmarkResultFile)
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.PerformanceEntry{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var report models.BenchmarkReport
+		if err := json.Unmarshal(data, &report); err != nil || report.TimeScore.Samples == 0 {
+			continue
+		}
+
+		username := filepath.Base(filepath.Dir(path))
+		entries = append(entries, models.PerformanceEntry{
+			Username:    username,
+			Passed:      ps.scoreboardService.HasPassed(username, challenge.ID),
+			TimeScore:   report.TimeScore,
+			MemoryScore: report.MemoryScore,
+			AllocsScore: report.AllocsScore,
+			RecordedAt:  report.RecordedAt,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].TimeScore.Median != entries[j].TimeScore.Median {
+			return entries[i].TimeScore.Median < entries[j].TimeScore.Median
+		}
+		return entries[i].Username < entries[j].Username
+	})
+	rankByOverlap(entries)
+	return entries, nil
+}
+
+// rankByOverlap gives entries sorted by time score their ranks. An entry
+// whose interval overlaps that of the first entry at the current rank is
+// tied with it, so noise between two equally fast solutions cannot reorder
+// the leaderboard from one run to the next.
+func rankByOverlap(entries []models.PerformanceEntry) {
+	leader := 0
+	for i := range entries {
+		if i == 0 || entries[i].TimeScore.Low > entries[leader].TimeScore.High {
+			leader = i
+		}
+		entries[i].Rank = leader + 1
+	}
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"web-ui/internal/models"
+)
+
+//go:embed prompts/*.tmpl
+var embeddedPrompts embed.FS
+
+// Prompt template names. Each is prompts/<name>.tmpl.
+const (
+	PromptCodeReview   = "code_review"
+	PromptQuestions    = "questions"
+	PromptLadderHint   = "ladder_hint"
+	PromptSuggestTests = "suggest_tests"
+	PromptApproaches   = "approaches"
+)
+
+// promptVersionPattern finds the version a template declares in its first
+// comment, e.g. {{- /* version: v2 */ -}}
+var promptVersionPattern = regexp.MustCompile(`^\{\{-?\s*/\*\s*version:\s*(\S+)\s*\*/`)
+
+// PromptData is what every prompt template is rendered with. Not every
+// template uses every field.
+type PromptData struct {
+	Title        string
+	Description  string
+	Code         string
+	Context      string // code review: interview context
+	UserProgress string // questions
+	HintLevel    int
+	HintType     string   // how direct the hint should be at HintLevel
+	ShownHints   []string // ladder hint: titles of the authored hints already read
+	FailingTests string   // ladder hint
+	TestFile     string   // suggest tests: the official test file
+	TestPackage  string   // suggest tests
+	TestFunc     string   // suggest tests
+	HelperPrefix string   // suggest tests
+	Approaches   []models.SolutionApproach
+}
+
+// Prompt is one loaded prompt template
+type Prompt struct {
+	Name    string `json:"name"`
+	Version string `json:"version"` // "<name>@<declared version>-<content hash>"
+	Source  string `json:"source"`  // "embedded" or the override file's path
+	tmpl    *template.Template
+}
+
+// PromptSet holds every prompt the AI service uses
+type PromptSet struct {
+	prompts map[string]*Prompt
+}
+
+var promptFuncs = template.FuncMap{
+	"inc":  func(i int) int { return i + 1 },
+	"join": strings.Join,
+}
+
+// LoadPromptSet loads the embedded prompt templates, then replaces any that
+// have a file of the same name in dir. An empty dir means embedded only. An
+// override that does not match a known prompt is an error, so a typo in a
+// file name cannot silently leave the embedded prompt in place.
+func LoadPromptSet(dir string) (*PromptSet, error) {
+	ps := &PromptSet{prompts: make(map[string]*Prompt)}
+
+	embedded, err := fs.Glob(embeddedPrompts, "prompts/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range embedded {
+		content, err := embeddedPrompts.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := ps.add(path, "embedded", string(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return ps, nil
+	}
+
+	overrides, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range overrides {
+		name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+		if ps.prompts[name] == nil {
+			return nil, fmt.Errorf("%s: no prompt named %q", path, name)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := ps.add(path, path, string(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	return ps, nil
+}
+
+func (ps *PromptSet) add(path, source, content string) error {
+	name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+
+	tmpl, err := template.New(name).Funcs(promptFuncs).Parse(content)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	declared := "unversioned"
+	if m := promptVersionPattern.FindStringSubmatch(content); m != nil {
+		declared = m[1]
+	}
+	sum := sha256.Sum256([]byte(content))
+
+	ps.prompts[name] = &Prompt{
+		Name:    name,
+		Version: fmt.Sprintf("%s@%s-%s", name, declared, hex.EncodeToString(sum[:])[:8]),
+		Source:  source,
+		tmpl:    tmpl,
+	}
+	return nil
+}
+
+// Render fills in a prompt and returns it with the version that produced it
+func (ps *PromptSet) Render(name string, data PromptData) (string, string, error) {
+	prompt := ps.prompts[name]
+	if prompt == nil {
+		return "", "", fmt.Errorf("no prompt named %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := prompt.tmpl.Execute(&buf, data); err != nil {
+		return "", prompt.Version, fmt.Errorf("prompt %s: %v", prompt.Version, err)
+	}
+	return buf.String(), prompt.Version, nil
+}
+
+// Version returns the version ID of a prompt, or "" if there is no such prompt
+func (ps *PromptSet) Version(name string) string {
+	if prompt := ps.prompts[name]; prompt != nil {
+		return prompt.Version
+	}
+	return ""
+}
+
+// Prompts lists the loaded prompts sorted by name
+func (ps *PromptSet) Prompts() []Prompt {
+	list := make([]Prompt, 0, len(ps.prompts))
+	for _, prompt := range ps.prompts {
+		list = append(list, *prompt)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReferenceFile is where a challenge keeps its optional reference solution,
+// relative to the challenge directory. The file starts with a
+// "//go:build reference" constraint so it never compiles together with the
+// challenge's own files. It is never served by the web UI, except in a
+// write-tests challenge, where it is the code under test.
+const ReferenceFile = "reference/solution.go"
+
+// ReadReference returns a challenge's reference solution, ready to be used in
+// place of solution-template.go. A challenge without one returns an error
+// satisfying os.IsNotExist.
+func ReadReference(challengeDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(challengeDir, ReferenceFile))
+	if err != nil {
+		return "", err
+	}
+	return StripBuildConstraints(string(content)), nil
+}
+
+// StripBuildConstraints blanks out the //go:build and // +build lines of a Go
+// file. The lines are emptied rather than removed so line numbers still match
+// the file on disk.
+func StripBuildConstraints(src string) string {
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "package ") {
+			break
+		}
+		if strings.HasPrefix(trimmed, "//go:build") || strings.HasPrefix(trimmed, "// +build") {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
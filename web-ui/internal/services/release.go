@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"web-ui/internal/models"
@@ -25,10 +26,39 @@ import (
 type ReleaseService struct {
 	releasesPath string
 	cached       []*models.Release
+
+	// Release challenges have no scoreboards, so passes are only known for
+	// runs since the server started: username -> version/feature/challenge.
+	passed map[string]map[string]bool
+	mutex  sync.RWMutex
 }
 
 func NewReleaseService() *ReleaseService {
-	return &ReleaseService{releasesPath: "../releases"} // relative to web-ui/
+	return &ReleaseService{
+		releasesPath: "../releases", // relative to web-ui/
+		passed:       make(map[string]map[string]bool),
+	}
+}
+
+// MarkPassed records that a user's run of a release challenge passed.
+func (s *ReleaseService) MarkPassed(username string, c *models.ReleaseChallenge) {
+	if username == "" {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.passed[username] == nil {
+		s.passed[username] = make(map[string]bool)
+	}
+	s.passed[username][c.ReleaseVersion+"/"+c.FeatureSlug+"/"+c.Slug] = true
+}
+
+// HasPassed reports whether a user has passed a release challenge since the
+// server started.
+func (s *ReleaseService) HasPassed(username, version, feature, challenge string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.passed[username][version+"/"+feature+"/"+challenge]
 }
 
 // Load reads every release from disk. Called once at start-up.
@@ -415,3 +445,25 @@ func splitHints(md string) []models.Hint {
 	}
 	return out
 }
+
+// HintHeading is one "## Hint N: title" heading of a hints.md file
+type HintHeading struct {
+	Number int
+	Title  string
+	Line   int // 1-based
+}
+
+// ParseHintHeadings lists the hint headings of a hints.md file in order, for
+// tools that check the numbering.
+func ParseHintHeadings(md string) []HintHeading {
+	var out []HintHeading
+	for _, loc := range reHintHeading.FindAllStringSubmatchIndex(md, -1) {
+		n, _ := strconv.Atoi(md[loc[2]:loc[3]])
+		out = append(out, HintHeading{
+			Number: n,
+			Title:  strings.TrimSpace(md[loc[4]:loc[5]]),
+			Line:   strings.Count(md[:loc[0]], "\n") + 1,
+		})
+	}
+	return out
+}
@@ -3,8 +3,10 @@ package services
 import (
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"web-ui/internal/models"
@@ -13,26 +15,29 @@ import (
 // ScoreboardService handles scoreboard-related operations
 type ScoreboardService struct {
 	scoreboards models.ScoreboardMap
+	passed      map[int]map[string]bool // challenge ID -> usernames that passed every test
+	mutex       sync.RWMutex            // guards scoreboards and passed
 }
 
 // NewScoreboardService creates a new scoreboard service
 func NewScoreboardService() *ScoreboardService {
 	return &ScoreboardService{
 		scoreboards: make(models.ScoreboardMap),
+		passed:      make(map[int]map[string]bool),
 	}
 }
 
 // LoadScoreboards loads all scoreboards from the filesystem
 func (ss *ScoreboardService) LoadScoreboards(challenges models.ChallengeMap) error {
-	for id := range challenges {
+	for id, challenge := range challenges {
 		challengeDir := filepath.Join("..", "challenge-"+strconv.Itoa(id))
-		ss.loadScoreboardForChallenge(id, challengeDir)
+		ss.loadScoreboardForChallenge(challenge, challengeDir)
 	}
 	return nil
 }
 
 // loadScoreboardForChallenge loads the scoreboard for a specific challenge
-func (ss *ScoreboardService) loadScoreboardForChallenge(id int, dir string) {
+func (ss *ScoreboardService) loadScoreboardForChallenge(challenge *models.Challenge, dir string) {
 	scoreboardPath := filepath.Join(dir, "SCOREBOARD.md")
 	scoreboardContent, err := ioutil.ReadFile(scoreboardPath)
 	if err != nil {
@@ -40,12 +45,15 @@ func (ss *ScoreboardService) loadScoreboardForChallenge(id int, dir string) {
 	}
 
 	// Parse scoreboard markdown table
-	entries := ss.parseScoreboardMarkdown(string(scoreboardContent), id)
-	ss.scoreboards[id] = entries
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+	entries := ss.parseScoreboardMarkdown(string(scoreboardContent), challenge)
+	ss.scoreboards[challenge.ID] = entries
 }
 
 // parseScoreboardMarkdown parses the scoreboard markdown table
-func (ss *ScoreboardService) parseScoreboardMarkdown(content string, challengeID int) []models.ScoreboardEntry {
+func (ss *ScoreboardService) parseScoreboardMarkdown(content string, challenge *models.Challenge) []models.ScoreboardEntry {
+	challengeID := challenge.ID
 	lines := strings.Split(content, "\n")
 	entries := []models.ScoreboardEntry{}
 
@@ -86,9 +94,25 @@ func (ss *ScoreboardService) parseScoreboardMarkdown(content string, challengeID
 
 		var username string
 
+		passedAll := true
+		raceClean := false
 		if format == 1 {
-			// Format is: | Username | Passed Tests | Total Tests |
+			// Format is: | Username | Passed Tests | Total Tests |, or
+			// | Username | Mutants Killed | Total Mutants | for write-tests
+			// challenges, which pass at their minimum mutation score
 			username = strings.TrimSpace(parts[1])
+			if len(parts) >= 4 {
+				passed, err1 := strconv.Atoi(strings.TrimSpace(parts[2]))
+				total, err2 := strconv.Atoi(strings.TrimSpace(parts[3]))
+				passedAll = err1 == nil && err2 == nil && total > 0 && passed == total
+				if challenge.WritesTests() {
+					passedAll = err1 == nil && err2 == nil && total > 0 && passed*100/total >= challenge.MinMutationScore
+				}
+			}
+			// Challenges with concurrency checks add a | Race Clean | column
+			if len(parts) >= 6 {
+				raceClean = strings.TrimSpace(parts[4]) == "✅"
+			}
 		} else {
 			// Format is: | Rank | Username | Solution | Date Submitted |
 			username = strings.TrimSpace(parts[2])
@@ -104,14 +128,47 @@ func (ss *ScoreboardService) parseScoreboardMarkdown(content string, challengeID
 			Username:    username,
 			ChallengeID: challengeID,
 			SubmittedAt: time.Now(),
+			RaceClean:   raceClean,
 		}
 
 		entries = append(entries, entry)
+		if passedAll {
+			ss.markPassed(challengeID, username)
+		}
 	}
 
 	return entries
 }
 
+// markPassed records that a user passed every test of a challenge. The
+// caller holds the lock.
+func (ss *ScoreboardService) markPassed(challengeID int, username string) {
+	if ss.passed[challengeID] == nil {
+		ss.passed[challengeID] = make(map[string]bool)
+	}
+	ss.passed[challengeID][username] = true
+}
+
+// HasPassed reports whether a user has passed every test of a challenge,
+// either on the SCOREBOARD.md or with a submission since the server started.
+func (ss *ScoreboardService) HasPassed(username string, challengeID int) bool {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	return username != "" && ss.passed[challengeID][username]
+}
+
+// PassingUsers returns, sorted, the users who passed every test of a challenge
+func (ss *ScoreboardService) PassingUsers(challengeID int) []string {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	users := make([]string, 0, len(ss.passed[challengeID]))
+	for username := range ss.passed[challengeID] {
+		users = append(users, username)
+	}
+	sort.Strings(users)
+	return users
+}
+
 // isNumeric checks if a string contains only digits
 func (ss *ScoreboardService) isNumeric(s string) bool {
 	for _, r := range s {
@@ -124,27 +181,44 @@ func (ss *ScoreboardService) isNumeric(s string) bool {
 
 // GetScoreboard returns the scoreboard for a specific challenge
 func (ss *ScoreboardService) GetScoreboard(challengeID int) ([]models.ScoreboardEntry, bool) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
 	scoreboard, exists := ss.scoreboards[challengeID]
 	return scoreboard, exists
 }
 
-// GetAllScoreboards returns all scoreboards
+// GetAllScoreboards returns all scoreboards, as a copy of the map
 func (ss *ScoreboardService) GetAllScoreboards() models.ScoreboardMap {
-	return ss.scoreboards
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+	scoreboards := make(models.ScoreboardMap, len(ss.scoreboards))
+	for id, entries := range ss.scoreboards {
+		scoreboards[id] = entries
+	}
+	return scoreboards
 }
 
 // AddSubmission adds a submission to the scoreboard
 func (ss *ScoreboardService) AddSubmission(submission models.Submission) {
 	entry := models.ScoreboardEntry{
-		Username:    submission.Username,
-		ChallengeID: submission.ChallengeID,
-		SubmittedAt: submission.SubmittedAt,
+		Username:     submission.Username,
+		ChallengeID:  submission.ChallengeID,
+		SubmittedAt:  submission.SubmittedAt,
+		WithoutHints: submission.HintsUsed == 0,
+		RaceClean:    submission.Concurrency.Clean(),
 	}
 
+	ss.mutex.Lock()
+	defer ss.mutex.Unlock()
+
 	// Add to the scoreboard for this challenge
 	if ss.scoreboards[submission.ChallengeID] == nil {
 		ss.scoreboards[submission.ChallengeID] = []models.ScoreboardEntry{}
 	}
 
 	ss.scoreboards[submission.ChallengeID] = append(ss.scoreboards[submission.ChallengeID], entry)
+
+	if submission.Passed {
+		ss.markPassed(submission.ChallengeID, submission.Username)
+	}
 }
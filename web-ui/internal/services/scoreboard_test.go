@@ -0,0 +1,50 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+// Passing submissions unlock a challenge for their user while other
+// goroutines are asking who passed
+func TestScoreboardPassed(t *testing.T) {
+	ss := NewScoreboardService()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			ss.AddSubmission(models.Submission{Username: fmt.Sprintf("user%d", i), ChallengeID: 1, Passed: i%2 == 0})
+		}(i)
+		go func() {
+			defer wg.Done()
+			ss.HasPassed("user0", 1)
+			ss.PassingUsers(1)
+		}()
+	}
+	wg.Wait()
+
+	want := []string{"user0", "user2", "user4", "user6", "user8"}
+	if got := ss.PassingUsers(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("PassingUsers = %q, want %q", got, want)
+	}
+	for _, tt := range []struct {
+		username    string
+		challengeID int
+		want        bool
+	}{
+		{"user2", 1, true},
+		{"user3", 1, false},
+		{"user2", 2, false},
+		{"", 1, false},
+	} {
+		if got := ss.HasPassed(tt.username, tt.challengeID); got != tt.want {
+			t.Errorf("HasPassed(%q, %d) = %v, want %v", tt.username, tt.challengeID, got, tt.want)
+		}
+	}
+}
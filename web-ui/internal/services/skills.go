@@ -0,0 +1,501 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+// DefaultRecommendations is the length of the next-challenge queue
+const DefaultRecommendations = 5
+
+var (
+	// "Challenge 20: Circuit Breaker Pattern" names a classic challenge
+	classicPrerequisitePattern = regexp.MustCompile(`^Challenge (\d+):`)
+	// "Completed Challenge 1 (MongoDB Connection & CRUD)" names a challenge
+	// of the same package
+	packagePrerequisitePattern = regexp.MustCompile(`^Completed Challenge (\d+) \(`)
+)
+
+// SkillService evaluates the prerequisites of classic, package and release
+// challenges against the skill graph in skills.json, and recommends what a
+// user should try next. Prerequisites that name neither a skill nor a
+// challenge are free text and never lock anything.
+type SkillService struct {
+	graphPath         string
+	graph             *models.SkillGraph
+	skills            map[string]*models.Skill // lowercased ID and aliases
+	challengeService  *ChallengeService
+	packageService    *PackageService
+	progressService   *PackageProgressService
+	releaseService    *ReleaseService
+	scoreboardService *ScoreboardService
+}
+
+// NewSkillService creates a new skill service
+func NewSkillService(
+	challengeService *ChallengeService,
+	packageService *PackageService,
+	progressService *PackageProgressService,
+	releaseService *ReleaseService,
+	scoreboardService *ScoreboardService,
+) *SkillService {
+	return &SkillService{
+		graphPath:         "../skills.json", // relative to web-ui/
+		graph:             &models.SkillGraph{},
+		skills:            make(map[string]*models.Skill),
+		challengeService:  challengeService,
+		packageService:    packageService,
+		progressService:   progressService,
+		releaseService:    releaseService,
+		scoreboardService: scoreboardService,
+	}
+}
+
+// Load reads the skill graph. Without one no prerequisite locks anything.
+func (s *SkillService) Load() error {
+	graph, err := ReadSkillGraph(s.graphPath)
+	if err != nil {
+		return err
+	}
+	s.graph = graph
+	s.skills = make(map[string]*models.Skill)
+	for i := range graph.Skills {
+		skill := &graph.Skills[i]
+		s.skills[strings.ToLower(skill.ID)] = skill
+		for _, alias := range skill.Aliases {
+			s.skills[strings.ToLower(alias)] = skill
+		}
+	}
+	log.Printf("Loaded %d skill(s)", len(graph.Skills))
+	return nil
+}
+
+// ReadSkillGraph reads a skills.json file
+func ReadSkillGraph(path string) (*models.SkillGraph, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var graph models.SkillGraph
+	if err := json.Unmarshal(content, &graph); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &graph, nil
+}
+
+// SkillProblem is a mistake in the skill graph, found by ValidateSkillGraph
+type SkillProblem struct {
+	Skill   string // skill ID, empty for the file as a whole
+	Message string
+}
+
+// ValidateSkillGraph checks that skill IDs and aliases are unique and that
+// every challenge reference exists under root, the repository root
+func ValidateSkillGraph(graph *models.SkillGraph, root string) []SkillProblem {
+	var problems []SkillProblem
+	names := make(map[string]string) // lowercased name -> skill ID
+	for _, skill := range graph.Skills {
+		if skill.ID == "" {
+			problems = append(problems, SkillProblem{Message: "skill without an id"})
+			continue
+		}
+		if skill.Title == "" {
+			problems = append(problems, SkillProblem{skill.ID, "missing title"})
+		}
+		if len(skill.Challenges) == 0 {
+			problems = append(problems, SkillProblem{skill.ID, "no challenges teach it, so it can never be met"})
+		}
+		for _, name := range append([]string{skill.ID}, skill.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := names[key]; ok {
+				problems = append(problems, SkillProblem{skill.ID, fmt.Sprintf("%q is already a name of skill %q", name, other)})
+				continue
+			}
+			names[key] = skill.ID
+		}
+		for _, ref := range skill.Challenges {
+			if _, err := parseChallengeRef(ref); err != nil {
+				problems = append(problems, SkillProblem{skill.ID, err.Error()})
+			} else if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(ref))); err != nil || !info.IsDir() {
+				problems = append(problems, SkillProblem{skill.ID, fmt.Sprintf("challenge %q does not exist", ref)})
+			}
+		}
+	}
+	return problems
+}
+
+// parseChallengeRef splits a challenge reference into its path elements and
+// checks it names a challenge directory on one of the tracks
+func parseChallengeRef(ref string) ([]string, error) {
+	parts := strings.Split(ref, "/")
+	switch {
+	case len(parts) == 1 && classicDirPattern.MatchString(parts[0]):
+	case len(parts) == 3 && parts[0] == "packages" && strings.HasPrefix(parts[2], "challenge-"):
+	case len(parts) == 4 && parts[0] == "releases" && strings.HasPrefix(parts[3], "challenge-"):
+	default:
+		return nil, fmt.Errorf("%q is not a challenge-N, packages/<package>/<challenge> or releases/<version>/<feature>/<challenge> path", ref)
+	}
+	return parts, nil
+}
+
+var classicDirPattern = regexp.MustCompile(`^challenge-\d+$`)
+
+// challengeNode is a challenge on any track with its prerequisites
+type challengeNode struct {
+	models.ChallengeRef
+	name          string // how reasons refer to it
+	prerequisites []string
+	packageName   string // package challenges only
+}
+
+// requirement is one prerequisite that names a skill or a challenge
+type requirement struct {
+	skill *models.Skill
+	ref   string
+}
+
+// nodes lists the challenges of every track: classic by ID, then packages
+// by name in learning path order, then releases newest first
+func (s *SkillService) nodes() []challengeNode {
+	var nodes []challengeNode
+
+	challenges := s.challengeService.GetChallenges()
+	ids := make([]int, 0, len(challenges))
+	for id := range challenges {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		challenge := challenges[id]
+		nodes = append(nodes, challengeNode{
+			ChallengeRef: models.ChallengeRef{
+				Ref:        "challenge-" + strconv.Itoa(id),
+				Track:      models.TrackClassic,
+				Title:      challenge.Title,
+				Difficulty: challenge.Difficulty,
+				URL:        "/challenge/" + strconv.Itoa(id),
+			},
+			name:          "Challenge " + strconv.Itoa(id),
+			prerequisites: challenge.Prerequisites,
+		})
+	}
+
+	packages := s.packageService.GetPackages()
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pkg, path, err := s.progressService.LearningPath(name)
+		if err != nil {
+			continue
+		}
+		for _, challenge := range path {
+			// The package's prerequisites apply to every challenge in it;
+			// each challenge's own come from its metadata.json
+			node := challengeNode{
+				ChallengeRef: models.ChallengeRef{
+					Ref:        "packages/" + name + "/" + challenge.ID,
+					Track:      models.TrackPackage,
+					Title:      challenge.Title,
+					Difficulty: challenge.Difficulty,
+					URL:        "/packages/" + name + "/" + challenge.ID,
+				},
+				prerequisites: append([]string{}, pkg.Prerequisites...),
+				packageName:   name,
+			}
+			if info := pkg.ChallengeDetails[challenge.ID]; info != nil {
+				node.Title = info.Title
+				node.prerequisites = append(node.prerequisites, info.Prerequisites...)
+			}
+			node.name = node.Title
+			nodes = append(nodes, node)
+		}
+	}
+
+	for _, rel := range s.releaseService.GetReleases() {
+		for _, feature := range rel.Features {
+			for _, challenge := range feature.Challenges {
+				nodes = append(nodes, challengeNode{
+					ChallengeRef: models.ChallengeRef{
+						Ref:        "releases/go" + rel.Version + "/" + feature.Slug + "/" + challenge.Slug,
+						Track:      models.TrackRelease,
+						Title:      challenge.Title,
+						Difficulty: challenge.Difficulty,
+						URL:        "/releases/" + rel.Version + "/" + feature.Slug + "/" + challenge.Slug,
+					},
+					name:          challenge.Title,
+					prerequisites: challenge.Prerequisites,
+				})
+			}
+		}
+	}
+	return nodes
+}
+
+// requirements resolves a challenge's prerequisites. A skill the challenge
+// itself teaches is not required to start it.
+func (s *SkillService) requirements(node challengeNode, refs map[string]bool) []requirement {
+	var reqs []requirement
+	seen := make(map[string]bool)
+	add := func(key string, req requirement) {
+		if !seen[key] {
+			seen[key] = true
+			reqs = append(reqs, req)
+		}
+	}
+
+	for _, prerequisite := range node.prerequisites {
+		prerequisite = strings.TrimSpace(prerequisite)
+		if m := packagePrerequisitePattern.FindStringSubmatch(prerequisite); m != nil && node.packageName != "" {
+			prefix := "packages/" + node.packageName + "/challenge-" + m[1] + "-"
+			for ref := range refs {
+				if strings.HasPrefix(ref, prefix) {
+					add(ref, requirement{ref: ref})
+				}
+			}
+			continue
+		}
+		if m := classicPrerequisitePattern.FindStringSubmatch(prerequisite); m != nil && refs["challenge-"+m[1]] {
+			add("challenge-"+m[1], requirement{ref: "challenge-" + m[1]})
+			continue
+		}
+		if skill, ok := s.skills[strings.ToLower(prerequisite)]; ok && !containsString(skill.Challenges, node.Ref) {
+			add("skill:"+skill.ID, requirement{skill: skill})
+		}
+	}
+	return reqs
+}
+
+// met returns the passed challenges that satisfy a requirement, if any
+func (req requirement) met(passed map[string]bool) []string {
+	if req.skill == nil {
+		if passed[req.ref] {
+			return []string{req.ref}
+		}
+		return nil
+	}
+	var by []string
+	for _, ref := range req.skill.Challenges {
+		if passed[ref] {
+			by = append(by, ref)
+		}
+	}
+	return by
+}
+
+// passedChallenges returns the references of every challenge a user has
+// passed: classic challenges on the scoreboards, package challenges on the
+// learning path and release challenges run since the server started
+func (s *SkillService) passedChallenges(username string, nodes []challengeNode) map[string]bool {
+	passed := make(map[string]bool)
+	if username == "" {
+		return passed
+	}
+
+	for id := range s.challengeService.GetChallenges() {
+		if s.scoreboardService.HasPassed(username, id) {
+			passed["challenge-"+strconv.Itoa(id)] = true
+		}
+	}
+	for name := range s.packageService.GetPackages() {
+		progress, err := s.progressService.GetProgress(username, name)
+		if err != nil {
+			continue
+		}
+		for _, id := range progress.CompletedChallenges {
+			passed["packages/"+name+"/"+id] = true
+		}
+	}
+	for _, node := range nodes {
+		if node.Track != models.TrackRelease {
+			continue
+		}
+		parts := strings.Split(node.Ref, "/")
+		if s.releaseService.HasPassed(username, strings.TrimPrefix(parts[1], "go"), parts[2], parts[3]) {
+			passed[node.Ref] = true
+		}
+	}
+	return passed
+}
+
+// Recommend builds a user's next-challenge queue of up to limit entries.
+// Challenges their passes have just unlocked come first, then ones that
+// teach the skills most locked challenges are waiting on, then challenges
+// without prerequisites, easiest first.
+func (s *SkillService) Recommend(username string, limit int) *models.Recommendations {
+	if limit <= 0 {
+		limit = DefaultRecommendations
+	}
+	nodes := s.nodes()
+	refs := make(map[string]bool, len(nodes))
+	byRef := make(map[string]challengeNode, len(nodes))
+	for _, node := range nodes {
+		refs[node.Ref] = true
+		byRef[node.Ref] = node
+	}
+	passed := s.passedChallenges(username, nodes)
+
+	result := &models.Recommendations{
+		Username: username,
+		Passed:   []string{},
+		Next:     []models.Recommendation{},
+		Locked:   []models.LockedChallenge{},
+	}
+	for _, node := range nodes {
+		if passed[node.Ref] {
+			result.Passed = append(result.Passed, node.Ref)
+		}
+	}
+
+	var unlocked, open []models.Recommendation
+	unlocks := make(map[string]int) // teaching challenge -> locked challenges it helps
+	queued := make(map[string]bool) // packages with a challenge in the queue
+	for _, node := range nodes {
+		if passed[node.Ref] {
+			continue
+		}
+		reqs := s.requirements(node, refs)
+		var by, missing []string
+		var helpers []string
+		for _, req := range reqs {
+			if met := req.met(passed); len(met) > 0 {
+				by = append(by, met[0])
+				continue
+			}
+			if req.skill != nil {
+				missing = append(missing, req.skill.Title)
+				helpers = append(helpers, req.skill.Challenges...)
+			} else {
+				missing = append(missing, byRef[req.ref].name)
+				helpers = append(helpers, req.ref)
+			}
+		}
+
+		switch {
+		case len(missing) > 0:
+			result.Locked = append(result.Locked, models.LockedChallenge{Challenge: node.ChallengeRef, Missing: missing})
+			for _, ref := range helpers {
+				unlocks[ref]++
+			}
+			continue
+		case queued[node.packageName]:
+			// Only the first open challenge of a learning path is queued
+			continue
+		case len(by) > 0:
+			names := make([]string, len(by))
+			for i, ref := range by {
+				names[i] = byRef[ref].name
+			}
+			unlocked = append(unlocked, models.Recommendation{
+				Challenge:  node.ChallengeRef,
+				Reason:     fmt.Sprintf("You've passed %s, so it's unlocked.", joinNames(uniqueStrings(names))),
+				UnlockedBy: uniqueStrings(by),
+			})
+		default:
+			open = append(open, models.Recommendation{
+				Challenge: node.ChallengeRef,
+				Reason:    "No prerequisites.",
+			})
+		}
+		if node.packageName != "" {
+			queued[node.packageName] = true
+		}
+	}
+
+	// A challenge that helps unlock others is worth more in either group
+	for _, group := range [][]models.Recommendation{unlocked, open} {
+		for i := range group {
+			group[i].Unlocks = unlocks[group[i].Challenge.Ref]
+			if group[i].Unlocks > 0 && len(group[i].UnlockedBy) == 0 {
+				group[i].Reason = fmt.Sprintf("Passing it helps unlock %d more challenge(s).", group[i].Unlocks)
+			}
+		}
+	}
+	sort.SliceStable(unlocked, func(i, j int) bool {
+		return len(unlocked[i].UnlockedBy) > len(unlocked[j].UnlockedBy)
+	})
+	sort.SliceStable(open, func(i, j int) bool {
+		if open[i].Unlocks != open[j].Unlocks {
+			return open[i].Unlocks > open[j].Unlocks
+		}
+		return difficultyRank(open[i].Challenge.Difficulty) < difficultyRank(open[j].Challenge.Difficulty)
+	})
+
+	for _, rec := range append(unlocked, open...) {
+		if len(result.Next) == limit {
+			break
+		}
+		result.Next = append(result.Next, rec)
+	}
+	return result
+}
+
+// UnreachableChallenges returns the challenges that stay locked even for a
+// user who passes everything they can, because their prerequisites wait on
+// each other
+func (s *SkillService) UnreachableChallenges() []string {
+	nodes := s.nodes()
+	refs := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		refs[node.Ref] = true
+	}
+
+	passed := make(map[string]bool)
+	for progress := true; progress; {
+		progress = false
+		for _, node := range nodes {
+			if passed[node.Ref] {
+				continue
+			}
+			open := true
+			for _, req := range s.requirements(node, refs) {
+				if len(req.met(passed)) == 0 {
+					open = false
+					break
+				}
+			}
+			if open {
+				passed[node.Ref] = true
+				progress = true
+			}
+		}
+	}
+
+	var unreachable []string
+	for _, node := range nodes {
+		if !passed[node.Ref] {
+			unreachable = append(unreachable, node.Ref)
+		}
+	}
+	return unreachable
+}
+
+// joinNames joins names as "A", "A and B" or "A, B and C"
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+func uniqueStrings(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if !containsString(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"web-ui/internal/models"
+)
+
+// newTestSkillService returns a skill service over a small graph:
+//
+//	challenge-1  Beginner, no prerequisites; teaches "testing"
+//	challenge-2  Intermediate, needs Challenge 1; teaches "http"
+//	challenge-3  Advanced, no prerequisites; teaches "goroutines"
+//	challenge-4  Advanced, needs "net/http" (an alias of http) and goroutines
+//	challenge-5  Beginner, needs "testing", which it teaches itself, and
+//	             free text
+//	packages/demo, needing http: challenge-2 needs its challenge 1 as well
+//
+// Nobody has passed anything yet.
+func newTestSkillService(t *testing.T, extra models.ChallengeMap, extraSkills ...models.Skill) *SkillService {
+	dir := t.TempDir()
+	packagePath := filepath.Join(dir, "demo")
+	for _, id := range []string{"challenge-1-basics", "challenge-2-more", "challenge-3-advanced"} {
+		if err := os.MkdirAll(filepath.Join(packagePath, id), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"package.json": `{"name": "demo", "prerequisites": ["http"],
+			"learning_path": ["challenge-1-basics", "challenge-2-more", "challenge-3-advanced"]}`,
+		"challenge-2-more/metadata.json": `{"title": "More", "prerequisites": ["Completed Challenge 1 (Basics)"]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(packagePath, filepath.FromSlash(name)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packageService := &PackageService{packagesPath: dir}
+
+	challenges := models.ChallengeMap{
+		1: {ID: 1, Title: "Sum", Difficulty: "Beginner"},
+		2: {ID: 2, Title: "Server", Difficulty: "Intermediate", Prerequisites: []string{"Challenge 1: Sum"}},
+		3: {ID: 3, Title: "Workers", Difficulty: "Advanced"},
+		4: {ID: 4, Title: "Crawler", Difficulty: "Advanced", Prerequisites: []string{"net/http", "Goroutines"}},
+		5: {ID: 5, Title: "Tables", Difficulty: "Beginner", Prerequisites: []string{"testing", "Knows some Go"}},
+	}
+	for id, challenge := range extra {
+		challenges[id] = challenge
+	}
+
+	s := NewSkillService(
+		&ChallengeService{challenges: challenges},
+		packageService,
+		NewPackageProgressService(packageService),
+		&ReleaseService{cached: []*models.Release{}, passed: make(map[string]map[string]bool)},
+		NewScoreboardService(),
+	)
+	s.graphPath = filepath.Join(dir, "skills.json")
+	graph := `{"skills": [
+		{"id": "http", "title": "HTTP handlers", "aliases": ["net/http"], "challenges": ["challenge-2"]},
+		{"id": "goroutines", "title": "Goroutines", "challenges": ["challenge-3"]},
+		{"id": "testing", "title": "Table tests", "challenges": ["challenge-1", "challenge-5"]}`
+	for _, skill := range extraSkills {
+		graph += `, {"id": "` + skill.ID + `", "title": "` + skill.Title + `", "challenges": ["` + strings.Join(skill.Challenges, `", "`) + `"]}`
+	}
+	if err := os.WriteFile(s.graphPath, []byte(graph+"]}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// pass records that alice passed each challenge
+func pass(s *SkillService, refs ...string) {
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "packages/demo/") {
+			s.progressService.RecordSubmission(models.PackageSubmission{
+				Username: "alice", PackageName: "demo", ChallengeID: strings.TrimPrefix(ref, "packages/demo/"), Passed: true,
+			})
+			continue
+		}
+		id, _ := strconv.Atoi(strings.TrimPrefix(ref, "challenge-"))
+		s.scoreboardService.markPassed(id, "alice")
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	type next struct {
+		Ref        string
+		Reason     string
+		UnlockedBy []string
+		Unlocks    int
+	}
+	tests := []struct {
+		name   string
+		passed []string
+		next   []next
+		locked map[string][]string // ref -> missing
+	}{
+		{
+			name: "nothing passed",
+			// Open challenges that help unlock the most come first, then
+			// the easiest
+			next: []next{
+				{"challenge-1", "Passing it helps unlock 1 more challenge(s).", nil, 1},
+				{"challenge-3", "Passing it helps unlock 1 more challenge(s).", nil, 1},
+				{"challenge-5", "No prerequisites.", nil, 0},
+			},
+			locked: map[string][]string{
+				"challenge-2":                        {"Challenge 1"},
+				"challenge-4":                        {"HTTP handlers", "Goroutines"},
+				"packages/demo/challenge-1-basics":   {"HTTP handlers"},
+				"packages/demo/challenge-2-more":     {"HTTP handlers", "Basics"},
+				"packages/demo/challenge-3-advanced": {"HTTP handlers"},
+			},
+		},
+		{
+			name:   "unlocked by a challenge",
+			passed: []string{"challenge-1"},
+			next: []next{
+				{"challenge-2", "You've passed Challenge 1, so it's unlocked.", []string{"challenge-1"}, 4},
+				{"challenge-3", "Passing it helps unlock 1 more challenge(s).", nil, 1},
+				{"challenge-5", "No prerequisites.", nil, 0},
+			},
+			locked: map[string][]string{
+				"challenge-4":                        {"HTTP handlers", "Goroutines"},
+				"packages/demo/challenge-1-basics":   {"HTTP handlers"},
+				"packages/demo/challenge-2-more":     {"HTTP handlers", "Basics"},
+				"packages/demo/challenge-3-advanced": {"HTTP handlers"},
+			},
+		},
+		{
+			// Only the first open challenge of the package is queued
+			name:   "unlocked by a skill, one per package",
+			passed: []string{"challenge-1", "challenge-2"},
+			next: []next{
+				{"packages/demo/challenge-1-basics", "You've passed Challenge 2, so it's unlocked.", []string{"challenge-2"}, 1},
+				{"challenge-3", "Passing it helps unlock 1 more challenge(s).", nil, 1},
+				{"challenge-5", "No prerequisites.", nil, 0},
+			},
+			locked: map[string][]string{
+				"challenge-4":                    {"Goroutines"},
+				"packages/demo/challenge-2-more": {"Basics"},
+			},
+		},
+		{
+			name:   "unlocked by skills through an alias",
+			passed: []string{"challenge-2", "challenge-3"},
+			next: []next{
+				{"challenge-4", "You've passed Challenge 2 and Challenge 3, so it's unlocked.", []string{"challenge-2", "challenge-3"}, 0},
+				{"packages/demo/challenge-1-basics", "You've passed Challenge 2, so it's unlocked.", []string{"challenge-2"}, 1},
+				{"challenge-1", "No prerequisites.", nil, 0},
+				{"challenge-5", "No prerequisites.", nil, 0},
+			},
+			locked: map[string][]string{
+				"packages/demo/challenge-2-more": {"Basics"},
+			},
+		},
+		{
+			name:   "package challenge unlocks the next",
+			passed: []string{"challenge-2", "packages/demo/challenge-1-basics"},
+			next: []next{
+				{"packages/demo/challenge-2-more", "You've passed Challenge 2 and Basics, so it's unlocked.", []string{"challenge-2", "packages/demo/challenge-1-basics"}, 0},
+				{"challenge-3", "Passing it helps unlock 1 more challenge(s).", nil, 1},
+				{"challenge-1", "No prerequisites.", nil, 0},
+				{"challenge-5", "No prerequisites.", nil, 0},
+			},
+			locked: map[string][]string{
+				"challenge-4": {"Goroutines"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSkillService(t, nil)
+			pass(s, tt.passed...)
+			username := ""
+			if len(tt.passed) > 0 {
+				username = "alice"
+			}
+			result := s.Recommend(username, 10)
+
+			var got []next
+			for _, rec := range result.Next {
+				got = append(got, next{rec.Challenge.Ref, rec.Reason, rec.UnlockedBy, rec.Unlocks})
+			}
+			if !reflect.DeepEqual(got, tt.next) {
+				t.Errorf("Next =\n%+v\nwant\n%+v", got, tt.next)
+			}
+			locked := make(map[string][]string)
+			for _, l := range result.Locked {
+				locked[l.Challenge.Ref] = l.Missing
+			}
+			if !reflect.DeepEqual(locked, tt.locked) {
+				t.Errorf("Locked =\n%q\nwant\n%q", locked, tt.locked)
+			}
+			if len(result.Passed) != len(tt.passed) {
+				t.Errorf("Passed = %q, want %q", result.Passed, tt.passed)
+			}
+		})
+	}
+}
+
+func TestRecommendLimit(t *testing.T) {
+	// Six open challenges: 1, 3, 5 and these
+	s := newTestSkillService(t, models.ChallengeMap{
+		10: {ID: 10, Title: "Ten"},
+		11: {ID: 11, Title: "Eleven"},
+		12: {ID: 12, Title: "Twelve"},
+	})
+	if got := len(s.Recommend("", 2).Next); got != 2 {
+		t.Errorf("Recommend with limit 2 queued %d challenges", got)
+	}
+	if got := len(s.Recommend("", 0).Next); got != DefaultRecommendations {
+		t.Errorf("Recommend without a limit queued %d challenges, want %d", got, DefaultRecommendations)
+	}
+}
+
+func TestUnreachableChallenges(t *testing.T) {
+	if got := newTestSkillService(t, nil).UnreachableChallenges(); got != nil {
+		t.Errorf("UnreachableChallenges = %q, want none", got)
+	}
+
+	// challenge-6 needs challenge-7, which needs a skill only challenge-6
+	// teaches; challenge-8 waits on the cycle
+	s := newTestSkillService(t, models.ChallengeMap{
+		6: {ID: 6, Title: "Chicken", Prerequisites: []string{"Challenge 7: Egg"}},
+		7: {ID: 7, Title: "Egg", Prerequisites: []string{"hatching"}},
+		8: {ID: 8, Title: "Omelette", Prerequisites: []string{"Challenge 6: Chicken"}},
+		9: {ID: 9, Title: "Self-taught", Prerequisites: []string{"self"}},
+	},
+		models.Skill{ID: "hatching", Title: "Hatching", Challenges: []string{"challenge-6"}},
+		models.Skill{ID: "self", Title: "Self", Challenges: []string{"challenge-9"}},
+	)
+	want := []string{"challenge-6", "challenge-7", "challenge-8"}
+	if got := s.UnreachableChallenges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("UnreachableChallenges = %q, want %q", got, want)
+	}
+}
+
+func TestValidateSkillGraph(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"challenge-1", "packages/demo/challenge-1-basics"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	graph := &models.SkillGraph{Skills: []models.Skill{
+		{ID: "http", Title: "HTTP", Aliases: []string{"net/http"}, Challenges: []string{"challenge-1", "packages/demo/challenge-1-basics"}},
+		{Title: "No ID", Challenges: []string{"challenge-1"}},
+		{ID: "untitled", Challenges: []string{"challenge-1"}},
+		{ID: "untaught", Title: "Untaught"},
+		{ID: "dup", Title: "Dup", Aliases: []string{"NET/HTTP"}, Challenges: []string{"challenge-1"}},
+		{ID: "refs", Title: "Refs", Challenges: []string{"challenge-2", "challenge-x", "packages/demo", "packages/demo/challenge-9-missing"}},
+	}}
+	want := []SkillProblem{
+		{"", "skill without an id"},
+		{"untitled", "missing title"},
+		{"untaught", "no challenges teach it, so it can never be met"},
+		{"dup", `"NET/HTTP" is already a name of skill "http"`},
+		{"refs", `challenge "challenge-2" does not exist`},
+		{"refs", `"challenge-x" is not a challenge-N, packages/<package>/<challenge> or releases/<version>/<feature>/<challenge> path`},
+		{"refs", `"packages/demo" is not a challenge-N, packages/<package>/<challenge> or releases/<version>/<feature>/<challenge> path`},
+		{"refs", `challenge "packages/demo/challenge-9-missing" does not exist`},
+	}
+	if got := ValidateSkillGraph(graph, root); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateSkillGraph =\n%q\nwant\n%q", got, want)
+	}
+}
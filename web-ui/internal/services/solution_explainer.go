@@ -0,0 +1,304 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+const (
+	// maxSampledSolutions caps how many passing submissions are parsed per challenge
+	maxSampledSolutions = 120
+	// maxApproaches is how many clusters are presented
+	maxApproaches = 3
+	// explanationRetry is how long an explanation without the AI write-up, or
+	// a failed build, is served before it is built again; the AI may only
+	// have failed for a moment
+	explanationRetry = 10 * time.Minute
+)
+
+// SolutionExplainerService groups the passing community submissions of a
+// classic challenge by approach and describes the most common ones. Clusters
+// come from AST features of each solution; the AI only names them and writes
+// up the tradeoffs. Explanations with the AI write-up are built once per
+// challenge and cached; others are retried after explanationRetry.
+type SolutionExplainerService struct {
+	aiService         *AIService
+	scoreboardService *ScoreboardService
+
+	mutex        sync.Mutex
+	explanations map[int]*explanationEntry
+}
+
+type explanationEntry struct {
+	mutex       sync.Mutex // Held while building, so a challenge is built once at a time
+	explanation *models.SolutionExplanation
+	err         error
+	builtAt     time.Time
+}
+
+// fresh reports whether the entry can be served as it is at now
+func (entry *explanationEntry) fresh(now time.Time) bool {
+	switch {
+	case entry.builtAt.IsZero():
+		return false
+	case entry.err == nil && entry.explanation.AISummary:
+		return true
+	default:
+		return now.Sub(entry.builtAt) < explanationRetry
+	}
+}
+
+// NewSolutionExplainerService creates a new solution explainer service
+func NewSolutionExplainerService(aiService *AIService, scoreboardService *ScoreboardService) *SolutionExplainerService {
+	return &SolutionExplainerService{
+		aiService:         aiService,
+		scoreboardService: scoreboardService,
+		explanations:      make(map[int]*explanationEntry),
+	}
+}
+
+// Explain returns the common approaches to a challenge
+func (ses *SolutionExplainerService) Explain(challenge *models.Challenge) (*models.SolutionExplanation, error) {
+	ses.mutex.Lock()
+	entry := ses.explanations[challenge.ID]
+	if entry == nil {
+		entry = &explanationEntry{}
+		ses.explanations[challenge.ID] = entry
+	}
+	ses.mutex.Unlock()
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+	if !entry.fresh(time.Now()) {
+		entry.explanation, entry.err = ses.build(challenge)
+		entry.builtAt = time.Now()
+	}
+	return entry.explanation, entry.err
+}
+
+// communitySolution is one parsed passing submission
+type communitySolution struct {
+	username string
+	code     string
+	features []string
+	lines    int
+}
+
+func (ses *SolutionExplainerService) build(challenge *models.Challenge) (*models.SolutionExplanation, error) {
+	users := ses.scoreboardService.PassingUsers(challenge.ID)
+	if len(users) > maxSampledSolutions {
+		users = users[:maxSampledSolutions]
+	}
+
+	var solutions []communitySolution
+	for _, username := range users {
+		path := filepath.Join("..", "challenge-"+strconv.Itoa(challenge.ID), "submissions", username, challenge.SubmissionFile())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		features, lines, err := solutionFeatures(string(content))
+		if err != nil {
+			continue
+		}
+		solutions = append(solutions, communitySolution{
+			username: username,
+			code:     string(content),
+			features: features,
+			lines:    lines,
+		})
+	}
+	if len(solutions) == 0 {
+		return nil, fmt.Errorf("no passing submissions found for challenge %d", challenge.ID)
+	}
+
+	explanation := &models.SolutionExplanation{
+		ChallengeID: challenge.ID,
+		Sampled:     len(solutions),
+		Approaches:  clusterSolutions(solutions),
+		GeneratedAt: time.Now(),
+	}
+	explanation.AISummary = ses.describe(challenge, explanation.Approaches)
+	if explanation.AISummary {
+		explanation.PromptVersion = ses.aiService.PromptVersion(PromptApproaches)
+	}
+
+	return explanation, nil
+}
+
+// clusterSolutions groups solutions with identical approach features and
+// returns the largest groups, each represented by its median-length solution.
+func clusterSolutions(solutions []communitySolution) []models.SolutionApproach {
+	clusters := make(map[string][]communitySolution)
+	for _, s := range solutions {
+		key := strings.Join(s.features, ",")
+		clusters[key] = append(clusters[key], s)
+	}
+
+	keys := make([]string, 0, len(clusters))
+	for key := range clusters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(clusters[keys[i]]) != len(clusters[keys[j]]) {
+			return len(clusters[keys[i]]) > len(clusters[keys[j]])
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > maxApproaches {
+		keys = keys[:maxApproaches]
+	}
+
+	approaches := make([]models.SolutionApproach, 0, len(keys))
+	for _, key := range keys {
+		members := clusters[key]
+		sort.SliceStable(members, func(i, j int) bool { return members[i].lines < members[j].lines })
+		representative := members[len(members)/2]
+
+		features := representative.features
+		if len(features) == 0 {
+			features = []string{"straight-line code"}
+		}
+		approaches = append(approaches, models.SolutionApproach{
+			Name:     strings.Join(features, " + "),
+			Summary:  fmt.Sprintf("%d of the sampled solutions use %s.", len(members), strings.Join(features, ", ")),
+			Features: features,
+			Count:    len(members),
+			Author:   representative.username,
+			Code:     representative.code,
+		})
+	}
+	return approaches
+}
+
+// describe asks the AI to name each approach and explain its tradeoffs. It
+// reports whether the AI text was applied; on any failure the feature-based
+// names are kept.
+func (ses *SolutionExplainerService) describe(challenge *models.Challenge, approaches []models.SolutionApproach) bool {
+	if ses.aiService == nil || !ses.aiService.Enabled() || len(approaches) == 0 {
+		return false
+	}
+
+	response, err := ses.aiService.complete(FeatureExplain, PromptApproaches, PromptData{
+		Title:      challenge.Title,
+		Approaches: approaches,
+	}, true /* expectJSON */)
+	if err != nil {
+		return false
+	}
+
+	var described struct {
+		Approaches []struct {
+			Name      string `json:"name"`
+			Summary   string `json:"summary"`
+			Tradeoffs string `json:"tradeoffs"`
+		} `json:"approaches"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(response)), &described); err != nil {
+		return false
+	}
+	if len(described.Approaches) != len(approaches) {
+		return false
+	}
+
+	for i, d := range described.Approaches {
+		if d.Name != "" {
+			approaches[i].Name = d.Name
+		}
+		if d.Summary != "" {
+			approaches[i].Summary = d.Summary
+		}
+		approaches[i].Tradeoffs = d.Tradeoffs
+	}
+	return true
+}
+
+// solutionFeatures extracts the approach-level features of a solution and
+// counts its code lines. Features are sorted so they can be compared.
+func solutionFeatures(code string) ([]string, int, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "solution.go", code, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	found := make(map[string]bool)
+	for _, imp := range file.Imports {
+		switch strings.Trim(imp.Path.Value, `"`) {
+		case "sort":
+			found["sorting"] = true
+		case "slices":
+			found["slices package"] = true
+		case "sync", "sync/atomic":
+			found["concurrency"] = true
+		case "container/heap":
+			found["heap"] = true
+		case "regexp":
+			found["regexp"] = true
+		}
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || fn.Name.Name == "main" {
+			continue
+		}
+		if fn.Type.TypeParams != nil {
+			found["generics"] = true
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.ForStmt, *ast.RangeStmt:
+				found["iteration"] = true
+			case *ast.GoStmt:
+				found["concurrency"] = true
+			case *ast.ChanType:
+				found["channels"] = true
+			case *ast.MapType:
+				found["hash map"] = true
+			case *ast.CallExpr:
+				if ident, ok := node.Fun.(*ast.Ident); ok && ident.Name == fn.Name.Name && fn.Recv == nil {
+					found["recursion"] = true
+				}
+			case *ast.SelectorExpr:
+				if pkg, ok := node.X.(*ast.Ident); ok && pkg.Name == "strings" && node.Sel.Name == "Builder" {
+					found["strings.Builder"] = true
+				}
+			}
+			return true
+		})
+	}
+
+	features := make([]string, 0, len(found))
+	for feature := range found {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+
+	return features, codeLines(code), nil
+}
+
+// codeLines counts non-blank lines that are not line comments
+func codeLines(code string) int {
+	count := 0
+	for _, line := range strings.Split(code, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "//") {
+			count++
+		}
+	}
+	return count
+}
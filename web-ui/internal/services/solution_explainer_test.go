@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"web-ui/internal/models"
+)
+
+func TestExplanationEntryFresh(t *testing.T) {
+	built := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	withAI := &models.SolutionExplanation{AISummary: true}
+	withoutAI := &models.SolutionExplanation{AISummary: false}
+
+	tests := []struct {
+		name  string
+		entry *explanationEntry
+		age   time.Duration
+		want  bool
+	}{
+		{"never built", &explanationEntry{}, 0, false},
+		{"AI write-up is kept", &explanationEntry{explanation: withAI, builtAt: built}, 24 * time.Hour, true},
+		{"fallback write-up, recent", &explanationEntry{explanation: withoutAI, builtAt: built}, time.Minute, true},
+		{"fallback write-up, retried", &explanationEntry{explanation: withoutAI, builtAt: built}, explanationRetry, false},
+		{"failed build, recent", &explanationEntry{err: errors.New("no passing submissions"), builtAt: built}, time.Minute, true},
+		{"failed build, retried", &explanationEntry{err: errors.New("no passing submissions"), builtAt: built}, explanationRetry, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.fresh(built.Add(tt.age)); got != tt.want {
+				t.Errorf("fresh after %v = %v, want %v", tt.age, got, tt.want)
+			}
+		})
+	}
+}
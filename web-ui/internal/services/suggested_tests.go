@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"web-ui/internal/models"
+)
+
+// SuggestedTestFile is the name AI-suggested tests are written under. It only
+// ever exists in the execution service's temporary directory; suggested tests
+// are never merged into a challenge's official test file.
+const SuggestedTestFile = "ai_suggested_test.go"
+
+// SuggestedTestPrefix starts the name of every top-level declaration in a
+// suggested test file, so it cannot collide with the official tests.
+const SuggestedTestPrefix = "TestAISuggested"
+
+const suggestedHelperPrefix = "aiSuggested"
+
+// SuggestedTestCase is the outcome of one AI-suggested test case
+type SuggestedTestCase struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Output string `json:"output,omitempty"`
+}
+
+// SuggestTests asks the LLM for extra table-driven test cases, written in the
+// style of the challenge's own tests, that target inputs the official tests
+// are likely to miss for this particular submission.
+func (ai *AIService) SuggestTests(code string, challenge *models.Challenge) (string, error) {
+	if !ai.Enabled() {
+		return "", fmt.Errorf("AI features require an API key")
+	}
+
+	response, err := ai.complete(FeatureTests, PromptSuggestTests, PromptData{
+		Title:        challenge.Title,
+		Description:  challenge.Description,
+		TestFile:     challenge.TestFile,
+		Code:         code,
+		TestPackage:  testPackage(challenge.TestFile),
+		TestFunc:     SuggestedTestPrefix + "Cases",
+		HelperPrefix: suggestedHelperPrefix,
+	}, false /* expectJSON */)
+	if err != nil {
+		return "", err
+	}
+
+	testCode := stripCodeFence(response)
+	if err := checkSuggestedTests(testCode, testPackage(challenge.TestFile)); err != nil {
+		return "", fmt.Errorf("AI returned unusable tests: %v", err)
+	}
+	return testCode, nil
+}
+
+// checkSuggestedTests makes sure a suggested test file parses, imports only
+// the standard library, and only declares names that cannot clash with the
+// challenge's own files.
+func checkSuggestedTests(src, pkg string) error {
+	file, err := parser.ParseFile(token.NewFileSet(), SuggestedTestFile, src, 0)
+	if err != nil {
+		return err
+	}
+	if file.Name.Name != pkg {
+		return fmt.Errorf("package %s, want %s", file.Name.Name, pkg)
+	}
+
+	// The run has no go.sum entries for anything else, so it would fail to
+	// build with an error that says nothing about the suggestion
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return err
+		}
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			return fmt.Errorf("import %q is not in the standard library", path)
+		}
+	}
+
+	// The file scope leaves out init functions, which would run before the
+	// official tests, so go through the declarations instead
+	for _, decl := range file.Decls {
+		for _, name := range declaredNames(decl) {
+			if name == "_" {
+				continue
+			}
+			if !strings.HasPrefix(name, SuggestedTestPrefix) && !strings.HasPrefix(name, suggestedHelperPrefix) {
+				return fmt.Errorf("top-level name %s is not prefixed with %s or %s", name, SuggestedTestPrefix, suggestedHelperPrefix)
+			}
+		}
+	}
+	return nil
+}
+
+// declaredNames returns the package-level names a declaration introduces,
+// including init. Methods declare none.
+func declaredNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, ident := range s.Names {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// testPackage returns the package clause of a challenge's test file. Most
+// challenges use main, but some (challenge 7's challenge7) do not.
+func testPackage(testFile string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", testFile, parser.PackageClauseOnly)
+	if err != nil {
+		return "main"
+	}
+	return file.Name.Name
+}
+
+// ParseSuggestedTestResults reads `go test -v` output from a run of the
+// suggested tests and returns one result per case. Subtests are reported when
+// there are any; otherwise each suggested test function counts as a case.
+func ParseSuggestedTestResults(output string) []SuggestedTestCase {
+	var cases, subtests []SuggestedTestCase
+	messages := make(map[string][]string)
+	current := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "=== RUN"):
+			current = strings.TrimSpace(strings.TrimPrefix(trimmed, "=== RUN"))
+		case strings.HasPrefix(trimmed, "--- PASS: "+SuggestedTestPrefix), strings.HasPrefix(trimmed, "--- FAIL: "+SuggestedTestPrefix):
+			fields := strings.Fields(trimmed)
+			if len(fields) < 3 {
+				continue
+			}
+			c := SuggestedTestCase{Name: fields[2], Passed: fields[1] == "PASS:"}
+			if strings.Contains(c.Name, "/") {
+				subtests = append(subtests, c)
+			} else {
+				cases = append(cases, c)
+			}
+		case strings.Contains(trimmed, SuggestedTestFile+":"):
+			messages[current] = append(messages[current], trimmed)
+		}
+	}
+
+	if len(subtests) > 0 {
+		cases = subtests
+	}
+	for i := range cases {
+		cases[i].Output = strings.Join(messages[cases[i].Name], "\n")
+	}
+	return cases
+}
+
+// stripCodeFence removes a markdown code fence the model may have added anyway
+func stripCodeFence(response string) string {
+	response = strings.TrimSpace(response)
+	if !strings.HasPrefix(response, "```") {
+		return response
+	}
+	if i := strings.Index(response, "\n"); i >= 0 {
+		response = response[i+1:]
+	}
+	response = strings.TrimSuffix(strings.TrimSpace(response), "```")
+	return strings.TrimSpace(response) + "\n"
+}
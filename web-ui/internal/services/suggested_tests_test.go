@@ -0,0 +1,104 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSuggestedTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string // "" for accepted
+	}{
+		{
+			name: "standard library",
+			src: `package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAISuggestedCases(t *testing.T) { _ = strings.ToUpper("") }
+`,
+		},
+		{
+			name: "third-party import",
+			src: `package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAISuggestedCases(t *testing.T) { assert.True(t, true) }
+`,
+			wantErr: `import "github.com/stretchr/testify/assert" is not in the standard library`,
+		},
+		{
+			name: "golang.org/x import",
+			src: `package main
+
+import "golang.org/x/exp/slices"
+
+var aiSuggestedSorted = slices.IsSorted[[]int]
+`,
+			wantErr: `import "golang.org/x/exp/slices" is not in the standard library`,
+		},
+		{
+			name:    "unprefixed name",
+			src:     "package main\n\nfunc helper() {}\n",
+			wantErr: "top-level name helper is not prefixed",
+		},
+		{
+			name:    "init function",
+			src:     "package main\n\nfunc init() {}\n",
+			wantErr: "top-level name init is not prefixed",
+		},
+		{
+			name:    "TestMain",
+			src:     "package main\n\nimport \"testing\"\n\nfunc TestMain(m *testing.M) {}\n",
+			wantErr: "top-level name TestMain is not prefixed",
+		},
+		{
+			name:    "unprefixed type in a group",
+			src:     "package main\n\ntype (\n\taiSuggestedCase struct{}\n\tcase2 struct{}\n)\n",
+			wantErr: "top-level name case2 is not prefixed",
+		},
+		{
+			name: "prefixed names, blank and methods",
+			src: `package main
+
+import "testing"
+
+var _ = aiSuggestedInputs
+
+var aiSuggestedInputs, aiSuggestedWant = []int{1}, []int{2}
+
+type aiSuggestedCase struct{}
+
+func (aiSuggestedCase) run() {}
+
+func TestAISuggestedCases(t *testing.T) {}
+`,
+		},
+		{
+			name:    "wrong package",
+			src:     "package challenge7\n",
+			wantErr: "package challenge7, want main",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSuggestedTests(tt.src, "main")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("rejected: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"web-ui/internal/models"
+	"web-ui/internal/mutation"
+)
+
+const (
+	// maxGradedMutants caps the mutants a submission is run against, so a
+	// grade comes back in seconds; they are sampled evenly over the file
+	maxGradedMutants = 40
+	// gradingTestTimeout stops a mutant that loops forever; the tests fail
+	// on the timeout, which counts as catching it
+	gradingTestTimeout = "10s"
+)
+
+// GradeTests runs a submitted test file against a write-tests challenge's
+// code under test, then against mutants of it. The tests must pass against
+// the real code, and pass the challenge when they fail against at least
+// MinMutationScore percent of the mutants that build.
+func (es *ExecutionService) GradeTests(tests string, challenge *models.Challenge) ExecutionResult {
+	start := time.Now()
+	result := func(passed bool, output string, report *models.MutationReport) ExecutionResult {
+		return ExecutionResult{
+			Passed:      passed,
+			Output:      output,
+			ExecutionMs: time.Since(start).Milliseconds(),
+			Mutation:    report,
+		}
+	}
+
+	mutants, err := mutation.Generate(challenge.Subject)
+	if err != nil {
+		return result(false, fmt.Sprintf("Failed to read the code under test: %v", err), nil)
+	}
+	mutants = mutation.Sample(mutants, maxGradedMutants)
+
+	workers := runtime.NumCPU()
+	if workers > 4 {
+		workers = 4
+	}
+	if workers > len(mutants) {
+		workers = len(mutants)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Each worker gets its own module with the tests in it, so only the code
+	// under test is rewritten between runs and the build cache stays warm
+	dirs := make([]string, 0, workers)
+	defer func() {
+		for _, dir := range dirs {
+			os.RemoveAll(dir)
+		}
+	}()
+	for i := 0; i < workers; i++ {
+		dir, err := es.prepareTestModule(tests, challenge)
+		if err != nil {
+			return result(false, err.Error(), nil)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	output, passed := runTestModule(dirs[0], challenge.Subject)
+	if !passed {
+		if !TestsRan(output) {
+			return result(false, output, nil)
+		}
+		return result(false, "Your tests fail against the correct code under test. "+
+			"Every test must pass before mutants are tried.\n\n"+output, nil)
+	}
+
+	survived := make([]bool, len(mutants))
+	built := make([]bool, len(mutants))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for _, dir := range dirs {
+		wg.Add(1)
+		go func(dir string) {
+			defer wg.Done()
+			for i := range queue {
+				mutantOutput, mutantPassed := runTestModule(dir, mutants[i].Source)
+				survived[i] = mutantPassed
+				built[i] = mutantPassed || TestsRan(mutantOutput)
+			}
+		}(dir)
+	}
+	for i := range mutants {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	report := &models.MutationReport{
+		MinScore:  challenge.MinMutationScore,
+		Survivors: []mutation.Mutant{},
+	}
+	for i, m := range mutants {
+		if !built[i] {
+			continue
+		}
+		report.Total++
+		if survived[i] {
+			report.Survivors = append(report.Survivors, m)
+		} else {
+			report.Killed++
+		}
+	}
+	report.Score = 100
+	if report.Total > 0 {
+		report.Score = report.Killed * 100 / report.Total
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "\nMutation score: %d of %d mutants caught (%d%%, %d%% needed)\n",
+		report.Killed, report.Total, report.Score, report.MinScore)
+	for _, m := range report.Survivors {
+		fmt.Fprintf(&summary, "  survived: line %d: %s\n", m.Line, m.Description())
+	}
+	return result(report.Score >= report.MinScore, output+summary.String(), report)
+}
+
+// prepareTestModule creates a temporary module holding a submitted test file,
+// ready for runTestModule to add the code under test
+func (es *ExecutionService) prepareTestModule(tests string, challenge *models.Challenge) (string, error) {
+	dir, err := ioutil.TempDir("", "grade-tests")
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temporary directory: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "solution_test.go"), []byte(tests), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("Failed to write test file: %v", err)
+	}
+	if err := es.initGoModule(dir, challenge.ID); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("Failed to initialize Go module: %v", err)
+	}
+	if err := es.installDependencies(dir, tests, challenge.ID); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("Failed to install dependencies: %v", err)
+	}
+	return dir, nil
+}
+
+// runTestModule writes the code under test into dir and runs the tests
+func runTestModule(dir, code string) (string, bool) {
+	if err := ioutil.WriteFile(filepath.Join(dir, "solution.go"), []byte(code), 0644); err != nil {
+		return fmt.Sprintf("Failed to write code file: %v", err), false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "test", "-v", "-count=1", "-timeout", gradingTestTimeout)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return string(output), err == nil
+}
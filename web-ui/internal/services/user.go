@@ -43,8 +43,8 @@ func (us *UserService) LoadUserAttempts(username string, challenges models.Chall
 	}
 
 	// Scan all challenge directories for this user's submissions
-	for id := range challenges {
-		if us.hasUserSubmission(username, id) {
+	for id, challenge := range challenges {
+		if us.hasUserSubmission(username, challenge) {
 			userAttempt.AttemptedIDs[id] = true
 			// Calculate score based on test results
 			score := us.calculateScore(username, id)
@@ -60,11 +60,11 @@ func (us *UserService) LoadUserAttempts(username string, challenges models.Chall
 }
 
 // hasUserSubmission checks if a user has a submission for a challenge
-func (us *UserService) hasUserSubmission(username string, challengeID int) bool {
+func (us *UserService) hasUserSubmission(username string, challenge *models.Challenge) bool {
 	// Try different path formats to handle potential path issues
 	// Absolute path
-	submissionDir := filepath.Join("..", fmt.Sprintf("challenge-%d", challengeID), "submissions", username)
-	submissionFile := filepath.Join(submissionDir, "solution-template.go")
+	submissionDir := filepath.Join("..", fmt.Sprintf("challenge-%d", challenge.ID), "submissions", username)
+	submissionFile := filepath.Join(submissionDir, challenge.SubmissionFile())
 
 	// Check if the file exists
 	if _, err := os.Stat(submissionFile); err == nil {
@@ -72,7 +72,7 @@ func (us *UserService) hasUserSubmission(username string, challengeID int) bool
 	}
 
 	// Alternative path (direct from workspace root)
-	altSubmissionFile := filepath.Join(fmt.Sprintf("challenge-%d", challengeID), "submissions", username, "solution-template.go")
+	altSubmissionFile := filepath.Join(fmt.Sprintf("challenge-%d", challenge.ID), "submissions", username, challenge.SubmissionFile())
 
 	if _, err := os.Stat(altSubmissionFile); err == nil {
 		return true
@@ -82,21 +82,21 @@ func (us *UserService) hasUserSubmission(username string, challengeID int) bool
 }
 
 // GetExistingSolution returns the content of an existing solution file if it exists
-func (us *UserService) GetExistingSolution(username string, challengeID int) string {
+func (us *UserService) GetExistingSolution(username string, challenge *models.Challenge) string {
 	if username == "" {
 		return ""
 	}
 
 	// Try different path formats
 	// First try the relative path from web-ui
-	submissionFile := filepath.Join("..", fmt.Sprintf("challenge-%d", challengeID), "submissions", username, "solution-template.go")
+	submissionFile := filepath.Join("..", fmt.Sprintf("challenge-%d", challenge.ID), "submissions", username, challenge.SubmissionFile())
 	content, err := ioutil.ReadFile(submissionFile)
 	if err == nil {
 		return string(content)
 	}
 
 	// Try alternative path from root directory
-	altSubmissionFile := filepath.Join(fmt.Sprintf("challenge-%d", challengeID), "submissions", username, "solution-template.go")
+	altSubmissionFile := filepath.Join(fmt.Sprintf("challenge-%d", challenge.ID), "submissions", username, challenge.SubmissionFile())
 	content, err = ioutil.ReadFile(altSubmissionFile)
 	if err == nil {
 		return string(content)
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ChallengeDirPattern matches the directory of a classic challenge and
+// captures its number
+var ChallengeDirPattern = regexp.MustCompile(`challenge-(\d+)$`)
+
+// RepoPath turns a path relative to web-ui into one relative to the
+// repository root, which is what editors and GitHub annotations expect
+func RepoPath(path string) string {
+	if rel := strings.TrimPrefix(filepath.ToSlash(path), "../"); rel != filepath.ToSlash(path) {
+		return rel
+	}
+	return filepath.ToSlash(filepath.Join("web-ui", path))
+}
+
+// ReadFile returns the contents of a file, or "" if it cannot be read
+func ReadFile(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"embed"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -18,6 +19,9 @@ import (
 var content embed.FS
 
 func main() {
+	offline := flag.Bool("offline", false, "never call GitHub; serve stars and sponsors from the on-disk cache only")
+	flag.Parse()
+
 	// Load environment variables from .env file
 	loadEnvFile()
 
@@ -28,6 +32,17 @@ func main() {
 	executionService := services.NewExecutionService()
 	packageService := services.NewPackageService()
 	aiService := services.NewAIService()
+	hintService := services.NewHintService(aiService)
+
+	// GitHub stars and sponsors are cached on disk and refreshed in the
+	// background, so startup never waits on the network
+	externalData := services.NewExternalData(services.DefaultExternalDataCacheDir)
+	externalData.SetOffline(*offline)
+	githubData := services.NewGitHubData(externalData, services.NewGitHubFetcher())
+	packageService.UseGitHubData(githubData)
+	if *offline {
+		log.Println("Offline mode: using cached GitHub data only")
+	}
 
 	// Load data
 	log.Println("Loading challenges...")
@@ -45,6 +60,8 @@ func main() {
 		log.Fatalf("Failed to load packages: %v", err)
 	}
 
+	checkAIConfig(aiService)
+
 	// Initialize server
 	srv := server.NewServer(
 		content,
@@ -54,6 +71,8 @@ func main() {
 		executionService,
 		packageService,
 		aiService,
+		hintService,
+		githubData,
 	)
 
 	// Setup routes
@@ -72,6 +91,31 @@ func main() {
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), mux))
 }
 
+// checkAIConfig logs problems with the AI configuration and, when
+// AI_STARTUP_PING=true, checks in the background that the provider accepts
+// the key. AI problems never stop the server: AI features just stay off.
+func checkAIConfig(aiService *services.AIService) {
+	status := aiService.Status()
+	for _, problem := range status.Problems {
+		log.Printf("AI config: %s", problem)
+	}
+	if !status.Enabled {
+		log.Println("AI features disabled: no usable API key")
+		return
+	}
+	log.Printf("AI provider %s, models %v", status.Provider, status.FeatureModels)
+
+	if strings.EqualFold(os.Getenv("AI_STARTUP_PING"), "true") {
+		go func() {
+			if result := aiService.Ping(0); result.OK {
+				log.Printf("AI ping ok (%d ms)", result.LatencyMS)
+			} else {
+				log.Printf("AI ping failed: %s", result.Error)
+			}
+		}()
+	}
+}
+
 // loadEnvFile loads environment variables from a .env file
 func loadEnvFile() {
 	// Try to load .env from current directory and parent directories
@@ -581,100 +581,152 @@ function initLearningMaterials(containerId, challengeId) {
     }
 }
 
-// Initialize hints system
-function initializeHints(hintsText) {
-    if (!hintsText) return;
-    
-    try {
-        const hints = JSON.parse(hintsText);
-        const container = document.getElementById('hints-container');
-        const showHintBtn = document.getElementById('show-hint-btn');
-        const resetHintsBtn = document.getElementById('reset-hints-btn');
-        const progressSpan = document.getElementById('hints-progress');
-        const totalHintsSpan = document.getElementById('total-hints');
-        
-        if (!container || !showHintBtn) return;
-        
-        let currentHintIndex = 0;
-        const storageKey = `hints_progress_${window.location.pathname.split('/').pop()}`;
-        
-        // Load saved progress
-        const savedProgress = localStorage.getItem(storageKey);
-        if (savedProgress) {
-            currentHintIndex = parseInt(savedProgress);
-        }
-        
-        // Update total hints count
-        totalHintsSpan.textContent = hints.length;
-        
-        // Show hints up to current index
-        for (let i = 0; i < currentHintIndex; i++) {
-            if (hints[i]) {
-                this.showHint(hints[i], i + 1);
-            }
-        }
-        
-        // Update progress display
-        this.updateHintsProgress();
-        
-        // Show hint button click handler
-        showHintBtn.addEventListener('click', () => {
-            if (currentHintIndex < hints.length) {
-                this.showHint(hints[currentHintIndex], currentHintIndex + 1);
-                currentHintIndex++;
-                localStorage.setItem(storageKey, currentHintIndex.toString());
-                this.updateHintsProgress();
-                
-                if (currentHintIndex >= hints.length) {
-                    showHintBtn.classList.add('d-none');
-                    resetHintsBtn.classList.remove('d-none');
-                }
-            }
+// Hint ladder shared by the classic, package and release challenge pages.
+// Authored hints come first; once they run out the server escalates to AI hints
+// that look at the current code and the last test output. The server remembers
+// how far the user got, so revealed hints survive a reload.
+function initHintLadder(options) {
+    const container = document.getElementById('hints-container');
+    const showHintBtn = document.getElementById('show-hint-btn');
+    const resetHintsBtn = document.getElementById('reset-hints-btn');
+    const progressSpan = document.getElementById('hints-progress');
+    const totalHintsSpan = document.getElementById('total-hints');
+
+    if (!container || !showHintBtn || !options || !options.key) return;
+
+    const getCode = options.getCode || (() => '');
+    const getTestOutput = options.getTestOutput || (() => '');
+    const getUsername = options.getUsername || (() => '');
+
+    let total = 0;
+    let authored = 0;
+    let shown = 0;
+
+    function renderStep(step) {
+        const hintDiv = document.createElement('div');
+        hintDiv.className = `alert ${step.source === 'ai' ? 'alert-primary' : 'alert-info'} hint-item mb-3`;
+        hintDiv.style.animation = 'slideIn 0.3s ease-in-out';
+        const badge = step.source === 'ai'
+            ? '<span class="badge bg-primary me-2"><i class="bi bi-robot me-1"></i>AI</span>'
+            : `<span class="badge bg-warning text-dark me-2">Hint ${step.level}</span>`;
+        hintDiv.innerHTML = `<div class="mb-2">${badge}<strong></strong></div><div class="markdown-content"></div>`;
+        hintDiv.querySelector('strong').textContent = step.title || '';
+        hintDiv.querySelector('.markdown-content').innerHTML = step.html;
+        container.appendChild(hintDiv);
+
+        hintDiv.querySelectorAll('pre code').forEach((el) => {
+            if (window.hljs) hljs.highlightElement(el);
         });
-        
-        // Reset hints button click handler
+        return hintDiv;
+    }
+
+    function updateHintsProgress() {
+        if (progressSpan) progressSpan.textContent = shown;
+        if (totalHintsSpan) totalHintsSpan.textContent = total;
+        showHintBtn.classList.toggle('d-none', shown >= total);
+        if (resetHintsBtn) resetHintsBtn.classList.toggle('d-none', shown === 0);
+        showHintBtn.innerHTML = shown >= authored
+            ? '<i class="bi bi-robot me-2"></i>Ask AI for a Hint'
+            : '<i class="bi bi-lightbulb me-2"></i>Show Next Hint';
+    }
+
+    const query = `key=${encodeURIComponent(options.key)}&username=${encodeURIComponent(getUsername() || '')}`;
+    fetch(`/api/hints?${query}`)
+        .then(response => response.json())
+        .then(ladder => {
+            total = ladder.total;
+            authored = ladder.authored;
+            (ladder.revealed || []).forEach(renderStep);
+            shown = (ladder.revealed || []).length;
+            updateHintsProgress();
+        })
+        .catch(error => console.error('Error loading hints:', error));
+
+    showHintBtn.addEventListener('click', () => {
+        if (shown >= total) return;
+        showHintBtn.disabled = true;
+
+        fetch('/api/hints', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                key: options.key,
+                username: getUsername() || '',
+                level: shown + 1,
+                code: getCode(),
+                testOutput: getTestOutput()
+            })
+        })
+        .then(response => {
+            if (!response.ok) return response.text().then(text => { throw new Error(text); });
+            return response.json();
+        })
+        .then(data => {
+            renderStep(data.step).scrollIntoView({ behavior: 'smooth', block: 'nearest' });
+            shown = data.step.level;
+            total = data.total;
+            updateHintsProgress();
+        })
+        .catch(error => console.error('Error revealing hint:', error))
+        .finally(() => {
+            showHintBtn.disabled = false;
+        });
+    });
+
+    // Reset only clears the view. Hints already revealed are replayed by the
+    // server, so they still count as used.
+    if (resetHintsBtn) {
         resetHintsBtn.addEventListener('click', () => {
-            if (confirm('Are you sure you want to reset all hints?')) {
-                container.innerHTML = '';
-                currentHintIndex = 0;
-                localStorage.removeItem(storageKey);
-                this.updateHintsProgress();
-                showHintBtn.classList.remove('d-none');
-                resetHintsBtn.classList.add('d-none');
-            }
+            container.innerHTML = '';
+            shown = 0;
+            updateHintsProgress();
         });
-        
-        // Helper function to show a hint
-        this.showHint = function(hint, hintNumber) {
-            const hintDiv = document.createElement('div');
-            hintDiv.className = 'hint-item alert alert-warning';
-            hintDiv.innerHTML = `
-                <div class="d-flex justify-content-between align-items-start">
-                    <div class="flex-grow-1">
-                        <h6 class="alert-heading">
-                            <i class="bi bi-lightbulb me-2"></i>Hint ${hintNumber}
-                        </h6>
-                        <div class="markdown-content">${marked.parse(hint)}</div>
-                    </div>
-                    <span class="badge bg-warning text-dark ms-2">${hintNumber}</span>
-                </div>
-            `;
-            container.appendChild(hintDiv);
-            
-            // Apply syntax highlighting to code blocks in the hint
-            setTimeout(() => {
-                hintDiv.querySelectorAll('pre code').forEach((el) => {
-                    hljs.highlightElement(el);
-                });
-            }, 100);
-        };
-        
-        // Helper function to update progress display
-        this.updateHintsProgress = function() {
-            progressSpan.textContent = currentHintIndex;
-        };
-        
-    } catch (error) {
-        console.error('Error initializing hints:', error);
     }
-} 
\ No newline at end of file
+}
+
+// Shade the lines of the solution editor that the tests ran (green), did not
+// run (red) or only partly ran (yellow), from the coverage in a run result.
+// Passing no coverage clears the shading.
+function applyCoverage(editor, coverage) {
+    const session = editor.session;
+    (editor.coverageMarkers || []).forEach(id => session.removeMarker(id));
+    editor.coverageMarkers = [];
+    if (!coverage) return;
+
+    const Range = ace.require('ace/range').Range;
+    const uncovered = new Set(coverage.uncoveredLines);
+    const covered = new Set(coverage.coveredLines);
+    const mark = (line, cls) => {
+        editor.coverageMarkers.push(session.addMarker(new Range(line - 1, 0, line - 1, 1), cls, 'fullLine'));
+    };
+    uncovered.forEach(line => mark(line, covered.has(line) ? 'coverage-partial' : 'coverage-uncovered'));
+    covered.forEach(line => {
+        if (!uncovered.has(line)) mark(line, 'coverage-covered');
+    });
+
+    // Edits move lines around, so the shading only holds until the next change
+    session.once('change', () => applyCoverage(editor, null));
+}
+
+// One-line coverage summary for the results pane
+function coverageSummaryHtml(coverage) {
+    if (!coverage) return '';
+    const missed = coverage.statements - coverage.covered;
+    return `<div class="alert alert-light border mb-3 py-2">
+        <i class="bi bi-bar-chart-line me-1"></i>
+        The tests ran <strong>${coverage.percent}%</strong> of the statements in your solution (${coverage.covered} of ${coverage.statements}).
+        ${missed > 0 ? '<span class="text-muted">Lines shaded red in the editor never ran; yellow lines only partly ran.</span>' : ''}
+    </div>`;
+}
+
+// hiddenTestsHtml summarises a challenge's hidden tests, which only run on submit
+function hiddenTestsHtml(hidden) {
+    if (!hidden) return '';
+    const passed = !hidden.message && hidden.total > 0 && hidden.passed === hidden.total;
+    const detail = hidden.message || `${hidden.passed} of ${hidden.total} hidden test cases passed.`;
+    return `<div class="alert ${passed ? 'alert-success' : 'alert-danger'} mb-3 py-2">
+        <i class="bi bi-eye-slash me-1"></i>
+        <strong>Hidden tests:</strong> ${escapeHtml(detail)}
+        ${passed ? '' : '<span class="text-muted">These cases are not shown; check your solution against the whole problem statement, not just the visible tests.</span>'}
+    </div>`;
+}